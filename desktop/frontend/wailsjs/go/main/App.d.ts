@@ -3,6 +3,10 @@
 import {auth} from '../models';
 import {company} from '../models';
 
+export function AddAccountReconciliationItem(arg1:string,arg2:number,arg3:Record<string, any>):Promise<Record<string, any>>;
+
+export function AddCheckStockRange(arg1:string,arg2:string,arg3:number,arg4:number,arg5:string,arg6:string):Promise<Record<string, any>>;
+
 export function AnalyzeGLBalancesByYear(arg1:string,arg2:string):Promise<Record<string, any>>;
 
 export function AuditBankReconciliation(arg1:string):Promise<Record<string, any>>;
@@ -11,6 +15,8 @@ export function AuditCheckBatches(arg1:string):Promise<Record<string, any>>;
 
 export function AuditCheckGLMatching(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Record<string, any>>;
 
+export function AuditCheckSequence(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Record<string, any>>;
+
 export function AuditDuplicateCIDCHEC(arg1:string):Promise<Record<string, any>>;
 
 export function AuditPayeeCIDVerification(arg1:string):Promise<Record<string, any>>;
@@ -19,36 +25,126 @@ export function AuditSingleBankAccount(arg1:string,arg2:string):Promise<Record<s
 
 export function AuditVoidChecks(arg1:string):Promise<Record<string, any>>;
 
+export function AutoMatchInterbankTransfers(arg1:string,arg2:number,arg3:number):Promise<Record<string, any>>;
+
+export function CheckAccountDeactivation(arg1:string,arg2:string):Promise<Record<string, any>>;
+
 export function CheckGLPeriodFields(arg1:string):Promise<Record<string, any>>;
 
 export function CheckOwnerStatementFiles(arg1:string):Promise<Record<string, any>>;
 
 export function ClearMatchesAndRerun(arg1:string,arg2:string,arg3:Record<string, any>):Promise<Record<string, any>>;
 
+export function CloseAFE(arg1:string,arg2:number,arg3:Record<string, any>,arg4:string):Promise<Record<string, any>>;
+
 export function CloseOLEConnection():Promise<Record<string, any>>;
 
 export function CommitReconciliation(arg1:string,arg2:string):Promise<Record<string, any>>;
 
+export function CopyBudgetFromActuals(arg1:string,arg2:number,arg3:Record<string, any>):Promise<Record<string, any>>;
+
 export function CreateUser(arg1:string,arg2:string,arg3:string,arg4:number):Promise<auth.User>;
 
+export function DeactivateAccount(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
+
+export function DeleteAFE(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function DeleteAccount(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
+
+export function DeleteAccountReconciliationItem(arg1:string,arg2:number):Promise<Record<string, any>>;
+
 export function DeleteBankStatement(arg1:string,arg2:string):Promise<void>;
 
+export function DeleteBudgetVersion(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function DeleteCheckStockRange(arg1:number):Promise<Record<string, any>>;
+
+export function DeleteConsolidationGroup(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function DeleteJournalEntry(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function DeleteJournalImportProfile(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function DeleteJournalTemplate(arg1:string,arg2:number):Promise<Record<string, any>>;
+
 export function DeleteReconciliationDraft(arg1:string,arg2:string):Promise<Record<string, any>>;
 
+export function DeleteStandardChart(arg1:string,arg2:string):Promise<Record<string, any>>;
+
+export function DeleteStatementLayout(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function DetectInterbankTransfers(arg1:string,arg2:number):Promise<Record<string, any>>;
+
 export function ExamineOwnerStatementStructure(arg1:string,arg2:string):Promise<Record<string, any>>;
 
+export function ExportAFEReport(arg1:string,arg2:number,arg3:Record<string, any>,arg4:string):Promise<string>;
+
+export function ExportAFESummary(arg1:string,arg2:Record<string, any>,arg3:string):Promise<string>;
+
+export function ExportAccountReconciliation(arg1:string,arg2:number,arg3:string):Promise<string>;
+
+export function ExportBudgetVariance(arg1:string,arg2:number,arg3:string,arg4:string,arg5:Record<string, any>,arg6:string):Promise<string>;
+
+export function ExportCashPosition(arg1:string,arg2:number,arg3:string):Promise<string>;
+
+export function ExportChartOfAccounts(arg1:string,arg2:string,arg3:boolean,arg4:string):Promise<string>;
+
+export function ExportConsolidatedFinancialStatement(arg1:string,arg2:number,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:number,arg9:string):Promise<string>;
+
+export function ExportConsolidatedTrialBalance(arg1:string,arg2:number,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:boolean,arg9:string):Promise<string>;
+
+export function ExportFinancialStatement(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:number,arg8:string):Promise<string>;
+
+export function ExportGLDetail(arg1:string,arg2:Record<string, any>,arg3:string):Promise<string>;
+
+export function ExportMappedFinancialStatement(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:number,arg9:string):Promise<string>;
+
+export function ExportMappedTrialBalance(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:boolean,arg9:string):Promise<string>;
+
 export function ExportNetDistribution(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function ExportSegmentReport(arg1:string,arg2:Record<string, any>,arg3:string):Promise<string>;
+
+export function ExportTrialBalance(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:boolean,arg8:string):Promise<string>;
+
+export function ExportYearEndCloseBatch(arg1:number):Promise<string>;
+
 export function FollowBatchNumber(arg1:string,arg2:string):Promise<Record<string, any>>;
 
 export function GenerateChartOfAccountsPDF(arg1:string,arg2:string,arg3:boolean):Promise<string>;
 
+export function GenerateJournalEntriesFromTemplates(arg1:string,arg2:string,arg3:Record<string, any>):Promise<Record<string, any>>;
+
 export function GenerateOwnerStatementPDF(arg1:string,arg2:string):Promise<string>;
 
+export function GetAFE(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function GetAFECostCategories(arg1:string):Promise<Record<string, any>>;
+
+export function GetAFEReport(arg1:string,arg2:number,arg3:Record<string, any>):Promise<Record<string, any>>;
+
+export function GetAFESummary(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
+export function GetAFEs(arg1:string,arg2:boolean):Promise<Record<string, any>>;
+
 export function GetAPIKey(arg1:string):Promise<string>;
 
 export function GetAccountBalance(arg1:string,arg2:string):Promise<number>;
 
+export function GetAccountBalanceAsOf(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
+
+export function GetAccountBalances(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function GetAccountMappings(arg1:string,arg2:string):Promise<Record<string, any>>;
+
+export function GetAccountReconciliation(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function GetAccountReconciliationLog(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function GetAccountReconciliationStatus(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
+
+export function GetAccountingPeriods(arg1:number):Promise<Record<string, any>>;
+
 export function GetAllRoles():Promise<Array<auth.Role>>;
 
 export function GetAllUsers():Promise<Array<auth.User>>;
@@ -57,16 +153,38 @@ export function GetAuthState():Promise<Record<string, any>>;
 
 export function GetBalanceHistory(arg1:string,arg2:string,arg3:number):Promise<Array<Record<string, any>>>;
 
+export function GetBalanceRefreshSettings(arg1:string):Promise<Record<string, any>>;
+
+export function GetBalanceRefreshStatus():Promise<Record<string, any>>;
+
 export function GetBankAccounts(arg1:string):Promise<Array<Record<string, any>>>;
 
 export function GetBankAccountsForAudit(arg1:string):Promise<Array<Record<string, any>>>;
 
 export function GetBankTransactions(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
 
+export function GetBudgetLines(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function GetBudgetVariance(arg1:string,arg2:number,arg3:string,arg4:string,arg5:Record<string, any>):Promise<Record<string, any>>;
+
+export function GetBudgetVersions(arg1:string,arg2:string):Promise<Record<string, any>>;
+
 export function GetCachedBalances(arg1:string):Promise<Array<Record<string, any>>>;
 
+export function GetCashAccountSettings(arg1:string):Promise<Record<string, any>>;
+
+export function GetCashPosition(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function GetCashPositionHistory(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Record<string, any>>;
+
 export function GetChartOfAccounts(arg1:string,arg2:string,arg3:boolean):Promise<Record<string, any>>;
 
+export function GetChartOfAccountsAuditLog(arg1:string,arg2:string):Promise<Record<string, any>>;
+
+export function GetCheckStockRanges(arg1:string,arg2:string):Promise<Record<string, any>>;
+
+export function GetClosingChecklist(arg1:string):Promise<Record<string, any>>;
+
 export function GetClosingStatus(arg1:string):Promise<string>;
 
 export function GetCompanies():Promise<Array<company.Company>>;
@@ -77,6 +195,14 @@ export function GetCompanyList():Promise<Array<Record<string, any>>>;
 
 export function GetConfig():Promise<Record<string, any>>;
 
+export function GetConsolidatedFinancialStatement(arg1:string,arg2:number,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:number):Promise<Record<string, any>>;
+
+export function GetConsolidatedTrialBalance(arg1:string,arg2:number,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:boolean):Promise<Record<string, any>>;
+
+export function GetConsolidationGroup(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function GetConsolidationGroups(arg1:string):Promise<Record<string, any>>;
+
 export function GetDBFFiles(arg1:string):Promise<Array<string>>;
 
 export function GetDBFTableData(arg1:string,arg2:string):Promise<Record<string, any>>;
@@ -85,44 +211,108 @@ export function GetDBFTableDataPaged(arg1:string,arg2:string,arg3:number,arg4:nu
 
 export function GetDashboardData(arg1:string):Promise<Record<string, any>>;
 
+export function GetDashboardKPIs(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
 export function GetDebugMode():Promise<boolean>;
 
+export function GetDueJournalTemplates(arg1:string,arg2:string):Promise<Record<string, any>>;
+
+export function GetFinancialStatement(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:number):Promise<Record<string, any>>;
+
+export function GetFiscalCalendar(arg1:number):Promise<Record<string, any>>;
+
+export function GetGLDetail(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
+export function GetGLLineSource(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
+
+export function GetInterbankTransfers(arg1:string,arg2:string):Promise<Record<string, any>>;
+
+export function GetJournalEntries(arg1:string,arg2:string):Promise<Record<string, any>>;
+
+export function GetJournalEntry(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function GetJournalImportProfiles(arg1:string):Promise<Record<string, any>>;
+
+export function GetJournalTemplates(arg1:string):Promise<Record<string, any>>;
+
 export function GetLastReconciliation(arg1:string,arg2:string):Promise<Record<string, any>>;
 
 export function GetLogFilePath():Promise<string>;
 
+export function GetMappedFinancialStatement(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:number):Promise<Record<string, any>>;
+
+export function GetMappedTrialBalance(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:boolean):Promise<Record<string, any>>;
+
 export function GetMatchedTransactions(arg1:string,arg2:string):Promise<Record<string, any>>;
 
 export function GetNetDistributionStatus(arg1:string,arg2:string):Promise<Record<string, any>>;
 
 export function GetOutstandingChecks(arg1:string,arg2:string):Promise<Record<string, any>>;
 
+export function GetOutstandingChecksAsOf(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
+
 export function GetOwnerStatementData(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
 
 export function GetOwnerStatementsList(arg1:string):Promise<Array<Record<string, any>>>;
 
 export function GetOwnersList(arg1:string,arg2:string):Promise<Array<Record<string, any>>>;
 
+export function GetPaidItemExceptions(arg1:string,arg2:string,arg3:boolean):Promise<Record<string, any>>;
+
+export function GetPeriodAuditLog(arg1:string):Promise<Record<string, any>>;
+
+export function GetPeriodEndBalances(arg1:string,arg2:string,arg3:number):Promise<Record<string, any>>;
+
 export function GetPlatform():Promise<Record<string, any>>;
 
 export function GetRecentBankStatements(arg1:string,arg2:string):Promise<Array<Record<string, any>>>;
 
+export function GetReconciledAccounts(arg1:string):Promise<Record<string, any>>;
+
 export function GetReconciliationDraft(arg1:string,arg2:string):Promise<Record<string, any>>;
 
 export function GetReconciliationHistory(arg1:string,arg2:string):Promise<Record<string, any>>;
 
+export function GetSegmentDrillDown(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
+export function GetSegmentReport(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
+export function GetStandardChart(arg1:string,arg2:string):Promise<Record<string, any>>;
+
+export function GetStandardCharts(arg1:string):Promise<Record<string, any>>;
+
+export function GetStatementLayouts(arg1:string,arg2:string):Promise<Record<string, any>>;
+
 export function GetTableList(arg1:string):Promise<Record<string, any>>;
 
+export function GetTrialBalance(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:boolean):Promise<Record<string, any>>;
+
 export function GetVFPCompany():Promise<Record<string, any>>;
 
 export function GetVFPFormList():Promise<Array<Record<string, string>>>;
 
 export function GetVFPSettings():Promise<Record<string, any>>;
 
+export function GetYearEndCloses():Promise<Record<string, any>>;
+
+export function GetYearEndSettings():Promise<Record<string, any>>;
+
 export function Greet(arg1:string):Promise<string>;
 
+export function HardClosePeriod(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<Record<string, any>>;
+
 export function ImportBankStatement(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
 
+export function ImportBudgetCSV(arg1:string,arg2:number,arg3:string,arg4:boolean):Promise<Record<string, any>>;
+
+export function ImportChartOfAccounts(arg1:string,arg2:string,arg3:string,arg4:Record<string, any>):Promise<Record<string, any>>;
+
+export function ImportJournalEntries(arg1:string,arg2:Record<string, any>,arg3:boolean):Promise<Record<string, any>>;
+
+export function ImportPaidItems(arg1:string,arg2:string,arg3:string,arg4:string,arg5:boolean):Promise<Record<string, any>>;
+
+export function ImportStandardChart(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<Record<string, any>>;
+
 export function InitializeCompanyDatabase(arg1:string):Promise<void>;
 
 export function InitializeLogging(arg1:boolean):Promise<Record<string, any>>;
@@ -139,30 +329,94 @@ export function Logout(arg1:string):Promise<void>;
 
 export function ManualMatchTransaction(arg1:number,arg2:string,arg3:number):Promise<Record<string, any>>;
 
+export function MatchInterbankTransfer(arg1:string,arg2:number,arg3:number):Promise<Record<string, any>>;
+
+export function MergeAccounts(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Record<string, any>>;
+
 export function MigrateReconciliationData(arg1:string):Promise<Record<string, any>>;
 
+export function PostJournalEntry(arg1:string,arg2:number):Promise<Record<string, any>>;
+
 export function PreloadOLEConnection(arg1:string):Promise<Record<string, any>>;
 
+export function PrepareAccountReconciliation(arg1:string,arg2:number,arg3:string):Promise<Record<string, any>>;
+
+export function PreviewAccountMerge(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
+
+export function PreviewYearEndClose(arg1:number,arg2:string):Promise<Record<string, any>>;
+
+export function PullAccountReconciliationGLItems(arg1:string,arg2:number,arg3:string,arg4:string):Promise<Record<string, any>>;
+
+export function ReactivateAccount(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
+
 export function RefreshAccountBalance(arg1:string,arg2:string):Promise<Record<string, any>>;
 
 export function RefreshAllBalances(arg1:string):Promise<Record<string, any>>;
 
+export function RefreshPeriodBalances(arg1:string):Promise<Record<string, any>>;
+
 export function Register(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Record<string, any>>;
 
+export function RemoveReconciledAccount(arg1:string,arg2:string):Promise<Record<string, any>>;
+
+export function ReopenAFE(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function ReopenAccountReconciliation(arg1:string,arg2:number,arg3:string):Promise<Record<string, any>>;
+
 export function ReopenPeriod(arg1:string,arg2:string):Promise<void>;
 
+export function ResolvePaidItemException(arg1:number,arg2:string):Promise<Record<string, any>>;
+
 export function RetryMatching(arg1:string,arg2:string,arg3:number):Promise<Record<string, any>>;
 
+export function ReverseYearEndClose(arg1:number,arg2:string):Promise<Record<string, any>>;
+
+export function ReviewAccountReconciliation(arg1:string,arg2:number,arg3:string):Promise<Record<string, any>>;
+
 export function RunClosingProcess(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<Record<string, any>>;
 
 export function RunMatching(arg1:string,arg2:string,arg3:Record<string, any>):Promise<Record<string, any>>;
 
 export function RunNetDistribution(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<Record<string, any>>;
 
+export function RunYearEndClose(arg1:number,arg2:string,arg3:string):Promise<Record<string, any>>;
+
+export function SaveAFE(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
+export function SaveAccount(arg1:string,arg2:Record<string, any>,arg3:boolean):Promise<Record<string, any>>;
+
+export function SaveAccountMappings(arg1:string,arg2:string,arg3:Record<string, any>):Promise<Record<string, any>>;
+
+export function SaveAccountReconciliationNotes(arg1:string,arg2:number,arg3:string):Promise<Record<string, any>>;
+
+export function SaveBalanceRefreshSettings(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
+export function SaveBudgetLines(arg1:string,arg2:number,arg3:Record<string, any>):Promise<Record<string, any>>;
+
+export function SaveBudgetVersion(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
+export function SaveCashAccountSettings(arg1:string,arg2:string,arg3:number,arg4:boolean):Promise<Record<string, any>>;
+
+export function SaveConsolidationGroup(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
+export function SaveFiscalCalendar(arg1:Record<string, any>):Promise<Record<string, any>>;
+
+export function SaveJournalEntry(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
+export function SaveJournalImportProfile(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
+export function SaveJournalTemplate(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
+export function SaveReconciledAccount(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
 export function SaveReconciliationDraft(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
 
+export function SaveStatementLayout(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
 export function SaveVFPSettings(arg1:string,arg2:number,arg3:boolean,arg4:number):Promise<void>;
 
+export function SaveYearEndSettings(arg1:string):Promise<Record<string, any>>;
+
 export function SearchDBFTable(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
 
 export function SelectDataFolder():Promise<string>;
@@ -175,6 +429,10 @@ export function SetDebugMode(arg1:boolean):Promise<Record<string, any>>;
 
 export function SetOLEIdleTimeout(arg1:number):Promise<Record<string, any>>;
 
+export function StartAccountReconciliation(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Record<string, any>>;
+
+export function SuggestAccountMappings(arg1:string,arg2:string):Promise<Record<string, any>>;
+
 export function SyncVFPCompany():Promise<Record<string, any>>;
 
 export function TestAPIKey(arg1:string,arg2:string):Promise<boolean>;
@@ -187,10 +445,16 @@ export function TestOLEConnection():Promise<Record<string, any>>;
 
 export function TestVFPConnection():Promise<Record<string, any>>;
 
+export function UnmatchInterbankTransfer(arg1:string,arg2:number):Promise<Record<string, any>>;
+
 export function UnmatchTransaction(arg1:number):Promise<Record<string, any>>;
 
+export function UpdateAccountReconciliationItem(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
 export function UpdateBatchFields(arg1:string,arg2:string,arg3:Record<string, string>,arg4:string,arg5:Record<string, boolean>):Promise<Record<string, any>>;
 
+export function UpdateCheckStockRangeStatus(arg1:number,arg2:string,arg3:string):Promise<Record<string, any>>;
+
 export function UpdateCompanyInfo(arg1:string):Promise<Record<string, any>>;
 
 export function UpdateDBFRecord(arg1:string,arg2:string,arg3:number,arg4:number,arg5:string):Promise<void>;
@@ -201,4 +465,8 @@ export function UpdateUserStatus(arg1:number,arg2:boolean):Promise<void>;
 
 export function ValidateGLBalances(arg1:string,arg2:string):Promise<Record<string, any>>;
 
+export function ValidateJournalEntry(arg1:string,arg2:number):Promise<Record<string, any>>;
+
+export function ValidateJournalImport(arg1:string,arg2:Record<string, any>):Promise<Record<string, any>>;
+
 export function ValidateSession(arg1:string,arg2:string):Promise<auth.User>;
@@ -2,6 +2,14 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddAccountReconciliationItem(arg1, arg2, arg3) {
+  return window['go']['main']['App']['AddAccountReconciliationItem'](arg1, arg2, arg3);
+}
+
+export function AddCheckStockRange(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['AddCheckStockRange'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
 export function AnalyzeGLBalancesByYear(arg1, arg2) {
   return window['go']['main']['App']['AnalyzeGLBalancesByYear'](arg1, arg2);
 }
@@ -18,6 +26,10 @@ export function AuditCheckGLMatching(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['AuditCheckGLMatching'](arg1, arg2, arg3, arg4);
 }
 
+export function AuditCheckSequence(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['AuditCheckSequence'](arg1, arg2, arg3, arg4);
+}
+
 export function AuditDuplicateCIDCHEC(arg1) {
   return window['go']['main']['App']['AuditDuplicateCIDCHEC'](arg1);
 }
@@ -34,6 +46,14 @@ export function AuditVoidChecks(arg1) {
   return window['go']['main']['App']['AuditVoidChecks'](arg1);
 }
 
+export function AutoMatchInterbankTransfers(arg1, arg2, arg3) {
+  return window['go']['main']['App']['AutoMatchInterbankTransfers'](arg1, arg2, arg3);
+}
+
+export function CheckAccountDeactivation(arg1, arg2) {
+  return window['go']['main']['App']['CheckAccountDeactivation'](arg1, arg2);
+}
+
 export function CheckGLPeriodFields(arg1) {
   return window['go']['main']['App']['CheckGLPeriodFields'](arg1);
 }
@@ -46,6 +66,10 @@ export function ClearMatchesAndRerun(arg1, arg2, arg3) {
   return window['go']['main']['App']['ClearMatchesAndRerun'](arg1, arg2, arg3);
 }
 
+export function CloseAFE(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['CloseAFE'](arg1, arg2, arg3, arg4);
+}
+
 export function CloseOLEConnection() {
   return window['go']['main']['App']['CloseOLEConnection']();
 }
@@ -54,26 +78,142 @@ export function CommitReconciliation(arg1, arg2) {
   return window['go']['main']['App']['CommitReconciliation'](arg1, arg2);
 }
 
+export function CopyBudgetFromActuals(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CopyBudgetFromActuals'](arg1, arg2, arg3);
+}
+
 export function CreateUser(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['CreateUser'](arg1, arg2, arg3, arg4);
 }
 
+export function DeactivateAccount(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeactivateAccount'](arg1, arg2, arg3);
+}
+
+export function DeleteAFE(arg1, arg2) {
+  return window['go']['main']['App']['DeleteAFE'](arg1, arg2);
+}
+
+export function DeleteAccount(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeleteAccount'](arg1, arg2, arg3);
+}
+
+export function DeleteAccountReconciliationItem(arg1, arg2) {
+  return window['go']['main']['App']['DeleteAccountReconciliationItem'](arg1, arg2);
+}
+
 export function DeleteBankStatement(arg1, arg2) {
   return window['go']['main']['App']['DeleteBankStatement'](arg1, arg2);
 }
 
+export function DeleteBudgetVersion(arg1, arg2) {
+  return window['go']['main']['App']['DeleteBudgetVersion'](arg1, arg2);
+}
+
+export function DeleteCheckStockRange(arg1) {
+  return window['go']['main']['App']['DeleteCheckStockRange'](arg1);
+}
+
+export function DeleteConsolidationGroup(arg1, arg2) {
+  return window['go']['main']['App']['DeleteConsolidationGroup'](arg1, arg2);
+}
+
+export function DeleteJournalEntry(arg1, arg2) {
+  return window['go']['main']['App']['DeleteJournalEntry'](arg1, arg2);
+}
+
+export function DeleteJournalImportProfile(arg1, arg2) {
+  return window['go']['main']['App']['DeleteJournalImportProfile'](arg1, arg2);
+}
+
+export function DeleteJournalTemplate(arg1, arg2) {
+  return window['go']['main']['App']['DeleteJournalTemplate'](arg1, arg2);
+}
+
 export function DeleteReconciliationDraft(arg1, arg2) {
   return window['go']['main']['App']['DeleteReconciliationDraft'](arg1, arg2);
 }
 
+export function DeleteStandardChart(arg1, arg2) {
+  return window['go']['main']['App']['DeleteStandardChart'](arg1, arg2);
+}
+
+export function DeleteStatementLayout(arg1, arg2) {
+  return window['go']['main']['App']['DeleteStatementLayout'](arg1, arg2);
+}
+
+export function DetectInterbankTransfers(arg1, arg2) {
+  return window['go']['main']['App']['DetectInterbankTransfers'](arg1, arg2);
+}
+
 export function ExamineOwnerStatementStructure(arg1, arg2) {
   return window['go']['main']['App']['ExamineOwnerStatementStructure'](arg1, arg2);
 }
 
+export function ExportAFEReport(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportAFEReport'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportAFESummary(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportAFESummary'](arg1, arg2, arg3);
+}
+
+export function ExportAccountReconciliation(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportAccountReconciliation'](arg1, arg2, arg3);
+}
+
+export function ExportBudgetVariance(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['ExportBudgetVariance'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function ExportCashPosition(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportCashPosition'](arg1, arg2, arg3);
+}
+
+export function ExportChartOfAccounts(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportChartOfAccounts'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportConsolidatedFinancialStatement(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9) {
+  return window['go']['main']['App']['ExportConsolidatedFinancialStatement'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9);
+}
+
+export function ExportConsolidatedTrialBalance(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9) {
+  return window['go']['main']['App']['ExportConsolidatedTrialBalance'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9);
+}
+
+export function ExportFinancialStatement(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['main']['App']['ExportFinancialStatement'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
+}
+
+export function ExportGLDetail(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportGLDetail'](arg1, arg2, arg3);
+}
+
+export function ExportMappedFinancialStatement(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9) {
+  return window['go']['main']['App']['ExportMappedFinancialStatement'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9);
+}
+
+export function ExportMappedTrialBalance(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9) {
+  return window['go']['main']['App']['ExportMappedTrialBalance'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9);
+}
+
 export function ExportNetDistribution(arg1, arg2, arg3) {
   return window['go']['main']['App']['ExportNetDistribution'](arg1, arg2, arg3);
 }
 
+export function ExportSegmentReport(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportSegmentReport'](arg1, arg2, arg3);
+}
+
+export function ExportTrialBalance(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['main']['App']['ExportTrialBalance'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
+}
+
+export function ExportYearEndCloseBatch(arg1) {
+  return window['go']['main']['App']['ExportYearEndCloseBatch'](arg1);
+}
+
 export function FollowBatchNumber(arg1, arg2) {
   return window['go']['main']['App']['FollowBatchNumber'](arg1, arg2);
 }
@@ -82,10 +222,34 @@ export function GenerateChartOfAccountsPDF(arg1, arg2, arg3) {
   return window['go']['main']['App']['GenerateChartOfAccountsPDF'](arg1, arg2, arg3);
 }
 
+export function GenerateJournalEntriesFromTemplates(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GenerateJournalEntriesFromTemplates'](arg1, arg2, arg3);
+}
+
 export function GenerateOwnerStatementPDF(arg1, arg2) {
   return window['go']['main']['App']['GenerateOwnerStatementPDF'](arg1, arg2);
 }
 
+export function GetAFE(arg1, arg2) {
+  return window['go']['main']['App']['GetAFE'](arg1, arg2);
+}
+
+export function GetAFECostCategories(arg1) {
+  return window['go']['main']['App']['GetAFECostCategories'](arg1);
+}
+
+export function GetAFEReport(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetAFEReport'](arg1, arg2, arg3);
+}
+
+export function GetAFESummary(arg1, arg2) {
+  return window['go']['main']['App']['GetAFESummary'](arg1, arg2);
+}
+
+export function GetAFEs(arg1, arg2) {
+  return window['go']['main']['App']['GetAFEs'](arg1, arg2);
+}
+
 export function GetAPIKey(arg1) {
   return window['go']['main']['App']['GetAPIKey'](arg1);
 }
@@ -94,6 +258,34 @@ export function GetAccountBalance(arg1, arg2) {
   return window['go']['main']['App']['GetAccountBalance'](arg1, arg2);
 }
 
+export function GetAccountBalanceAsOf(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetAccountBalanceAsOf'](arg1, arg2, arg3);
+}
+
+export function GetAccountBalances(arg1, arg2) {
+  return window['go']['main']['App']['GetAccountBalances'](arg1, arg2);
+}
+
+export function GetAccountMappings(arg1, arg2) {
+  return window['go']['main']['App']['GetAccountMappings'](arg1, arg2);
+}
+
+export function GetAccountReconciliation(arg1, arg2) {
+  return window['go']['main']['App']['GetAccountReconciliation'](arg1, arg2);
+}
+
+export function GetAccountReconciliationLog(arg1, arg2) {
+  return window['go']['main']['App']['GetAccountReconciliationLog'](arg1, arg2);
+}
+
+export function GetAccountReconciliationStatus(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetAccountReconciliationStatus'](arg1, arg2, arg3);
+}
+
+export function GetAccountingPeriods(arg1) {
+  return window['go']['main']['App']['GetAccountingPeriods'](arg1);
+}
+
 export function GetAllRoles() {
   return window['go']['main']['App']['GetAllRoles']();
 }
@@ -110,6 +302,14 @@ export function GetBalanceHistory(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetBalanceHistory'](arg1, arg2, arg3);
 }
 
+export function GetBalanceRefreshSettings(arg1) {
+  return window['go']['main']['App']['GetBalanceRefreshSettings'](arg1);
+}
+
+export function GetBalanceRefreshStatus() {
+  return window['go']['main']['App']['GetBalanceRefreshStatus']();
+}
+
 export function GetBankAccounts(arg1) {
   return window['go']['main']['App']['GetBankAccounts'](arg1);
 }
@@ -122,14 +322,50 @@ export function GetBankTransactions(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetBankTransactions'](arg1, arg2, arg3);
 }
 
+export function GetBudgetLines(arg1, arg2) {
+  return window['go']['main']['App']['GetBudgetLines'](arg1, arg2);
+}
+
+export function GetBudgetVariance(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['GetBudgetVariance'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GetBudgetVersions(arg1, arg2) {
+  return window['go']['main']['App']['GetBudgetVersions'](arg1, arg2);
+}
+
 export function GetCachedBalances(arg1) {
   return window['go']['main']['App']['GetCachedBalances'](arg1);
 }
 
+export function GetCashAccountSettings(arg1) {
+  return window['go']['main']['App']['GetCashAccountSettings'](arg1);
+}
+
+export function GetCashPosition(arg1, arg2) {
+  return window['go']['main']['App']['GetCashPosition'](arg1, arg2);
+}
+
+export function GetCashPositionHistory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetCashPositionHistory'](arg1, arg2, arg3, arg4);
+}
+
 export function GetChartOfAccounts(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetChartOfAccounts'](arg1, arg2, arg3);
 }
 
+export function GetChartOfAccountsAuditLog(arg1, arg2) {
+  return window['go']['main']['App']['GetChartOfAccountsAuditLog'](arg1, arg2);
+}
+
+export function GetCheckStockRanges(arg1, arg2) {
+  return window['go']['main']['App']['GetCheckStockRanges'](arg1, arg2);
+}
+
+export function GetClosingChecklist(arg1) {
+  return window['go']['main']['App']['GetClosingChecklist'](arg1);
+}
+
 export function GetClosingStatus(arg1) {
   return window['go']['main']['App']['GetClosingStatus'](arg1);
 }
@@ -150,6 +386,22 @@ export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
 
+export function GetConsolidatedFinancialStatement(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['main']['App']['GetConsolidatedFinancialStatement'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
+}
+
+export function GetConsolidatedTrialBalance(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['main']['App']['GetConsolidatedTrialBalance'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
+}
+
+export function GetConsolidationGroup(arg1, arg2) {
+  return window['go']['main']['App']['GetConsolidationGroup'](arg1, arg2);
+}
+
+export function GetConsolidationGroups(arg1) {
+  return window['go']['main']['App']['GetConsolidationGroups'](arg1);
+}
+
 export function GetDBFFiles(arg1) {
   return window['go']['main']['App']['GetDBFFiles'](arg1);
 }
@@ -166,10 +418,54 @@ export function GetDashboardData(arg1) {
   return window['go']['main']['App']['GetDashboardData'](arg1);
 }
 
+export function GetDashboardKPIs(arg1, arg2) {
+  return window['go']['main']['App']['GetDashboardKPIs'](arg1, arg2);
+}
+
 export function GetDebugMode() {
   return window['go']['main']['App']['GetDebugMode']();
 }
 
+export function GetDueJournalTemplates(arg1, arg2) {
+  return window['go']['main']['App']['GetDueJournalTemplates'](arg1, arg2);
+}
+
+export function GetFinancialStatement(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['main']['App']['GetFinancialStatement'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+}
+
+export function GetFiscalCalendar(arg1) {
+  return window['go']['main']['App']['GetFiscalCalendar'](arg1);
+}
+
+export function GetGLDetail(arg1, arg2) {
+  return window['go']['main']['App']['GetGLDetail'](arg1, arg2);
+}
+
+export function GetGLLineSource(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetGLLineSource'](arg1, arg2, arg3);
+}
+
+export function GetInterbankTransfers(arg1, arg2) {
+  return window['go']['main']['App']['GetInterbankTransfers'](arg1, arg2);
+}
+
+export function GetJournalEntries(arg1, arg2) {
+  return window['go']['main']['App']['GetJournalEntries'](arg1, arg2);
+}
+
+export function GetJournalEntry(arg1, arg2) {
+  return window['go']['main']['App']['GetJournalEntry'](arg1, arg2);
+}
+
+export function GetJournalImportProfiles(arg1) {
+  return window['go']['main']['App']['GetJournalImportProfiles'](arg1);
+}
+
+export function GetJournalTemplates(arg1) {
+  return window['go']['main']['App']['GetJournalTemplates'](arg1);
+}
+
 export function GetLastReconciliation(arg1, arg2) {
   return window['go']['main']['App']['GetLastReconciliation'](arg1, arg2);
 }
@@ -178,6 +474,14 @@ export function GetLogFilePath() {
   return window['go']['main']['App']['GetLogFilePath']();
 }
 
+export function GetMappedFinancialStatement(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['main']['App']['GetMappedFinancialStatement'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
+}
+
+export function GetMappedTrialBalance(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['main']['App']['GetMappedTrialBalance'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
+}
+
 export function GetMatchedTransactions(arg1, arg2) {
   return window['go']['main']['App']['GetMatchedTransactions'](arg1, arg2);
 }
@@ -190,6 +494,10 @@ export function GetOutstandingChecks(arg1, arg2) {
   return window['go']['main']['App']['GetOutstandingChecks'](arg1, arg2);
 }
 
+export function GetOutstandingChecksAsOf(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetOutstandingChecksAsOf'](arg1, arg2, arg3);
+}
+
 export function GetOwnerStatementData(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetOwnerStatementData'](arg1, arg2, arg3);
 }
@@ -202,6 +510,18 @@ export function GetOwnersList(arg1, arg2) {
   return window['go']['main']['App']['GetOwnersList'](arg1, arg2);
 }
 
+export function GetPaidItemExceptions(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetPaidItemExceptions'](arg1, arg2, arg3);
+}
+
+export function GetPeriodAuditLog(arg1) {
+  return window['go']['main']['App']['GetPeriodAuditLog'](arg1);
+}
+
+export function GetPeriodEndBalances(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetPeriodEndBalances'](arg1, arg2, arg3);
+}
+
 export function GetPlatform() {
   return window['go']['main']['App']['GetPlatform']();
 }
@@ -210,6 +530,10 @@ export function GetRecentBankStatements(arg1, arg2) {
   return window['go']['main']['App']['GetRecentBankStatements'](arg1, arg2);
 }
 
+export function GetReconciledAccounts(arg1) {
+  return window['go']['main']['App']['GetReconciledAccounts'](arg1);
+}
+
 export function GetReconciliationDraft(arg1, arg2) {
   return window['go']['main']['App']['GetReconciliationDraft'](arg1, arg2);
 }
@@ -218,10 +542,34 @@ export function GetReconciliationHistory(arg1, arg2) {
   return window['go']['main']['App']['GetReconciliationHistory'](arg1, arg2);
 }
 
+export function GetSegmentDrillDown(arg1, arg2) {
+  return window['go']['main']['App']['GetSegmentDrillDown'](arg1, arg2);
+}
+
+export function GetSegmentReport(arg1, arg2) {
+  return window['go']['main']['App']['GetSegmentReport'](arg1, arg2);
+}
+
+export function GetStandardChart(arg1, arg2) {
+  return window['go']['main']['App']['GetStandardChart'](arg1, arg2);
+}
+
+export function GetStandardCharts(arg1) {
+  return window['go']['main']['App']['GetStandardCharts'](arg1);
+}
+
+export function GetStatementLayouts(arg1, arg2) {
+  return window['go']['main']['App']['GetStatementLayouts'](arg1, arg2);
+}
+
 export function GetTableList(arg1) {
   return window['go']['main']['App']['GetTableList'](arg1);
 }
 
+export function GetTrialBalance(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['main']['App']['GetTrialBalance'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+}
+
 export function GetVFPCompany() {
   return window['go']['main']['App']['GetVFPCompany']();
 }
@@ -234,14 +582,46 @@ export function GetVFPSettings() {
   return window['go']['main']['App']['GetVFPSettings']();
 }
 
+export function GetYearEndCloses() {
+  return window['go']['main']['App']['GetYearEndCloses']();
+}
+
+export function GetYearEndSettings() {
+  return window['go']['main']['App']['GetYearEndSettings']();
+}
+
 export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function HardClosePeriod(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['HardClosePeriod'](arg1, arg2, arg3, arg4);
+}
+
 export function ImportBankStatement(arg1, arg2, arg3) {
   return window['go']['main']['App']['ImportBankStatement'](arg1, arg2, arg3);
 }
 
+export function ImportBudgetCSV(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ImportBudgetCSV'](arg1, arg2, arg3, arg4);
+}
+
+export function ImportChartOfAccounts(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ImportChartOfAccounts'](arg1, arg2, arg3, arg4);
+}
+
+export function ImportJournalEntries(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ImportJournalEntries'](arg1, arg2, arg3);
+}
+
+export function ImportPaidItems(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['ImportPaidItems'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function ImportStandardChart(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['ImportStandardChart'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
 export function InitializeCompanyDatabase(arg1) {
   return window['go']['main']['App']['InitializeCompanyDatabase'](arg1);
 }
@@ -274,14 +654,46 @@ export function ManualMatchTransaction(arg1, arg2, arg3) {
   return window['go']['main']['App']['ManualMatchTransaction'](arg1, arg2, arg3);
 }
 
+export function MatchInterbankTransfer(arg1, arg2, arg3) {
+  return window['go']['main']['App']['MatchInterbankTransfer'](arg1, arg2, arg3);
+}
+
+export function MergeAccounts(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['MergeAccounts'](arg1, arg2, arg3, arg4);
+}
+
 export function MigrateReconciliationData(arg1) {
   return window['go']['main']['App']['MigrateReconciliationData'](arg1);
 }
 
+export function PostJournalEntry(arg1, arg2) {
+  return window['go']['main']['App']['PostJournalEntry'](arg1, arg2);
+}
+
 export function PreloadOLEConnection(arg1) {
   return window['go']['main']['App']['PreloadOLEConnection'](arg1);
 }
 
+export function PrepareAccountReconciliation(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PrepareAccountReconciliation'](arg1, arg2, arg3);
+}
+
+export function PreviewAccountMerge(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PreviewAccountMerge'](arg1, arg2, arg3);
+}
+
+export function PreviewYearEndClose(arg1, arg2) {
+  return window['go']['main']['App']['PreviewYearEndClose'](arg1, arg2);
+}
+
+export function PullAccountReconciliationGLItems(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['PullAccountReconciliationGLItems'](arg1, arg2, arg3, arg4);
+}
+
+export function ReactivateAccount(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ReactivateAccount'](arg1, arg2, arg3);
+}
+
 export function RefreshAccountBalance(arg1, arg2) {
   return window['go']['main']['App']['RefreshAccountBalance'](arg1, arg2);
 }
@@ -290,18 +702,46 @@ export function RefreshAllBalances(arg1) {
   return window['go']['main']['App']['RefreshAllBalances'](arg1);
 }
 
+export function RefreshPeriodBalances(arg1) {
+  return window['go']['main']['App']['RefreshPeriodBalances'](arg1);
+}
+
 export function Register(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['Register'](arg1, arg2, arg3, arg4);
 }
 
+export function RemoveReconciledAccount(arg1, arg2) {
+  return window['go']['main']['App']['RemoveReconciledAccount'](arg1, arg2);
+}
+
+export function ReopenAFE(arg1, arg2) {
+  return window['go']['main']['App']['ReopenAFE'](arg1, arg2);
+}
+
+export function ReopenAccountReconciliation(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ReopenAccountReconciliation'](arg1, arg2, arg3);
+}
+
 export function ReopenPeriod(arg1, arg2) {
   return window['go']['main']['App']['ReopenPeriod'](arg1, arg2);
 }
 
+export function ResolvePaidItemException(arg1, arg2) {
+  return window['go']['main']['App']['ResolvePaidItemException'](arg1, arg2);
+}
+
 export function RetryMatching(arg1, arg2, arg3) {
   return window['go']['main']['App']['RetryMatching'](arg1, arg2, arg3);
 }
 
+export function ReverseYearEndClose(arg1, arg2) {
+  return window['go']['main']['App']['ReverseYearEndClose'](arg1, arg2);
+}
+
+export function ReviewAccountReconciliation(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ReviewAccountReconciliation'](arg1, arg2, arg3);
+}
+
 export function RunClosingProcess(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['RunClosingProcess'](arg1, arg2, arg3, arg4);
 }
@@ -314,14 +754,82 @@ export function RunNetDistribution(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['RunNetDistribution'](arg1, arg2, arg3, arg4);
 }
 
+export function RunYearEndClose(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RunYearEndClose'](arg1, arg2, arg3);
+}
+
+export function SaveAFE(arg1, arg2) {
+  return window['go']['main']['App']['SaveAFE'](arg1, arg2);
+}
+
+export function SaveAccount(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SaveAccount'](arg1, arg2, arg3);
+}
+
+export function SaveAccountMappings(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SaveAccountMappings'](arg1, arg2, arg3);
+}
+
+export function SaveAccountReconciliationNotes(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SaveAccountReconciliationNotes'](arg1, arg2, arg3);
+}
+
+export function SaveBalanceRefreshSettings(arg1, arg2) {
+  return window['go']['main']['App']['SaveBalanceRefreshSettings'](arg1, arg2);
+}
+
+export function SaveBudgetLines(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SaveBudgetLines'](arg1, arg2, arg3);
+}
+
+export function SaveBudgetVersion(arg1, arg2) {
+  return window['go']['main']['App']['SaveBudgetVersion'](arg1, arg2);
+}
+
+export function SaveCashAccountSettings(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SaveCashAccountSettings'](arg1, arg2, arg3, arg4);
+}
+
+export function SaveConsolidationGroup(arg1, arg2) {
+  return window['go']['main']['App']['SaveConsolidationGroup'](arg1, arg2);
+}
+
+export function SaveFiscalCalendar(arg1) {
+  return window['go']['main']['App']['SaveFiscalCalendar'](arg1);
+}
+
+export function SaveJournalEntry(arg1, arg2) {
+  return window['go']['main']['App']['SaveJournalEntry'](arg1, arg2);
+}
+
+export function SaveJournalImportProfile(arg1, arg2) {
+  return window['go']['main']['App']['SaveJournalImportProfile'](arg1, arg2);
+}
+
+export function SaveJournalTemplate(arg1, arg2) {
+  return window['go']['main']['App']['SaveJournalTemplate'](arg1, arg2);
+}
+
+export function SaveReconciledAccount(arg1, arg2) {
+  return window['go']['main']['App']['SaveReconciledAccount'](arg1, arg2);
+}
+
 export function SaveReconciliationDraft(arg1, arg2) {
   return window['go']['main']['App']['SaveReconciliationDraft'](arg1, arg2);
 }
 
+export function SaveStatementLayout(arg1, arg2) {
+  return window['go']['main']['App']['SaveStatementLayout'](arg1, arg2);
+}
+
 export function SaveVFPSettings(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['SaveVFPSettings'](arg1, arg2, arg3, arg4);
 }
 
+export function SaveYearEndSettings(arg1) {
+  return window['go']['main']['App']['SaveYearEndSettings'](arg1);
+}
+
 export function SearchDBFTable(arg1, arg2, arg3) {
   return window['go']['main']['App']['SearchDBFTable'](arg1, arg2, arg3);
 }
@@ -346,6 +854,14 @@ export function SetOLEIdleTimeout(arg1) {
   return window['go']['main']['App']['SetOLEIdleTimeout'](arg1);
 }
 
+export function StartAccountReconciliation(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['StartAccountReconciliation'](arg1, arg2, arg3, arg4);
+}
+
+export function SuggestAccountMappings(arg1, arg2) {
+  return window['go']['main']['App']['SuggestAccountMappings'](arg1, arg2);
+}
+
 export function SyncVFPCompany() {
   return window['go']['main']['App']['SyncVFPCompany']();
 }
@@ -370,14 +886,26 @@ export function TestVFPConnection() {
   return window['go']['main']['App']['TestVFPConnection']();
 }
 
+export function UnmatchInterbankTransfer(arg1, arg2) {
+  return window['go']['main']['App']['UnmatchInterbankTransfer'](arg1, arg2);
+}
+
 export function UnmatchTransaction(arg1) {
   return window['go']['main']['App']['UnmatchTransaction'](arg1);
 }
 
+export function UpdateAccountReconciliationItem(arg1, arg2) {
+  return window['go']['main']['App']['UpdateAccountReconciliationItem'](arg1, arg2);
+}
+
 export function UpdateBatchFields(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['main']['App']['UpdateBatchFields'](arg1, arg2, arg3, arg4, arg5);
 }
 
+export function UpdateCheckStockRangeStatus(arg1, arg2, arg3) {
+  return window['go']['main']['App']['UpdateCheckStockRangeStatus'](arg1, arg2, arg3);
+}
+
 export function UpdateCompanyInfo(arg1) {
   return window['go']['main']['App']['UpdateCompanyInfo'](arg1);
 }
@@ -398,6 +926,14 @@ export function ValidateGLBalances(arg1, arg2) {
   return window['go']['main']['App']['ValidateGLBalances'](arg1, arg2);
 }
 
+export function ValidateJournalEntry(arg1, arg2) {
+  return window['go']['main']['App']['ValidateJournalEntry'](arg1, arg2);
+}
+
+export function ValidateJournalImport(arg1, arg2) {
+  return window['go']['main']['App']['ValidateJournalImport'](arg1, arg2);
+}
+
 export function ValidateSession(arg1, arg2) {
   return window['go']['main']['App']['ValidateSession'](arg1, arg2);
 }
@@ -0,0 +1,395 @@
+// Package afe tracks authorities for expenditure: the approved cost estimate
+// of a drilling, completion or workover project on a well, broken down by cost
+// category, compared with the costs coded to its CAFENO in GLMASTER.dbf or
+// EXPENSE.dbf. AFE headers and estimates are stored in SQLite.
+package afe
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// AFE statuses
+const (
+	StatusOpen   = "open"
+	StatusClosed = "closed"
+)
+
+// Service provides AFE operations
+type Service struct {
+	db *database.DB
+}
+
+// NewService creates a new AFE service
+func NewService(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// AFE is an authority for expenditure. Estimates are gross (8/8ths); the
+// company's share is the gross estimate times WorkingInterest.
+type AFE struct {
+	ID              int        `json:"id"`
+	CompanyName     string     `json:"company_name"`
+	AFENo           string     `json:"afe_number"`
+	WellID          string     `json:"well_id"`
+	WellName        string     `json:"well_name,omitempty"`
+	Description     string     `json:"description"`
+	ApprovalDate    *time.Time `json:"approval_date,omitempty"`
+	GrossEstimate   float64    `json:"gross_estimate"`
+	WorkingInterest float64    `json:"working_interest"` // Percent, 0-100
+	NetEstimate     float64    `json:"net_estimate"`
+	Status          string     `json:"status"`
+	FinalActual     *float64   `json:"final_actual,omitempty"`  // Actual cost recorded at close-out
+	ActualSource    string     `json:"actual_source,omitempty"` // Where FinalActual came from
+	ClosedBy        string     `json:"closed_by,omitempty"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+	CloseNotes      string     `json:"close_notes,omitempty"`
+	Lines           []Line     `json:"lines,omitempty"`
+	CreatedBy       string     `json:"created_by"`
+	CreatedAt       *time.Time `json:"created_at,omitempty"`
+	UpdatedBy       string     `json:"updated_by,omitempty"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+}
+
+// Line is the gross estimate for one cost category (an EXPCAT.dbf CCATCODE)
+type Line struct {
+	Category      string  `json:"category"`
+	Description   string  `json:"description"`
+	GrossEstimate float64 `json:"gross_estimate"`
+}
+
+// IsClosed reports whether the AFE has been closed out
+func (a *AFE) IsClosed() bool {
+	return a.Status == StatusClosed
+}
+
+// GetAFEs lists a company's AFEs without their lines
+func (s *Service) GetAFEs(companyName string, includeClosed bool) ([]AFE, error) {
+	query := afeSelect + ` WHERE company_name = ?`
+	args := []interface{}{companyName}
+	if !includeClosed {
+		query += ` AND status = ?`
+		args = append(args, StatusOpen)
+	}
+	query += ` ORDER BY afe_number`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query AFEs: %w", err)
+	}
+	defer rows.Close()
+
+	afes := []AFE{}
+	for rows.Next() {
+		a, err := scanAFE(rows)
+		if err != nil {
+			return nil, err
+		}
+		afes = append(afes, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	names := wellNames(companyName)
+	for i := range afes {
+		afes[i].WellName = names[afes[i].WellID]
+	}
+	return afes, nil
+}
+
+// GetAFE returns an AFE with its lines
+func (s *Service) GetAFE(companyName string, id int) (*AFE, error) {
+	a, err := scanAFE(s.db.QueryRow(afeSelect+` WHERE company_name = ? AND id = ?`, companyName, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("AFE %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT category, COALESCE(description, ''), gross_estimate
+		FROM afe_lines WHERE afe_id = ? ORDER BY line_order, category
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query AFE lines: %w", err)
+	}
+	defer rows.Close()
+
+	a.Lines = []Line{}
+	for rows.Next() {
+		var l Line
+		if err := rows.Scan(&l.Category, &l.Description, &l.GrossEstimate); err != nil {
+			return nil, fmt.Errorf("failed to scan AFE line: %w", err)
+		}
+		l.GrossEstimate = currency.NewFromFloat(l.GrossEstimate).ToFloat64()
+		a.Lines = append(a.Lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	a.WellName = wellNames(companyName)[a.WellID]
+	return a, nil
+}
+
+// findAFE returns the ID of the AFE with a CAFENO, or 0
+func (s *Service) findAFE(companyName, afeNo string) (int, error) {
+	var id int
+	err := s.db.QueryRow(`SELECT id FROM afes WHERE company_name = ? AND afe_number = ?`, companyName, afeNo).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// SaveAFE creates or updates an AFE and replaces its lines. A zero gross
+// estimate is taken from the lines; otherwise the lines must add up to it.
+// Closed AFEs cannot be changed until they are reopened.
+func (s *Service) SaveAFE(a *AFE, username string) (*AFE, error) {
+	a.AFENo = strings.TrimSpace(a.AFENo)
+	a.WellID = strings.TrimSpace(a.WellID)
+	a.Description = strings.TrimSpace(a.Description)
+	if a.AFENo == "" {
+		return nil, fmt.Errorf("AFE number is required")
+	}
+	if a.WorkingInterest == 0 {
+		a.WorkingInterest = 100
+	}
+	if a.WorkingInterest < 0 || a.WorkingInterest > 100 {
+		return nil, fmt.Errorf("working interest must be between 0 and 100 percent")
+	}
+	if a.GrossEstimate < 0 {
+		return nil, fmt.Errorf("the gross estimate cannot be negative")
+	}
+
+	linesTotal := currency.Zero()
+	seen := make(map[string]bool)
+	for i := range a.Lines {
+		l := &a.Lines[i]
+		l.Category = strings.ToUpper(strings.TrimSpace(l.Category))
+		l.Description = strings.TrimSpace(l.Description)
+		if l.Category == "" {
+			return nil, fmt.Errorf("line %d: a cost category is required", i+1)
+		}
+		if seen[l.Category] {
+			return nil, fmt.Errorf("line %d: cost category %s appears more than once", i+1, l.Category)
+		}
+		seen[l.Category] = true
+		l.GrossEstimate = currency.NewFromFloat(l.GrossEstimate).ToFloat64()
+		linesTotal = linesTotal.Add(currency.NewFromFloat(l.GrossEstimate))
+	}
+	gross := currency.NewFromFloat(a.GrossEstimate)
+	if len(a.Lines) > 0 {
+		if gross.IsZero() {
+			gross = linesTotal
+		} else if !gross.Equal(linesTotal) {
+			return nil, fmt.Errorf("the line estimates total %s but the AFE gross estimate is %s",
+				linesTotal.ToString(), gross.ToString())
+		}
+	}
+
+	existing, err := s.findAFE(a.CompanyName, a.AFENo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up AFE %s: %w", a.AFENo, err)
+	}
+	if existing != 0 && existing != a.ID {
+		return nil, fmt.Errorf("AFE %s already exists", a.AFENo)
+	}
+	if a.ID > 0 {
+		current, err := s.GetAFE(a.CompanyName, a.ID)
+		if err != nil {
+			return nil, err
+		}
+		if current.IsClosed() {
+			return nil, fmt.Errorf("AFE %s is closed; reopen it before making changes", current.AFENo)
+		}
+	}
+
+	var approval interface{}
+	if a.ApprovalDate != nil && !a.ApprovalDate.IsZero() {
+		approval = a.ApprovalDate.Format("2006-01-02")
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	id := a.ID
+	if id > 0 {
+		if _, err := tx.Exec(`
+			UPDATE afes SET afe_number = ?, well_id = ?, description = ?, approval_date = ?, gross_estimate = ?,
+				working_interest = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND company_name = ?
+		`, a.AFENo, a.WellID, a.Description, approval, gross.ToFloat64(), a.WorkingInterest, username, id, a.CompanyName); err != nil {
+			return nil, fmt.Errorf("failed to update AFE: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM afe_lines WHERE afe_id = ?`, id); err != nil {
+			return nil, fmt.Errorf("failed to clear AFE lines: %w", err)
+		}
+	} else {
+		result, err := tx.Exec(`
+			INSERT INTO afes (company_name, afe_number, well_id, description, approval_date, gross_estimate,
+				working_interest, status, created_by, updated_by)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, a.CompanyName, a.AFENo, a.WellID, a.Description, approval, gross.ToFloat64(), a.WorkingInterest,
+			StatusOpen, username, username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AFE: %w", err)
+		}
+		newID, _ := result.LastInsertId()
+		id = int(newID)
+	}
+	for i, l := range a.Lines {
+		if _, err := tx.Exec(`
+			INSERT INTO afe_lines (afe_id, line_order, category, description, gross_estimate)
+			VALUES (?, ?, ?, ?, ?)
+		`, id, i+1, l.Category, l.Description, l.GrossEstimate); err != nil {
+			return nil, fmt.Errorf("failed to save AFE line: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save AFE: %w", err)
+	}
+	return s.GetAFE(a.CompanyName, id)
+}
+
+// DeleteAFE removes an open AFE and its lines. The GL lines coded to it are
+// not touched.
+func (s *Service) DeleteAFE(companyName string, id int) error {
+	a, err := s.GetAFE(companyName, id)
+	if err != nil {
+		return err
+	}
+	if a.IsClosed() {
+		return fmt.Errorf("AFE %s is closed and cannot be deleted", a.AFENo)
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM afe_lines WHERE afe_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete AFE lines: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM afes WHERE id = ? AND company_name = ?`, id, companyName); err != nil {
+		return fmt.Errorf("failed to delete AFE: %w", err)
+	}
+	return tx.Commit()
+}
+
+// CloseAFE closes out an AFE, recording its actual cost as of today, and
+// returns the close-out report
+func (s *Service) CloseAFE(companyName string, id int, req ReportRequest, notes, username string) (*Report, error) {
+	a, err := s.GetAFE(companyName, id)
+	if err != nil {
+		return nil, err
+	}
+	if a.IsClosed() {
+		return nil, fmt.Errorf("AFE %s is already closed", a.AFENo)
+	}
+	req.AsOf = time.Time{}
+	report, err := s.AFEReport(companyName, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.Exec(`
+		UPDATE afes SET status = ?, final_actual = ?, actual_source = ?, closed_by = ?, closed_at = CURRENT_TIMESTAMP,
+			close_notes = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND company_name = ?
+	`, StatusClosed, report.Total.Actual, report.Source, username, strings.TrimSpace(notes), username, id, companyName); err != nil {
+		return nil, fmt.Errorf("failed to close AFE: %w", err)
+	}
+	return s.AFEReport(companyName, id, req)
+}
+
+// ReopenAFE reopens a closed AFE and clears its close-out
+func (s *Service) ReopenAFE(companyName string, id int, username string) (*AFE, error) {
+	a, err := s.GetAFE(companyName, id)
+	if err != nil {
+		return nil, err
+	}
+	if !a.IsClosed() {
+		return nil, fmt.Errorf("AFE %s is not closed", a.AFENo)
+	}
+	if _, err := s.db.Exec(`
+		UPDATE afes SET status = ?, final_actual = NULL, actual_source = NULL, closed_by = NULL, closed_at = NULL,
+			updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND company_name = ?
+	`, StatusOpen, username, id, companyName); err != nil {
+		return nil, fmt.Errorf("failed to reopen AFE: %w", err)
+	}
+	return s.GetAFE(companyName, id)
+}
+
+// wellNames maps well ID to name; a company without WELLS.dbf gets no names
+func wellNames(companyName string) map[string]string {
+	wells, err := ledger.LoadWells(companyName)
+	if err != nil {
+		return map[string]string{}
+	}
+	return ledger.WellNames(wells)
+}
+
+const afeSelect = `
+	SELECT id, company_name, afe_number, COALESCE(well_id, ''), COALESCE(description, ''), approval_date,
+	       gross_estimate, working_interest, status, final_actual, COALESCE(actual_source, ''), COALESCE(closed_by, ''), closed_at,
+	       COALESCE(close_notes, ''), COALESCE(created_by, ''), created_at, COALESCE(updated_by, ''), updated_at
+	FROM afes`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAFE(row rowScanner) (*AFE, error) {
+	var a AFE
+	var approval, closedAt, createdAt, updatedAt interface{}
+	var finalActual sql.NullFloat64
+	if err := row.Scan(&a.ID, &a.CompanyName, &a.AFENo, &a.WellID, &a.Description, &approval,
+		&a.GrossEstimate, &a.WorkingInterest, &a.Status, &finalActual, &a.ActualSource, &a.ClosedBy, &closedAt,
+		&a.CloseNotes, &a.CreatedBy, &createdAt, &a.UpdatedBy, &updatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan AFE: %w", err)
+	}
+	a.GrossEstimate = currency.NewFromFloat(a.GrossEstimate).ToFloat64()
+	a.NetEstimate = netAmount(a.GrossEstimate, a.WorkingInterest)
+	if finalActual.Valid {
+		f := currency.NewFromFloat(finalActual.Float64).ToFloat64()
+		a.FinalActual = &f
+	}
+	if d, ok := ledger.AsDate(approval); ok {
+		a.ApprovalDate = &d
+	}
+	a.ClosedAt = timestamp(closedAt)
+	a.CreatedAt = timestamp(createdAt)
+	a.UpdatedAt = timestamp(updatedAt)
+	return &a, nil
+}
+
+// netAmount returns the working interest share of a gross amount
+func netAmount(gross, workingInterest float64) float64 {
+	return currency.NewFromFloat(gross * workingInterest / 100).ToFloat64()
+}
+
+func timestamp(v interface{}) *time.Time {
+	if t, ok := v.(time.Time); ok && !t.IsZero() {
+		return &t
+	}
+	if t, ok := ledger.AsDate(v); ok {
+		return &t
+	}
+	return nil
+}
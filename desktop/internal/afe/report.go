@@ -0,0 +1,668 @@
+package afe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/reports"
+)
+
+// Actual cost sources
+const (
+	SourceGL      = "glmaster" // GLMASTER.dbf lines coded to the AFE
+	SourceExpense = "expense"  // EXPENSE.dbf well expense records
+)
+
+// Alert levels for an AFE or cost category
+const (
+	AlertWarning = "warning" // Percent spent has reached the warning level
+	AlertOver    = "over"    // Actual is over the estimate but within the overrun tolerance
+	AlertOverrun = "overrun" // Actual is over the estimate by the tolerance or more, or has no estimate
+)
+
+// Defaults for the alert levels
+const (
+	DefaultWarnPercent    = 90
+	DefaultOverrunPercent = 10
+)
+
+// ReportRequest controls how actual costs are gathered and flagged. A line is
+// a warning once WarnPercent of its estimate is spent and an overrun once the
+// actual exceeds the estimate by OverrunPercent.
+type ReportRequest struct {
+	Source         string    `json:"source"`          // SourceGL (default) or SourceExpense
+	Net            bool      `json:"net"`             // Compare with the working interest share of the estimate
+	AsOf           time.Time `json:"as_of"`           // Costs dated through this day; zero for all
+	WarnPercent    float64   `json:"warn_percent"`    // Default 90
+	OverrunPercent float64   `json:"overrun_percent"` // Default 10
+	IncludeClosed  bool      `json:"include_closed"`  // Summary only
+}
+
+func (r *ReportRequest) normalize() error {
+	r.Source = strings.ToLower(strings.TrimSpace(r.Source))
+	if r.Source == "" {
+		r.Source = SourceGL
+	}
+	if r.Source != SourceGL && r.Source != SourceExpense {
+		return fmt.Errorf("unknown actual cost source: %s", r.Source)
+	}
+	if r.WarnPercent < 0 || r.OverrunPercent < 0 {
+		return fmt.Errorf("alert levels cannot be negative")
+	}
+	if r.WarnPercent == 0 {
+		r.WarnPercent = DefaultWarnPercent
+	}
+	return nil
+}
+
+// CostLine compares estimate and actual for one cost category. Variance is
+// actual minus estimate; PercentSpent is nil when there is no estimate.
+type CostLine struct {
+	Category     string   `json:"category"`
+	Description  string   `json:"description"`
+	Estimate     float64  `json:"estimate"`
+	Actual       float64  `json:"actual"`
+	Variance     float64  `json:"variance"`
+	PercentSpent *float64 `json:"percent_spent"`
+	Alert        string   `json:"alert"`
+	LineCount    int      `json:"line_count"`
+	Unestimated  bool     `json:"unestimated"` // Costs in a category the AFE has no estimate for
+}
+
+// Report is the estimate vs actual report for one AFE. For a closed AFE it is
+// the close-out report, and CostsSinceClose shows costs posted after close-out.
+type Report struct {
+	CompanyName     string     `json:"company_name"`
+	AFE             AFE        `json:"afe"`
+	Source          string     `json:"source"`
+	Net             bool       `json:"net"`
+	AsOf            *time.Time `json:"as_of,omitempty"`
+	WarnPercent     float64    `json:"warn_percent"`
+	OverrunPercent  float64    `json:"overrun_percent"`
+	Lines           []CostLine `json:"lines"`
+	Total           CostLine   `json:"total"`
+	FirstCostDate   *time.Time `json:"first_cost_date,omitempty"`
+	LastCostDate    *time.Time `json:"last_cost_date,omitempty"`
+	CostsSinceClose float64    `json:"costs_since_close"`
+	Alerts          []string   `json:"alerts"`
+	Warnings        []string   `json:"warnings"`
+	GeneratedAt     time.Time  `json:"generated_at"`
+}
+
+// SummaryRow is one AFE's estimate, actual and alert
+type SummaryRow struct {
+	ID           int        `json:"id"`
+	AFENo        string     `json:"afe_number"`
+	WellID       string     `json:"well_id"`
+	WellName     string     `json:"well_name"`
+	Description  string     `json:"description"`
+	Status       string     `json:"status"`
+	ApprovalDate *time.Time `json:"approval_date,omitempty"`
+	Estimate     float64    `json:"estimate"`
+	Actual       float64    `json:"actual"`
+	Variance     float64    `json:"variance"`
+	PercentSpent *float64   `json:"percent_spent"`
+	Alert        string     `json:"alert"`
+	LastCostDate *time.Time `json:"last_cost_date,omitempty"`
+}
+
+// UnmatchedAFE is a CAFENO with costs but no AFE record
+type UnmatchedAFE struct {
+	AFENo     string  `json:"afe_number"`
+	Actual    float64 `json:"actual"`
+	LineCount int     `json:"line_count"`
+}
+
+// Summary lists every AFE with its percent spent and alerts
+type Summary struct {
+	CompanyName    string         `json:"company_name"`
+	Source         string         `json:"source"`
+	Net            bool           `json:"net"`
+	AsOf           *time.Time     `json:"as_of,omitempty"`
+	WarnPercent    float64        `json:"warn_percent"`
+	OverrunPercent float64        `json:"overrun_percent"`
+	Rows           []SummaryRow   `json:"rows"`
+	Total          SummaryRow     `json:"total"`
+	AlertCount     int            `json:"alert_count"`
+	Unmatched      []UnmatchedAFE `json:"unmatched"`
+	Warnings       []string       `json:"warnings"`
+	GeneratedAt    time.Time      `json:"generated_at"`
+}
+
+// afeCosts is the actual cost coded to one CAFENO
+type afeCosts struct {
+	byCategory  map[string]currency.Currency
+	lines       map[string]int
+	total       currency.Currency
+	first, last time.Time
+}
+
+func (c *afeCosts) lineCount() int {
+	n := 0
+	for _, count := range c.lines {
+		n += count
+	}
+	return n
+}
+
+// loadCosts totals actual costs by CAFENO (upper case) and CCATCODE. From
+// GLMASTER these are the debits less credits on lines coded to an AFE, other
+// than year-end closing entries and lines on bank and credit-normal accounts,
+// which are the other side of the cost.
+func (s *Service) loadCosts(companyName string, req ReportRequest) (map[string]*afeCosts, error) {
+	costs := make(map[string]*afeCosts)
+	add := func(afeNo, category string, amount currency.Currency, date time.Time) {
+		afeNo = strings.ToUpper(strings.TrimSpace(afeNo))
+		if afeNo == "" || (!req.AsOf.IsZero() && !date.IsZero() && date.After(req.AsOf)) {
+			return
+		}
+		category = strings.ToUpper(strings.TrimSpace(category))
+		c, ok := costs[afeNo]
+		if !ok {
+			c = &afeCosts{byCategory: make(map[string]currency.Currency), lines: make(map[string]int), total: currency.Zero()}
+			costs[afeNo] = c
+		}
+		if amt, found := c.byCategory[category]; found {
+			c.byCategory[category] = amt.Add(amount)
+		} else {
+			c.byCategory[category] = amount
+		}
+		c.lines[category]++
+		c.total = c.total.Add(amount)
+		if !date.IsZero() {
+			if c.first.IsZero() || date.Before(c.first) {
+				c.first = date
+			}
+			if date.After(c.last) {
+				c.last = date
+			}
+		}
+	}
+
+	if req.Source == SourceExpense {
+		expenses, err := ledger.LoadExpenses(companyName)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range expenses {
+			date := e.AcctDate
+			if date.IsZero() {
+				date = e.Date
+			}
+			add(e.AFENo, e.CatCode, currency.NewFromFloat(e.Amount), date)
+		}
+		return costs, nil
+	}
+
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, err
+	}
+	accountMap := ledger.AccountMap(accounts)
+	entries, err := ledger.LoadGLEntries(companyName)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.AFENo == "" || e.IsClosingEntry() {
+			continue
+		}
+		if a, ok := accountMap[e.AccountNo]; ok && (a.IsBank || !ledger.IsDebitNormal(a.Type)) {
+			continue
+		}
+		add(e.AFENo, e.CatCode, e.Net(), e.Date)
+	}
+	return costs, nil
+}
+
+// categoryNames maps EXPCAT.dbf cost category codes to descriptions
+func categoryNames(companyName string) map[string]string {
+	names := make(map[string]string)
+	categories, err := ledger.LoadExpenseCategories(companyName)
+	if err != nil {
+		return names
+	}
+	for _, c := range categories {
+		desc := c.Description
+		if desc == "" {
+			desc = c.Category
+		}
+		names[strings.ToUpper(c.Code)] = desc
+	}
+	return names
+}
+
+// AFEReport compares an AFE's estimate with its actual costs by cost category
+func (s *Service) AFEReport(companyName string, id int, req ReportRequest) (*Report, error) {
+	if err := req.normalize(); err != nil {
+		return nil, err
+	}
+	a, err := s.GetAFE(companyName, id)
+	if err != nil {
+		return nil, err
+	}
+	costs, err := s.loadCosts(companyName, req)
+	if err != nil {
+		return nil, err
+	}
+	c := costs[strings.ToUpper(a.AFENo)]
+	if c == nil {
+		c = &afeCosts{byCategory: map[string]currency.Currency{}, lines: map[string]int{}, total: currency.Zero()}
+	}
+	names := categoryNames(companyName)
+
+	r := &Report{
+		CompanyName:    companyName,
+		AFE:            *a,
+		Source:         req.Source,
+		Net:            req.Net,
+		WarnPercent:    req.WarnPercent,
+		OverrunPercent: req.OverrunPercent,
+		Lines:          []CostLine{},
+		Alerts:         []string{},
+		Warnings:       []string{},
+		GeneratedAt:    time.Now(),
+	}
+	if !req.AsOf.IsZero() {
+		asOf := req.AsOf
+		r.AsOf = &asOf
+	}
+	if !c.first.IsZero() {
+		first, last := c.first, c.last
+		r.FirstCostDate, r.LastCostDate = &first, &last
+	}
+
+	estimateTotal := currency.Zero()
+	estimated := make(map[string]bool)
+	for _, l := range a.Lines {
+		estimate := currency.NewFromFloat(l.GrossEstimate)
+		if req.Net {
+			estimate = currency.NewFromFloat(netAmount(l.GrossEstimate, a.WorkingInterest))
+		}
+		actual, ok := c.byCategory[l.Category]
+		if !ok {
+			actual = currency.Zero()
+		}
+		desc := l.Description
+		if desc == "" {
+			desc = names[l.Category]
+		}
+		line := costLine(estimate, actual, req)
+		line.Category, line.Description, line.LineCount = l.Category, desc, c.lines[l.Category]
+		r.Lines = append(r.Lines, line)
+		estimated[l.Category] = true
+		estimateTotal = estimateTotal.Add(estimate)
+	}
+
+	var other []string
+	for category := range c.byCategory {
+		if !estimated[category] {
+			other = append(other, category)
+		}
+	}
+	sort.Strings(other)
+	for _, category := range other {
+		actual := c.byCategory[category]
+		if actual.IsZero() && len(a.Lines) > 0 {
+			continue
+		}
+		line := costLine(currency.Zero(), actual, req)
+		line.Category, line.LineCount = category, c.lines[category]
+		line.Description = names[category]
+		if category == "" {
+			line.Description = "Uncategorized"
+		}
+		if len(a.Lines) > 0 {
+			line.Unestimated = true
+		} else {
+			line.Alert = ""
+		}
+		r.Lines = append(r.Lines, line)
+	}
+
+	// An AFE estimated only as a total is compared as a whole
+	if len(a.Lines) == 0 {
+		estimateTotal = currency.NewFromFloat(a.GrossEstimate)
+		if req.Net {
+			estimateTotal = currency.NewFromFloat(a.NetEstimate)
+		}
+	}
+	r.Total = costLine(estimateTotal, c.total, req)
+	r.Total.Description, r.Total.LineCount = "Total", c.lineCount()
+
+	for _, l := range r.Lines {
+		switch {
+		case l.Alert == "":
+			continue
+		case l.Unestimated:
+			r.Alerts = append(r.Alerts, fmt.Sprintf("%s of costs in category %s, which has no estimate",
+				reports.FormatAmount(l.Actual), categoryLabel(l.Category)))
+			continue
+		}
+		r.Alerts = append(r.Alerts, fmt.Sprintf("Category %s is %s: %s spent of %s", categoryLabel(l.Category),
+			alertName(l.Alert), reports.FormatAmount(l.Actual), reports.FormatAmount(l.Estimate)))
+	}
+	if r.Total.Alert != "" {
+		r.Alerts = append([]string{fmt.Sprintf("AFE %s is %s: %s spent of %s (%s)", a.AFENo, alertName(r.Total.Alert),
+			reports.FormatAmount(r.Total.Actual), reports.FormatAmount(r.Total.Estimate), formatPercent(r.Total.PercentSpent))}, r.Alerts...)
+	}
+
+	if a.IsClosed() && a.FinalActual != nil {
+		r.CostsSinceClose = c.total.Sub(currency.NewFromFloat(*a.FinalActual)).ToFloat64()
+		if r.CostsSinceClose != 0 && a.ActualSource == req.Source {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("%s of costs have been posted since the AFE was closed at %s",
+				reports.FormatAmount(r.CostsSinceClose), reports.FormatAmount(*a.FinalActual)))
+		}
+	}
+	if c.lineCount() == 0 {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("No costs are coded to AFE %s in %s", a.AFENo, sourceName(req.Source)))
+	}
+	if a.WellID == "" {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("AFE %s has no well", a.AFENo))
+	}
+	return r, nil
+}
+
+// AFESummary lists AFEs with estimate, actual, percent spent and alerts, and
+// the AFE numbers that have costs but no AFE record
+func (s *Service) AFESummary(companyName string, req ReportRequest) (*Summary, error) {
+	if err := req.normalize(); err != nil {
+		return nil, err
+	}
+	afes, err := s.GetAFEs(companyName, true)
+	if err != nil {
+		return nil, err
+	}
+	costs, err := s.loadCosts(companyName, req)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := &Summary{
+		CompanyName:    companyName,
+		Source:         req.Source,
+		Net:            req.Net,
+		WarnPercent:    req.WarnPercent,
+		OverrunPercent: req.OverrunPercent,
+		Rows:           []SummaryRow{},
+		Unmatched:      []UnmatchedAFE{},
+		Warnings:       []string{},
+		GeneratedAt:    time.Now(),
+	}
+	if !req.AsOf.IsZero() {
+		asOf := req.AsOf
+		sum.AsOf = &asOf
+	}
+
+	estimateTotal, actualTotal := currency.Zero(), currency.Zero()
+	known := make(map[string]bool)
+	for _, a := range afes {
+		known[strings.ToUpper(a.AFENo)] = true
+		if a.IsClosed() && !req.IncludeClosed {
+			continue
+		}
+		estimate := currency.NewFromFloat(a.GrossEstimate)
+		if req.Net {
+			estimate = currency.NewFromFloat(a.NetEstimate)
+		}
+		actual := currency.Zero()
+		var last *time.Time
+		if c, ok := costs[strings.ToUpper(a.AFENo)]; ok {
+			actual = c.total
+			if !c.last.IsZero() {
+				d := c.last
+				last = &d
+			}
+		}
+		line := costLine(estimate, actual, req)
+		sum.Rows = append(sum.Rows, SummaryRow{
+			ID:           a.ID,
+			AFENo:        a.AFENo,
+			WellID:       a.WellID,
+			WellName:     a.WellName,
+			Description:  a.Description,
+			Status:       a.Status,
+			ApprovalDate: a.ApprovalDate,
+			Estimate:     line.Estimate,
+			Actual:       line.Actual,
+			Variance:     line.Variance,
+			PercentSpent: line.PercentSpent,
+			Alert:        line.Alert,
+			LastCostDate: last,
+		})
+		if line.Alert != "" {
+			sum.AlertCount++
+		}
+		estimateTotal = estimateTotal.Add(estimate)
+		actualTotal = actualTotal.Add(actual)
+	}
+	total := costLine(estimateTotal, actualTotal, req)
+	sum.Total = SummaryRow{Description: "Total", Estimate: total.Estimate, Actual: total.Actual,
+		Variance: total.Variance, PercentSpent: total.PercentSpent}
+
+	for afeNo, c := range costs {
+		if !known[afeNo] {
+			sum.Unmatched = append(sum.Unmatched, UnmatchedAFE{AFENo: afeNo, Actual: c.total.ToFloat64(), LineCount: c.lineCount()})
+		}
+	}
+	sort.Slice(sum.Unmatched, func(i, j int) bool { return sum.Unmatched[i].AFENo < sum.Unmatched[j].AFENo })
+	if len(sum.Unmatched) > 0 {
+		sum.Warnings = append(sum.Warnings, fmt.Sprintf("%d AFE number(s) in %s have costs but no AFE record",
+			len(sum.Unmatched), sourceName(req.Source)))
+	}
+	return sum, nil
+}
+
+// costLine computes the variance, percent spent and alert of an estimate and actual
+func costLine(estimate, actual currency.Currency, req ReportRequest) CostLine {
+	line := CostLine{
+		Estimate: estimate.ToFloat64(),
+		Actual:   actual.ToFloat64(),
+		Variance: actual.Sub(estimate).ToFloat64(),
+	}
+	if !estimate.IsPositive() {
+		if actual.IsPositive() {
+			line.Alert = AlertOverrun
+		}
+		return line
+	}
+	pct := line.Actual / line.Estimate * 100
+	line.PercentSpent = &pct
+	switch {
+	case actual.GreaterThan(estimate) && pct >= 100+req.OverrunPercent:
+		line.Alert = AlertOverrun
+	case actual.GreaterThan(estimate):
+		line.Alert = AlertOver
+	case pct >= req.WarnPercent:
+		line.Alert = AlertWarning
+	}
+	return line
+}
+
+func alertName(alert string) string {
+	switch alert {
+	case AlertOverrun:
+		return "overrun"
+	case AlertOver:
+		return "over estimate"
+	case AlertWarning:
+		return "near its estimate"
+	}
+	return ""
+}
+
+func categoryLabel(category string) string {
+	if category == "" {
+		return "(none)"
+	}
+	return category
+}
+
+func sourceName(source string) string {
+	if source == SourceExpense {
+		return "EXPENSE"
+	}
+	return "GLMASTER"
+}
+
+func formatPercent(pct *float64) string {
+	if pct == nil {
+		return ""
+	}
+	return reports.FormatPercent(*pct / 100)
+}
+
+// alertFlag is the report column text for an alert
+func alertFlag(alert string) string {
+	switch alert {
+	case AlertOverrun:
+		return "OVERRUN"
+	case AlertOver:
+		return "OVER"
+	case AlertWarning:
+		return "WARN"
+	}
+	return ""
+}
+
+// basisLine describes where actuals come from and which estimate they are compared with
+func basisLine(source string, net bool, asOf *time.Time) string {
+	basis := "gross estimate"
+	if net {
+		basis = "net estimate (working interest share)"
+	}
+	line := fmt.Sprintf("Actual costs from %s compared with the %s", sourceName(source), basis)
+	if asOf != nil {
+		line += ", through " + asOf.Format("01/02/2006")
+	}
+	return line
+}
+
+// ReportTable converts an AFE report for PDF/CSV/XLSX output
+func ReportTable(r *Report, displayName string) *reports.Table {
+	a := r.AFE
+	title := "AFE Estimate vs Actual"
+	if a.IsClosed() {
+		title = "AFE Close-Out"
+	}
+	header := "AFE " + a.AFENo
+	if a.Description != "" {
+		header += " - " + a.Description
+	}
+	t := &reports.Table{
+		CompanyName: displayName,
+		Title:       title,
+		Subtitles:   []string{header},
+		Columns: []reports.Column{
+			{Header: "Category", Width: 25},
+			{Header: "Description", Width: 70},
+			{Header: "Estimate", Width: 32, Align: "R"},
+			{Header: "Actual", Width: 32, Align: "R"},
+			{Header: "Variance", Width: 32, Align: "R"},
+			{Header: "% Spent", Width: 22, Align: "R"},
+			{Header: "Lines", Width: 18, Align: "R"},
+			{Header: "", Width: 26, Align: "C"},
+		},
+	}
+	well := "Well " + a.WellID
+	if a.WellName != "" {
+		well += " - " + a.WellName
+	}
+	if a.WellID != "" {
+		t.Subtitles = append(t.Subtitles, well)
+	}
+	approved := fmt.Sprintf("Gross estimate %s, working interest %s", reports.FormatAmount(a.GrossEstimate),
+		reports.FormatPercent(a.WorkingInterest/100))
+	if a.ApprovalDate != nil {
+		approved = "Approved " + a.ApprovalDate.Format("01/02/2006") + ", " + strings.ToLower(approved[:1]) + approved[1:]
+	}
+	t.Subtitles = append(t.Subtitles, approved, basisLine(r.Source, r.Net, r.AsOf))
+
+	for _, l := range r.Lines {
+		desc := l.Description
+		if l.Unestimated {
+			desc += " (no estimate)"
+		}
+		t.Rows = append(t.Rows, reports.Row{
+			Cells: []string{categoryLabel(l.Category), strings.TrimSpace(desc), reports.FormatAmount(l.Estimate),
+				reports.FormatAmount(l.Actual), reports.FormatAmount(l.Variance), formatPercent(l.PercentSpent),
+				fmt.Sprintf("%d", l.LineCount), alertFlag(l.Alert)},
+			Bold: l.Alert == AlertOver || l.Alert == AlertOverrun,
+		})
+	}
+	t.AddTotal("", "Total", reports.FormatAmount(r.Total.Estimate), reports.FormatAmount(r.Total.Actual),
+		reports.FormatAmount(r.Total.Variance), formatPercent(r.Total.PercentSpent),
+		fmt.Sprintf("%d", r.Total.LineCount), alertFlag(r.Total.Alert))
+
+	if a.IsClosed() {
+		closed := "Closed"
+		if a.ClosedAt != nil {
+			closed += " " + a.ClosedAt.Format("01/02/2006")
+		}
+		if a.ClosedBy != "" {
+			closed += " by " + a.ClosedBy
+		}
+		if a.FinalActual != nil {
+			closed += " with final cost " + reports.FormatAmount(*a.FinalActual)
+		}
+		t.Notes = append(t.Notes, closed+".")
+		if a.CloseNotes != "" {
+			t.Notes = append(t.Notes, a.CloseNotes)
+		}
+	}
+	if r.FirstCostDate != nil {
+		t.Notes = append(t.Notes, fmt.Sprintf("Costs dated %s through %s.",
+			r.FirstCostDate.Format("01/02/2006"), r.LastCostDate.Format("01/02/2006")))
+	}
+	t.Notes = append(t.Notes, r.Alerts...)
+	t.Notes = append(t.Notes, r.Warnings...)
+	return t
+}
+
+// SummaryTable converts an AFE summary for PDF/CSV/XLSX output
+func SummaryTable(sum *Summary, displayName string) *reports.Table {
+	t := &reports.Table{
+		CompanyName: displayName,
+		Title:       "AFE Status Summary",
+		Subtitles:   []string{basisLine(sum.Source, sum.Net, sum.AsOf)},
+		Columns: []reports.Column{
+			{Header: "AFE", Width: 22},
+			{Header: "Well", Width: 45},
+			{Header: "Description", Width: 50},
+			{Header: "Status", Width: 16},
+			{Header: "Estimate", Width: 30, Align: "R"},
+			{Header: "Actual", Width: 30, Align: "R"},
+			{Header: "Variance", Width: 30, Align: "R"},
+			{Header: "% Spent", Width: 18, Align: "R"},
+			{Header: "", Width: 18, Align: "C"},
+		},
+	}
+	for _, row := range sum.Rows {
+		well := row.WellID
+		if row.WellName != "" {
+			well += " " + row.WellName
+		}
+		t.Rows = append(t.Rows, reports.Row{
+			Cells: []string{row.AFENo, well, row.Description, row.Status, reports.FormatAmount(row.Estimate),
+				reports.FormatAmount(row.Actual), reports.FormatAmount(row.Variance), formatPercent(row.PercentSpent),
+				alertFlag(row.Alert)},
+			Bold: row.Alert == AlertOver || row.Alert == AlertOverrun,
+		})
+	}
+	t.AddTotal("", "", "Total", "", reports.FormatAmount(sum.Total.Estimate), reports.FormatAmount(sum.Total.Actual),
+		reports.FormatAmount(sum.Total.Variance), formatPercent(sum.Total.PercentSpent), "")
+
+	if sum.AlertCount > 0 {
+		t.Notes = append(t.Notes, fmt.Sprintf("%d AFEs are flagged: WARN at %s spent, OVER above the estimate, OVERRUN at %s over.",
+			sum.AlertCount, reports.FormatPercent(sum.WarnPercent/100), reports.FormatPercent(sum.OverrunPercent/100)))
+	}
+	for _, u := range sum.Unmatched {
+		t.Notes = append(t.Notes, fmt.Sprintf("AFE %s has %s of costs on %d lines but no AFE record.",
+			u.AFENo, reports.FormatAmount(u.Actual), u.LineCount))
+	}
+	return t
+}
@@ -299,6 +299,34 @@ func (u *User) HasAnyPermission(permissions ...string) bool {
 	return false
 }
 
+// HasUserPermission reports whether this database has an active user with the
+// username who is root, an admin, or holds one of the permissions. It is used
+// to check a user's rights in another company's database.
+func (a *Auth) HasUserPermission(username string, permissions ...string) (bool, error) {
+	var user User
+	err := a.db.GetConn().QueryRow(`
+		SELECT u.id, u.is_root, r.name
+		FROM users u
+		JOIN roles r ON u.role_id = r.id
+		WHERE u.username = ? AND u.is_active = TRUE
+	`, username).Scan(&user.ID, &user.IsRoot, &user.RoleName)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.IsAdmin() {
+		return true, nil
+	}
+
+	user.Permissions, err = a.getUserPermissions(user.ID)
+	if err != nil {
+		return false, err
+	}
+	return user.HasAnyPermission(permissions...), nil
+}
+
 // IsAdmin checks if user has admin or root privileges
 func (u *User) IsAdmin() bool {
 	return u.IsRoot || u.RoleName == "admin"
@@ -0,0 +1,517 @@
+// Package balancesync keeps the account balance cache current in the background.
+// The scheduler watches GLMASTER.dbf and CHECKS.dbf for changes and checks the
+// cache for stale balances on a configurable cadence. It runs at most one refresh
+// at a time and reports progress and updated balances through an event callback.
+package balancesync
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/company"
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Events emitted while refreshing
+const (
+	EventStarted  = "balances:refresh-started"
+	EventProgress = "balances:refresh-progress"
+	EventUpdated  = "balances:updated"
+	EventFailed   = "balances:refresh-failed"
+)
+
+// What started a refresh
+const (
+	TriggerStartup    = "startup"
+	TriggerSchedule   = "schedule"
+	TriggerFileChange = "file_change"
+	TriggerManual     = "manual"
+)
+
+// Refresh stages reported in Progress
+const (
+	StageGL     = "gl"
+	StageChecks = "checks"
+	StageDone   = "done"
+)
+
+// Cadence defaults and limits
+const (
+	DefaultIntervalMinutes = 15
+	DefaultPollSeconds     = 30
+	MinIntervalMinutes     = 1
+	MinPollSeconds         = 5
+)
+
+// schedulerUser is recorded in balance_history for background refreshes
+const schedulerUser = "scheduler"
+
+// ErrRefreshRunning is returned when a refresh is requested while one is running
+var ErrRefreshRunning = errors.New("a balance refresh is already running")
+
+// Settings control the background refresh for one company
+type Settings struct {
+	CompanyName     string     `json:"company_name"`
+	Enabled         bool       `json:"enabled"`
+	IntervalMinutes int        `json:"interval_minutes"` // How often the cache is checked for stale balances
+	WatchFiles      bool       `json:"watch_files"`      // Refresh as soon as GLMASTER or CHECKS changes
+	PollSeconds     int        `json:"poll_seconds"`     // How often the files are checked for changes
+	UpdatedBy       string     `json:"updated_by,omitempty"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+}
+
+// DefaultSettings are used until a company saves its own
+func DefaultSettings(companyName string) *Settings {
+	return &Settings{
+		CompanyName:     companyName,
+		Enabled:         true,
+		IntervalMinutes: DefaultIntervalMinutes,
+		WatchFiles:      true,
+		PollSeconds:     DefaultPollSeconds,
+	}
+}
+
+// Validate checks the cadence limits
+func (s Settings) Validate() error {
+	if s.IntervalMinutes < MinIntervalMinutes {
+		return fmt.Errorf("refresh interval must be at least %d minute(s)", MinIntervalMinutes)
+	}
+	if s.WatchFiles && s.PollSeconds < MinPollSeconds {
+		return fmt.Errorf("file check interval must be at least %d seconds", MinPollSeconds)
+	}
+	return nil
+}
+
+// Progress is emitted as EventProgress during a refresh
+type Progress struct {
+	CompanyName string `json:"company_name"`
+	Trigger     string `json:"trigger"`
+	Stage       string `json:"stage"`
+	Current     int    `json:"current"`
+	Total       int    `json:"total"`
+	Message     string `json:"message"`
+}
+
+// Result describes a finished refresh and is emitted as EventUpdated
+type Result struct {
+	CompanyName     string                     `json:"company_name"`
+	Trigger         string                     `json:"trigger"`
+	Reasons         []string                   `json:"reasons"`
+	StartedAt       time.Time                  `json:"started_at"`
+	FinishedAt      time.Time                  `json:"finished_at"`
+	Duration        string                     `json:"duration"`
+	GL              *database.GLBalanceRefresh `json:"gl,omitempty"`
+	BankAccounts    int                        `json:"bank_accounts"`
+	ChecksRefreshed int                        `json:"checks_refreshed"`
+	Errors          []string                   `json:"errors"`
+	Balances        []database.CachedBalance   `json:"balances"`
+	RefreshedBy     string                     `json:"refreshed_by"`
+}
+
+// Status is a snapshot of the scheduler
+type Status struct {
+	CompanyName string    `json:"company_name"`
+	Running     bool      `json:"running"`    // The background loop is active
+	Refreshing  bool      `json:"refreshing"` // A refresh is in progress
+	Settings    *Settings `json:"settings"`
+	NextCheck   time.Time `json:"next_check"`
+	LastResult  *Result   `json:"last_result,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// Emitter delivers an event to the UI
+type Emitter func(event string, data interface{})
+
+// Service runs the background refresh for the open company
+type Service struct {
+	db   *database.DB
+	emit Emitter
+
+	refreshing sync.Mutex // Held for the duration of a refresh
+
+	mu          sync.Mutex // Guards the fields below
+	companyName string
+	settings    *Settings
+	cancel      context.CancelFunc
+	done        chan struct{}
+	nextCheck   time.Time
+	last        *Result
+	lastErr     string
+	busy        bool
+}
+
+// NewService creates a scheduler; call Start to begin background refreshes
+func NewService(db *database.DB) *Service {
+	return &Service{db: db, emit: func(string, interface{}) {}}
+}
+
+// SetEmitter sets where events are sent
+func (s *Service) SetEmitter(emit Emitter) {
+	if emit == nil {
+		emit = func(string, interface{}) {}
+	}
+	s.mu.Lock()
+	s.emit = emit
+	s.mu.Unlock()
+}
+
+// GetSettings returns the company's saved settings or the defaults
+func (s *Service) GetSettings(companyName string) (*Settings, error) {
+	settings := DefaultSettings(companyName)
+	var updatedAt interface{}
+	err := s.db.QueryRow(`
+		SELECT enabled, interval_minutes, watch_files, poll_seconds, COALESCE(updated_by, ''), updated_at
+		FROM balance_refresh_settings WHERE company_name = ?
+	`, companyName).Scan(&settings.Enabled, &settings.IntervalMinutes, &settings.WatchFiles,
+		&settings.PollSeconds, &settings.UpdatedBy, &updatedAt)
+	if err == sql.ErrNoRows {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load balance refresh settings: %w", err)
+	}
+	if t, ok := updatedAt.(time.Time); ok && !t.IsZero() {
+		settings.UpdatedAt = &t
+	} else if t, ok := ledger.AsDate(updatedAt); ok {
+		settings.UpdatedAt = &t
+	}
+	return settings, nil
+}
+
+// SaveSettings stores the company's settings and restarts the background loop
+// with them when it is running for that company
+func (s *Service) SaveSettings(companyName string, settings Settings, username string) (*Settings, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	if settings.PollSeconds < MinPollSeconds {
+		settings.PollSeconds = DefaultPollSeconds
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO balance_refresh_settings
+		(company_name, enabled, interval_minutes, watch_files, poll_seconds, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(company_name) DO UPDATE SET
+			enabled = excluded.enabled,
+			interval_minutes = excluded.interval_minutes,
+			watch_files = excluded.watch_files,
+			poll_seconds = excluded.poll_seconds,
+			updated_by = excluded.updated_by,
+			updated_at = CURRENT_TIMESTAMP
+	`, companyName, settings.Enabled, settings.IntervalMinutes, settings.WatchFiles, settings.PollSeconds, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save balance refresh settings: %w", err)
+	}
+
+	s.mu.Lock()
+	current := s.companyName
+	s.mu.Unlock()
+	if current == companyName {
+		if err := s.Start(companyName); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetSettings(companyName)
+}
+
+// Start begins background refreshes for a company with its saved settings,
+// replacing any loop already running. A disabled company is tracked but not
+// refreshed until its settings are enabled.
+func (s *Service) Start(companyName string) error {
+	settings, err := s.GetSettings(companyName)
+	if err != nil {
+		return err
+	}
+	s.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.companyName = companyName
+	s.settings = settings
+	if !settings.Enabled {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(ctx, s.done, companyName, *settings)
+	return nil
+}
+
+// Stop ends the background loop and waits for it to exit. A refresh already in
+// progress finishes first.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.nextCheck = time.Time{}
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Status returns a snapshot of the scheduler
+func (s *Service) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Status{
+		CompanyName: s.companyName,
+		Running:     s.cancel != nil,
+		Refreshing:  s.busy,
+		Settings:    s.settings,
+		NextCheck:   s.nextCheck,
+		LastResult:  s.last,
+		LastError:   s.lastErr,
+	}
+}
+
+// run is the background loop: a stale check on every interval and, when
+// watching files, a cheap modification-time check on every poll
+func (s *Service) run(ctx context.Context, done chan struct{}, companyName string, settings Settings) {
+	defer close(done)
+
+	interval := time.Duration(settings.IntervalMinutes) * time.Minute
+	check := time.NewTicker(interval)
+	defer check.Stop()
+
+	var poll <-chan time.Time
+	if settings.WatchFiles {
+		ticker := time.NewTicker(time.Duration(settings.PollSeconds) * time.Second)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	s.setNextCheck(time.Now().Add(interval))
+	s.refreshIfNeeded(companyName, TriggerStartup, true)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-check.C:
+			s.setNextCheck(time.Now().Add(interval))
+			s.refreshIfNeeded(companyName, TriggerSchedule, true)
+		case <-poll:
+			s.refreshIfNeeded(companyName, TriggerFileChange, false)
+		}
+	}
+}
+
+func (s *Service) setNextCheck(t time.Time) {
+	s.mu.Lock()
+	s.nextCheck = t
+	s.mu.Unlock()
+}
+
+// refreshIfNeeded runs a refresh when the cache is behind the DBF files or, with
+// checkAge, when the summary view marks balances stale. A refresh already in
+// progress is left to finish; the next tick picks up anything it missed.
+func (s *Service) refreshIfNeeded(companyName, trigger string, checkAge bool) {
+	plan, err := s.plan(companyName, checkAge)
+	if err != nil {
+		s.fail(companyName, trigger, err)
+		return
+	}
+	if !plan.gl && !plan.checks {
+		return
+	}
+	if _, err := s.refresh(companyName, trigger, schedulerUser, plan); err != nil && err != ErrRefreshRunning {
+		fmt.Printf("balancesync: %s refresh for %s failed: %v\n", trigger, companyName, err)
+	}
+}
+
+// Refresh brings every cached balance up to date now, regardless of age
+func (s *Service) Refresh(companyName, username string) (*Result, error) {
+	return s.refresh(companyName, TriggerManual, username, refreshPlan{
+		gl:      true,
+		checks:  true,
+		reasons: []string{"requested"},
+	})
+}
+
+// refreshPlan says which parts of the cache a refresh rebuilds and why
+type refreshPlan struct {
+	gl      bool
+	checks  bool
+	reasons []string
+}
+
+// plan compares the cache with GLMASTER and CHECKS. A file modified after the
+// oldest refresh of the balances derived from it means those balances may be
+// out of date.
+func (s *Service) plan(companyName string, checkAge bool) (refreshPlan, error) {
+	var p refreshPlan
+	balances, err := database.GetCachedBalancesByType(s.db, companyName)
+	if err != nil {
+		return p, fmt.Errorf("failed to read cached balances: %w", err)
+	}
+	if len(balances) == 0 {
+		p.gl, p.checks = true, true
+		p.reasons = append(p.reasons, "balance cache is empty")
+		return p, nil
+	}
+
+	var oldestGL, oldestChecks time.Time
+	staleGL, staleChecks := 0, 0
+	for _, b := range balances {
+		if oldestGL.IsZero() || b.GLLastUpdated.Before(oldestGL) {
+			oldestGL = b.GLLastUpdated
+		}
+		if b.GLFreshness == "stale" {
+			staleGL++
+		}
+		if !b.IsBankAccount {
+			continue
+		}
+		if oldestChecks.IsZero() || b.OutstandingLastUpdated.Before(oldestChecks) {
+			oldestChecks = b.OutstandingLastUpdated
+		}
+		if b.ChecksFreshness == "stale" {
+			staleChecks++
+		}
+	}
+
+	if changed, err := modifiedSince(companyName, "GLMASTER.dbf", oldestGL); err != nil {
+		return p, err
+	} else if changed {
+		p.gl = true
+		p.reasons = append(p.reasons, "GLMASTER.dbf changed")
+	}
+	if !oldestChecks.IsZero() {
+		changed, err := modifiedSince(companyName, "CHECKS.dbf", oldestChecks)
+		if err != nil {
+			return p, err
+		}
+		if changed {
+			p.checks = true
+			p.reasons = append(p.reasons, "CHECKS.dbf changed")
+		}
+	}
+	if checkAge && staleGL > 0 && !p.gl {
+		p.gl = true
+		p.reasons = append(p.reasons, fmt.Sprintf("%d stale GL balance(s)", staleGL))
+	}
+	if checkAge && staleChecks > 0 && !p.checks {
+		p.checks = true
+		p.reasons = append(p.reasons, fmt.Sprintf("%d stale outstanding check total(s)", staleChecks))
+	}
+	return p, nil
+}
+
+// modifiedSince reports whether a DBF file changed after t. Cache timestamps are
+// stored to the second, so a change within the same second counts as newer. A
+// company without the file has nothing to refresh from it.
+func modifiedSince(companyName, fileName string, t time.Time) (bool, error) {
+	info, err := company.StatDBFFile(companyName, fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", fileName, err)
+	}
+	return !info.ModTime().Before(t), nil
+}
+
+// refresh runs one refresh, refusing to start while another is in progress
+func (s *Service) refresh(companyName, trigger, username string, plan refreshPlan) (*Result, error) {
+	if !s.refreshing.TryLock() {
+		return nil, ErrRefreshRunning
+	}
+	defer s.refreshing.Unlock()
+
+	s.mu.Lock()
+	s.busy = true
+	emit := s.emit
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.busy = false
+		s.mu.Unlock()
+	}()
+
+	result := &Result{
+		CompanyName: companyName,
+		Trigger:     trigger,
+		Reasons:     plan.reasons,
+		StartedAt:   time.Now(),
+		Errors:      []string{},
+		RefreshedBy: username,
+	}
+	emit(EventStarted, result)
+	progress := func(stage string, current, total int, message string) {
+		emit(EventProgress, Progress{
+			CompanyName: companyName,
+			Trigger:     trigger,
+			Stage:       stage,
+			Current:     current,
+			Total:       total,
+			Message:     message,
+		})
+	}
+
+	if plan.gl {
+		progress(StageGL, 0, 1, "Totaling GLMASTER")
+		gl, err := database.RefreshAllGLBalances(s.db, companyName, username)
+		if err != nil {
+			s.fail(companyName, trigger, err)
+			return nil, fmt.Errorf("failed to refresh GL balances: %w", err)
+		}
+		result.GL = gl
+		progress(StageGL, 1, 1, fmt.Sprintf("Cached %d account balances", gl.Accounts))
+	}
+
+	bankAccounts, err := database.GetAllCachedBalances(s.db, companyName)
+	if err != nil {
+		s.fail(companyName, trigger, err)
+		return nil, fmt.Errorf("failed to get bank accounts: %w", err)
+	}
+	result.BankAccounts = len(bankAccounts)
+	if plan.checks {
+		for i, account := range bankAccounts {
+			progress(StageChecks, i, len(bankAccounts), fmt.Sprintf("Outstanding checks for %s", account.AccountNumber))
+			if err := database.RefreshOutstandingChecks(s.db, companyName, account.AccountNumber, username); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("Account %s: %v", account.AccountNumber, err))
+				continue
+			}
+			result.ChecksRefreshed++
+		}
+		progress(StageChecks, len(bankAccounts), len(bankAccounts), "Outstanding checks refreshed")
+	}
+
+	result.Balances, err = database.GetCachedBalancesByType(s.db, companyName)
+	if err != nil {
+		s.fail(companyName, trigger, err)
+		return nil, fmt.Errorf("failed to read cached balances: %w", err)
+	}
+	result.FinishedAt = time.Now()
+	result.Duration = result.FinishedAt.Sub(result.StartedAt).Round(time.Millisecond).String()
+	progress(StageDone, 1, 1, "Balances updated")
+
+	s.mu.Lock()
+	s.last = result
+	s.lastErr = ""
+	s.mu.Unlock()
+	emit(EventUpdated, result)
+	return result, nil
+}
+
+// fail records and reports a refresh error
+func (s *Service) fail(companyName, trigger string, err error) {
+	s.mu.Lock()
+	s.lastErr = err.Error()
+	emit := s.emit
+	s.mu.Unlock()
+	emit(EventFailed, map[string]interface{}{
+		"company_name": companyName,
+		"trigger":      trigger,
+		"error":        err.Error(),
+	})
+}
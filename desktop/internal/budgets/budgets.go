@@ -0,0 +1,579 @@
+// Package budgets stores budget versions in SQLite - amounts by account and
+// fiscal period, optionally by unit and department - and compares them with
+// GLMASTER.dbf actuals. Amounts are kept in each account's natural sign, so
+// revenue and expense budgets are both positive.
+package budgets
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/periods"
+	"github.com/shopspring/decimal"
+)
+
+// Service provides budget operations
+type Service struct {
+	db      *database.DB
+	periods *periods.Service
+}
+
+// NewService creates a new budgets service
+func NewService(db *database.DB) *Service {
+	return &Service{db: db, periods: periods.NewService(db)}
+}
+
+// Version is one budget for a fiscal year (original, revised, forecast...)
+type Version struct {
+	ID          int        `json:"id"`
+	CompanyName string     `json:"company_name"`
+	FiscalYear  int        `json:"fiscal_year"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	LineCount   int        `json:"line_count"`
+	Total       float64    `json:"total"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedBy   string     `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// Line is the budget for one account, unit and department with an amount for
+// each period of the year (Amounts[0] is period 1)
+type Line struct {
+	AccountNo   string    `json:"account_number"`
+	Description string    `json:"description,omitempty"`
+	UnitNo      string    `json:"unit_number"`
+	DeptNo      string    `json:"dept_number"`
+	Amounts     []float64 `json:"amounts"`
+	Total       float64   `json:"total"`
+}
+
+// key identifies a budget line
+type key struct {
+	account, unit, dept string
+}
+
+func (l Line) key() key {
+	return key{l.AccountNo, l.UnitNo, l.DeptNo}
+}
+
+// ImportResult summarizes a budget CSV import
+type ImportResult struct {
+	LinesImported int      `json:"lines_imported"`
+	RowsSkipped   int      `json:"rows_skipped"`
+	Warnings      []string `json:"warnings"`
+}
+
+// GetVersions lists budget versions; a zero year returns every year
+func (s *Service) GetVersions(companyName string, year int) ([]Version, error) {
+	query := versionSelect + ` WHERE v.company_name = ?`
+	args := []interface{}{companyName}
+	if year > 0 {
+		query += ` AND v.fiscal_year = ?`
+		args = append(args, year)
+	}
+	query += ` GROUP BY v.id ORDER BY v.fiscal_year DESC, v.name`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budget versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []Version{}
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *v)
+	}
+	return versions, rows.Err()
+}
+
+// GetVersion returns one budget version
+func (s *Service) GetVersion(companyName string, id int) (*Version, error) {
+	v, err := scanVersion(s.db.QueryRow(versionSelect+` WHERE v.company_name = ? AND v.id = ? GROUP BY v.id`, companyName, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("budget version %d not found", id)
+	}
+	return v, err
+}
+
+// SaveVersion creates a version or renames an existing one. Names are unique
+// within a fiscal year.
+func (s *Service) SaveVersion(v *Version, username string) (*Version, error) {
+	v.Name = strings.TrimSpace(v.Name)
+	if v.Name == "" {
+		return nil, fmt.Errorf("budget name is required")
+	}
+	if v.FiscalYear < 1900 || v.FiscalYear > 2999 {
+		return nil, fmt.Errorf("invalid fiscal year: %d", v.FiscalYear)
+	}
+
+	if v.ID > 0 {
+		result, err := s.db.Exec(`
+			UPDATE budget_versions SET name = ?, description = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND company_name = ?
+		`, v.Name, v.Description, username, v.ID, v.CompanyName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update budget version: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return nil, fmt.Errorf("budget version %d not found", v.ID)
+		}
+		return s.GetVersion(v.CompanyName, v.ID)
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO budget_versions (company_name, fiscal_year, name, description, created_by, updated_by)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, v.CompanyName, v.FiscalYear, v.Name, v.Description, username, username)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, fmt.Errorf("a %d budget named %s already exists", v.FiscalYear, v.Name)
+		}
+		return nil, fmt.Errorf("failed to create budget version: %w", err)
+	}
+	id, _ := result.LastInsertId()
+	return s.GetVersion(v.CompanyName, int(id))
+}
+
+// DeleteVersion removes a version and its amounts
+func (s *Service) DeleteVersion(companyName string, id int) error {
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM budget_versions WHERE id = ? AND company_name = ?`, id, companyName)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget version: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("budget version %d not found", id)
+	}
+	if _, err := tx.Exec(`DELETE FROM budget_amounts WHERE version_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete budget amounts: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetLines returns a version's amounts as one line per account, unit and department
+func (s *Service) GetLines(companyName string, versionID int) ([]Line, error) {
+	if _, err := s.GetVersion(companyName, versionID); err != nil {
+		return nil, err
+	}
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`
+		SELECT account_number, unit_number, dept_number, period, amount
+		FROM budget_amounts WHERE version_id = ?
+		ORDER BY account_number, unit_number, dept_number, period
+	`, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budget amounts: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []Line{}
+	index := make(map[key]int)
+	for rows.Next() {
+		var k key
+		var period int
+		var amount float64
+		if err := rows.Scan(&k.account, &k.unit, &k.dept, &period, &amount); err != nil {
+			return nil, fmt.Errorf("failed to scan budget amount: %w", err)
+		}
+		i, ok := index[k]
+		if !ok {
+			i = len(lines)
+			index[k] = i
+			lines = append(lines, Line{AccountNo: k.account, UnitNo: k.unit, DeptNo: k.dept,
+				Amounts: make([]float64, cal.PeriodCount())})
+		}
+		if period >= 1 && period <= len(lines[i].Amounts) {
+			lines[i].Amounts[period-1] = amount
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if accounts, err := ledger.LoadAccounts(companyName); err == nil {
+		accountMap := ledger.AccountMap(accounts)
+		for i := range lines {
+			lines[i].Description = accountMap[lines[i].AccountNo].Description
+		}
+	}
+	for i := range lines {
+		lines[i].Total = lineTotal(lines[i].Amounts)
+	}
+	return lines, nil
+}
+
+// SaveLines writes budget lines to a version. With replace the version's
+// existing amounts are removed first; otherwise lines are merged by account,
+// unit and department.
+func (s *Service) SaveLines(companyName string, versionID int, lines []Line, replace bool, username string) error {
+	if _, err := s.GetVersion(companyName, versionID); err != nil {
+		return err
+	}
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return err
+	}
+	seen := make(map[key]bool)
+	for i := range lines {
+		l := &lines[i]
+		l.AccountNo = strings.TrimSpace(l.AccountNo)
+		l.UnitNo = strings.TrimSpace(l.UnitNo)
+		l.DeptNo = strings.TrimSpace(l.DeptNo)
+		if l.AccountNo == "" {
+			return fmt.Errorf("line %d: an account is required", i+1)
+		}
+		if len(l.Amounts) > cal.PeriodCount() {
+			return fmt.Errorf("line %d: %d periods given, the year has %d", i+1, len(l.Amounts), cal.PeriodCount())
+		}
+		if seen[l.key()] {
+			return fmt.Errorf("line %d: account %s appears more than once for the same unit and department", i+1, l.AccountNo)
+		}
+		seen[l.key()] = true
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if replace {
+		if _, err := tx.Exec(`DELETE FROM budget_amounts WHERE version_id = ?`, versionID); err != nil {
+			return fmt.Errorf("failed to clear budget amounts: %w", err)
+		}
+	}
+	for _, l := range lines {
+		for p := 1; p <= cal.PeriodCount(); p++ {
+			amount := 0.0
+			if p <= len(l.Amounts) {
+				amount = currency.NewFromFloat(l.Amounts[p-1]).ToFloat64()
+			}
+			if _, err := tx.Exec(`
+				INSERT INTO budget_amounts (version_id, account_number, unit_number, dept_number, period, amount)
+				VALUES (?, ?, ?, ?, ?, ?)
+				ON CONFLICT(version_id, account_number, unit_number, dept_number, period) DO UPDATE SET amount = excluded.amount
+			`, versionID, l.AccountNo, l.UnitNo, l.DeptNo, p, amount); err != nil {
+				return fmt.Errorf("failed to save budget amount: %w", err)
+			}
+		}
+	}
+	if _, err := tx.Exec(`UPDATE budget_versions SET updated_by = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		username, versionID); err != nil {
+		return fmt.Errorf("failed to update budget version: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ImportCSV loads budget amounts from CSV. Two layouts are accepted, both with
+// a header row:
+//
+//	Account, Unit, Dept, P1 ... P12        (one column per period; Jan..Dec also work)
+//	Account, Unit, Dept, Period, Amount    (one row per period)
+//
+// Unit and Dept are optional. Accounts missing from COA are imported with a warning.
+func (s *Service) ImportCSV(companyName string, versionID int, content string, replace bool, username string) (*ImportResult, error) {
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(strings.TrimSpace(content), "\ufeff")))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget header: %w", err)
+	}
+	col := func(names ...string) int {
+		for i, h := range header {
+			h = strings.ToLower(strings.TrimSpace(h))
+			for _, n := range names {
+				if h == n {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+	acctCol := col("account", "account number", "account no", "acct", "acctno", "cacctno")
+	unitCol := col("unit", "unit number", "unit no", "well", "cunitno")
+	deptCol := col("dept", "department", "dept no", "cdeptno")
+	periodCol := col("period", "cperiod")
+	amountCol := col("amount", "budget")
+	if acctCol < 0 {
+		return nil, fmt.Errorf("the budget file needs an Account column")
+	}
+	periodCols := make(map[int]int) // column -> period
+	months := []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		for p := 1; p <= cal.PeriodCount(); p++ {
+			if h == fmt.Sprintf("p%d", p) || h == fmt.Sprintf("p%02d", p) || h == fmt.Sprintf("period %d", p) ||
+				h == strconv.Itoa(p) || (p <= len(months) && strings.HasPrefix(h, months[p-1])) {
+				periodCols[i] = p
+			}
+		}
+	}
+	long := periodCol >= 0 && amountCol >= 0
+	if !long && len(periodCols) == 0 {
+		return nil, fmt.Errorf("the budget file needs Period and Amount columns or one column per period (P1..P12)")
+	}
+
+	accountMap := map[string]ledger.Account{}
+	if accounts, err := ledger.LoadAccounts(companyName); err == nil {
+		accountMap = ledger.AccountMap(accounts)
+	}
+
+	result := &ImportResult{Warnings: []string{}}
+	var lines []Line
+	index := make(map[key]int)
+	cell := func(record []string, i int) string {
+		if i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	rowNo := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read budget file: %w", err)
+		}
+		rowNo++
+		k := key{cell(record, acctCol), cell(record, unitCol), cell(record, deptCol)}
+		if k.account == "" {
+			result.RowsSkipped++
+			continue
+		}
+		i, ok := index[k]
+		if !ok {
+			i = len(lines)
+			index[k] = i
+			lines = append(lines, Line{AccountNo: k.account, UnitNo: k.unit, DeptNo: k.dept,
+				Amounts: make([]float64, cal.PeriodCount())})
+			if _, inChart := accountMap[k.account]; !inChart && len(accountMap) > 0 {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("row %d: account %s is not in the chart of accounts", rowNo, k.account))
+			}
+		}
+
+		if long {
+			p, err := strconv.Atoi(cell(record, periodCol))
+			if err != nil || p < 1 || p > cal.PeriodCount() {
+				return nil, fmt.Errorf("row %d: invalid period %q", rowNo, cell(record, periodCol))
+			}
+			amount, err := parseAmount(cell(record, amountCol))
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", rowNo, err)
+			}
+			lines[i].Amounts[p-1] += amount
+			continue
+		}
+		for c, p := range periodCols {
+			amount, err := parseAmount(cell(record, c))
+			if err != nil {
+				return nil, fmt.Errorf("row %d, %s: %w", rowNo, header[c], err)
+			}
+			lines[i].Amounts[p-1] += amount
+		}
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("the budget file has no amounts")
+	}
+
+	if err := s.SaveLines(companyName, versionID, lines, replace, username); err != nil {
+		return nil, err
+	}
+	result.LinesImported = len(lines)
+	return result, nil
+}
+
+// CopyOptions controls building a budget from a prior year's actuals
+type CopyOptions struct {
+	SourceYear    int     `json:"source_year"`
+	AdjustPercent float64 `json:"adjust_percent"` // 5 raises every amount 5%, -10 lowers it 10%
+	ByUnit        bool    `json:"by_unit"`
+	ByDept        bool    `json:"by_dept"`
+}
+
+// CopyFromActuals replaces a version's amounts with the income statement
+// activity of another fiscal year, adjusted by a percentage. Year-end closing
+// entries are ignored.
+func (s *Service) CopyFromActuals(companyName string, versionID int, opts CopyOptions, username string) (int, error) {
+	if _, err := s.GetVersion(companyName, versionID); err != nil {
+		return 0, err
+	}
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return 0, err
+	}
+	entries, err := ledger.LoadGLEntries(companyName)
+	if err != nil {
+		return 0, err
+	}
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return 0, err
+	}
+	accountMap := ledger.AccountMap(accounts)
+	factor := decimal.NewFromFloat(1 + opts.AdjustPercent/100)
+
+	activity := make(map[key][]currency.Currency)
+	for _, e := range entries {
+		p, ok := e.FiscalPeriodIn(cal)
+		if !ok || p.Year != opts.SourceYear || e.IsClosingEntry() || p.Period > cal.PeriodCount() {
+			continue
+		}
+		a, ok := accountMap[e.AccountNo]
+		if !ok || !isIncomeStatement(a.Type) {
+			continue
+		}
+		k := key{account: e.AccountNo}
+		if opts.ByUnit {
+			k.unit = e.UnitNo
+		}
+		if opts.ByDept {
+			k.dept = e.DeptNo
+		}
+		amounts, ok := activity[k]
+		if !ok {
+			amounts = make([]currency.Currency, cal.PeriodCount())
+			for i := range amounts {
+				amounts[i] = currency.Zero()
+			}
+			activity[k] = amounts
+		}
+		amounts[p.Period-1] = amounts[p.Period-1].Add(natural(e.Net(), a.Type))
+	}
+	if len(activity) == 0 {
+		return 0, fmt.Errorf("no income statement activity was found for %d", opts.SourceYear)
+	}
+
+	keys := make([]key, 0, len(activity))
+	for k := range activity {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].account != keys[j].account {
+			return keys[i].account < keys[j].account
+		}
+		if keys[i].unit != keys[j].unit {
+			return keys[i].unit < keys[j].unit
+		}
+		return keys[i].dept < keys[j].dept
+	})
+	lines := make([]Line, 0, len(keys))
+	for _, k := range keys {
+		l := Line{AccountNo: k.account, UnitNo: k.unit, DeptNo: k.dept, Amounts: make([]float64, cal.PeriodCount())}
+		for i, amt := range activity[k] {
+			l.Amounts[i] = amt.Mul(factor).ToFloat64()
+		}
+		lines = append(lines, l)
+	}
+	if err := s.SaveLines(companyName, versionID, lines, true, username); err != nil {
+		return 0, err
+	}
+	return len(lines), nil
+}
+
+// isIncomeStatement reports whether an account type closes to retained earnings
+func isIncomeStatement(accountType int) bool {
+	return accountType >= 4
+}
+
+// natural converts debit-positive GL activity to the account's natural sign
+func natural(net currency.Currency, accountType int) currency.Currency {
+	if ledger.IsDebitNormal(accountType) {
+		return net
+	}
+	return net.Neg()
+}
+
+func parseAmount(s string) (float64, error) {
+	s = strings.NewReplacer("$", "", ",", "", " ", "").Replace(s)
+	if s == "" || s == "-" {
+		return 0, nil
+	}
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	if negative {
+		s = s[1 : len(s)-1]
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+func lineTotal(amounts []float64) float64 {
+	total := currency.Zero()
+	for _, a := range amounts {
+		total = total.Add(currency.NewFromFloat(a))
+	}
+	return total.ToFloat64()
+}
+
+const versionSelect = `
+	SELECT v.id, v.company_name, v.fiscal_year, v.name, COALESCE(v.description, ''),
+	       COUNT(DISTINCT a.account_number || '|' || a.unit_number || '|' || a.dept_number), COALESCE(SUM(a.amount), 0),
+	       COALESCE(v.created_by, ''), v.created_at, COALESCE(v.updated_by, ''), v.updated_at
+	FROM budget_versions v
+	LEFT JOIN budget_amounts a ON a.version_id = v.id`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanVersion(row rowScanner) (*Version, error) {
+	var v Version
+	var createdAt, updatedAt interface{}
+	if err := row.Scan(&v.ID, &v.CompanyName, &v.FiscalYear, &v.Name, &v.Description, &v.LineCount, &v.Total,
+		&v.CreatedBy, &createdAt, &v.UpdatedBy, &updatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan budget version: %w", err)
+	}
+	v.Total = currency.NewFromFloat(v.Total).ToFloat64()
+	v.CreatedAt = timestamp(createdAt)
+	v.UpdatedAt = timestamp(updatedAt)
+	return &v, nil
+}
+
+func timestamp(v interface{}) *time.Time {
+	if t, ok := v.(time.Time); ok && !t.IsZero() {
+		return &t
+	}
+	if t, ok := ledger.AsDate(v); ok {
+		return &t
+	}
+	return nil
+}
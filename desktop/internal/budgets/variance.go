@@ -0,0 +1,351 @@
+package budgets
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/reports"
+)
+
+// DefaultThresholdPercent is the overrun threshold used when none is given
+const DefaultThresholdPercent = 10
+
+// VarianceRequest selects the budget and period of a variance report. A line
+// is an overrun when it is unfavorable by at least ThresholdPercent of budget
+// and at least ThresholdAmount; a zero threshold is not applied.
+type VarianceRequest struct {
+	VersionID         int           `json:"version_id"`
+	Period            ledger.Period `json:"period"`
+	ThresholdPercent  float64       `json:"threshold_percent"`
+	ThresholdAmount   float64       `json:"threshold_amount"`
+	IncludeUnbudgeted bool          `json:"include_unbudgeted"` // Income statement accounts with actuals but no budget
+}
+
+// VarianceLine compares budget and actual for one budget line. Amounts are in
+// natural sign and variance is actual minus budget; percentages are of budget
+// and nil when there is no budget.
+type VarianceLine struct {
+	AccountNumber  string   `json:"account_number"`
+	Description    string   `json:"description"`
+	AccountType    int      `json:"account_type"`
+	TypeName       string   `json:"account_type_name"`
+	UnitNo         string   `json:"unit_number"`
+	DeptNo         string   `json:"dept_number"`
+	PeriodBudget   float64  `json:"period_budget"`
+	PeriodActual   float64  `json:"period_actual"`
+	PeriodVariance float64  `json:"period_variance"`
+	PeriodPercent  *float64 `json:"period_percent"`
+	PeriodOverrun  bool     `json:"period_overrun"`
+	YTDBudget      float64  `json:"ytd_budget"`
+	YTDActual      float64  `json:"ytd_actual"`
+	YTDVariance    float64  `json:"ytd_variance"`
+	YTDPercent     *float64 `json:"ytd_percent"`
+	YTDOverrun     bool     `json:"ytd_overrun"`
+}
+
+// Variance is a budget-vs-actual report for one period and year to date
+type Variance struct {
+	CompanyName      string         `json:"company_name"`
+	Version          Version        `json:"version"`
+	Period           ledger.Period  `json:"period"`
+	ThresholdPercent float64        `json:"threshold_percent"`
+	ThresholdAmount  float64        `json:"threshold_amount"`
+	Lines            []VarianceLine `json:"lines"`
+	Totals           []VarianceLine `json:"totals"` // Revenue, expenses and net income for income statement lines
+	OverrunCount     int            `json:"overrun_count"`
+	Warnings         []string       `json:"warnings"`
+	GeneratedAt      time.Time      `json:"generated_at"`
+}
+
+// varianceAmounts accumulates budget and actual for a period and year to date
+type varianceAmounts struct {
+	periodBudget, periodActual, ytdBudget, ytdActual currency.Currency
+}
+
+func newVarianceAmounts() *varianceAmounts {
+	return &varianceAmounts{currency.Zero(), currency.Zero(), currency.Zero(), currency.Zero()}
+}
+
+func (v *varianceAmounts) add(o *varianceAmounts, sign int) {
+	if sign < 0 {
+		o = &varianceAmounts{o.periodBudget.Neg(), o.periodActual.Neg(), o.ytdBudget.Neg(), o.ytdActual.Neg()}
+	}
+	v.periodBudget = v.periodBudget.Add(o.periodBudget)
+	v.periodActual = v.periodActual.Add(o.periodActual)
+	v.ytdBudget = v.ytdBudget.Add(o.ytdBudget)
+	v.ytdActual = v.ytdActual.Add(o.ytdActual)
+}
+
+// BudgetVariance compares a budget version with GLMASTER actuals for a period
+// and the year to date. Actuals exclude year-end closing entries. Accounts
+// budgeted by unit or department are compared at that level; overruns are
+// debit-normal lines (expenses) over budget and credit-normal lines (revenue)
+// under it.
+func (s *Service) BudgetVariance(companyName string, req VarianceRequest) (*Variance, error) {
+	version, err := s.GetVersion(companyName, req.VersionID)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	if req.Period.IsZero() {
+		req.Period = ledger.Period{Year: version.FiscalYear, Period: cal.PeriodCount()}
+	}
+	if req.Period.Year != version.FiscalYear {
+		return nil, fmt.Errorf("budget %s is for %d, not %d", version.Name, version.FiscalYear, req.Period.Year)
+	}
+	if req.Period.Period < 1 || req.Period.Period > cal.PeriodCount() {
+		return nil, fmt.Errorf("invalid period: %s", req.Period)
+	}
+	if req.ThresholdPercent < 0 || req.ThresholdAmount < 0 {
+		return nil, fmt.Errorf("thresholds cannot be negative")
+	}
+
+	lines, err := s.GetLines(companyName, req.VersionID)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ledger.LoadGLEntries(companyName)
+	if err != nil {
+		return nil, err
+	}
+	accountMap := ledger.AccountMap(accounts)
+
+	v := &Variance{
+		CompanyName:      companyName,
+		Version:          *version,
+		Period:           req.Period,
+		ThresholdPercent: req.ThresholdPercent,
+		ThresholdAmount:  req.ThresholdAmount,
+		Lines:            []VarianceLine{},
+		Totals:           []VarianceLine{},
+		Warnings:         []string{},
+		GeneratedAt:      time.Now(),
+	}
+
+	// Budget amounts and the level each account is budgeted at
+	byUnit, byDept := make(map[string]bool), make(map[string]bool)
+	totals := make(map[key]*varianceAmounts)
+	for _, l := range lines {
+		amt := newVarianceAmounts()
+		for i, a := range l.Amounts {
+			if i+1 == req.Period.Period {
+				amt.periodBudget = currency.NewFromFloat(a)
+			}
+			if i+1 <= req.Period.Period {
+				amt.ytdBudget = amt.ytdBudget.Add(currency.NewFromFloat(a))
+			}
+		}
+		totals[l.key()] = amt
+		byUnit[l.AccountNo] = byUnit[l.AccountNo] || l.UnitNo != ""
+		byDept[l.AccountNo] = byDept[l.AccountNo] || l.DeptNo != ""
+	}
+
+	missing := make(map[string]bool)
+	for _, e := range entries {
+		p, ok := e.FiscalPeriodIn(cal)
+		if !ok || p.Year != req.Period.Year || p.Period > req.Period.Period || e.IsClosingEntry() {
+			continue
+		}
+		k := key{account: e.AccountNo}
+		if byUnit[e.AccountNo] {
+			k.unit = e.UnitNo
+		}
+		if byDept[e.AccountNo] {
+			k.dept = e.DeptNo
+		}
+		amt, ok := totals[k]
+		if !ok {
+			a, inChart := accountMap[e.AccountNo]
+			if !req.IncludeUnbudgeted || !inChart || !isIncomeStatement(a.Type) {
+				continue
+			}
+			amt = newVarianceAmounts()
+			totals[k] = amt
+		}
+		actual := natural(e.Net(), accountMap[e.AccountNo].Type)
+		if p.Period == req.Period.Period {
+			amt.periodActual = amt.periodActual.Add(actual)
+		}
+		amt.ytdActual = amt.ytdActual.Add(actual)
+	}
+
+	keys := make([]key, 0, len(totals))
+	for k := range totals {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].account != keys[j].account {
+			return keys[i].account < keys[j].account
+		}
+		if keys[i].unit != keys[j].unit {
+			return keys[i].unit < keys[j].unit
+		}
+		return keys[i].dept < keys[j].dept
+	})
+
+	revenue, expenses := newVarianceAmounts(), newVarianceAmounts()
+	for _, k := range keys {
+		a, inChart := accountMap[k.account]
+		if !inChart && !missing[k.account] {
+			missing[k.account] = true
+			v.Warnings = append(v.Warnings, fmt.Sprintf("Budgeted account %s is not in the chart of accounts", k.account))
+		}
+		amt := totals[k]
+		line := varianceLine(amt, a.Type, req)
+		line.AccountNumber, line.Description, line.UnitNo, line.DeptNo = k.account, a.Description, k.unit, k.dept
+		line.AccountType, line.TypeName = a.Type, ledger.AccountTypeName(a.Type)
+		if line.PeriodOverrun || line.YTDOverrun {
+			v.OverrunCount++
+		}
+		v.Lines = append(v.Lines, line)
+
+		switch {
+		case a.Type == 4:
+			revenue.add(amt, 1)
+		case isIncomeStatement(a.Type):
+			expenses.add(amt, 1)
+		}
+	}
+
+	netIncome := newVarianceAmounts()
+	netIncome.add(revenue, 1)
+	netIncome.add(expenses, -1)
+	for _, t := range []struct {
+		label       string
+		amt         *varianceAmounts
+		accountType int
+	}{{"Total Revenue", revenue, 4}, {"Total Expenses", expenses, 5}, {"Net Income", netIncome, 4}} {
+		line := varianceLine(t.amt, t.accountType, req)
+		line.Description = t.label
+		v.Totals = append(v.Totals, line)
+	}
+	return v, nil
+}
+
+// varianceLine computes the variances and overrun flags for budget and actual
+// amounts of an account type
+func varianceLine(amt *varianceAmounts, accountType int, req VarianceRequest) VarianceLine {
+	line := VarianceLine{
+		PeriodBudget: amt.periodBudget.ToFloat64(),
+		PeriodActual: amt.periodActual.ToFloat64(),
+		YTDBudget:    amt.ytdBudget.ToFloat64(),
+		YTDActual:    amt.ytdActual.ToFloat64(),
+	}
+	line.PeriodVariance = amt.periodActual.Sub(amt.periodBudget).ToFloat64()
+	line.YTDVariance = amt.ytdActual.Sub(amt.ytdBudget).ToFloat64()
+	line.PeriodPercent = variancePercent(line.PeriodVariance, line.PeriodBudget)
+	line.YTDPercent = variancePercent(line.YTDVariance, line.YTDBudget)
+	line.PeriodOverrun = isOverrun(line.PeriodVariance, line.PeriodPercent, accountType, req)
+	line.YTDOverrun = isOverrun(line.YTDVariance, line.YTDPercent, accountType, req)
+	return line
+}
+
+func variancePercent(variance, budget float64) *float64 {
+	if budget == 0 {
+		return nil
+	}
+	pct := variance / abs(budget) * 100
+	return &pct
+}
+
+// isOverrun reports whether a variance is unfavorable and past the thresholds.
+// Without a budget only the amount threshold applies.
+func isOverrun(variance float64, pct *float64, accountType int, req VarianceRequest) bool {
+	unfavorable := variance > 0
+	if !ledger.IsDebitNormal(accountType) {
+		unfavorable = variance < 0
+	}
+	if !unfavorable || variance == 0 {
+		return false
+	}
+	if req.ThresholdAmount > 0 && abs(variance) < req.ThresholdAmount {
+		return false
+	}
+	if req.ThresholdPercent > 0 && pct != nil && abs(*pct) < req.ThresholdPercent {
+		return false
+	}
+	return true
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+// VarianceTable converts a variance report for PDF/CSV output
+func VarianceTable(v *Variance, displayName string) *reports.Table {
+	t := &reports.Table{
+		CompanyName: displayName,
+		Title:       "Budget vs Actual",
+		Subtitles: []string{
+			fmt.Sprintf("%s %d - period %s and year to date", v.Version.Name, v.Version.FiscalYear, v.Period),
+		},
+		Columns: []reports.Column{
+			{Header: "Account", Width: 20},
+			{Header: "Description", Width: 45},
+			{Header: "Budget", Width: 21, Align: "R"},
+			{Header: "Actual", Width: 21, Align: "R"},
+			{Header: "Variance", Width: 21, Align: "R"},
+			{Header: "Var %", Width: 14, Align: "R"},
+			{Header: "YTD Budget", Width: 23, Align: "R"},
+			{Header: "YTD Actual", Width: 23, Align: "R"},
+			{Header: "YTD Variance", Width: 23, Align: "R"},
+			{Header: "YTD Var %", Width: 16, Align: "R"},
+			{Header: "", Width: 12, Align: "C"},
+		},
+	}
+	if v.ThresholdPercent > 0 || v.ThresholdAmount > 0 {
+		t.Subtitles = append(t.Subtitles, fmt.Sprintf("Overrun threshold: %s and %s",
+			reports.FormatPercent(v.ThresholdPercent/100), reports.FormatAmount(v.ThresholdAmount)))
+	}
+
+	cells := func(l VarianceLine, account, desc string) []string {
+		flag := ""
+		if l.PeriodOverrun || l.YTDOverrun {
+			flag = "OVER"
+		}
+		return []string{account, desc,
+			reports.FormatAmount(l.PeriodBudget), reports.FormatAmount(l.PeriodActual),
+			reports.FormatAmount(l.PeriodVariance), formatPercent(l.PeriodPercent),
+			reports.FormatAmount(l.YTDBudget), reports.FormatAmount(l.YTDActual),
+			reports.FormatAmount(l.YTDVariance), formatPercent(l.YTDPercent), flag}
+	}
+	for _, l := range v.Lines {
+		desc := l.Description
+		if l.UnitNo != "" {
+			desc += " / unit " + l.UnitNo
+		}
+		if l.DeptNo != "" {
+			desc += " / dept " + l.DeptNo
+		}
+		t.Rows = append(t.Rows, reports.Row{Cells: cells(l, l.AccountNumber, desc), Bold: l.PeriodOverrun || l.YTDOverrun})
+	}
+	for _, l := range v.Totals {
+		t.AddTotal(cells(l, "", l.Description)...)
+	}
+
+	if v.OverrunCount > 0 {
+		t.Notes = append(t.Notes, fmt.Sprintf("%d lines marked OVER are expenses over budget or revenue under budget beyond the threshold.", v.OverrunCount))
+	}
+	t.Notes = append(t.Notes, v.Warnings...)
+	return t
+}
+
+func formatPercent(pct *float64) string {
+	if pct == nil {
+		return ""
+	}
+	return reports.FormatPercent(*pct / 100)
+}
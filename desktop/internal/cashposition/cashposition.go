@@ -0,0 +1,473 @@
+// Package cashposition builds the daily cash position across all bank accounts:
+// GL balance, latest bank balance, outstanding items and upcoming AP, with a
+// snapshot history and minimum balance alerts.
+package cashposition
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/reports"
+)
+
+// DefaultHorizonDays is how far ahead AP due dates are counted as upcoming
+const DefaultHorizonDays = 14
+
+// AccountSettings holds the per-account cash position options
+type AccountSettings struct {
+	AccountNumber  string  `json:"account_number"`
+	MinimumBalance float64 `json:"minimum_balance"`
+	PaysAP         bool    `json:"pays_ap"` // Upcoming AP is projected against this account
+}
+
+// AccountPosition is one bank account's line in the cash position
+type AccountPosition struct {
+	AccountNumber     string     `json:"account_number"`
+	AccountName       string     `json:"account_name"`
+	GLBalance         float64    `json:"gl_balance"`
+	GLLastUpdated     time.Time  `json:"gl_last_updated"`
+	GLFreshness       string     `json:"gl_freshness"`
+	BankBalance       *float64   `json:"bank_balance"`
+	BankBalanceDate   *time.Time `json:"bank_balance_date"`
+	BankBalanceSource string     `json:"bank_balance_source"`
+	OutstandingChecks float64    `json:"outstanding_checks"`
+	CheckCount        int        `json:"check_count"`
+	DepositsInTransit float64    `json:"deposits_in_transit"`
+	DepositCount      int        `json:"deposit_count"`
+	// ExpectedBankBalance is GL + outstanding checks - deposits in transit
+	ExpectedBankBalance float64 `json:"expected_bank_balance"`
+	UpcomingAP          float64 `json:"upcoming_ap"`
+	ProjectedBalance    float64 `json:"projected_balance"` // GL balance less upcoming AP
+	MinimumBalance      float64 `json:"minimum_balance"`
+	BelowMinimum        bool    `json:"below_minimum"`
+	PaysAP              bool    `json:"pays_ap"`
+}
+
+// Alert is raised when an account's projected balance drops below its minimum
+type Alert struct {
+	AccountNumber    string  `json:"account_number"`
+	AccountName      string  `json:"account_name"`
+	ProjectedBalance float64 `json:"projected_balance"`
+	MinimumBalance   float64 `json:"minimum_balance"`
+	Shortfall        float64 `json:"shortfall"`
+	Message          string  `json:"message"`
+}
+
+// Position is the cash position for a company
+type Position struct {
+	CompanyName      string            `json:"company_name"`
+	AsOf             time.Time         `json:"as_of"`
+	HorizonDays      int               `json:"horizon_days"`
+	Accounts         []AccountPosition `json:"accounts"`
+	UpcomingAP       []ledger.Payable  `json:"upcoming_ap"`
+	TotalGL          float64           `json:"total_gl_balance"`
+	TotalBank        float64           `json:"total_bank_balance"`
+	TotalOutstanding float64           `json:"total_outstanding_checks"`
+	TotalDeposits    float64           `json:"total_deposits_in_transit"`
+	TotalUpcomingAP  float64           `json:"total_upcoming_ap"`
+	TotalProjected   float64           `json:"total_projected_balance"`
+	UnassignedAP     float64           `json:"unassigned_ap"` // AP not projected against any account
+	Alerts           []Alert           `json:"alerts"`
+	Warnings         []string          `json:"warnings"`
+}
+
+// Snapshot is a saved daily cash position line
+type Snapshot struct {
+	SnapshotDate      time.Time `json:"snapshot_date"`
+	AccountNumber     string    `json:"account_number"`
+	AccountName       string    `json:"account_name"`
+	GLBalance         float64   `json:"gl_balance"`
+	BankBalance       *float64  `json:"bank_balance"`
+	OutstandingChecks float64   `json:"outstanding_checks"`
+	DepositsInTransit float64   `json:"deposits_in_transit"`
+	UpcomingAP        float64   `json:"upcoming_ap"`
+	ProjectedBalance  float64   `json:"projected_balance"`
+	MinimumBalance    float64   `json:"minimum_balance"`
+	BelowMinimum      bool      `json:"below_minimum"`
+	CreatedBy         string    `json:"created_by"`
+}
+
+// Service provides cash position operations
+type Service struct {
+	db *database.DB
+}
+
+// NewService creates a new cash position service
+func NewService(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// GetPosition builds the current cash position from the balance cache
+// (account_balance_summary), imported bank statements and open AP invoices due
+// within horizonDays.
+func (s *Service) GetPosition(companyName string, horizonDays int) (*Position, error) {
+	if horizonDays <= 0 {
+		horizonDays = DefaultHorizonDays
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	position := &Position{
+		CompanyName: companyName,
+		AsOf:        now,
+		HorizonDays: horizonDays,
+		Accounts:    []AccountPosition{},
+		UpcomingAP:  []ledger.Payable{},
+		Alerts:      []Alert{},
+		Warnings:    []string{},
+	}
+
+	balances, err := database.GetAllCachedBalances(s.db, companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached balances: %w", err)
+	}
+	if len(balances) == 0 {
+		position.Warnings = append(position.Warnings, "No cached bank balances found - refresh account balances first")
+	}
+
+	settings, err := s.GetAccountSettings(companyName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Upcoming AP: open invoices due on or before the horizon (past due included)
+	horizon := today.AddDate(0, 0, horizonDays)
+	upcomingAP := currency.Zero()
+	payables, err := ledger.LoadPayables(companyName)
+	if err != nil {
+		position.Warnings = append(position.Warnings, fmt.Sprintf("AP invoices not available: %v", err))
+	}
+	for _, p := range payables {
+		if p.Balance <= 0 || p.DueDate.IsZero() || p.DueDate.After(horizon) {
+			continue
+		}
+		position.UpcomingAP = append(position.UpcomingAP, p)
+		upcomingAP = upcomingAP.Add(currency.NewFromFloat(p.Balance))
+	}
+	sort.Slice(position.UpcomingAP, func(i, j int) bool {
+		return position.UpcomingAP[i].DueDate.Before(position.UpcomingAP[j].DueDate)
+	})
+
+	apAccount := ""
+	for _, b := range balances {
+		if settings[b.AccountNumber].PaysAP {
+			apAccount = b.AccountNumber
+			break
+		}
+	}
+	if apAccount == "" && !upcomingAP.IsZero() {
+		position.Warnings = append(position.Warnings, "No bank account is marked as paying AP - upcoming AP is shown in the totals only")
+	}
+
+	totals := struct{ gl, bank, outstanding, deposits, projected currency.Currency }{
+		currency.Zero(), currency.Zero(), currency.Zero(), currency.Zero(), currency.Zero(),
+	}
+	for _, b := range balances {
+		gl := currency.NewFromFloat(b.GLBalance)
+		checks := currency.NewFromFloat(b.UnclearedChecks)
+		deposits := currency.NewFromFloat(b.UnclearedDeposits)
+		setting := settings[b.AccountNumber]
+
+		account := AccountPosition{
+			AccountNumber:       b.AccountNumber,
+			AccountName:         b.AccountName,
+			GLBalance:           b.GLBalance,
+			GLLastUpdated:       b.GLLastUpdated,
+			GLFreshness:         b.GLFreshness,
+			OutstandingChecks:   b.UnclearedChecks,
+			CheckCount:          b.CheckCount,
+			DepositsInTransit:   b.UnclearedDeposits,
+			DepositCount:        b.DepositCount,
+			ExpectedBankBalance: gl.Add(checks).Sub(deposits).ToFloat64(),
+			MinimumBalance:      setting.MinimumBalance,
+			PaysAP:              setting.PaysAP,
+		}
+
+		projected := gl
+		if b.AccountNumber == apAccount {
+			account.UpcomingAP = upcomingAP.ToFloat64()
+			projected = projected.Sub(upcomingAP)
+		}
+		account.ProjectedBalance = projected.ToFloat64()
+
+		if balance, date, source, ok := s.latestBankBalance(companyName, b.AccountNumber); ok {
+			account.BankBalance = &balance
+			account.BankBalanceDate = &date
+			account.BankBalanceSource = source
+			totals.bank = totals.bank.Add(currency.NewFromFloat(balance))
+		}
+
+		if setting.MinimumBalance != 0 && projected.LessThan(currency.NewFromFloat(setting.MinimumBalance)) {
+			account.BelowMinimum = true
+			shortfall := currency.NewFromFloat(setting.MinimumBalance).Sub(projected).ToFloat64()
+			position.Alerts = append(position.Alerts, Alert{
+				AccountNumber:    b.AccountNumber,
+				AccountName:      b.AccountName,
+				ProjectedBalance: account.ProjectedBalance,
+				MinimumBalance:   setting.MinimumBalance,
+				Shortfall:        shortfall,
+				Message: fmt.Sprintf("%s %s is projected at %s, %s below its minimum of %s",
+					b.AccountNumber, b.AccountName, reports.FormatAmount(account.ProjectedBalance),
+					reports.FormatAmount(shortfall), reports.FormatAmount(setting.MinimumBalance)),
+			})
+		}
+		if b.GLFreshness == "stale" {
+			position.Warnings = append(position.Warnings, fmt.Sprintf("GL balance for %s is stale (%.0f hours old)", b.AccountNumber, b.GLAgeHours))
+		}
+
+		totals.gl = totals.gl.Add(gl)
+		totals.outstanding = totals.outstanding.Add(checks)
+		totals.deposits = totals.deposits.Add(deposits)
+		totals.projected = totals.projected.Add(projected)
+		position.Accounts = append(position.Accounts, account)
+	}
+
+	position.TotalGL = totals.gl.ToFloat64()
+	position.TotalBank = totals.bank.ToFloat64()
+	position.TotalOutstanding = totals.outstanding.ToFloat64()
+	position.TotalDeposits = totals.deposits.ToFloat64()
+	position.TotalUpcomingAP = upcomingAP.ToFloat64()
+	if apAccount == "" {
+		position.UnassignedAP = upcomingAP.ToFloat64()
+		totals.projected = totals.projected.Sub(upcomingAP)
+	}
+	position.TotalProjected = totals.projected.ToFloat64()
+
+	return position, nil
+}
+
+// latestBankBalance returns the most recent bank balance we know of, from an
+// imported statement or a committed reconciliation, whichever is newer
+func (s *Service) latestBankBalance(companyName, accountNumber string) (float64, time.Time, string, bool) {
+	var best struct {
+		balance float64
+		date    time.Time
+		source  string
+		ok      bool
+	}
+
+	sources := []struct {
+		name  string
+		query string
+	}{
+		{"bank_statement", `
+			SELECT ending_balance, statement_date FROM bank_statements
+			WHERE company_name = ? AND account_number = ? AND is_active = TRUE AND ending_balance IS NOT NULL
+			ORDER BY statement_date DESC LIMIT 1`},
+		{"reconciliation", `
+			SELECT statement_balance, statement_date FROM reconciliations
+			WHERE company_name = ? AND account_number = ? AND status = 'committed'
+			ORDER BY statement_date DESC LIMIT 1`},
+	}
+	for _, src := range sources {
+		var balance float64
+		var rawDate interface{}
+		err := s.db.QueryRow(src.query, companyName, accountNumber).Scan(&balance, &rawDate)
+		if err != nil {
+			continue
+		}
+		date, ok := ledger.AsDate(rawDate)
+		if !ok {
+			continue
+		}
+		if !best.ok || date.After(best.date) {
+			best.balance, best.date, best.source, best.ok = balance, date, src.name, true
+		}
+	}
+	return best.balance, best.date, best.source, best.ok
+}
+
+// GetAccountSettings returns the cash settings for every configured account
+func (s *Service) GetAccountSettings(companyName string) (map[string]AccountSettings, error) {
+	rows, err := s.db.Query(`
+		SELECT account_number, COALESCE(minimum_balance, 0), COALESCE(pays_ap, FALSE)
+		FROM cash_account_settings WHERE company_name = ?
+	`, companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cash account settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := make(map[string]AccountSettings)
+	for rows.Next() {
+		var st AccountSettings
+		if err := rows.Scan(&st.AccountNumber, &st.MinimumBalance, &st.PaysAP); err != nil {
+			return nil, fmt.Errorf("failed to scan cash account settings: %w", err)
+		}
+		settings[st.AccountNumber] = st
+	}
+	return settings, rows.Err()
+}
+
+// SaveAccountSettings stores the minimum balance and AP flag for an account.
+// Only one account pays AP, so setting the flag clears it everywhere else.
+func (s *Service) SaveAccountSettings(companyName string, st AccountSettings, updatedBy string) error {
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if st.PaysAP {
+		if _, err := tx.Exec(`UPDATE cash_account_settings SET pays_ap = FALSE WHERE company_name = ?`, companyName); err != nil {
+			return fmt.Errorf("failed to clear AP account: %w", err)
+		}
+	}
+	_, err = tx.Exec(`
+		INSERT INTO cash_account_settings (company_name, account_number, minimum_balance, pays_ap, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(company_name, account_number) DO UPDATE SET
+			minimum_balance = excluded.minimum_balance,
+			pays_ap = excluded.pays_ap,
+			updated_by = excluded.updated_by,
+			updated_at = CURRENT_TIMESTAMP
+	`, companyName, st.AccountNumber, st.MinimumBalance, st.PaysAP, updatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to save cash account settings: %w", err)
+	}
+	return tx.Commit()
+}
+
+// SaveSnapshot records the position as today's snapshot. Saving again on the
+// same day replaces that day's figures.
+func (s *Service) SaveSnapshot(position *Position, createdBy string) error {
+	day := position.AsOf.Format("2006-01-02")
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, a := range position.Accounts {
+		var bankDate interface{}
+		if a.BankBalanceDate != nil {
+			bankDate = a.BankBalanceDate.Format("2006-01-02")
+		}
+		_, err := tx.Exec(`
+			INSERT INTO cash_position_snapshots (
+				company_name, snapshot_date, account_number, account_name, gl_balance, bank_balance,
+				bank_balance_date, outstanding_checks, deposits_in_transit, upcoming_ap,
+				projected_balance, minimum_balance, below_minimum, created_by
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(company_name, snapshot_date, account_number) DO UPDATE SET
+				account_name = excluded.account_name,
+				gl_balance = excluded.gl_balance,
+				bank_balance = excluded.bank_balance,
+				bank_balance_date = excluded.bank_balance_date,
+				outstanding_checks = excluded.outstanding_checks,
+				deposits_in_transit = excluded.deposits_in_transit,
+				upcoming_ap = excluded.upcoming_ap,
+				projected_balance = excluded.projected_balance,
+				minimum_balance = excluded.minimum_balance,
+				below_minimum = excluded.below_minimum,
+				created_by = excluded.created_by,
+				created_at = CURRENT_TIMESTAMP
+		`, position.CompanyName, day, a.AccountNumber, a.AccountName, a.GLBalance, a.BankBalance,
+			bankDate, a.OutstandingChecks, a.DepositsInTransit, a.UpcomingAP,
+			a.ProjectedBalance, a.MinimumBalance, a.BelowMinimum, createdBy)
+		if err != nil {
+			return fmt.Errorf("failed to save snapshot for %s: %w", a.AccountNumber, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSnapshots returns snapshot history between two dates (inclusive). An empty
+// accountNumber returns every account.
+func (s *Service) GetSnapshots(companyName, accountNumber string, from, to time.Time) ([]Snapshot, error) {
+	query := `
+		SELECT snapshot_date, account_number, COALESCE(account_name, ''), gl_balance, bank_balance,
+		       outstanding_checks, deposits_in_transit, upcoming_ap, projected_balance,
+		       COALESCE(minimum_balance, 0), COALESCE(below_minimum, FALSE), COALESCE(created_by, '')
+		FROM cash_position_snapshots
+		WHERE company_name = ? AND snapshot_date >= ? AND snapshot_date <= ?`
+	args := []interface{}{companyName, from.Format("2006-01-02"), to.Format("2006-01-02")}
+	if accountNumber != "" {
+		query += ` AND account_number = ?`
+		args = append(args, accountNumber)
+	}
+	query += ` ORDER BY snapshot_date, account_number`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []Snapshot{}
+	for rows.Next() {
+		var snap Snapshot
+		var rawDate interface{}
+		var bank sql.NullFloat64
+		if err := rows.Scan(&rawDate, &snap.AccountNumber, &snap.AccountName, &snap.GLBalance, &bank,
+			&snap.OutstandingChecks, &snap.DepositsInTransit, &snap.UpcomingAP, &snap.ProjectedBalance,
+			&snap.MinimumBalance, &snap.BelowMinimum, &snap.CreatedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot: %w", err)
+		}
+		snap.SnapshotDate, _ = ledger.AsDate(rawDate)
+		if bank.Valid {
+			v := bank.Float64
+			snap.BankBalance = &v
+		}
+		snapshots = append(snapshots, snap)
+	}
+	return snapshots, rows.Err()
+}
+
+// ToTable lays the position out for PDF/CSV export
+func ToTable(p *Position, displayName string) *reports.Table {
+	t := &reports.Table{
+		CompanyName: displayName,
+		Title:       "Daily Cash Position",
+		Subtitles: []string{
+			fmt.Sprintf("As of %s", p.AsOf.Format("January 2, 2006 3:04 PM")),
+			fmt.Sprintf("Upcoming AP through %s (%d days)", p.AsOf.AddDate(0, 0, p.HorizonDays).Format("01/02/2006"), p.HorizonDays),
+		},
+		Columns: []reports.Column{
+			{Header: "Account", Width: 22},
+			{Header: "Description", Width: 55},
+			{Header: "GL Balance", Width: 28, Align: "R"},
+			{Header: "Bank Balance", Width: 28, Align: "R"},
+			{Header: "Bank Date", Width: 20, Align: "C"},
+			{Header: "Outstanding Checks", Width: 28, Align: "R"},
+			{Header: "Deposits in Transit", Width: 28, Align: "R"},
+			{Header: "Upcoming AP", Width: 25, Align: "R"},
+			{Header: "Projected", Width: 25, Align: "R"},
+		},
+	}
+
+	for _, a := range p.Accounts {
+		bank, bankDate := "", ""
+		if a.BankBalance != nil {
+			bank = reports.FormatAmount(*a.BankBalance)
+		}
+		if a.BankBalanceDate != nil {
+			bankDate = a.BankBalanceDate.Format("01/02/2006")
+		}
+		projected := reports.FormatAmount(a.ProjectedBalance)
+		if a.BelowMinimum {
+			projected = "* " + projected
+		}
+		t.AddRow(a.AccountNumber, a.AccountName, reports.FormatAmount(a.GLBalance), bank, bankDate,
+			reports.FormatAmount(a.OutstandingChecks), reports.FormatAmount(a.DepositsInTransit),
+			reports.FormatAmount(a.UpcomingAP), projected)
+	}
+	if p.UnassignedAP != 0 {
+		t.AddRow("", "Upcoming AP (no paying account set)", "", "", "", "", "", reports.FormatAmount(p.UnassignedAP), reports.FormatAmount(-p.UnassignedAP))
+	}
+	t.AddTotal("", "Total", reports.FormatAmount(p.TotalGL), reports.FormatAmount(p.TotalBank), "",
+		reports.FormatAmount(p.TotalOutstanding), reports.FormatAmount(p.TotalDeposits),
+		reports.FormatAmount(p.TotalUpcomingAP), reports.FormatAmount(p.TotalProjected))
+
+	for _, alert := range p.Alerts {
+		t.Notes = append(t.Notes, "* "+alert.Message)
+	}
+	t.Notes = append(t.Notes, p.Warnings...)
+	return t
+}
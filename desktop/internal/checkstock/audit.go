@@ -0,0 +1,327 @@
+package checkstock
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Finding types
+const (
+	FindingGap            = "gap"
+	FindingSequenceBreak  = "sequence_break" // Jump too large to be missing checks, usually new stock
+	FindingDuplicate      = "duplicate"
+	FindingReuseAfterVoid = "reuse_after_void"
+	FindingOutOfSequence  = "out_of_sequence"
+	FindingOutsideStock   = "outside_stock"
+	FindingDestroyedStock = "destroyed_stock"
+	FindingNonNumeric     = "non_numeric"
+)
+
+// AuditOptions controls the sequence audit
+type AuditOptions struct {
+	AccountNumber     string    `json:"account_number"` // Empty audits every account in CHECKS.dbf
+	StartDate         time.Time `json:"start_date"`     // Zero means no lower bound
+	EndDate           time.Time `json:"end_date"`       // Zero means no upper bound
+	LargeGapThreshold int       `json:"large_gap_threshold"`
+	OutOfSequenceDays int       `json:"out_of_sequence_days"`
+}
+
+// Finding is one integrity issue
+type Finding struct {
+	Type          string         `json:"type"`
+	AccountNumber string         `json:"account_number"`
+	FromNumber    int            `json:"from_number,omitempty"`
+	ToNumber      int            `json:"to_number,omitempty"`
+	MissingCount  int            `json:"missing_count,omitempty"`
+	Checks        []ledger.Check `json:"checks,omitempty"`
+	Message       string         `json:"message"`
+}
+
+// RangeUsage shows how much of a registered range has been used
+type RangeUsage struct {
+	Range
+	UsedCount      int `json:"used_count"`
+	VoidCount      int `json:"void_count"`
+	HighestUsed    int `json:"highest_used"`
+	SkippedCount   int `json:"skipped_count"` // Numbers below HighestUsed never issued
+	RemainingCount int `json:"remaining_count"`
+}
+
+// AccountAudit is the audit result for one bank account
+type AccountAudit struct {
+	AccountNumber string         `json:"account_number"`
+	CheckCount    int            `json:"check_count"`
+	VoidCount     int            `json:"void_count"`
+	LowestNumber  int            `json:"lowest_number"`
+	HighestNumber int            `json:"highest_number"`
+	Findings      []Finding      `json:"findings"`
+	Counts        map[string]int `json:"counts"`
+	StockUsage    []RangeUsage   `json:"stock_usage"`
+	HasStock      bool           `json:"has_stock"`
+}
+
+// AuditResult is the sequence audit across accounts
+type AuditResult struct {
+	CompanyName   string         `json:"company_name"`
+	Options       AuditOptions   `json:"options"`
+	Accounts      []AccountAudit `json:"accounts"`
+	TotalFindings int            `json:"total_findings"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+}
+
+// numberedCheck is a check with a parsed numeric check number
+type numberedCheck struct {
+	number int
+	check  ledger.Check
+}
+
+// AuditSequence scans CHECKS.dbf per bank account for gaps, duplicates, numbers
+// reused after a void, out-of-sequence issue dates and checks outside the
+// registered check stock. Deposits are ignored.
+func (s *Service) AuditSequence(companyName string, opts AuditOptions) (*AuditResult, error) {
+	if opts.LargeGapThreshold <= 0 {
+		opts.LargeGapThreshold = 100
+	}
+	if opts.OutOfSequenceDays <= 0 {
+		opts.OutOfSequenceDays = 7
+	}
+
+	checks, err := ledger.LoadChecks(companyName)
+	if err != nil {
+		return nil, err
+	}
+	ranges, err := s.GetRanges(companyName, opts.AccountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	byAccount := make(map[string][]ledger.Check)
+	for _, c := range checks {
+		if c.IsDeposit() || c.CheckNumber == "" {
+			continue
+		}
+		if opts.AccountNumber != "" && c.AccountNo != opts.AccountNumber {
+			continue
+		}
+		if !opts.StartDate.IsZero() && c.CheckDate.Before(opts.StartDate) {
+			continue
+		}
+		if !opts.EndDate.IsZero() && c.CheckDate.After(opts.EndDate) {
+			continue
+		}
+		byAccount[c.AccountNo] = append(byAccount[c.AccountNo], c)
+	}
+
+	rangesByAccount := make(map[string][]Range)
+	for _, r := range ranges {
+		rangesByAccount[r.AccountNumber] = append(rangesByAccount[r.AccountNumber], r)
+	}
+
+	accounts := make([]string, 0, len(byAccount))
+	for account := range byAccount {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	result := &AuditResult{
+		CompanyName: companyName,
+		Options:     opts,
+		Accounts:    []AccountAudit{},
+		GeneratedAt: time.Now(),
+	}
+	for _, account := range accounts {
+		audit := auditAccount(account, byAccount[account], rangesByAccount[account], opts)
+		result.TotalFindings += len(audit.Findings)
+		result.Accounts = append(result.Accounts, audit)
+	}
+	return result, nil
+}
+
+func auditAccount(account string, checks []ledger.Check, ranges []Range, opts AuditOptions) AccountAudit {
+	audit := AccountAudit{
+		AccountNumber: account,
+		Findings:      []Finding{},
+		Counts:        make(map[string]int),
+		StockUsage:    []RangeUsage{},
+		HasStock:      len(ranges) > 0,
+	}
+	add := func(f Finding) {
+		f.AccountNumber = account
+		audit.Findings = append(audit.Findings, f)
+		audit.Counts[f.Type]++
+	}
+
+	var numbered []numberedCheck
+	for _, c := range checks {
+		audit.CheckCount++
+		if c.IsVoid {
+			audit.VoidCount++
+		}
+		n, err := strconv.Atoi(ledger.NormalizeCheckNumber(c.CheckNumber))
+		if err != nil || n <= 0 {
+			add(Finding{
+				Type:    FindingNonNumeric,
+				Checks:  []ledger.Check{c},
+				Message: fmt.Sprintf("Check number %q is not numeric and was left out of the sequence audit", c.CheckNumber),
+			})
+			continue
+		}
+		numbered = append(numbered, numberedCheck{number: n, check: c})
+	}
+	if len(numbered) == 0 {
+		return audit
+	}
+
+	sort.SliceStable(numbered, func(i, j int) bool {
+		if numbered[i].number != numbered[j].number {
+			return numbered[i].number < numbered[j].number
+		}
+		return numbered[i].check.CheckDate.Before(numbered[j].check.CheckDate)
+	})
+	audit.LowestNumber = numbered[0].number
+	audit.HighestNumber = numbered[len(numbered)-1].number
+
+	// Duplicates and reuse after void - numbered is sorted so equal numbers are adjacent
+	for i := 0; i < len(numbered); {
+		j := i
+		for j < len(numbered) && numbered[j].number == numbered[i].number {
+			j++
+		}
+		if j-i > 1 {
+			group := make([]ledger.Check, 0, j-i)
+			live := 0
+			voidedFirst := false
+			for k := i; k < j; k++ {
+				group = append(group, numbered[k].check)
+				if numbered[k].check.IsVoid {
+					if live == 0 {
+						voidedFirst = true
+					}
+				} else {
+					live++
+				}
+			}
+			switch {
+			case live > 1:
+				add(Finding{
+					Type:       FindingDuplicate,
+					FromNumber: numbered[i].number,
+					Checks:     group,
+					Message:    fmt.Sprintf("Check number %d was issued %d times", numbered[i].number, live),
+				})
+			case live == 1 && voidedFirst:
+				add(Finding{
+					Type:       FindingReuseAfterVoid,
+					FromNumber: numbered[i].number,
+					Checks:     group,
+					Message:    fmt.Sprintf("Check number %d was voided and then issued again", numbered[i].number),
+				})
+			}
+		}
+		i = j
+	}
+
+	// Gaps and out-of-sequence dates, walking unique numbers in order
+	var latest time.Time
+	var latestNumber int
+	for i, nc := range numbered {
+		if i > 0 {
+			prev := numbered[i-1].number
+			if missing := nc.number - prev - 1; missing > 0 {
+				f := Finding{
+					Type:         FindingGap,
+					FromNumber:   prev + 1,
+					ToNumber:     nc.number - 1,
+					MissingCount: missing,
+					Message:      fmt.Sprintf("Check numbers %d-%d are missing (%d)", prev+1, nc.number-1, missing),
+				}
+				if missing == 1 {
+					f.Message = fmt.Sprintf("Check number %d is missing", prev+1)
+				}
+				if missing > opts.LargeGapThreshold {
+					f.Type = FindingSequenceBreak
+					f.Message = fmt.Sprintf("Sequence jumps from %d to %d (%d numbers) - likely a new check stock", prev, nc.number, missing)
+				}
+				add(f)
+			}
+		}
+
+		date := nc.check.CheckDate
+		if !date.IsZero() {
+			if !latest.IsZero() && date.Before(latest.AddDate(0, 0, -opts.OutOfSequenceDays)) && nc.number != latestNumber {
+				add(Finding{
+					Type:       FindingOutOfSequence,
+					FromNumber: nc.number,
+					Checks:     []ledger.Check{nc.check},
+					Message: fmt.Sprintf("Check %d dated %s was issued after lower-numbered check %d dated %s",
+						nc.number, date.Format("01/02/2006"), latestNumber, latest.Format("01/02/2006")),
+				})
+			}
+			if date.After(latest) {
+				latest, latestNumber = date, nc.number
+			}
+		}
+	}
+
+	// Registered stock: checks outside every range, or from destroyed stock
+	if len(ranges) > 0 {
+		for _, nc := range numbered {
+			var owner *Range
+			for k := range ranges {
+				if ranges[k].Contains(nc.number) {
+					owner = &ranges[k]
+					break
+				}
+			}
+			switch {
+			case owner == nil:
+				add(Finding{
+					Type:       FindingOutsideStock,
+					FromNumber: nc.number,
+					Checks:     []ledger.Check{nc.check},
+					Message:    fmt.Sprintf("Check %d is not in any registered check stock range", nc.number),
+				})
+			case owner.Status == StatusDestroyed:
+				add(Finding{
+					Type:       FindingDestroyedStock,
+					FromNumber: nc.number,
+					Checks:     []ledger.Check{nc.check},
+					Message:    fmt.Sprintf("Check %d was issued from stock %d-%d that is marked destroyed", nc.number, owner.StartNumber, owner.EndNumber),
+				})
+			}
+		}
+
+		for _, r := range ranges {
+			usage := RangeUsage{Range: r}
+			seen := make(map[int]bool)
+			for _, nc := range numbered {
+				if !r.Contains(nc.number) {
+					continue
+				}
+				if nc.check.IsVoid {
+					usage.VoidCount++
+				}
+				if !seen[nc.number] {
+					seen[nc.number] = true
+					usage.UsedCount++
+				}
+				if nc.number > usage.HighestUsed {
+					usage.HighestUsed = nc.number
+				}
+			}
+			if usage.HighestUsed > 0 {
+				usage.SkippedCount = usage.HighestUsed - r.StartNumber + 1 - usage.UsedCount
+				usage.RemainingCount = r.EndNumber - usage.HighestUsed
+			} else {
+				usage.RemainingCount = r.EndNumber - r.StartNumber + 1
+			}
+			audit.StockUsage = append(audit.StockUsage, usage)
+		}
+	}
+
+	return audit
+}
@@ -0,0 +1,164 @@
+// Package checkstock keeps a registry of check stock (ranges of pre-printed check
+// numbers received per bank account) and audits CHECKS.dbf for gaps, duplicates,
+// reuse after void and numbers issued outside the registered stock.
+package checkstock
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Check stock range statuses
+const (
+	StatusActive    = "active"
+	StatusExhausted = "exhausted"
+	StatusDestroyed = "destroyed"
+)
+
+// Range is a block of check numbers received for a bank account
+type Range struct {
+	ID            int        `json:"id"`
+	CompanyName   string     `json:"company_name"`
+	AccountNumber string     `json:"account_number"`
+	StartNumber   int        `json:"start_number"`
+	EndNumber     int        `json:"end_number"`
+	ReceivedDate  *time.Time `json:"received_date"`
+	Description   string     `json:"description"`
+	Status        string     `json:"status"`
+	Notes         string     `json:"notes"`
+	CreatedBy     string     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Contains reports whether a check number falls inside the range
+func (r Range) Contains(n int) bool {
+	return n >= r.StartNumber && n <= r.EndNumber
+}
+
+// Service provides check stock operations
+type Service struct {
+	db *database.DB
+}
+
+// NewService creates a new check stock service
+func NewService(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// AddRange registers newly received check stock. Ranges for the same account may
+// not overlap.
+func (s *Service) AddRange(r Range) (*Range, error) {
+	if r.StartNumber <= 0 || r.EndNumber < r.StartNumber {
+		return nil, fmt.Errorf("invalid check range %d-%d", r.StartNumber, r.EndNumber)
+	}
+	if r.AccountNumber == "" {
+		return nil, fmt.Errorf("account number is required")
+	}
+
+	var overlapID int
+	err := s.db.QueryRow(`
+		SELECT id FROM check_stock_ranges
+		WHERE company_name = ? AND account_number = ? AND status != ?
+		  AND start_number <= ? AND end_number >= ?
+		LIMIT 1
+	`, r.CompanyName, r.AccountNumber, StatusDestroyed, r.EndNumber, r.StartNumber).Scan(&overlapID)
+	if err == nil {
+		return nil, fmt.Errorf("check range %d-%d overlaps existing range #%d", r.StartNumber, r.EndNumber, overlapID)
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check for overlapping ranges: %w", err)
+	}
+
+	var received interface{}
+	if r.ReceivedDate != nil {
+		received = r.ReceivedDate.Format("2006-01-02")
+	}
+	result, err := s.db.Exec(`
+		INSERT INTO check_stock_ranges (
+			company_name, account_number, start_number, end_number, received_date,
+			description, status, notes, created_by
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, r.CompanyName, r.AccountNumber, r.StartNumber, r.EndNumber, received,
+		r.Description, StatusActive, r.Notes, r.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save check range: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	r.ID = int(id)
+	r.Status = StatusActive
+	r.CreatedAt = time.Now()
+	return &r, nil
+}
+
+// GetRanges returns registered ranges. An empty accountNumber returns all accounts.
+func (s *Service) GetRanges(companyName, accountNumber string) ([]Range, error) {
+	query := `
+		SELECT id, company_name, account_number, start_number, end_number, received_date,
+		       COALESCE(description, ''), status, COALESCE(notes, ''), created_by, created_at
+		FROM check_stock_ranges
+		WHERE company_name = ?`
+	args := []interface{}{companyName}
+	if accountNumber != "" {
+		query += ` AND account_number = ?`
+		args = append(args, accountNumber)
+	}
+	query += ` ORDER BY account_number, start_number`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query check ranges: %w", err)
+	}
+	defer rows.Close()
+
+	ranges := []Range{}
+	for rows.Next() {
+		var r Range
+		var received interface{}
+		if err := rows.Scan(&r.ID, &r.CompanyName, &r.AccountNumber, &r.StartNumber, &r.EndNumber,
+			&received, &r.Description, &r.Status, &r.Notes, &r.CreatedBy, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan check range: %w", err)
+		}
+		if d, ok := ledger.AsDate(received); ok {
+			r.ReceivedDate = &d
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, rows.Err()
+}
+
+// UpdateRangeStatus marks a range exhausted or destroyed (or active again)
+func (s *Service) UpdateRangeStatus(id int, status, notes string) error {
+	switch status {
+	case StatusActive, StatusExhausted, StatusDestroyed:
+	default:
+		return fmt.Errorf("invalid check range status: %s", status)
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE check_stock_ranges SET status = ?, notes = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, status, notes, id)
+	if err != nil {
+		return fmt.Errorf("failed to update check range: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("check range %d not found", id)
+	}
+	return nil
+}
+
+// DeleteRange removes a range entered in error
+func (s *Service) DeleteRange(id int) error {
+	result, err := s.db.Exec(`DELETE FROM check_stock_ranges WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete check range: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("check range %d not found", id)
+	}
+	return nil
+}
@@ -0,0 +1,596 @@
+// Package coa maintains the chart of accounts in COA.dbf: adding and editing
+// accounts, the CPARENT hierarchy, deactivation and deletion, merging one
+// account into another, CSV/JSON import and export, and mapping company
+// accounts onto a standard reporting chart such as the built-in COPAS chart.
+// Every change is written to coa_audit_log in SQLite.
+package coa
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/company"
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Audit log actions
+const (
+	ActionAdd        = "add"
+	ActionUpdate     = "update"
+	ActionDeactivate = "deactivate"
+	ActionReactivate = "reactivate"
+	ActionDelete     = "delete"
+	ActionMerge      = "merge"
+)
+
+// RecentActivityDays is how far back GLMASTER activity blocks deactivating an account
+const RecentActivityDays = 90
+
+// defaultAccountWidth is the CACCTNO width assumed when COA.dbf cannot be inspected
+const defaultAccountWidth = 10
+
+// Account numbers are letters and digits, optionally split by dots or dashes
+var accountNoPattern = regexp.MustCompile(`^[0-9A-Za-z]+([.\-][0-9A-Za-z]+)*$`)
+
+// Service provides chart of accounts maintenance
+type Service struct {
+	db *database.DB
+}
+
+// NewService creates a new chart of accounts service
+func NewService(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// ValidationError lists every problem found with an account change
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid account: " + strings.Join(e.Problems, "; ")
+}
+
+// AuditEntry is one chart of accounts change
+type AuditEntry struct {
+	ID            int             `json:"id"`
+	CompanyName   string          `json:"company_name"`
+	Action        string          `json:"action"`
+	AccountNo     string          `json:"account_number"`
+	TargetAccount string          `json:"target_account,omitempty"` // Merge target
+	Username      string          `json:"username"`
+	Reason        string          `json:"reason,omitempty"`
+	Details       json.RawMessage `json:"details,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// Usage summarises an account's GLMASTER history. Balance is debit-positive:
+// the lifetime net for balance sheet accounts and the current fiscal year's
+// net for income statement accounts, which closing entries zero each year.
+type Usage struct {
+	AccountNo    string          `json:"account_number"`
+	Lines        int             `json:"lines"`
+	Debits       float64         `json:"debits"`
+	Credits      float64         `json:"credits"`
+	Balance      float64         `json:"balance"`
+	FirstDate    *time.Time      `json:"first_date,omitempty"`
+	LastDate     *time.Time      `json:"last_date,omitempty"`
+	Periods      []ledger.Period `json:"periods,omitempty"`
+	MissingUnits int             `json:"missing_units"` // Lines without a CUNITNO
+	MissingDepts int             `json:"missing_depts"` // Lines without a CDEPTNO
+}
+
+// DeactivationCheck reports whether an account can be deactivated or deleted
+type DeactivationCheck struct {
+	Account        ledger.Account `json:"account"`
+	Usage          Usage          `json:"usage"`
+	ActiveChildren []string       `json:"active_children"`
+	Children       []string       `json:"children"`
+	Blockers       []string       `json:"blockers"`
+	CanDeactivate  bool           `json:"can_deactivate"`
+	CanDelete      bool           `json:"can_delete"`
+	ActivityCutoff time.Time      `json:"activity_cutoff"`
+}
+
+// ValidateAccountNumber checks an account number's format and that it fits a
+// CACCTNO column of the given width
+func ValidateAccountNumber(accountNo string, width int) error {
+	if accountNo == "" {
+		return fmt.Errorf("account number is required")
+	}
+	if !accountNoPattern.MatchString(accountNo) {
+		return fmt.Errorf("account number %q may only contain letters and digits separated by dots or dashes", accountNo)
+	}
+	if width > 0 && len(accountNo) > width {
+		return fmt.Errorf("account number %q is longer than %d characters", accountNo, width)
+	}
+	return nil
+}
+
+// GetAccount returns one COA.dbf account, including inactive ones
+func (s *Service) GetAccount(companyName, accountNo string) (*ledger.Account, error) {
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+	acct, ok := ledger.AccountMap(accounts)[strings.TrimSpace(accountNo)]
+	if !ok {
+		return nil, fmt.Errorf("account %s not found", accountNo)
+	}
+	return &acct, nil
+}
+
+// SaveAccount adds a new account or updates an existing one. The active flag is
+// not changed here; use Deactivate and Reactivate.
+func (s *Service) SaveAccount(companyName string, acct ledger.Account, isNew bool, username string) (*ledger.Account, error) {
+	acct.AccountNo = strings.TrimSpace(acct.AccountNo)
+	acct.Description = strings.TrimSpace(acct.Description)
+	acct.Parent = strings.TrimSpace(acct.Parent)
+
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+	byNo := ledger.AccountMap(accounts)
+	widths, err := company.DBFColumnWidths(companyName, "COA.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	before, exists := byNo[acct.AccountNo]
+	switch {
+	case isNew && exists:
+		return nil, &ValidationError{Problems: []string{fmt.Sprintf("account %s already exists", acct.AccountNo)}}
+	case !isNew && !exists:
+		return nil, fmt.Errorf("account %s not found", acct.AccountNo)
+	}
+	acct.IsInactive = before.IsInactive
+	if !acct.HasParent() {
+		acct.Parent = ""
+	}
+
+	if problems := validate(acct, before, isNew, byNo, widths); len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	values := accountValues(acct, widths)
+	action := ActionUpdate
+	if isNew {
+		action = ActionAdd
+		values["CACCTNO"] = acct.AccountNo
+		if _, ok := widths["LINACTIVE"]; ok {
+			values["LINACTIVE"] = false
+		}
+		if _, err := company.AppendDBFRows(companyName, "COA.dbf", []map[string]interface{}{values}); err != nil {
+			return nil, fmt.Errorf("failed to add account %s: %w", acct.AccountNo, err)
+		}
+	} else {
+		if _, err := company.UpdateDBFRowsByKey(companyName, "COA.dbf", "CACCTNO", map[string]map[string]interface{}{
+			acct.AccountNo: values,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to update account %s: %w", acct.AccountNo, err)
+		}
+	}
+
+	details := map[string]interface{}{"after": acct}
+	if !isNew {
+		details["before"] = before
+	}
+	if err := s.writeAudit(companyName, action, acct.AccountNo, "", username, "", details); err != nil {
+		return nil, err
+	}
+	s.refreshBalances(companyName, username, acct.AccountNo)
+
+	return s.GetAccount(companyName, acct.AccountNo)
+}
+
+// validate returns every problem with saving acct over before (the zero value
+// for a new account) into the chart byNo
+func validate(acct, before ledger.Account, isNew bool, byNo map[string]ledger.Account, widths map[string]int) []string {
+	var problems []string
+
+	width := widths["CACCTNO"]
+	if width == 0 {
+		width = defaultAccountWidth
+	}
+	if err := ValidateAccountNumber(acct.AccountNo, width); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if acct.Description == "" {
+		problems = append(problems, "description is required")
+	} else if w := widths["CACCTDESC"]; w > 0 && len(acct.Description) > w {
+		problems = append(problems, fmt.Sprintf("description is longer than %d characters", w))
+	}
+	if acct.Type < ledger.TypeAsset || acct.Type > ledger.TypeOther {
+		problems = append(problems, fmt.Sprintf("account type must be between %d and %d", ledger.TypeAsset, ledger.TypeOther))
+	}
+	if acct.IsBank && acct.Type != ledger.TypeAsset {
+		problems = append(problems, "only asset accounts can be bank accounts")
+	}
+	if acct.IsBank && (acct.IsTitle || acct.IsTotal) {
+		problems = append(problems, "title and total accounts cannot be bank accounts")
+	}
+
+	// Existing parents are left alone so legacy charts can still be edited;
+	// a new or changed parent must be a valid, acyclic link
+	if acct.Parent != "" && (isNew || acct.Parent != strings.TrimSpace(before.Parent)) {
+		parent, ok := byNo[acct.Parent]
+		switch {
+		case acct.Parent == acct.AccountNo:
+			problems = append(problems, "an account cannot be its own parent")
+		case !ok:
+			problems = append(problems, fmt.Sprintf("parent account %s does not exist", acct.Parent))
+		case parent.IsInactive:
+			problems = append(problems, fmt.Sprintf("parent account %s is inactive", acct.Parent))
+		default:
+			if cycle := ancestorCycle(acct.AccountNo, acct.Parent, byNo); cycle != nil {
+				problems = append(problems, fmt.Sprintf("parent %s would create a cycle: %s", acct.Parent, strings.Join(cycle, " → ")))
+			}
+		}
+	}
+	return problems
+}
+
+// ancestorCycle walks up the CPARENT chain from parent and returns the path if
+// it reaches accountNo, which would make accountNo its own ancestor
+func ancestorCycle(accountNo, parent string, byNo map[string]ledger.Account) []string {
+	path := []string{accountNo, parent}
+	seen := map[string]bool{accountNo: true}
+	for current := parent; current != ""; {
+		if seen[current] {
+			if current == accountNo {
+				return path
+			}
+			return nil // An existing cycle above us that does not involve accountNo
+		}
+		seen[current] = true
+		next, ok := byNo[current]
+		if !ok || !next.HasParent() {
+			return nil
+		}
+		current = strings.TrimSpace(next.Parent)
+		path = append(path, current)
+	}
+	return nil
+}
+
+// accountValues maps an account onto the COA.dbf columns present in the file
+func accountValues(acct ledger.Account, widths map[string]int) map[string]interface{} {
+	values := map[string]interface{}{}
+	set := func(column string, value interface{}) {
+		if _, ok := widths[column]; ok {
+			values[column] = value
+		}
+	}
+	set("CACCTDESC", acct.Description)
+	set("NACCTTYPE", float64(acct.Type))
+	set("CACCTTYPE", strconv.Itoa(acct.Type))
+	set("CPARENT", acct.Parent)
+	set("LACCTUNIT", acct.RequiresUnit)
+	set("LACCTDEPT", acct.RequiresDept)
+	set("LBANKACCT", acct.IsBank)
+	set("LTITLE", acct.IsTitle)
+	set("LTOTALACCT", acct.IsTotal)
+	return values
+}
+
+// CheckDeactivation reports what stands in the way of deactivating or
+// deleting an account
+func (s *Service) CheckDeactivation(companyName, accountNo string, cal *ledger.Calendar) (*DeactivationCheck, error) {
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+	byNo := ledger.AccountMap(accounts)
+	acct, ok := byNo[strings.TrimSpace(accountNo)]
+	if !ok {
+		return nil, fmt.Errorf("account %s not found", accountNo)
+	}
+
+	usage, err := scanUsage(companyName, byNo, cal, time.Now(), acct.AccountNo)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &DeactivationCheck{
+		Account:        acct,
+		Usage:          *usage[acct.AccountNo],
+		ActiveChildren: []string{},
+		Children:       []string{},
+		Blockers:       []string{},
+		ActivityCutoff: time.Now().AddDate(0, 0, -RecentActivityDays).Truncate(24 * time.Hour),
+	}
+	for _, child := range children(acct.AccountNo, accounts) {
+		check.Children = append(check.Children, child.AccountNo)
+		if !child.IsInactive {
+			check.ActiveChildren = append(check.ActiveChildren, child.AccountNo)
+		}
+	}
+
+	if len(check.ActiveChildren) > 0 {
+		check.Blockers = append(check.Blockers, fmt.Sprintf("account has %d active sub-accounts: %s",
+			len(check.ActiveChildren), strings.Join(check.ActiveChildren, ", ")))
+	}
+	if check.Usage.Balance != 0 {
+		check.Blockers = append(check.Blockers, fmt.Sprintf("account has a balance of %.2f", check.Usage.Balance))
+	}
+	if check.Usage.LastDate != nil && !check.Usage.LastDate.Before(check.ActivityCutoff) {
+		check.Blockers = append(check.Blockers, fmt.Sprintf("account has activity on %s, within the last %d days",
+			check.Usage.LastDate.Format("2006-01-02"), RecentActivityDays))
+	}
+
+	check.CanDeactivate = !acct.IsInactive && len(check.Blockers) == 0
+	check.CanDelete = check.Usage.Lines == 0 && len(check.Children) == 0
+	return check, nil
+}
+
+// Deactivate marks an account inactive after CheckDeactivation passes
+func (s *Service) Deactivate(companyName, accountNo, username, reason string, cal *ledger.Calendar) (*ledger.Account, error) {
+	check, err := s.CheckDeactivation(companyName, accountNo, cal)
+	if err != nil {
+		return nil, err
+	}
+	if check.Account.IsInactive {
+		return nil, fmt.Errorf("account %s is already inactive", check.Account.AccountNo)
+	}
+	if len(check.Blockers) > 0 {
+		return nil, fmt.Errorf("account %s cannot be deactivated: %s", check.Account.AccountNo, strings.Join(check.Blockers, "; "))
+	}
+
+	if err := setInactive(companyName, check.Account.AccountNo, true); err != nil {
+		return nil, err
+	}
+	if err := s.writeAudit(companyName, ActionDeactivate, check.Account.AccountNo, "", username, reason, map[string]interface{}{
+		"last_activity": check.Usage.LastDate,
+		"lines":         check.Usage.Lines,
+	}); err != nil {
+		return nil, err
+	}
+	s.refreshBalances(companyName, username, check.Account.AccountNo)
+
+	return s.GetAccount(companyName, check.Account.AccountNo)
+}
+
+// Reactivate marks an inactive account active again. Its parent must be active.
+func (s *Service) Reactivate(companyName, accountNo, username, reason string) (*ledger.Account, error) {
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+	byNo := ledger.AccountMap(accounts)
+	acct, ok := byNo[strings.TrimSpace(accountNo)]
+	if !ok {
+		return nil, fmt.Errorf("account %s not found", accountNo)
+	}
+	if !acct.IsInactive {
+		return nil, fmt.Errorf("account %s is already active", acct.AccountNo)
+	}
+	if acct.HasParent() {
+		if parent, ok := byNo[strings.TrimSpace(acct.Parent)]; ok && parent.IsInactive {
+			return nil, fmt.Errorf("parent account %s is inactive; reactivate it first", parent.AccountNo)
+		}
+	}
+
+	if err := setInactive(companyName, acct.AccountNo, false); err != nil {
+		return nil, err
+	}
+	if err := s.writeAudit(companyName, ActionReactivate, acct.AccountNo, "", username, reason, nil); err != nil {
+		return nil, err
+	}
+	s.refreshBalances(companyName, username, acct.AccountNo)
+
+	return s.GetAccount(companyName, acct.AccountNo)
+}
+
+// Delete removes an account that has never been posted to and has no sub-accounts
+func (s *Service) Delete(companyName, accountNo, username, reason string, cal *ledger.Calendar) error {
+	check, err := s.CheckDeactivation(companyName, accountNo, cal)
+	if err != nil {
+		return err
+	}
+	if check.Usage.Lines > 0 {
+		return fmt.Errorf("account %s has %d GLMASTER lines; deactivate or merge it instead", check.Account.AccountNo, check.Usage.Lines)
+	}
+	if len(check.Children) > 0 {
+		return fmt.Errorf("account %s has sub-accounts: %s", check.Account.AccountNo, strings.Join(check.Children, ", "))
+	}
+
+	if _, err := company.DeleteDBFRowsByKey(companyName, "COA.dbf", "CACCTNO", []string{check.Account.AccountNo}); err != nil {
+		return fmt.Errorf("failed to delete account %s: %w", check.Account.AccountNo, err)
+	}
+	if err := s.writeAudit(companyName, ActionDelete, check.Account.AccountNo, "", username, reason, map[string]interface{}{
+		"before": check.Account,
+	}); err != nil {
+		return err
+	}
+
+	if _, err := s.db.Exec(`DELETE FROM account_balances WHERE company_name = ? AND account_number = ?`,
+		companyName, check.Account.AccountNo); err != nil {
+		fmt.Printf("coa: failed to remove cached balance for %s: %v\n", check.Account.AccountNo, err)
+	}
+	return nil
+}
+
+// GetAuditLog returns chart of accounts changes, newest first. An empty
+// accountNo returns the whole company history; otherwise changes where the
+// account was the subject or the merge target.
+func (s *Service) GetAuditLog(companyName, accountNo string) ([]AuditEntry, error) {
+	query := `
+		SELECT id, company_name, action, account_number, COALESCE(target_account, ''),
+		       username, COALESCE(reason, ''), COALESCE(details, ''), created_at
+		FROM coa_audit_log
+		WHERE company_name = ?`
+	args := []interface{}{companyName}
+	if accountNo = strings.TrimSpace(accountNo); accountNo != "" {
+		query += ` AND (account_number = ? OR target_account = ?)`
+		args = append(args, accountNo, accountNo)
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chart of accounts audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		var details string
+		if err := rows.Scan(&e.ID, &e.CompanyName, &e.Action, &e.AccountNo, &e.TargetAccount,
+			&e.Username, &e.Reason, &details, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan chart of accounts audit entry: %w", err)
+		}
+		if details != "" {
+			e.Details = json.RawMessage(details)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// writeAudit records a chart of accounts change
+func (s *Service) writeAudit(companyName, action, accountNo, target, username, reason string, details interface{}) error {
+	var detailsJSON interface{}
+	if details != nil {
+		raw, err := json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit details: %w", err)
+		}
+		detailsJSON = string(raw)
+	}
+	var targetValue interface{}
+	if target != "" {
+		targetValue = target
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO coa_audit_log (company_name, action, account_number, target_account, username, reason, details)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, companyName, action, accountNo, targetValue, username, reason, detailsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to write chart of accounts audit log: %w", err)
+	}
+	return nil
+}
+
+// refreshBalances brings the balance cache in line with a COA change. The
+// change itself is already saved, so a failure here is only logged; the next
+// scheduled refresh will catch up.
+func (s *Service) refreshBalances(companyName, username string, accountNos ...string) {
+	for _, accountNo := range accountNos {
+		if err := database.RefreshGLBalance(s.db, companyName, accountNo, username); err != nil {
+			fmt.Printf("coa: failed to refresh cached balance for %s: %v\n", accountNo, err)
+		}
+	}
+}
+
+// setInactive writes LINACTIVE for one account
+func setInactive(companyName, accountNo string, inactive bool) error {
+	n, err := company.UpdateDBFRowsByKey(companyName, "COA.dbf", "CACCTNO", map[string]map[string]interface{}{
+		accountNo: {"LINACTIVE": inactive},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update account %s: %w", accountNo, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("account %s not found", accountNo)
+	}
+	return nil
+}
+
+// children returns the accounts whose CPARENT is accountNo
+func children(accountNo string, accounts []ledger.Account) []ledger.Account {
+	var out []ledger.Account
+	for _, a := range accounts {
+		if a.HasParent() && strings.TrimSpace(a.Parent) == accountNo {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// usageTotals accumulates one account's GLMASTER lines
+type usageTotals struct {
+	usage   Usage
+	debits  currency.Currency
+	credits currency.Currency
+	balance currency.Currency
+	periods map[ledger.Period]bool
+}
+
+// scanUsage totals the GLMASTER history of the given accounts in one pass.
+// Income statement balances are limited to the fiscal year containing asOf.
+func scanUsage(companyName string, byNo map[string]ledger.Account, cal *ledger.Calendar, asOf time.Time, accountNos ...string) (map[string]*Usage, error) {
+	currentYear := cal.PeriodForDate(asOf).Year
+	totals := make(map[string]*usageTotals, len(accountNos))
+	for _, no := range accountNos {
+		totals[no] = &usageTotals{
+			usage:   Usage{AccountNo: no},
+			debits:  currency.Zero(),
+			credits: currency.Zero(),
+			balance: currency.Zero(),
+			periods: map[ledger.Period]bool{},
+		}
+	}
+
+	err := ledger.ScanGLEntries(companyName, func(e ledger.GLEntry) error {
+		t := totals[strings.TrimSpace(e.AccountNo)]
+		if t == nil {
+			return nil
+		}
+		t.usage.Lines++
+		t.debits = t.debits.Add(currency.NewFromFloat(e.Debit))
+		t.credits = t.credits.Add(currency.NewFromFloat(e.Credit))
+		if strings.TrimSpace(e.UnitNo) == "" {
+			t.usage.MissingUnits++
+		}
+		if strings.TrimSpace(e.DeptNo) == "" {
+			t.usage.MissingDepts++
+		}
+
+		p, ok := e.FiscalPeriodIn(cal)
+		if ok {
+			t.periods[p] = true
+		}
+		acct, known := byNo[t.usage.AccountNo]
+		if !known || ledger.IsBalanceSheet(acct.Type) || (ok && p.Year == currentYear) {
+			t.balance = t.balance.Add(e.Net())
+		}
+
+		if !e.Date.IsZero() {
+			d := e.Date
+			if t.usage.FirstDate == nil || d.Before(*t.usage.FirstDate) {
+				t.usage.FirstDate = &d
+			}
+			if t.usage.LastDate == nil || d.After(*t.usage.LastDate) {
+				t.usage.LastDate = &d
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GLMASTER: %w", err)
+	}
+
+	result := make(map[string]*Usage, len(totals))
+	for no, t := range totals {
+		u := t.usage
+		u.Debits = t.debits.ToFloat64()
+		u.Credits = t.credits.ToFloat64()
+		u.Balance = t.balance.ToFloat64()
+		for p := range t.periods {
+			u.Periods = append(u.Periods, p)
+		}
+		sort.Slice(u.Periods, func(i, j int) bool { return u.Periods[i].Before(u.Periods[j]) })
+		result[no] = &u
+	}
+	return result, nil
+}
@@ -0,0 +1,366 @@
+package coa
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// ActionMap is the audit log action for mapping changes
+const ActionMap = "map"
+
+// AccountMapping is one company account and the standard account it maps to
+type AccountMapping struct {
+	AccountNo           string     `json:"account_number"`
+	Description         string     `json:"description"`
+	Type                int        `json:"account_type"`
+	TypeName            string     `json:"account_type_name"`
+	IsInactive          bool       `json:"is_inactive"`
+	IsHeading           bool       `json:"is_heading"` // Title and total accounts need no mapping
+	StandardAccount     string     `json:"standard_account,omitempty"`
+	StandardDescription string     `json:"standard_description,omitempty"`
+	Problem             string     `json:"problem,omitempty"` // Why a stored mapping is not usable
+	MappedBy            string     `json:"mapped_by,omitempty"`
+	MappedAt            *time.Time `json:"mapped_at,omitempty"`
+}
+
+// MappingStatus is the company chart against one standard chart
+type MappingStatus struct {
+	Chart    *StandardChart   `json:"chart"`
+	Accounts []AccountMapping `json:"accounts"`
+	Mapped   int              `json:"mapped"`
+	Unmapped []string         `json:"unmapped"` // Posting accounts with no usable mapping
+	Invalid  []string         `json:"invalid"`  // Stored mappings that no longer fit the chart
+}
+
+// Suggestion is a proposed mapping for an unmapped account. Score 0 means the
+// account type's catch-all account was chosen because nothing matched.
+type Suggestion struct {
+	AccountNo           string `json:"account_number"`
+	Description         string `json:"description"`
+	StandardAccount     string `json:"standard_account"`
+	StandardDescription string `json:"standard_description"`
+	Score               int    `json:"score"`
+	Reason              string `json:"reason"`
+}
+
+// MappedChart is what reports need to restate the ledger on a standard chart
+type MappedChart struct {
+	Chart   *StandardChart
+	Targets map[string]string // Company account -> standard account
+}
+
+// checkMapping validates mapping a company account to a standard account. The
+// account types must agree so the amount lands on the same statement; company
+// accounts typed Other may map anywhere.
+func checkMapping(acct ledger.Account, standardNo string, chartAccounts map[string]ledger.Account) error {
+	std, ok := chartAccounts[standardNo]
+	switch {
+	case !ok:
+		return fmt.Errorf("standard account %s is not in the chart", standardNo)
+	case std.IsTitle || std.IsTotal:
+		return fmt.Errorf("standard account %s is a heading and cannot be mapped to", standardNo)
+	case acct.Type != std.Type && acct.Type != ledger.TypeOther:
+		return fmt.Errorf("%s is %s but standard account %s is %s", acct.AccountNo, acct.TypeName, standardNo, std.TypeName)
+	}
+	return nil
+}
+
+// mappingTargets returns the stored mappings of a company onto a chart
+func (s *Service) mappingTargets(companyName, chartCode string) (map[string]string, error) {
+	rows, err := s.db.Query(`SELECT account_number, standard_account FROM coa_mappings WHERE company_name = ? AND chart_code = ?`,
+		companyName, strings.ToLower(chartCode))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account mappings: %w", err)
+	}
+	defer rows.Close()
+
+	targets := map[string]string{}
+	for rows.Next() {
+		var acct, std string
+		if err := rows.Scan(&acct, &std); err != nil {
+			return nil, fmt.Errorf("failed to scan account mapping: %w", err)
+		}
+		targets[acct] = std
+	}
+	return targets, rows.Err()
+}
+
+// GetMappings returns every company account with its mapping onto a chart and
+// lists the accounts still to be mapped
+func (s *Service) GetMappings(companyName, chartCode string) (*MappingStatus, error) {
+	chart, err := s.GetStandardChart(companyName, chartCode)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+
+	type stored struct {
+		standard, by string
+		at           sql.NullTime
+	}
+	rows, err := s.db.Query(`
+		SELECT account_number, standard_account, COALESCE(mapped_by, ''), mapped_at
+		FROM coa_mappings WHERE company_name = ? AND chart_code = ?`, companyName, chart.Code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account mappings: %w", err)
+	}
+	defer rows.Close()
+	mappings := map[string]stored{}
+	for rows.Next() {
+		var acct string
+		var m stored
+		if err := rows.Scan(&acct, &m.standard, &m.by, &m.at); err != nil {
+			return nil, fmt.Errorf("failed to scan account mapping: %w", err)
+		}
+		mappings[acct] = m
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	chartAccounts := chart.AccountMap()
+	status := &MappingStatus{Chart: chart, Accounts: []AccountMapping{}, Unmapped: []string{}, Invalid: []string{}}
+	for _, a := range accounts {
+		m := AccountMapping{
+			AccountNo:   a.AccountNo,
+			Description: a.Description,
+			Type:        a.Type,
+			TypeName:    a.TypeName,
+			IsInactive:  a.IsInactive,
+			IsHeading:   a.IsTitle || a.IsTotal,
+		}
+		if stored, ok := mappings[a.AccountNo]; ok {
+			m.StandardAccount = stored.standard
+			m.StandardDescription = chartAccounts[stored.standard].Description
+			m.MappedBy = stored.by
+			if stored.at.Valid {
+				m.MappedAt = &stored.at.Time
+			}
+			if err := checkMapping(a, stored.standard, chartAccounts); err != nil {
+				m.Problem = err.Error()
+				status.Invalid = append(status.Invalid, a.AccountNo)
+			}
+		}
+		switch {
+		case m.StandardAccount != "" && m.Problem == "":
+			status.Mapped++
+		case !m.IsHeading:
+			status.Unmapped = append(status.Unmapped, a.AccountNo)
+		}
+		status.Accounts = append(status.Accounts, m)
+	}
+	return status, nil
+}
+
+// SaveMappings sets the standard account of company accounts on a chart. A
+// blank standard account removes the mapping.
+func (s *Service) SaveMappings(companyName, chartCode string, mappings map[string]string, username string) (*MappingStatus, error) {
+	chart, err := s.GetStandardChart(companyName, chartCode)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+	byNo := ledger.AccountMap(accounts)
+	chartAccounts := chart.AccountMap()
+
+	cleaned := make(map[string]string, len(mappings))
+	var problems []string
+	for acctNo, std := range mappings {
+		acctNo, std = strings.TrimSpace(acctNo), strings.TrimSpace(std)
+		acct, ok := byNo[acctNo]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("account %s is not in the chart of accounts", acctNo))
+			continue
+		}
+		if std != "" {
+			if err := checkMapping(acct, std, chartAccounts); err != nil {
+				problems = append(problems, err.Error())
+				continue
+			}
+		}
+		cleaned[acctNo] = std
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	if err := s.saveMappings(companyName, chart.Code, cleaned, username); err != nil {
+		return nil, err
+	}
+	if err := s.writeAudit(companyName, ActionMap, "*", chart.Code, username, "", cleaned); err != nil {
+		return nil, err
+	}
+	return s.GetMappings(companyName, chart.Code)
+}
+
+// saveMappings writes validated mappings; blank targets are deleted
+func (s *Service) saveMappings(companyName, chartCode string, mappings map[string]string, username string) error {
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveMappingsTx(tx, companyName, chartCode, mappings, username); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveMappingsTx writes mappings in tx; an empty standard account removes the mapping
+func saveMappingsTx(tx *sql.Tx, companyName, chartCode string, mappings map[string]string, username string) error {
+	for acctNo, std := range mappings {
+		if std == "" {
+			if _, err := tx.Exec(`DELETE FROM coa_mappings WHERE company_name = ? AND chart_code = ? AND account_number = ?`,
+				companyName, chartCode, acctNo); err != nil {
+				return fmt.Errorf("failed to remove mapping for %s: %w", acctNo, err)
+			}
+			continue
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO coa_mappings (company_name, chart_code, account_number, standard_account, mapped_by, mapped_at)
+			VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(company_name, chart_code, account_number) DO UPDATE SET
+				standard_account = excluded.standard_account,
+				mapped_by = excluded.mapped_by,
+				mapped_at = CURRENT_TIMESTAMP
+		`, companyName, chartCode, acctNo, std, username); err != nil {
+			return fmt.Errorf("failed to save mapping for %s: %w", acctNo, err)
+		}
+	}
+	return nil
+}
+
+// SuggestMappings proposes a standard account for every unmapped posting
+// account by matching its description against the standard accounts of the
+// same type. Nothing is saved.
+func (s *Service) SuggestMappings(companyName, chartCode string) ([]Suggestion, error) {
+	status, err := s.GetMappings(companyName, chartCode)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+	byNo := ledger.AccountMap(accounts)
+	candidates := postingAccounts(status.Chart)
+
+	suggestions := []Suggestion{}
+	for _, acctNo := range status.Unmapped {
+		acct := byNo[acctNo]
+		text := normalize(acct.Description)
+		if acct.IsBank {
+			text += " bank"
+		}
+		words := wordSet(text)
+
+		best := Suggestion{AccountNo: acct.AccountNo, Description: acct.Description}
+		for _, std := range candidates {
+			if std.Type != acct.Type && acct.Type != ledger.TypeOther {
+				continue
+			}
+			score, matched := 0, ""
+			for _, kw := range keywordsFor(status.Chart.Code, std.AccountNo) {
+				if containsPhrase(text, kw) {
+					// Longer phrases are more specific than single words
+					score += 2 * len(strings.Fields(kw))
+					if matched == "" {
+						matched = kw
+					}
+				}
+			}
+			for w := range wordSet(normalize(std.Description)) {
+				if words[w] && !stopWords[w] {
+					score++
+					if matched == "" {
+						matched = w
+					}
+				}
+			}
+			if score > best.Score {
+				best.StandardAccount, best.StandardDescription, best.Score = std.AccountNo, std.Description, score
+				best.Reason = fmt.Sprintf("description matches %q", matched)
+			}
+		}
+		if best.Score == 0 && status.Chart.Code == ChartCOPAS {
+			if fallback, ok := status.Chart.AccountMap()[copasFallbacks[acct.Type]]; ok {
+				best.StandardAccount, best.StandardDescription = fallback.AccountNo, fallback.Description
+				best.Reason = fmt.Sprintf("catch-all %s account", strings.ToLower(acct.TypeName))
+			}
+		}
+		if best.StandardAccount != "" {
+			suggestions = append(suggestions, best)
+		}
+	}
+	return suggestions, nil
+}
+
+// stopWords are description words too common to suggest a mapping on their own
+var stopWords = map[string]bool{
+	"and": true, "of": true, "the": true, "for": true, "other": true, "accounts": true, "account": true,
+	"expense": true, "expenses": true, "payable": true, "receivable": true, "income": true, "costs": true, "cost": true,
+}
+
+// normalize lower-cases text and replaces punctuation other than & and / with spaces
+func normalize(s string) string {
+	s = strings.ToLower(s)
+	return " " + strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&' && r != '/' && r != '-'
+	}), " ") + " "
+}
+
+func wordSet(normalized string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.Fields(normalized) {
+		words[w] = true
+	}
+	return words
+}
+
+// containsPhrase reports whether a normalized text contains a keyword as whole words
+func containsPhrase(normalized, keyword string) bool {
+	return strings.Contains(normalized, normalize(keyword))
+}
+
+// MappedChart loads a chart and the company's usable mappings onto it.
+// Accounts whose stored mapping no longer fits the chart are left unmapped.
+func (s *Service) MappedChart(companyName, chartCode string) (*MappedChart, error) {
+	chart, err := s.GetStandardChart(companyName, chartCode)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := s.mappingTargets(companyName, chart.Code)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+	byNo := ledger.AccountMap(accounts)
+	chartAccounts := chart.AccountMap()
+
+	mc := &MappedChart{Chart: chart, Targets: map[string]string{}}
+	for acctNo, std := range targets {
+		acct, ok := byNo[acctNo]
+		if !ok || checkMapping(acct, std, chartAccounts) != nil {
+			continue
+		}
+		mc.Targets[acctNo] = std
+	}
+	return mc, nil
+}
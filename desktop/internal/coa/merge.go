@@ -0,0 +1,186 @@
+package coa
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/company"
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// MergePreview describes what merging one account into another will change.
+// The merge is refused while Blockers is not empty; Warnings are shown to the
+// user but do not stop it.
+type MergePreview struct {
+	From     ledger.Account `json:"from"`
+	To       ledger.Account `json:"to"`
+	Usage    Usage          `json:"usage"`    // GLMASTER lines that move to To
+	Children []string       `json:"children"` // Sub-accounts re-parented to To
+	Warnings []string       `json:"warnings"`
+	Blockers []string       `json:"blockers"`
+}
+
+// MergeResult is what a merge changed
+type MergeResult struct {
+	Preview       *MergePreview `json:"preview"`
+	LinesMoved    int           `json:"lines_moved"`
+	ChildrenMoved int           `json:"children_moved"`
+	MergedAt      time.Time     `json:"merged_at"`
+}
+
+// PreviewMerge reports the GLMASTER history and sub-accounts that merging
+// from into to would reassign, without changing anything
+func (s *Service) PreviewMerge(companyName, from, to string, cal *ledger.Calendar) (*MergePreview, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+	byNo := ledger.AccountMap(accounts)
+
+	preview := &MergePreview{Children: []string{}, Warnings: []string{}, Blockers: []string{}}
+	fromAcct, fromOK := byNo[from]
+	toAcct, toOK := byNo[to]
+	preview.From, preview.To = fromAcct, toAcct
+
+	switch {
+	case from == "" || to == "":
+		return nil, fmt.Errorf("both a source and a target account are required")
+	case from == to:
+		preview.Blockers = append(preview.Blockers, "source and target are the same account")
+	case !fromOK:
+		preview.Blockers = append(preview.Blockers, fmt.Sprintf("source account %s does not exist", from))
+	case !toOK:
+		preview.Blockers = append(preview.Blockers, fmt.Sprintf("target account %s does not exist", to))
+	}
+	if len(preview.Blockers) > 0 {
+		return preview, nil
+	}
+
+	if toAcct.IsInactive {
+		preview.Blockers = append(preview.Blockers, fmt.Sprintf("target account %s is inactive", to))
+	}
+	if toAcct.IsTitle || toAcct.IsTotal {
+		preview.Blockers = append(preview.Blockers, fmt.Sprintf("target account %s is a title or total account and cannot hold postings", to))
+	}
+	if isDescendant(to, from, byNo) {
+		preview.Blockers = append(preview.Blockers, fmt.Sprintf("target account %s is a sub-account of %s", to, from))
+	}
+
+	if fromAcct.Type != toAcct.Type {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("account types differ: %s is %s, %s is %s",
+			from, fromAcct.TypeName, to, toAcct.TypeName))
+	}
+	if fromAcct.IsBank != toAcct.IsBank {
+		preview.Warnings = append(preview.Warnings, "only one of the accounts is a bank account; CHECKS.dbf entries are not reassigned")
+	}
+
+	usage, err := scanUsage(companyName, byNo, cal, time.Now(), from)
+	if err != nil {
+		return nil, err
+	}
+	preview.Usage = *usage[from]
+	if toAcct.RequiresUnit && preview.Usage.MissingUnits > 0 {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("%s requires a unit but %d of the moved lines have none",
+			to, preview.Usage.MissingUnits))
+	}
+	if toAcct.RequiresDept && preview.Usage.MissingDepts > 0 {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("%s requires a department but %d of the moved lines have none",
+			to, preview.Usage.MissingDepts))
+	}
+
+	for _, child := range children(from, accounts) {
+		preview.Children = append(preview.Children, child.AccountNo)
+	}
+	return preview, nil
+}
+
+// isDescendant reports whether account sits somewhere below ancestor in the
+// CPARENT hierarchy
+func isDescendant(account, ancestor string, byNo map[string]ledger.Account) bool {
+	seen := map[string]bool{}
+	for current := account; current != "" && !seen[current]; {
+		seen[current] = true
+		a, ok := byNo[current]
+		if !ok || !a.HasParent() {
+			return false
+		}
+		current = strings.TrimSpace(a.Parent)
+		if current == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeAccounts moves every GLMASTER line from one account to another,
+// re-parents the source's sub-accounts to the target and deactivates the
+// source. periodOpen is called for each fiscal period the moved lines fall in
+// and can refuse the merge when one of them is closed.
+func (s *Service) MergeAccounts(companyName, from, to, username, reason string, cal *ledger.Calendar, periodOpen func(ledger.Period) error) (*MergeResult, error) {
+	preview, err := s.PreviewMerge(companyName, from, to, cal)
+	if err != nil {
+		return nil, err
+	}
+	if len(preview.Blockers) > 0 {
+		return nil, fmt.Errorf("cannot merge %s into %s: %s", from, to, strings.Join(preview.Blockers, "; "))
+	}
+	if periodOpen != nil {
+		for _, p := range preview.Usage.Periods {
+			if err := periodOpen(p); err != nil {
+				return nil, fmt.Errorf("cannot merge %s into %s: %w", from, to, err)
+			}
+		}
+	}
+
+	from, to = preview.From.AccountNo, preview.To.AccountNo
+	result := &MergeResult{Preview: preview, MergedAt: time.Now()}
+
+	// The lines, the sub-accounts and the source account change in one VFP
+	// transaction, so a failure leaves the chart and ledger as they were
+	batch := company.NewDBFBatch(companyName)
+	result.LinesMoved, err = batch.Update("GLMASTER.dbf", "CACCTNO", map[string]map[string]interface{}{
+		from: {"CACCTNO": to},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reassign GLMASTER lines from %s to %s: %w", from, to, err)
+	}
+	if len(preview.Children) > 0 {
+		result.ChildrenMoved, err = batch.Update("COA.dbf", "CPARENT", map[string]map[string]interface{}{
+			from: {"CPARENT": to},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to re-parent sub-accounts of %s: %w", from, err)
+		}
+	}
+	if !preview.From.IsInactive {
+		if _, err := batch.Update("COA.dbf", "CACCTNO", map[string]map[string]interface{}{
+			from: {"LINACTIVE": true},
+		}); err != nil {
+			return nil, fmt.Errorf("failed to update account %s: %w", from, err)
+		}
+	}
+	if err := batch.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to merge %s into %s: %w", from, to, err)
+	}
+
+	if err := s.writeAudit(companyName, ActionMerge, from, to, username, reason, map[string]interface{}{
+		"lines_previewed": preview.Usage.Lines,
+		"lines_moved":     result.LinesMoved,
+		"debits":          preview.Usage.Debits,
+		"credits":         preview.Usage.Credits,
+		"periods":         preview.Usage.Periods,
+		"children":        preview.Children,
+		"warnings":        preview.Warnings,
+	}); err != nil {
+		return nil, err
+	}
+
+	if _, err := database.RefreshAllGLBalances(s.db, companyName, username); err != nil {
+		fmt.Printf("coa: failed to refresh cached balances after merge: %v\n", err)
+	}
+	return result, nil
+}
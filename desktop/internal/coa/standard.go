@@ -0,0 +1,361 @@
+package coa
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// ChartCOPAS is the built-in oil and gas reporting chart
+const ChartCOPAS = "copas"
+
+// StandardChart is a reporting chart that company accounts are mapped onto.
+// Title and total accounts group the chart; only posting accounts can be
+// mapped to.
+type StandardChart struct {
+	Code        string           `json:"code"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	BuiltIn     bool             `json:"built_in"`
+	Accounts    []ledger.Account `json:"accounts,omitempty"`
+	UpdatedBy   string           `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time       `json:"updated_at,omitempty"`
+}
+
+// AccountMap indexes the chart's accounts by number
+func (c *StandardChart) AccountMap() map[string]ledger.Account {
+	return ledger.AccountMap(c.Accounts)
+}
+
+// standardAccount is a built-in chart account with the words that suggest it
+// when mapping a company account
+type standardAccount struct {
+	number, description, parent string
+	accountType                 int
+	title                       bool
+	keywords                    []string
+}
+
+// copasAccounts is a COPAS-oriented chart for oil and gas operators and
+// non-operators: joint interest billing receivables, revenue distribution
+// payables, successful-efforts property accounts and lease operating expense
+var copasAccounts = []standardAccount{
+	{"1000", "Current Assets", "", ledger.TypeAsset, true, nil},
+	{"1010", "Cash", "1000", ledger.TypeAsset, false, []string{"cash", "bank", "checking", "savings", "money market", "petty"}},
+	{"1100", "Accounts Receivable - Joint Interest Billing", "1000", ledger.TypeAsset, false, []string{"jib", "joint interest", "joint owner", "partner receivable", "billing"}},
+	{"1110", "Accounts Receivable - Oil and Gas Sales", "1000", ledger.TypeAsset, false, []string{"revenue receivable", "sales receivable", "purchaser", "oil receivable", "gas receivable", "accrued revenue"}},
+	{"1120", "Accounts Receivable - Other", "1000", ledger.TypeAsset, false, []string{"receivable", "a/r", "note receivable", "employee advance"}},
+	{"1200", "Materials and Supplies Inventory", "1000", ledger.TypeAsset, false, []string{"inventory", "material", "supplies", "tubular", "pipe", "warehouse"}},
+	{"1300", "Prepaid Expenses", "1000", ledger.TypeAsset, false, []string{"prepaid", "deposit", "prepayment"}},
+	{"1400", "Oil and Gas Properties", "", ledger.TypeAsset, true, nil},
+	{"1410", "Unproved Properties - Leasehold", "1400", ledger.TypeAsset, false, []string{"unproved", "undeveloped", "lease bonus", "leasehold acquisition"}},
+	{"1420", "Proved Properties - Leasehold", "1400", ledger.TypeAsset, false, []string{"proved", "leasehold", "mineral", "royalty interest", "acquisition"}},
+	{"1430", "Intangible Drilling Costs", "1400", ledger.TypeAsset, false, []string{"intangible", "idc", "drilling cost", "completion cost"}},
+	{"1440", "Lease and Well Equipment", "1400", ledger.TypeAsset, false, []string{"tangible", "well equipment", "lease equipment", "tank", "pumping unit", "flowline", "wellhead"}},
+	{"1450", "Work in Progress - AFE", "1400", ledger.TypeAsset, false, []string{"wip", "work in progress", "afe", "construction in progress"}},
+	{"1490", "Accumulated DD&A - Oil and Gas Properties", "1400", ledger.TypeAsset, false, []string{"accumulated depletion", "accumulated dd&a", "accum depletion", "allowance for depletion"}},
+	{"1500", "Other Assets", "", ledger.TypeAsset, true, nil},
+	{"1510", "Furniture, Equipment and Vehicles", "1500", ledger.TypeAsset, false, []string{"furniture", "vehicle", "truck", "computer", "office equipment", "building", "land"}},
+	{"1520", "Accumulated Depreciation - Other Assets", "1500", ledger.TypeAsset, false, []string{"accumulated depreciation", "accum depreciation", "accum deprec"}},
+	{"1590", "Other Assets", "1500", ledger.TypeAsset, false, []string{"other asset", "investment", "bond", "goodwill"}},
+
+	{"2000", "Current Liabilities", "", ledger.TypeLiability, true, nil},
+	{"2010", "Accounts Payable - Trade", "2000", ledger.TypeLiability, false, []string{"accounts payable", "a/p", "trade payable", "vendor"}},
+	{"2020", "Revenue Distribution Payable", "2000", ledger.TypeLiability, false, []string{"revenue payable", "royalty payable", "owner payable", "distribution", "revenue distribution", "owners"}},
+	{"2030", "Owner Suspense", "2000", ledger.TypeLiability, false, []string{"suspense", "held revenue", "escheat", "unclaimed"}},
+	{"2040", "Severance and Production Taxes Payable", "2000", ledger.TypeLiability, false, []string{"severance", "production tax", "sev tax", "conservation tax"}},
+	{"2050", "Ad Valorem Taxes Payable", "2000", ledger.TypeLiability, false, []string{"ad valorem", "property tax"}},
+	{"2060", "Joint Interest Prepayments and Cash Calls", "2000", ledger.TypeLiability, false, []string{"cash call", "prepayment", "advance", "jib credit"}},
+	{"2090", "Accrued and Other Current Liabilities", "2000", ledger.TypeLiability, false, []string{"accrued", "payroll", "withholding", "payable", "credit card"}},
+	{"2500", "Long-Term Liabilities", "", ledger.TypeLiability, true, nil},
+	{"2510", "Notes Payable", "2500", ledger.TypeLiability, false, []string{"note payable", "loan", "line of credit", "mortgage", "debt"}},
+	{"2520", "Asset Retirement Obligations", "2500", ledger.TypeLiability, false, []string{"aro", "asset retirement", "plugging", "abandonment obligation"}},
+
+	{"3000", "Equity", "", ledger.TypeEquity, true, nil},
+	{"3010", "Capital", "3000", ledger.TypeEquity, false, []string{"capital", "stock", "partner", "member", "owner equity", "contribution"}},
+	{"3020", "Additional Paid-In Capital", "3000", ledger.TypeEquity, false, []string{"paid-in", "paid in", "apic", "surplus"}},
+	{"3100", "Retained Earnings", "3000", ledger.TypeEquity, false, []string{"retained", "earnings", "accumulated deficit"}},
+	{"3200", "Distributions", "3000", ledger.TypeEquity, false, []string{"distribution", "draw", "dividend"}},
+
+	{"4000", "Revenue", "", ledger.TypeRevenue, true, nil},
+	{"4010", "Oil Sales", "4000", ledger.TypeRevenue, false, []string{"oil", "crude", "condensate"}},
+	{"4020", "Gas Sales", "4000", ledger.TypeRevenue, false, []string{"gas", "residue", "casinghead"}},
+	{"4030", "Natural Gas Liquids Sales", "4000", ledger.TypeRevenue, false, []string{"ngl", "plant products", "liquids"}},
+	{"4040", "Other Product Sales", "4000", ledger.TypeRevenue, false, []string{"sulfur", "helium", "product", "water sales"}},
+	{"4100", "Operator Overhead Income", "4000", ledger.TypeRevenue, false, []string{"overhead", "copas", "drilling overhead", "producing overhead", "supervision"}},
+	{"4200", "Gain or Loss on Sale of Assets", "4000", ledger.TypeRevenue, false, []string{"gain", "loss on sale", "sale of assets", "disposal"}},
+	{"4300", "Interest Income", "4000", ledger.TypeRevenue, false, []string{"interest income", "interest earned", "dividend income"}},
+	{"4900", "Other Income", "4000", ledger.TypeRevenue, false, []string{"other income", "miscellaneous income", "misc income", "rental income"}},
+
+	{"5000", "Production Expenses", "", ledger.TypeExpense, true, nil},
+	{"5010", "Lease Operating Expenses", "5000", ledger.TypeExpense, false, []string{"lease operating", "loe", "operating expense", "pumper", "chemical", "saltwater", "disposal", "repairs", "electricity", "utilities", "compression"}},
+	{"5020", "Workover Expenses", "5000", ledger.TypeExpense, false, []string{"workover", "well service", "rework", "recompletion"}},
+	{"5030", "Severance and Production Taxes", "5000", ledger.TypeExpense, false, []string{"severance", "production tax", "sev tax", "conservation"}},
+	{"5040", "Ad Valorem Taxes", "5000", ledger.TypeExpense, false, []string{"ad valorem", "property tax"}},
+	{"5050", "Gathering, Transportation and Processing", "5000", ledger.TypeExpense, false, []string{"gathering", "transportation", "processing", "marketing", "trucking", "compression fee"}},
+	{"5100", "Exploration Expenses", "", ledger.TypeExpense, true, nil},
+	{"5110", "Geological and Geophysical", "5100", ledger.TypeExpense, false, []string{"geological", "geophysical", "g&g", "seismic"}},
+	{"5120", "Dry Hole Costs", "5100", ledger.TypeExpense, false, []string{"dry hole", "dry-hole"}},
+	{"5130", "Delay Rentals", "5100", ledger.TypeExpense, false, []string{"delay rental", "rental", "shut-in royalty", "shut in royalty"}},
+	{"5140", "Abandoned and Impaired Leasehold", "5100", ledger.TypeExpense, false, []string{"abandoned", "expired lease", "leasehold impairment"}},
+	{"5200", "Depletion, Depreciation and Amortization", "", ledger.TypeExpense, true, nil},
+	{"5210", "DD&A Expense", "5200", ledger.TypeExpense, false, []string{"depletion", "depreciation", "amortization", "dd&a"}},
+	{"5220", "Impairment", "5200", ledger.TypeExpense, false, []string{"impairment", "writedown", "write-down"}},
+	{"5230", "Accretion Expense", "5200", ledger.TypeExpense, false, []string{"accretion"}},
+	{"5300", "General and Administrative", "", ledger.TypeExpense, true, nil},
+	{"5310", "Salaries and Benefits", "5300", ledger.TypeExpense, false, []string{"salary", "salaries", "wages", "payroll", "benefits", "health insurance", "401k", "bonus"}},
+	{"5320", "Office and Administrative", "5300", ledger.TypeExpense, false, []string{"office", "rent", "telephone", "postage", "supplies", "software", "travel", "dues", "bank charges", "bank fees"}},
+	{"5330", "Professional Fees", "5300", ledger.TypeExpense, false, []string{"legal", "accounting", "audit", "professional", "consulting", "engineering", "landman"}},
+	{"5340", "Insurance", "5300", ledger.TypeExpense, false, []string{"insurance", "bond premium"}},
+	{"5400", "Interest Expense", "", ledger.TypeExpense, false, []string{"interest expense", "finance charge", "loan interest"}},
+	{"5900", "Other Expense", "", ledger.TypeExpense, false, []string{"other expense", "miscellaneous", "misc", "penalties", "donations", "income tax"}},
+
+	{"6000", "Other", "", ledger.TypeOther, true, nil},
+	{"6010", "Clearing and Suspense Accounts", "6000", ledger.TypeOther, false, []string{"clearing", "suspense", "holding", "transfer"}},
+}
+
+// copasFallbacks is the catch-all account suggested for a type when no keyword matches
+var copasFallbacks = map[int]string{
+	ledger.TypeAsset:     "1590",
+	ledger.TypeLiability: "2090",
+	ledger.TypeEquity:    "3010",
+	ledger.TypeRevenue:   "4900",
+	ledger.TypeExpense:   "5900",
+	ledger.TypeOther:     "6010",
+}
+
+// copasChart returns the built-in COPAS chart
+func copasChart() *StandardChart {
+	chart := &StandardChart{
+		Code:        ChartCOPAS,
+		Name:        "COPAS Oil and Gas",
+		Description: "COPAS-oriented chart for oil and gas operators: JIB receivables, revenue distribution payables, successful-efforts properties and lease operating expense",
+		BuiltIn:     true,
+		Accounts:    make([]ledger.Account, 0, len(copasAccounts)),
+	}
+	for i, a := range copasAccounts {
+		chart.Accounts = append(chart.Accounts, ledger.Account{
+			RowIndex:    i,
+			AccountNo:   a.number,
+			Type:        a.accountType,
+			TypeName:    ledger.AccountTypeName(a.accountType),
+			Description: a.description,
+			Parent:      a.parent,
+			IsTitle:     a.title,
+		})
+	}
+	return chart
+}
+
+// keywordsFor returns the suggestion keywords of a built-in chart account
+func keywordsFor(chartCode, accountNo string) []string {
+	if chartCode != ChartCOPAS {
+		return nil
+	}
+	for _, a := range copasAccounts {
+		if a.number == accountNo {
+			return a.keywords
+		}
+	}
+	return nil
+}
+
+// GetStandardCharts lists the built-in chart and the company's custom charts, without accounts
+func (s *Service) GetStandardCharts(companyName string) ([]StandardChart, error) {
+	builtIn := copasChart()
+	builtIn.Accounts = nil
+	charts := []StandardChart{*builtIn}
+
+	rows, err := s.db.Query(`
+		SELECT code, name, COALESCE(description, ''), COALESCE(updated_by, ''), updated_at
+		FROM standard_charts
+		WHERE company_name = ?
+		ORDER BY name`, companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query standard charts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c StandardChart
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&c.Code, &c.Name, &c.Description, &c.UpdatedBy, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan standard chart: %w", err)
+		}
+		if updatedAt.Valid {
+			c.UpdatedAt = &updatedAt.Time
+		}
+		charts = append(charts, c)
+	}
+	return charts, rows.Err()
+}
+
+// GetStandardChart returns a chart with its accounts
+func (s *Service) GetStandardChart(companyName, code string) (*StandardChart, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == ChartCOPAS {
+		return copasChart(), nil
+	}
+
+	var c StandardChart
+	var updatedAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT code, name, COALESCE(description, ''), COALESCE(updated_by, ''), updated_at
+		FROM standard_charts
+		WHERE company_name = ? AND code = ?`, companyName, code).Scan(&c.Code, &c.Name, &c.Description, &c.UpdatedBy, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("standard chart %q not found", code)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load standard chart: %w", err)
+	}
+	if updatedAt.Valid {
+		c.UpdatedAt = &updatedAt.Time
+	}
+
+	rows, err := s.db.Query(`
+		SELECT account_number, description, account_type, COALESCE(parent, ''), is_title, is_total
+		FROM standard_chart_accounts
+		WHERE company_name = ? AND chart_code = ?
+		ORDER BY sort_order, account_number`, companyName, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load standard chart accounts: %w", err)
+	}
+	defer rows.Close()
+
+	c.Accounts = []ledger.Account{}
+	for rows.Next() {
+		a := ledger.Account{RowIndex: len(c.Accounts)}
+		if err := rows.Scan(&a.AccountNo, &a.Description, &a.Type, &a.Parent, &a.IsTitle, &a.IsTotal); err != nil {
+			return nil, fmt.Errorf("failed to scan standard chart account: %w", err)
+		}
+		a.TypeName = ledger.AccountTypeName(a.Type)
+		c.Accounts = append(c.Accounts, a)
+	}
+	return &c, rows.Err()
+}
+
+// SaveStandardChart creates or replaces a custom chart from CSV or JSON in the
+// chart of accounts export format. Parents must be accounts of the same chart.
+func (s *Service) SaveStandardChart(companyName, code, name, description, content, format, username string) (*StandardChart, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	name = strings.TrimSpace(name)
+	switch {
+	case code == "":
+		return nil, fmt.Errorf("chart code is required")
+	case code == ChartCOPAS:
+		return nil, fmt.Errorf("%q is the built-in chart and cannot be replaced", ChartCOPAS)
+	case name == "":
+		return nil, fmt.Errorf("chart name is required")
+	}
+
+	rows, err := ParseAccounts(content, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the chart file has no accounts")
+	}
+
+	byNo := make(map[string]ledger.Account, len(rows))
+	var problems []string
+	for _, r := range rows {
+		if _, dup := byNo[r.AccountNo]; dup {
+			problems = append(problems, fmt.Sprintf("row %d: account %s appears more than once", r.Row, r.AccountNo))
+			continue
+		}
+		byNo[r.AccountNo] = r.account()
+	}
+	for _, r := range rows {
+		for _, p := range validate(r.account(), ledger.Account{}, true, byNo, nil) {
+			problems = append(problems, fmt.Sprintf("row %d: %s", r.Row, p))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO standard_charts (company_name, code, name, description, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(company_name, code) DO UPDATE SET
+			name = excluded.name,
+			description = excluded.description,
+			updated_by = excluded.updated_by,
+			updated_at = CURRENT_TIMESTAMP
+	`, companyName, code, name, description, username); err != nil {
+		return nil, fmt.Errorf("failed to save standard chart: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM standard_chart_accounts WHERE company_name = ? AND chart_code = ?`, companyName, code); err != nil {
+		return nil, fmt.Errorf("failed to replace standard chart accounts: %w", err)
+	}
+	for i, r := range rows {
+		if _, err := tx.Exec(`
+			INSERT INTO standard_chart_accounts (company_name, chart_code, account_number, description, account_type, parent, is_title, is_total, sort_order)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, companyName, code, r.AccountNo, r.Description, r.Type, nullIfEmpty(r.Parent), r.IsTitle, r.IsTotal, i); err != nil {
+			return nil, fmt.Errorf("failed to save standard chart account %s: %w", r.AccountNo, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit standard chart: %w", err)
+	}
+	return s.GetStandardChart(companyName, code)
+}
+
+// DeleteStandardChart removes a custom chart and the mappings onto it
+func (s *Service) DeleteStandardChart(companyName, code string) error {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == ChartCOPAS {
+		return fmt.Errorf("the built-in chart cannot be deleted")
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM standard_charts WHERE company_name = ? AND code = ?`, companyName, code)
+	if err != nil {
+		return fmt.Errorf("failed to delete standard chart: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("standard chart %q not found", code)
+	}
+	for _, table := range []string{"standard_chart_accounts", "coa_mappings"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE company_name = ? AND chart_code = ?`, companyName, code); err != nil {
+			return fmt.Errorf("failed to delete standard chart: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// postingAccounts returns the chart accounts that can be mapped to, in chart order
+func postingAccounts(chart *StandardChart) []ledger.Account {
+	var out []ledger.Account
+	for _, a := range chart.Accounts {
+		if !a.IsTitle && !a.IsTotal {
+			out = append(out, a)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].AccountNo < out[j].AccountNo })
+	return out
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
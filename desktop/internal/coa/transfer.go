@@ -0,0 +1,507 @@
+package coa
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/company"
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Transfer formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// ActionImport is the audit log action for a chart of accounts import
+const ActionImport = "import"
+
+// TransferAccount is one account in an exported or imported chart. StandardAccount
+// is the account it maps to on the standard chart named in the file or import.
+type TransferAccount struct {
+	Row             int    `json:"-"` // Source row for import errors
+	AccountNo       string `json:"account_number"`
+	Description     string `json:"description"`
+	Type            int    `json:"account_type"`
+	TypeName        string `json:"account_type_name,omitempty"`
+	Parent          string `json:"parent,omitempty"`
+	RequiresUnit    bool   `json:"requires_unit"`
+	RequiresDept    bool   `json:"requires_dept"`
+	IsBank          bool   `json:"is_bank_account"`
+	IsTitle         bool   `json:"is_title"`
+	IsTotal         bool   `json:"is_total"`
+	IsInactive      bool   `json:"is_inactive"`
+	StandardAccount string `json:"standard_account,omitempty"`
+}
+
+func (t TransferAccount) account() ledger.Account {
+	return ledger.Account{
+		AccountNo:    t.AccountNo,
+		Type:         t.Type,
+		TypeName:     ledger.AccountTypeName(t.Type),
+		Description:  t.Description,
+		Parent:       t.Parent,
+		RequiresUnit: t.RequiresUnit,
+		RequiresDept: t.RequiresDept,
+		IsBank:       t.IsBank,
+		IsInactive:   t.IsInactive,
+		IsTitle:      t.IsTitle,
+		IsTotal:      t.IsTotal,
+	}
+}
+
+// ChartFile is the JSON export format
+type ChartFile struct {
+	CompanyName string            `json:"company_name"`
+	ChartCode   string            `json:"chart_code,omitempty"` // Standard chart of the StandardAccount values
+	ExportedAt  time.Time         `json:"exported_at"`
+	Accounts    []TransferAccount `json:"accounts"`
+}
+
+// ImportOptions control a chart of accounts import
+type ImportOptions struct {
+	UpdateExisting bool   `json:"update_existing"` // Otherwise accounts already in COA.dbf are skipped
+	DryRun         bool   `json:"dry_run"`         // Validate and report without writing
+	ChartCode      string `json:"chart_code"`      // Standard chart for the file's standard account column
+}
+
+// RowError lists the problems with one imported row
+type RowError struct {
+	Row       int      `json:"row"`
+	AccountNo string   `json:"account_number"`
+	Problems  []string `json:"problems"`
+}
+
+// ImportResult summarises an import. Nothing is written when Errors is not
+// empty or the import was a dry run.
+type ImportResult struct {
+	Added     []string   `json:"added"`
+	Updated   []string   `json:"updated"`
+	Unchanged int        `json:"unchanged"`
+	Skipped   []string   `json:"skipped"` // Existing accounts left alone without UpdateExisting
+	Mapped    int        `json:"mapped"`
+	Errors    []RowError `json:"errors"`
+	Warnings  []string   `json:"warnings"`
+	Applied   bool       `json:"applied"`
+}
+
+// Export writes the chart of accounts as CSV or JSON. With a chartCode each
+// account carries the standard account it is mapped to.
+func (s *Service) Export(companyName, format string, includeInactive bool, chartCode string) ([]byte, error) {
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+	var targets map[string]string
+	if chartCode != "" {
+		if _, err := s.GetStandardChart(companyName, chartCode); err != nil {
+			return nil, err
+		}
+		if targets, err = s.mappingTargets(companyName, chartCode); err != nil {
+			return nil, err
+		}
+	}
+
+	file := ChartFile{CompanyName: companyName, ChartCode: chartCode, ExportedAt: time.Now(), Accounts: []TransferAccount{}}
+	for _, a := range accounts {
+		if a.IsInactive && !includeInactive {
+			continue
+		}
+		file.Accounts = append(file.Accounts, TransferAccount{
+			AccountNo:       a.AccountNo,
+			Description:     a.Description,
+			Type:            a.Type,
+			TypeName:        a.TypeName,
+			Parent:          parentOf(a),
+			RequiresUnit:    a.RequiresUnit,
+			RequiresDept:    a.RequiresDept,
+			IsBank:          a.IsBank,
+			IsTitle:         a.IsTitle,
+			IsTotal:         a.IsTotal,
+			IsInactive:      a.IsInactive,
+			StandardAccount: targets[a.AccountNo],
+		})
+	}
+
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		return json.MarshalIndent(file, "", "  ")
+	case FormatCSV, "":
+		return exportCSV(file)
+	}
+	return nil, fmt.Errorf("unsupported export format: %s", format)
+}
+
+func exportCSV(file ChartFile) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := []string{"Account", "Description", "Type", "Type Name", "Parent", "Requires Unit", "Requires Dept", "Bank", "Title", "Total", "Inactive"}
+	if file.ChartCode != "" {
+		header = append(header, "Standard Account")
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	yn := func(b bool) string {
+		if b {
+			return "Y"
+		}
+		return "N"
+	}
+	for _, a := range file.Accounts {
+		record := []string{a.AccountNo, a.Description, strconv.Itoa(a.Type), a.TypeName, a.Parent,
+			yn(a.RequiresUnit), yn(a.RequiresDept), yn(a.IsBank), yn(a.IsTitle), yn(a.IsTotal), yn(a.IsInactive)}
+		if file.ChartCode != "" {
+			record = append(record, a.StandardAccount)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// parentOf returns an account's CPARENT, blank for the legacy "no parent" values
+func parentOf(a ledger.Account) string {
+	if !a.HasParent() {
+		return ""
+	}
+	return strings.TrimSpace(a.Parent)
+}
+
+// ParseAccounts reads accounts from CSV (with a header row) or JSON (a ChartFile
+// or a bare array of accounts). An empty format is detected from the content.
+// Type may be the NACCTTYPE number or its name.
+func ParseAccounts(content, format string) ([]TransferAccount, error) {
+	content = strings.TrimPrefix(strings.TrimSpace(content), "\ufeff")
+	if format == "" {
+		format = FormatCSV
+		if strings.HasPrefix(content, "{") || strings.HasPrefix(content, "[") {
+			format = FormatJSON
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		return parseJSON(content)
+	case FormatCSV:
+		return parseCSV(content)
+	}
+	return nil, fmt.Errorf("unsupported import format: %s", format)
+}
+
+func parseJSON(content string) ([]TransferAccount, error) {
+	var accounts []TransferAccount
+	if strings.HasPrefix(content, "[") {
+		if err := json.Unmarshal([]byte(content), &accounts); err != nil {
+			return nil, fmt.Errorf("invalid chart of accounts JSON: %w", err)
+		}
+	} else {
+		var file ChartFile
+		if err := json.Unmarshal([]byte(content), &file); err != nil {
+			return nil, fmt.Errorf("invalid chart of accounts JSON: %w", err)
+		}
+		accounts = file.Accounts
+	}
+
+	out := make([]TransferAccount, 0, len(accounts))
+	for i, a := range accounts {
+		a.Row = i + 1
+		a.AccountNo = strings.TrimSpace(a.AccountNo)
+		a.Description = strings.TrimSpace(a.Description)
+		a.Parent = strings.TrimSpace(a.Parent)
+		a.StandardAccount = strings.TrimSpace(a.StandardAccount)
+		if a.Type == 0 && a.TypeName != "" {
+			a.Type = parseAccountType(a.TypeName)
+		}
+		if a.AccountNo == "" {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func parseCSV(content string) ([]TransferAccount, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts header: %w", err)
+	}
+	// Headers match case-insensitively, with underscores read as spaces so the
+	// JSON field names work too
+	col := func(names ...string) int {
+		for i, h := range header {
+			h = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(h), "_", " "))
+			for _, n := range names {
+				if h == n {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+	acctCol := col("account", "account number", "account no", "acct", "acctno", "cacctno", "number")
+	descCol := col("description", "desc", "name", "account name", "cacctdesc")
+	typeCol := col("type", "account type", "naccttype")
+	typeNameCol := col("type name", "account type name")
+	parentCol := col("parent", "parent account", "cparent")
+	unitCol := col("requires unit", "unit", "lacctunit")
+	deptCol := col("requires dept", "dept", "lacctdept")
+	bankCol := col("bank", "bank account", "is bank", "is bank account", "lbankacct")
+	titleCol := col("title", "is title", "ltitle")
+	totalCol := col("total", "is total", "ltotalacct")
+	inactiveCol := col("inactive", "is inactive", "linactive")
+	standardCol := col("standard account", "standard", "mapped to", "copas")
+	if acctCol < 0 {
+		return nil, fmt.Errorf("the chart of accounts file needs an Account column")
+	}
+
+	cell := func(record []string, i int) string {
+		if i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	var accounts []TransferAccount
+	rowNo := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chart of accounts file: %w", err)
+		}
+		rowNo++
+		a := TransferAccount{
+			Row:             rowNo,
+			AccountNo:       cell(record, acctCol),
+			Description:     cell(record, descCol),
+			Parent:          cell(record, parentCol),
+			RequiresUnit:    parseFlag(cell(record, unitCol)),
+			RequiresDept:    parseFlag(cell(record, deptCol)),
+			IsBank:          parseFlag(cell(record, bankCol)),
+			IsTitle:         parseFlag(cell(record, titleCol)),
+			IsTotal:         parseFlag(cell(record, totalCol)),
+			IsInactive:      parseFlag(cell(record, inactiveCol)),
+			StandardAccount: cell(record, standardCol),
+		}
+		if a.AccountNo == "" {
+			continue
+		}
+		a.Type = parseAccountType(cell(record, typeCol))
+		if a.Type == 0 {
+			a.Type = parseAccountType(cell(record, typeNameCol))
+		}
+		a.TypeName = ledger.AccountTypeName(a.Type)
+		accounts = append(accounts, a)
+	}
+	return accounts, nil
+}
+
+// parseAccountType reads a NACCTTYPE number or type name; 0 when unrecognised
+func parseAccountType(s string) int {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	switch s {
+	case "asset", "assets":
+		return ledger.TypeAsset
+	case "liability", "liabilities":
+		return ledger.TypeLiability
+	case "equity", "capital":
+		return ledger.TypeEquity
+	case "revenue", "income":
+		return ledger.TypeRevenue
+	case "expense", "expenses":
+		return ledger.TypeExpense
+	case "other":
+		return ledger.TypeOther
+	}
+	return 0
+}
+
+// parseFlag reads the logical values VFP and spreadsheets produce
+func parseFlag(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "y", "yes", "t", "true", ".t.", "1", "x":
+		return true
+	}
+	return false
+}
+
+// Import adds and optionally updates COA.dbf accounts from CSV or JSON. Every
+// row is validated against the chart as it will be after the import, so
+// parents may appear anywhere in the file. Any row error stops the whole
+// import. The active flag of existing accounts is not changed; deactivation
+// goes through Deactivate and its checks.
+func (s *Service) Import(companyName, content, format string, opts ImportOptions, username string) (*ImportResult, error) {
+	rows, err := ParseAccounts(content, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the chart of accounts file has no accounts")
+	}
+
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+	existing := ledger.AccountMap(accounts)
+	widths, err := company.DBFColumnWidths(companyName, "COA.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	var chartAccounts map[string]ledger.Account
+	if opts.ChartCode != "" {
+		chart, err := s.GetStandardChart(companyName, opts.ChartCode)
+		if err != nil {
+			return nil, err
+		}
+		opts.ChartCode = chart.Code
+		chartAccounts = chart.AccountMap()
+	}
+
+	result := &ImportResult{Added: []string{}, Updated: []string{}, Skipped: []string{}, Errors: []RowError{}, Warnings: []string{}}
+	rowErr := func(r TransferAccount, problems ...string) {
+		result.Errors = append(result.Errors, RowError{Row: r.Row, AccountNo: r.AccountNo, Problems: problems})
+	}
+
+	// The chart after the import, for parent and cycle checks
+	combined := make(map[string]ledger.Account, len(existing)+len(rows))
+	for no, a := range existing {
+		combined[no] = a
+	}
+	seen := map[string]bool{}
+	var apply []TransferAccount
+	for _, r := range rows {
+		if seen[r.AccountNo] {
+			rowErr(r, fmt.Sprintf("account %s appears more than once", r.AccountNo))
+			continue
+		}
+		seen[r.AccountNo] = true
+		before, exists := existing[r.AccountNo]
+		if exists && !opts.UpdateExisting {
+			result.Skipped = append(result.Skipped, r.AccountNo)
+			continue
+		}
+		acct := r.account()
+		if exists {
+			if acct.IsInactive != before.IsInactive {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("row %d: the active flag of %s is not changed by an import", r.Row, r.AccountNo))
+			}
+			acct.IsInactive = before.IsInactive
+		}
+		combined[r.AccountNo] = acct
+		apply = append(apply, r)
+	}
+
+	adds := []map[string]interface{}{}
+	updates := map[string]map[string]interface{}{}
+	mappings := map[string]string{}
+	for _, r := range apply {
+		acct := combined[r.AccountNo]
+		before, exists := existing[r.AccountNo]
+		problems := validate(acct, before, !exists, combined, widths)
+
+		if r.StandardAccount != "" {
+			if chartAccounts == nil {
+				problems = append(problems, "a standard account is given but no standard chart was selected")
+			} else if err := checkMapping(acct, r.StandardAccount, chartAccounts); err != nil {
+				problems = append(problems, err.Error())
+			} else {
+				mappings[r.AccountNo] = r.StandardAccount
+			}
+		}
+		if len(problems) > 0 {
+			rowErr(r, problems...)
+			continue
+		}
+
+		values := accountValues(acct, widths)
+		switch {
+		case !exists:
+			values["CACCTNO"] = acct.AccountNo
+			if _, ok := widths["LINACTIVE"]; ok {
+				values["LINACTIVE"] = acct.IsInactive
+			}
+			adds = append(adds, values)
+			result.Added = append(result.Added, acct.AccountNo)
+		case sameAccount(acct, before):
+			result.Unchanged++
+		default:
+			updates[acct.AccountNo] = values
+			result.Updated = append(result.Updated, acct.AccountNo)
+		}
+	}
+	result.Mapped = len(mappings)
+
+	if len(result.Errors) > 0 || opts.DryRun {
+		return result, nil
+	}
+
+	// Every row is valid: write the accounts in one VFP transaction, and the
+	// mappings in a SQLite transaction that is only committed once they succeed
+	batch := company.NewDBFBatch(companyName)
+	if err := batch.Append("COA.dbf", adds); err != nil {
+		return nil, fmt.Errorf("failed to add imported accounts: %w", err)
+	}
+	if _, err := batch.Update("COA.dbf", "CACCTNO", updates); err != nil {
+		return nil, fmt.Errorf("failed to update imported accounts: %w", err)
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := saveMappingsTx(tx, companyName, opts.ChartCode, mappings, username); err != nil {
+		return nil, err
+	}
+	if err := batch.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to import accounts: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("accounts were imported but the standard chart mappings were not saved: %w", err)
+	}
+	result.Applied = true
+
+	if err := s.writeAudit(companyName, ActionImport, "*", opts.ChartCode, username, "", map[string]interface{}{
+		"added":     result.Added,
+		"updated":   result.Updated,
+		"unchanged": result.Unchanged,
+		"skipped":   result.Skipped,
+		"mapped":    result.Mapped,
+	}); err != nil {
+		return nil, err
+	}
+	if len(adds) > 0 || len(updates) > 0 {
+		if _, err := database.RefreshAllGLBalances(s.db, companyName, username); err != nil {
+			fmt.Printf("coa: failed to refresh cached balances after import: %v\n", err)
+		}
+	}
+	return result, nil
+}
+
+// sameAccount reports whether an import row would leave an account unchanged
+func sameAccount(a, b ledger.Account) bool {
+	return a.Description == b.Description && a.Type == b.Type && parentOf(a) == parentOf(b) &&
+		a.RequiresUnit == b.RequiresUnit && a.RequiresDept == b.RequiresDept && a.IsBank == b.IsBank &&
+		a.IsTitle == b.IsTitle && a.IsTotal == b.IsTotal
+}
@@ -535,33 +535,9 @@ func ReadDBFFileDirectly(filePath, searchTerm string, offset, limit int, sortCol
 	}, nil
 }
 
-// ReadDBFFile reads a DBF file and returns its structure and data with pagination and sorting
-// If searchTerm is provided, it searches across all records and returns only matching ones
-//
-// ⚠️ CRITICAL WARNING: NEVER use arbitrary limits for financial calculations!
-// For GL balances, outstanding checks, or any financial reporting, ALWAYS use:
-//   offset=0, limit=0 (which means read ALL records)
-//
-// Only use non-zero limits for:
-//   - UI pagination/display
-//   - User-requested limited views  
-//   - Quick data sampling/preview
-//
-// Example of CORRECT usage for financial calculations:
-//   ReadDBFFile(companyName, "GLMASTER.dbf", "", 0, 0, "", "") // Reads ALL records
-//
-// A hardcoded limit of 50,000 caused a $400,000 discrepancy in GL calculations!
-func ReadDBFFile(companyName, fileName, searchTerm string, offset, limit int, sortColumn, sortDirection string) (map[string]interface{}, error) {
-	// Use defer/recover to prevent crashes
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Printf("PANIC RECOVERED in ReadDBFFile %s/%s: %v\n", companyName, fileName, r)
-		}
-	}()
-	
-	fmt.Printf("ReadDBFFile: %s/%s - reading actual DBF data\n", companyName, fileName)
-	debug.LogInfo("ReadDBFFile", fmt.Sprintf("Called with company=%s, file=%s", companyName, fileName))
-	
+// resolveDBFPath builds the full path to a DBF file for a company, honoring the
+// platform-specific rules for absolute, relative and folder-name company paths
+func resolveDBFPath(companyName, fileName string) (string, error) {
 	var filePath string
 	
 	// Log the incoming parameters
@@ -600,7 +576,7 @@ func ReadDBFFile(companyName, fileName, searchTerm string, offset, limit int, so
 			datafilesPath, err := getDatafilesPath()
 			if err != nil {
 				writeErrorLog(fmt.Sprintf("ReadDBFFile: Failed to get datafiles path: %v", err))
-				return nil, err
+				return "", err
 			}
 			filePath = filepath.Join(datafilesPath, filepath.Base(companyName), fileName)
 		} else {
@@ -608,13 +584,47 @@ func ReadDBFFile(companyName, fileName, searchTerm string, offset, limit int, so
 			datafilesPath, err := getDatafilesPath()
 			if err != nil {
 				writeErrorLog(fmt.Sprintf("ReadDBFFile: Failed to get datafiles path: %v", err))
-				return nil, err
+				return "", err
 			}
 			filePath = filepath.Join(datafilesPath, companyName, fileName)
 		}
 		writeErrorLog(fmt.Sprintf("ReadDBFFile: Mac/Linux path, result='%s'", filePath))
 		debug.LogInfo("ReadDBFFile", fmt.Sprintf("Mac/Linux path: %s", filePath))
 	}
+	return filePath, nil
+}
+
+// ReadDBFFile reads a DBF file and returns its structure and data with pagination and sorting
+// If searchTerm is provided, it searches across all records and returns only matching ones
+//
+// ⚠️ CRITICAL WARNING: NEVER use arbitrary limits for financial calculations!
+// For GL balances, outstanding checks, or any financial reporting, ALWAYS use:
+//   offset=0, limit=0 (which means read ALL records)
+//
+// Only use non-zero limits for:
+//   - UI pagination/display
+//   - User-requested limited views  
+//   - Quick data sampling/preview
+//
+// Example of CORRECT usage for financial calculations:
+//   ReadDBFFile(companyName, "GLMASTER.dbf", "", 0, 0, "", "") // Reads ALL records
+//
+// A hardcoded limit of 50,000 caused a $400,000 discrepancy in GL calculations!
+func ReadDBFFile(companyName, fileName, searchTerm string, offset, limit int, sortColumn, sortDirection string) (map[string]interface{}, error) {
+	// Use defer/recover to prevent crashes
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("PANIC RECOVERED in ReadDBFFile %s/%s: %v\n", companyName, fileName, r)
+		}
+	}()
+	
+	fmt.Printf("ReadDBFFile: %s/%s - reading actual DBF data\n", companyName, fileName)
+	debug.LogInfo("ReadDBFFile", fmt.Sprintf("Called with company=%s, file=%s", companyName, fileName))
+	
+	filePath, err := resolveDBFPath(companyName, fileName)
+	if err != nil {
+		return nil, err
+	}
 	fmt.Printf("Full file path: %s\n", filePath)
 	
 	// Check if file exists
@@ -831,6 +841,66 @@ func ReadDBFFile(companyName, fileName, searchTerm string, offset, limit int, so
 	}, nil
 }
 
+// StatDBFFile returns the file info of a company DBF file. Callers use the size and
+// modification time to tell whether a cached result derived from the file is stale.
+func StatDBFFile(companyName, fileName string) (os.FileInfo, error) {
+	filePath, err := resolveDBFPath(companyName, fileName)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(filePath)
+}
+
+// ScanDBFFile streams every active record of a DBF file to fn without holding the
+// table in memory, for full-file passes over large tables like GLMASTER. The
+// columns slice is the same on every call. A non-nil error from fn stops the scan
+// and is returned.
+func ScanDBFFile(companyName, fileName string, fn func(columns []string, row []interface{}) error) error {
+	filePath, err := resolveDBFPath(companyName, fileName)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		return fmt.Errorf("DBF file does not exist: %s", fileName)
+	}
+
+	table, err := dbase.OpenTable(&dbase.Config{
+		Filename:   filePath,
+		TrimSpaces: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open DBF file: %w", err)
+	}
+	defer table.Close()
+
+	var columns []string
+	for _, column := range table.Columns() {
+		columns = append(columns, column.Name())
+	}
+
+	rowData := make([]interface{}, len(columns))
+	for !table.EOF() {
+		row, err := table.Next()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", fileName, err)
+		}
+		if row.Deleted {
+			continue
+		}
+		for i, column := range table.Columns() {
+			if field := row.FieldByName(column.Name()); field != nil {
+				rowData[i] = field.GetValue()
+			} else {
+				rowData[i] = ""
+			}
+		}
+		if err := fn(columns, rowData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // UpdateDBFRecord updates a specific cell in a DBF file
 // Note: This is a simplified implementation that shows the update concept
 // For production use, you'd need more robust DBF editing capabilities
@@ -840,6 +910,85 @@ func UpdateDBFRecord(companyName, fileName string, rowIndex, colIndex int, value
 		rowIndex, colIndex, value, fileName, companyName)
 }
 
+// UpdateDBFRowsByKey writes field values to every active row whose keyColumn value
+// matches one of the keys in updates. Each entry maps a key value (e.g. a CIDCHEC)
+// to the column values to set on that row. The rows are written through VFP in one
+// transaction so the table's indexes stay current. Returns the number of rows written.
+func UpdateDBFRowsByKey(companyName, fileName, keyColumn string, updates map[string]map[string]interface{}) (int, error) {
+	batch := NewDBFBatch(companyName)
+	updated, err := batch.Update(fileName, keyColumn, updates)
+	if err != nil {
+		return 0, err
+	}
+	if err := batch.Commit(); err != nil {
+		return 0, err
+	}
+
+	writeErrorLog(fmt.Sprintf("UpdateDBFRowsByKey: Updated %d rows in %s by %s", updated, fileName, keyColumn))
+	return updated, nil
+}
+
+// DeleteDBFRowsByKey marks every active row whose keyColumn value is one of keys
+// as deleted with VFP's DELETE, so the indexes stay current. Returns the number
+// of rows deleted.
+func DeleteDBFRowsByKey(companyName, fileName, keyColumn string, keys []string) (int, error) {
+	batch := NewDBFBatch(companyName)
+	deleted, err := batch.Delete(fileName, keyColumn, keys)
+	if err != nil {
+		return 0, err
+	}
+	if err := batch.Commit(); err != nil {
+		return 0, err
+	}
+
+	writeErrorLog(fmt.Sprintf("DeleteDBFRowsByKey: Deleted %d rows in %s by %s", deleted, fileName, keyColumn))
+	return deleted, nil
+}
+
+// DBFColumnWidths returns the width of each column in a DBF file keyed by
+// upper-case column name
+func DBFColumnWidths(companyName, fileName string) (map[string]int, error) {
+	filePath, err := resolveDBFPath(companyName, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, fmt.Errorf("DBF file does not exist: %s", fileName)
+	}
+
+	table, err := dbase.OpenTable(&dbase.Config{
+		Filename:   filePath,
+		TrimSpaces: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DBF file: %w", err)
+	}
+	defer table.Close()
+
+	widths := make(map[string]int, len(table.Columns()))
+	for _, column := range table.Columns() {
+		widths[strings.ToUpper(column.Name())] = int(column.Length)
+	}
+	return widths, nil
+}
+
+// AppendDBFRows appends new records to a DBF file through VFP so its indexes
+// stay current. Each map is keyed by column name; columns that are not given are
+// written blank. Returns the number of rows added.
+func AppendDBFRows(companyName, fileName string, records []map[string]interface{}) (int, error) {
+	batch := NewDBFBatch(companyName)
+	if err := batch.Append(fileName, records); err != nil {
+		return 0, err
+	}
+	added := batch.Len()
+	if err := batch.Commit(); err != nil {
+		return 0, err
+	}
+
+	writeErrorLog(fmt.Sprintf("AppendDBFRows: Added %d rows to %s", added, fileName))
+	return added, nil
+}
+
 // GetDashboardData returns lightweight dashboard data with well types
 func GetDashboardData(companyName string) (map[string]interface{}, error) {
 	fmt.Printf("Getting dashboard data for company: %s\n", companyName)
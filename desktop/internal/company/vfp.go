@@ -0,0 +1,503 @@
+package company
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Valentin-Kaiser/go-dbase/dbase"
+	"github.com/pivoten/financialsx/desktop/internal/ole"
+)
+
+// DBFBatch collects changes to a company's DBF files and runs them as VFP
+// commands in one transaction through the Pivoten.DbApi OLE server. Writing
+// through VFP keeps the tables' .CDX indexes current, which go-dbase cannot do,
+// so every change to legacy data goes through a batch.
+type DBFBatch struct {
+	companyName string
+	commands    []string
+	keyed       []*keyedAppend
+	layouts     map[string]map[string]*dbase.Column
+}
+
+// keyedAppend is an Append whose key columns are numbered when the batch commits
+type keyedAppend struct {
+	fileName string
+	keys     []string
+	build    func(next map[string]int64) ([]map[string]interface{}, error)
+}
+
+// keyLockSeconds is how long a commit waits for another user's lock on a table
+// whose keys it is numbering
+const keyLockSeconds = 30
+
+// NewDBFBatch starts an empty batch of changes for a company
+func NewDBFBatch(companyName string) *DBFBatch {
+	return &DBFBatch{companyName: companyName, layouts: make(map[string]map[string]*dbase.Column)}
+}
+
+// Len returns the number of commands queued, not counting keyed appends
+func (b *DBFBatch) Len() int {
+	return len(b.commands)
+}
+
+// Update queues field values for every active row whose keyColumn value matches
+// one of the keys in updates, and returns the number of rows that currently
+// match. Every column is checked against the file before anything is queued.
+func (b *DBFBatch) Update(fileName, keyColumn string, updates map[string]map[string]interface{}) (int, error) {
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	columns, err := b.layout(fileName)
+	if err != nil {
+		return 0, err
+	}
+	keyColumn = strings.ToUpper(keyColumn)
+	if _, ok := columns[keyColumn]; !ok {
+		return 0, fmt.Errorf("column %s not found in %s", keyColumn, fileName)
+	}
+
+	keys := make([]string, 0, len(updates))
+	for key := range updates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var commands []string
+	for _, key := range keys {
+		assignments, err := vfpAssignments(fileName, columns, updates[key])
+		if err != nil {
+			return 0, err
+		}
+		if assignments == "" {
+			continue
+		}
+		commands = append(commands, fmt.Sprintf("UPDATE %s SET %s WHERE %s",
+			vfpTableName(fileName), assignments, vfpKeyMatch(keyColumn, key)))
+	}
+
+	matched, err := countDBFRowsByKey(b.companyName, fileName, keyColumn, keys)
+	if err != nil {
+		return 0, err
+	}
+	b.commands = append(b.commands, commands...)
+	return matched, nil
+}
+
+// Delete queues the deletion of every active row whose keyColumn value is one of
+// keys, and returns the number of rows that currently match
+func (b *DBFBatch) Delete(fileName, keyColumn string, keys []string) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	columns, err := b.layout(fileName)
+	if err != nil {
+		return 0, err
+	}
+	keyColumn = strings.ToUpper(keyColumn)
+	if _, ok := columns[keyColumn]; !ok {
+		return 0, fmt.Errorf("column %s not found in %s", keyColumn, fileName)
+	}
+
+	sorted := append([]string{}, keys...)
+	sort.Strings(sorted)
+	commands := make([]string, 0, len(sorted))
+	for _, key := range sorted {
+		commands = append(commands, fmt.Sprintf("DELETE FROM %s WHERE %s", vfpTableName(fileName), vfpKeyMatch(keyColumn, key)))
+	}
+
+	matched, err := countDBFRowsByKey(b.companyName, fileName, keyColumn, sorted)
+	if err != nil {
+		return 0, err
+	}
+	b.commands = append(b.commands, commands...)
+	return matched, nil
+}
+
+// Append queues one new row per record. Columns a record does not set are left
+// blank. Every column is checked against the file before anything is queued.
+func (b *DBFBatch) Append(fileName string, records []map[string]interface{}) error {
+	if len(records) == 0 {
+		return nil
+	}
+	columns, err := b.layout(fileName)
+	if err != nil {
+		return err
+	}
+	commands, err := vfpInserts(fileName, columns, records)
+	if err != nil {
+		return err
+	}
+	b.commands = append(b.commands, commands...)
+	return nil
+}
+
+// AppendKeyed queues rows whose key columns hold zero-padded numbers, such as
+// GLMASTER.CIDGLMA and CBATCH, numbered when the batch commits. The table is
+// locked inside the transaction, the highest value of each key column is read,
+// and build is called with the next free value of each, so two writers cannot
+// take the same keys. Keyed rows are written after the batch's other commands,
+// and a key wider than its column is refused rather than truncated.
+func (b *DBFBatch) AppendKeyed(fileName string, keyColumns []string, build func(next map[string]int64) ([]map[string]interface{}, error)) error {
+	columns, err := b.layout(fileName)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(keyColumns))
+	for _, key := range keyColumns {
+		key = strings.ToUpper(key)
+		if _, ok := columns[key]; !ok {
+			return fmt.Errorf("column %s not found in %s", key, fileName)
+		}
+		keys = append(keys, key)
+	}
+	b.keyed = append(b.keyed, &keyedAppend{fileName: fileName, keys: keys, build: build})
+	return nil
+}
+
+// keyedInserts locks the table, reads its highest keys and builds the rows
+func (b *DBFBatch) keyedInserts(client *ole.DbApiClient, k *keyedAppend) ([]string, error) {
+	table := vfpTableName(k.fileName)
+	if err := client.LockTable(table, keyLockSeconds); err != nil {
+		return nil, err
+	}
+
+	maxima := make([]string, 0, len(k.keys))
+	for _, key := range k.keys {
+		maxima = append(maxima, fmt.Sprintf("NVL(MAX(VAL(%s)), 0) AS %s", key, key))
+	}
+	result, err := client.QueryToJson(fmt.Sprintf("SELECT %s FROM %s", strings.Join(maxima, ", "), table))
+	if err != nil {
+		return nil, err
+	}
+	var parsed struct {
+		Success bool                `json:"success"`
+		Error   string              `json:"error"`
+		Data    []map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(result), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to read the highest keys in %s: %w", k.fileName, err)
+	}
+	if !parsed.Success {
+		return nil, fmt.Errorf("failed to read the highest keys in %s: %s", k.fileName, parsed.Error)
+	}
+	next := make(map[string]int64, len(k.keys))
+	for _, key := range k.keys {
+		var max float64
+		if len(parsed.Data) > 0 {
+			if v := strings.TrimSpace(parsed.Data[0][key]); v != "" && v != ".NULL." {
+				if max, err = strconv.ParseFloat(strings.ReplaceAll(v, ",", ""), 64); err != nil {
+					return nil, fmt.Errorf("failed to read the highest %s in %s: %s", key, k.fileName, v)
+				}
+			}
+		}
+		next[key] = int64(max) + 1
+	}
+
+	records, err := k.build(next)
+	if err != nil {
+		return nil, err
+	}
+	columns := b.layouts[strings.ToUpper(k.fileName)]
+	for _, record := range records {
+		for _, key := range k.keys {
+			value := strings.TrimSpace(fmt.Sprintf("%v", lookupColumn(record, key)))
+			if width := int(columns[key].Length); len(value) > width {
+				return nil, fmt.Errorf("%s %s is wider than the %d characters of %s.%s", key, value, width, k.fileName, key)
+			}
+		}
+	}
+	return vfpInserts(k.fileName, columns, records)
+}
+
+// Commit runs the queued commands in one VFP transaction. Nothing is written if
+// any command fails.
+func (b *DBFBatch) Commit() error {
+	if len(b.commands) == 0 && len(b.keyed) == 0 {
+		return nil
+	}
+	filePath, err := resolveDBFPath(b.companyName, "appdata.dbc")
+	if err != nil {
+		return err
+	}
+
+	commands := b.commands
+	err = ole.ExecuteOnCOMThread(filepath.Dir(filePath), func(client *ole.DbApiClient) error {
+		var locked []string
+		defer func() {
+			for _, table := range locked {
+				client.UnlockTable(table)
+			}
+		}()
+
+		if err := client.ExecNonQuery("BEGIN TRANSACTION"); err != nil {
+			return err
+		}
+		for _, k := range b.keyed {
+			locked = append(locked, vfpTableName(k.fileName))
+			inserts, err := b.keyedInserts(client, k)
+			if err != nil {
+				client.ExecNonQuery("ROLLBACK")
+				return err
+			}
+			commands = append(commands, inserts...)
+		}
+		for _, command := range commands {
+			if err := client.ExecNonQuery(command); err != nil {
+				client.ExecNonQuery("ROLLBACK")
+				return err
+			}
+		}
+		if err := client.ExecNonQuery("END TRANSACTION"); err != nil {
+			client.ExecNonQuery("ROLLBACK")
+			return err
+		}
+		// Flush so the changes are on disk for the go-dbase readers
+		return client.ExecNonQuery("FLUSH")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write DBF changes through VFP: %w", err)
+	}
+
+	writeErrorLog(fmt.Sprintf("DBFBatch: Committed %d VFP commands for %s", len(commands), b.companyName))
+	b.commands, b.keyed = nil, nil
+	return nil
+}
+
+// layout returns a DBF's columns keyed by upper-case name
+func (b *DBFBatch) layout(fileName string) (map[string]*dbase.Column, error) {
+	key := strings.ToUpper(fileName)
+	if columns, ok := b.layouts[key]; ok {
+		return columns, nil
+	}
+
+	filePath, err := resolveDBFPath(b.companyName, fileName)
+	if err != nil {
+		return nil, err
+	}
+	table, err := dbase.OpenTable(&dbase.Config{
+		Filename:   filePath,
+		TrimSpaces: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DBF file: %w", err)
+	}
+	defer table.Close()
+
+	columns := make(map[string]*dbase.Column, len(table.Columns()))
+	for _, column := range table.Columns() {
+		columns[strings.ToUpper(column.Name())] = column
+	}
+	b.layouts[key] = columns
+	return columns, nil
+}
+
+// countDBFRowsByKey counts the active rows whose keyColumn value is one of keys
+func countDBFRowsByKey(companyName, fileName, keyColumn string, keys []string) (int, error) {
+	wanted := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		wanted[strings.TrimSpace(k)] = true
+	}
+	count := 0
+	keyIdx := -1
+	err := ScanDBFFile(companyName, fileName, func(columns []string, row []interface{}) error {
+		if keyIdx == -1 {
+			for i, c := range columns {
+				if strings.EqualFold(c, keyColumn) {
+					keyIdx = i
+				}
+			}
+		}
+		if keyIdx >= 0 && wanted[strings.TrimSpace(fmt.Sprintf("%v", row[keyIdx]))] {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// vfpInserts formats an INSERT for each record, checking every column
+func vfpInserts(fileName string, columns map[string]*dbase.Column, records []map[string]interface{}) ([]string, error) {
+	commands := make([]string, 0, len(records))
+	for _, values := range records {
+		names := make([]string, 0, len(values))
+		literals := make([]string, 0, len(values))
+		for _, name := range sortedColumnNames(values) {
+			column, ok := columns[name]
+			if !ok {
+				return nil, fmt.Errorf("column %s not found in %s", name, fileName)
+			}
+			literal, err := vfpLiteral(column, lookupColumn(values, name))
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", fileName, name, err)
+			}
+			names = append(names, name)
+			literals = append(literals, literal)
+		}
+		if len(names) == 0 {
+			continue
+		}
+		commands = append(commands, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+			vfpTableName(fileName), strings.Join(names, ", "), strings.Join(literals, ", ")))
+	}
+	return commands, nil
+}
+
+// sortedColumnNames returns the upper-case column names of values in order
+func sortedColumnNames(values map[string]interface{}) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, strings.ToUpper(name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupColumn returns the value for an upper-case column name, whatever case the caller used
+func lookupColumn(values map[string]interface{}, name string) interface{} {
+	if v, ok := values[name]; ok {
+		return v
+	}
+	for k, v := range values {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// vfpAssignments formats "COL = value, ..." for an UPDATE, in column order
+func vfpAssignments(fileName string, columns map[string]*dbase.Column, values map[string]interface{}) (string, error) {
+	names := sortedColumnNames(values)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		column, ok := columns[name]
+		if !ok {
+			return "", fmt.Errorf("column %s not found in %s", name, fileName)
+		}
+		literal, err := vfpLiteral(column, lookupColumn(values, name))
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %w", fileName, name, err)
+		}
+		parts = append(parts, name+" = "+literal)
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// vfpTableName is the DBC table name of a DBF file
+func vfpTableName(fileName string) string {
+	return strings.ToUpper(strings.TrimSuffix(fileName, filepath.Ext(fileName)))
+}
+
+// vfpKeyMatch matches the active rows whose trimmed key column equals key
+func vfpKeyMatch(keyColumn, key string) string {
+	return fmt.Sprintf("ALLTRIM(%s) == %s AND !DELETED()", keyColumn, vfpString(strings.TrimSpace(key)))
+}
+
+// vfpLiteral formats a Go value as a VFP literal for a column
+func vfpLiteral(column *dbase.Column, value interface{}) (string, error) {
+	switch dbase.DataType(column.DataType) {
+	case dbase.Character, dbase.Varchar, dbase.Memo:
+		switch v := value.(type) {
+		case nil:
+			return `""`, nil
+		case string:
+			return vfpString(v), nil
+		}
+		return vfpString(fmt.Sprintf("%v", value)), nil
+
+	case dbase.Numeric, dbase.Float, dbase.Double, dbase.Currency, dbase.Integer:
+		var f float64
+		switch v := value.(type) {
+		case nil:
+		case float64:
+			f = v
+		case float32:
+			f = float64(v)
+		case int:
+			f = float64(v)
+		case int32:
+			f = float64(v)
+		case int64:
+			f = float64(v)
+		default:
+			return "", fmt.Errorf("expected a number, got %T", value)
+		}
+		decimals := int(column.Decimals)
+		if dbase.DataType(column.DataType) == dbase.Integer {
+			decimals = 0
+		}
+		return strconv.FormatFloat(f, 'f', decimals, 64), nil
+
+	case dbase.Logical:
+		switch v := value.(type) {
+		case nil:
+			return ".F.", nil
+		case bool:
+			if v {
+				return ".T.", nil
+			}
+			return ".F.", nil
+		}
+		return "", fmt.Errorf("expected a logical, got %T", value)
+
+	case dbase.Date:
+		switch v := value.(type) {
+		case nil:
+			return "{}", nil
+		case time.Time:
+			if v.IsZero() {
+				return "{}", nil
+			}
+			return v.Format("{^2006-01-02}"), nil
+		}
+		return "", fmt.Errorf("expected a date, got %T", value)
+
+	case dbase.DateTime:
+		switch v := value.(type) {
+		case nil:
+			return "{/:}", nil
+		case time.Time:
+			if v.IsZero() {
+				return "{/:}", nil
+			}
+			return v.Format("{^2006-01-02 15:04:05}"), nil
+		}
+		return "", fmt.Errorf("expected a date and time, got %T", value)
+	}
+	return "", fmt.Errorf("column type %s cannot be written", column.Type())
+}
+
+// vfpString quotes a string for VFP. Quotes and control characters become
+// CHR() calls, and long text is split since VFP literals hold 255 characters.
+func vfpString(s string) string {
+	if s == "" {
+		return `""`
+	}
+	var parts []string
+	var chunk strings.Builder
+	flush := func() {
+		if chunk.Len() > 0 {
+			parts = append(parts, `"`+chunk.String()+`"`)
+			chunk.Reset()
+		}
+	}
+	for _, r := range s {
+		if r == '"' || r < 32 {
+			flush()
+			parts = append(parts, fmt.Sprintf("CHR(%d)", r))
+			continue
+		}
+		if chunk.Len()+utf8.RuneLen(r) > 250 {
+			flush()
+		}
+		chunk.WriteRune(r)
+	}
+	flush()
+	return strings.Join(parts, "+")
+}
@@ -0,0 +1,379 @@
+// Package consolidation keeps groups of companies that are reported together -
+// the companies, the standard chart they are restated on and the intercompany
+// elimination rules - and runs consolidated trial balances and financial
+// statements for them. Groups belong to the reporting company whose database
+// stores them; each member's ledger, calendar and account mappings are read
+// from its own files and database.
+package consolidation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/coa"
+	"github.com/pivoten/financialsx/desktop/internal/company"
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/financials"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Service provides consolidation operations
+type Service struct {
+	db         *database.DB
+	financials *financials.Service
+	charts     *coa.Service
+}
+
+// NewService creates a new consolidation service
+func NewService(db *database.DB) *Service {
+	return &Service{db: db, financials: financials.NewService(db), charts: coa.NewService(db)}
+}
+
+// Group is a set of companies reported as one
+type Group struct {
+	ID           int                      `json:"id"`
+	CompanyName  string                   `json:"company_name"` // Reporting company
+	Name         string                   `json:"name"`
+	Description  string                   `json:"description"`
+	Chart        string                   `json:"chart"` // Standard chart code; empty when the companies share account numbers
+	Companies    []string                 `json:"companies"`
+	Eliminations []financials.Elimination `json:"eliminations"`
+	CreatedBy    string                   `json:"created_by"`
+	CreatedAt    *time.Time               `json:"created_at,omitempty"`
+	UpdatedBy    string                   `json:"updated_by,omitempty"`
+	UpdatedAt    *time.Time               `json:"updated_at,omitempty"`
+}
+
+// MemberAccess is called with each member company other than the reporting
+// company, and refuses the group when the user may not read that company's ledger
+type MemberAccess func(companyName string) error
+
+// checkAccess runs access for every member other than the reporting company
+func (g *Group) checkAccess(access MemberAccess) error {
+	if access == nil {
+		return nil
+	}
+	for _, member := range g.Companies {
+		if member == g.CompanyName {
+			continue
+		}
+		if err := access(member); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// consolidation is the group as the financials service reports it
+func (g *Group) consolidation() financials.Consolidation {
+	return financials.Consolidation{Name: g.Name, Companies: g.Companies, Chart: g.Chart, Eliminations: g.Eliminations}
+}
+
+// GetGroups lists the reporting company's consolidation groups
+func (s *Service) GetGroups(companyName string) ([]Group, error) {
+	rows, err := s.db.Query(groupSelect+` WHERE company_name = ? ORDER BY name`, companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query consolidation groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []Group{}
+	for rows.Next() {
+		g, err := scanGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, *g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if groups[i].Eliminations, err = s.eliminations(groups[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+// GetGroup returns one consolidation group with its elimination rules
+func (s *Service) GetGroup(companyName string, id int) (*Group, error) {
+	g, err := scanGroup(s.db.QueryRow(groupSelect+` WHERE company_name = ? AND id = ?`, companyName, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("consolidation group %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if g.Eliminations, err = s.eliminations(g.ID); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SaveGroup creates a group or replaces an existing one's settings and
+// elimination rules. Names are unique within the reporting company.
+func (s *Service) SaveGroup(g *Group, username string, access MemberAccess) (*Group, error) {
+	if err := s.validate(g); err != nil {
+		return nil, err
+	}
+	if err := g.checkAccess(access); err != nil {
+		return nil, err
+	}
+	companiesJSON, _ := json.Marshal(g.Companies)
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if g.ID > 0 {
+		result, err := tx.Exec(`
+			UPDATE consolidation_groups SET name = ?, description = ?, chart_code = ?, companies_json = ?,
+				updated_by = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND company_name = ?
+		`, g.Name, g.Description, g.Chart, string(companiesJSON), username, g.ID, g.CompanyName)
+		if err != nil {
+			return nil, groupError(g, err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return nil, fmt.Errorf("consolidation group %d not found", g.ID)
+		}
+		if _, err := tx.Exec(`DELETE FROM consolidation_eliminations WHERE group_id = ?`, g.ID); err != nil {
+			return nil, fmt.Errorf("failed to replace elimination rules: %w", err)
+		}
+	} else {
+		result, err := tx.Exec(`
+			INSERT INTO consolidation_groups (company_name, name, description, chart_code, companies_json, created_by, updated_by)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, g.CompanyName, g.Name, g.Description, g.Chart, string(companiesJSON), username, username)
+		if err != nil {
+			return nil, groupError(g, err)
+		}
+		id, _ := result.LastInsertId()
+		g.ID = int(id)
+	}
+
+	for i, e := range g.Eliminations {
+		accountsJSON, _ := json.Marshal(e.Accounts)
+		var scope interface{}
+		if len(e.Companies) > 0 {
+			raw, _ := json.Marshal(e.Companies)
+			scope = string(raw)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO consolidation_eliminations (group_id, name, accounts_json, companies_json, offset_account, sort_order)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, g.ID, e.Name, string(accountsJSON), scope, e.OffsetAccount, i); err != nil {
+			return nil, fmt.Errorf("failed to save elimination rule %s: %w", e.Name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save consolidation group: %w", err)
+	}
+	return s.GetGroup(g.CompanyName, g.ID)
+}
+
+func groupError(g *Group, err error) error {
+	if strings.Contains(err.Error(), "UNIQUE") {
+		return fmt.Errorf("a consolidation group named %s already exists", g.Name)
+	}
+	return fmt.Errorf("failed to save consolidation group: %w", err)
+}
+
+// DeleteGroup removes a group and its elimination rules
+func (s *Service) DeleteGroup(companyName string, id int) error {
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM consolidation_groups WHERE id = ? AND company_name = ?`, id, companyName)
+	if err != nil {
+		return fmt.Errorf("failed to delete consolidation group: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("consolidation group %d not found", id)
+	}
+	if _, err := tx.Exec(`DELETE FROM consolidation_eliminations WHERE group_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete elimination rules: %w", err)
+	}
+	return tx.Commit()
+}
+
+// TrialBalance runs the group's consolidated trial balance
+func (s *Service) TrialBalance(companyName string, groupID int, req financials.TrialBalanceRequest, access MemberAccess) (*financials.TrialBalance, error) {
+	g, err := s.GetGroup(companyName, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.checkAccess(access); err != nil {
+		return nil, err
+	}
+	return s.financials.ConsolidatedTrialBalance(companyName, g.consolidation(), req)
+}
+
+// Statement runs the group's consolidated balance sheet or income statement
+func (s *Service) Statement(companyName string, groupID int, req financials.StatementRequest, access MemberAccess) (*financials.Statement, error) {
+	g, err := s.GetGroup(companyName, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.checkAccess(access); err != nil {
+		return nil, err
+	}
+	return s.financials.ConsolidatedStatement(companyName, g.consolidation(), req)
+}
+
+// validate cleans up a group and checks its companies, chart and rules
+func (s *Service) validate(g *Group) error {
+	g.Name = strings.TrimSpace(g.Name)
+	g.Chart = strings.ToLower(strings.TrimSpace(g.Chart))
+	if g.Name == "" {
+		return fmt.Errorf("group name is required")
+	}
+
+	members := make(map[string]bool)
+	var companies []string
+	for _, name := range g.Companies {
+		name = strings.TrimSpace(name)
+		if name == "" || members[name] {
+			continue
+		}
+		if _, err := company.StatDBFFile(name, "GLMASTER.dbf"); err != nil {
+			return fmt.Errorf("company %s has no general ledger: %w", name, err)
+		}
+		members[name] = true
+		companies = append(companies, name)
+	}
+	if len(companies) < 2 {
+		return fmt.Errorf("a consolidation group needs at least two companies")
+	}
+	g.Companies = companies
+
+	if g.Chart != "" {
+		if _, err := s.charts.GetStandardChart(g.CompanyName, g.Chart); err != nil {
+			return err
+		}
+	}
+
+	names := make(map[string]bool)
+	for i := range g.Eliminations {
+		e := &g.Eliminations[i]
+		e.Name = strings.TrimSpace(e.Name)
+		e.OffsetAccount = strings.TrimSpace(e.OffsetAccount)
+		if e.Name == "" {
+			return fmt.Errorf("elimination rule %d needs a name", i+1)
+		}
+		if names[strings.ToLower(e.Name)] {
+			return fmt.Errorf("there is more than one elimination rule named %s", e.Name)
+		}
+		names[strings.ToLower(e.Name)] = true
+
+		e.Accounts = cleanList(e.Accounts)
+		if len(e.Accounts) == 0 {
+			return fmt.Errorf("elimination rule %s needs at least one account", e.Name)
+		}
+		for _, account := range e.Accounts {
+			if account == e.OffsetAccount {
+				return fmt.Errorf("elimination rule %s cannot offset to an account it eliminates (%s)", e.Name, account)
+			}
+		}
+		e.Companies = cleanList(e.Companies)
+		for _, name := range e.Companies {
+			if !members[name] {
+				return fmt.Errorf("elimination rule %s covers %s, which is not in the group", e.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// cleanList trims and de-duplicates a list, dropping blanks
+func cleanList(values []string) []string {
+	seen := make(map[string]bool)
+	out := []string{}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// eliminations loads a group's rules in order
+func (s *Service) eliminations(groupID int) ([]financials.Elimination, error) {
+	rows, err := s.db.Query(`
+		SELECT name, accounts_json, COALESCE(companies_json, ''), COALESCE(offset_account, '')
+		FROM consolidation_eliminations
+		WHERE group_id = ?
+		ORDER BY sort_order, id`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query elimination rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []financials.Elimination{}
+	for rows.Next() {
+		var e financials.Elimination
+		var accountsJSON, companiesJSON string
+		if err := rows.Scan(&e.Name, &accountsJSON, &companiesJSON, &e.OffsetAccount); err != nil {
+			return nil, fmt.Errorf("failed to scan elimination rule: %w", err)
+		}
+		if err := json.Unmarshal([]byte(accountsJSON), &e.Accounts); err != nil {
+			return nil, fmt.Errorf("elimination rule %s has invalid accounts: %w", e.Name, err)
+		}
+		if companiesJSON != "" {
+			if err := json.Unmarshal([]byte(companiesJSON), &e.Companies); err != nil {
+				return nil, fmt.Errorf("elimination rule %s has invalid companies: %w", e.Name, err)
+			}
+		}
+		rules = append(rules, e)
+	}
+	return rules, rows.Err()
+}
+
+const groupSelect = `
+	SELECT id, company_name, name, COALESCE(description, ''), chart_code, companies_json,
+	       COALESCE(created_by, ''), created_at, COALESCE(updated_by, ''), updated_at
+	FROM consolidation_groups`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanGroup(row rowScanner) (*Group, error) {
+	var g Group
+	var companiesJSON string
+	var createdAt, updatedAt interface{}
+	if err := row.Scan(&g.ID, &g.CompanyName, &g.Name, &g.Description, &g.Chart, &companiesJSON,
+		&g.CreatedBy, &createdAt, &g.UpdatedBy, &updatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan consolidation group: %w", err)
+	}
+	if err := json.Unmarshal([]byte(companiesJSON), &g.Companies); err != nil {
+		return nil, fmt.Errorf("consolidation group %s has invalid companies: %w", g.Name, err)
+	}
+	g.CreatedAt = timestamp(createdAt)
+	g.UpdatedAt = timestamp(updatedAt)
+	return &g, nil
+}
+
+func timestamp(v interface{}) *time.Time {
+	if t, ok := v.(time.Time); ok && !t.IsZero() {
+		return &t
+	}
+	if t, ok := ledger.AsDate(v); ok {
+		return &t
+	}
+	return nil
+}
@@ -0,0 +1,481 @@
+// Package dashboard computes the money figures on the company dashboard -
+// revenue, expenses, net income, cash and the top wells by revenue - over a
+// rolling twelve fiscal periods, from GLMASTER or from the revenue and expense
+// subledgers. Results are cached until the DBF files they came from change.
+package dashboard
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/company"
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/periods"
+)
+
+// Where revenue and expenses come from. Cash always comes from the GL bank
+// accounts.
+const (
+	SourceGL        = "glmaster"  // Revenue and expense accounts in GLMASTER.dbf
+	SourceSubledger = "subledger" // INCOME.dbf and EXPENSE.dbf
+)
+
+// RollingPeriods is the number of fiscal periods the dashboard covers
+const RollingPeriods = 12
+
+// DefaultTopWells is the number of wells ranked when the request does not say
+const DefaultTopWells = 5
+
+// Service computes and caches dashboard figures
+type Service struct {
+	db      *database.DB
+	periods *periods.Service
+}
+
+// NewService creates a new dashboard service
+func NewService(db *database.DB) *Service {
+	return &Service{db: db, periods: periods.NewService(db)}
+}
+
+// Request selects the dashboard figures
+type Request struct {
+	Source   string        `json:"source"`
+	AsOf     ledger.Period `json:"as_of"` // Last period shown; zero for the current period
+	TopWells int           `json:"top_wells"`
+	Refresh  bool          `json:"refresh"` // Recompute even when the cache is current
+}
+
+// Month is one fiscal period of the rolling window
+type Month struct {
+	Period    string    `json:"period"`
+	Label     string    `json:"label"`
+	EndDate   time.Time `json:"end_date"`
+	Revenue   float64   `json:"revenue"`
+	Expenses  float64   `json:"expenses"`
+	NetIncome float64   `json:"net_income"`
+	Cash      float64   `json:"cash"` // Bank account balances at the end of the period
+}
+
+// WellRevenue is a well's revenue and expenses over the window
+type WellRevenue struct {
+	WellID   string  `json:"well_id"`
+	Name     string  `json:"name"`
+	Revenue  float64 `json:"revenue"`
+	Expenses float64 `json:"expenses"`
+	Net      float64 `json:"net"`
+	Share    float64 `json:"share"` // Percent of total revenue
+}
+
+// KPIs are the dashboard figures for one company and window
+type KPIs struct {
+	CompanyName  string        `json:"company_name"`
+	Source       string        `json:"source"`
+	From         string        `json:"from"`
+	To           string        `json:"to"`
+	Months       []Month       `json:"months"`
+	Revenue      float64       `json:"revenue"`
+	Expenses     float64       `json:"expenses"`
+	NetIncome    float64       `json:"net_income"`
+	Cash         float64       `json:"cash"`
+	CashChange   float64       `json:"cash_change"` // Cash at the end of the window less cash before it
+	BankAccounts int           `json:"bank_accounts"`
+	TopWells     []WellRevenue `json:"top_wells"`
+	Warnings     []string      `json:"warnings"`
+	BuiltAt      time.Time     `json:"built_at"`
+	Cached       bool          `json:"cached"`
+}
+
+// window is the rolling range of periods with a slot for each
+type window struct {
+	from, to ledger.Period
+	periods  []ledger.Period
+	slot     map[int]int // period index -> position in periods
+}
+
+func newWindow(cal *ledger.Calendar, to ledger.Period) window {
+	w := window{to: to, slot: make(map[int]int, RollingPeriods)}
+	w.from = to.Add(-(RollingPeriods - 1), cal.PeriodCount())
+	for i := 0; i < RollingPeriods; i++ {
+		p := w.from.Add(i, cal.PeriodCount())
+		w.periods = append(w.periods, p)
+		w.slot[p.Index()] = i
+	}
+	return w
+}
+
+// wellTotals accumulates one well's figures
+type wellTotals struct {
+	revenue  currency.Currency
+	expenses currency.Currency
+}
+
+// totals accumulates the figures for the window
+type totals struct {
+	revenue     []currency.Currency
+	expenses    []currency.Currency
+	cashChange  []currency.Currency
+	openingCash currency.Currency
+	wells       map[string]*wellTotals
+	unplaced    int // Revenue or expense records with no period
+}
+
+func newTotals() *totals {
+	t := &totals{
+		revenue:     make([]currency.Currency, RollingPeriods),
+		expenses:    make([]currency.Currency, RollingPeriods),
+		cashChange:  make([]currency.Currency, RollingPeriods),
+		openingCash: currency.Zero(),
+		wells:       make(map[string]*wellTotals),
+	}
+	for i := 0; i < RollingPeriods; i++ {
+		t.revenue[i] = currency.Zero()
+		t.expenses[i] = currency.Zero()
+		t.cashChange[i] = currency.Zero()
+	}
+	return t
+}
+
+func (t *totals) well(wellID string) *wellTotals {
+	w := t.wells[wellID]
+	if w == nil {
+		w = &wellTotals{revenue: currency.Zero(), expenses: currency.Zero()}
+		t.wells[wellID] = w
+	}
+	return w
+}
+
+func (t *totals) addRevenue(slot int, wellID string, amount currency.Currency) {
+	t.revenue[slot] = t.revenue[slot].Add(amount)
+	if wellID != "" {
+		w := t.well(wellID)
+		w.revenue = w.revenue.Add(amount)
+	}
+}
+
+func (t *totals) addExpense(slot int, wellID string, amount currency.Currency) {
+	t.expenses[slot] = t.expenses[slot].Add(amount)
+	if wellID != "" {
+		w := t.well(wellID)
+		w.expenses = w.expenses.Add(amount)
+	}
+}
+
+// GetKPIs returns the dashboard figures, from the cache when none of the files
+// they are computed from have changed since they were built
+func (s *Service) GetKPIs(companyName string, req Request) (*KPIs, error) {
+	switch req.Source {
+	case "":
+		req.Source = SourceGL
+	case SourceGL, SourceSubledger:
+	default:
+		return nil, fmt.Errorf("unknown source: %s", req.Source)
+	}
+	if req.TopWells <= 0 {
+		req.TopWells = DefaultTopWells
+	}
+
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	if req.AsOf.IsZero() {
+		req.AsOf = cal.PeriodForDate(time.Now())
+	}
+	if req.AsOf.Period < 1 || req.AsOf.Period > cal.PeriodCount() {
+		return nil, fmt.Errorf("invalid period: %s", req.AsOf)
+	}
+
+	key := fmt.Sprintf("%s|%s|%d", req.Source, req.AsOf, req.TopWells)
+	signature := sourceSignature(companyName, req.Source, cal)
+	if !req.Refresh {
+		if cached, err := s.cached(companyName, key, signature); err == nil && cached != nil {
+			return cached, nil
+		}
+	}
+
+	k, err := s.compute(companyName, req, cal)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store(companyName, key, signature, k); err != nil {
+		k.Warnings = append(k.Warnings, fmt.Sprintf("Dashboard figures could not be cached: %v", err))
+	}
+	return k, nil
+}
+
+// Invalidate drops a company's cached dashboard figures
+func (s *Service) Invalidate(companyName string) error {
+	_, err := s.db.Exec(`DELETE FROM dashboard_kpi_cache WHERE company_name = ?`, companyName)
+	return err
+}
+
+// compute builds the figures from the DBF files
+func (s *Service) compute(companyName string, req Request, cal *ledger.Calendar) (*KPIs, error) {
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, err
+	}
+	accountMap := ledger.AccountMap(accounts)
+	bankAccounts := 0
+	for _, a := range accounts {
+		if a.IsBank {
+			bankAccounts++
+		}
+	}
+
+	w := newWindow(cal, req.AsOf)
+	t := newTotals()
+	fromGL := req.Source == SourceGL
+
+	// One pass over GLMASTER for cash and, from the GL, revenue and expenses
+	err = ledger.ScanGLEntries(companyName, func(e ledger.GLEntry) error {
+		account, ok := accountMap[e.AccountNo]
+		if !ok {
+			return nil
+		}
+		p, placed := e.FiscalPeriodIn(cal)
+		slot, inWindow := w.slot[p.Index()]
+
+		if account.IsBank {
+			switch {
+			case !placed || p.Before(w.from):
+				t.openingCash = t.openingCash.Add(e.Net())
+			case inWindow:
+				t.cashChange[slot] = t.cashChange[slot].Add(e.Net())
+			}
+			return nil
+		}
+		if !fromGL || e.IsClosingEntry() || account.Type < ledger.TypeRevenue {
+			return nil
+		}
+		if !placed {
+			t.unplaced++
+			return nil
+		}
+		if !inWindow {
+			return nil
+		}
+		if account.Type == ledger.TypeRevenue {
+			t.addRevenue(slot, e.UnitNo, e.Net().Neg())
+		} else {
+			t.addExpense(slot, e.UnitNo, e.Net())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !fromGL {
+		if err := s.addSubledgers(companyName, cal, w, t); err != nil {
+			return nil, err
+		}
+	}
+
+	k := &KPIs{
+		CompanyName:  companyName,
+		Source:       req.Source,
+		From:         w.from.String(),
+		To:           w.to.String(),
+		Months:       make([]Month, 0, RollingPeriods),
+		BankAccounts: bankAccounts,
+		TopWells:     []WellRevenue{},
+		Warnings:     []string{},
+		BuiltAt:      time.Now(),
+	}
+
+	revenue, expenses := currency.Zero(), currency.Zero()
+	cash := t.openingCash
+	for i, p := range w.periods {
+		cash = cash.Add(t.cashChange[i])
+		_, end := cal.PeriodDates(p)
+		label := end.Format("Jan 2006")
+		if p.Period > ledger.DefaultPeriodsPerYear {
+			label = p.String()
+		}
+		k.Months = append(k.Months, Month{
+			Period:    p.String(),
+			Label:     label,
+			EndDate:   end,
+			Revenue:   t.revenue[i].ToFloat64(),
+			Expenses:  t.expenses[i].ToFloat64(),
+			NetIncome: t.revenue[i].Sub(t.expenses[i]).ToFloat64(),
+			Cash:      cash.ToFloat64(),
+		})
+		revenue = revenue.Add(t.revenue[i])
+		expenses = expenses.Add(t.expenses[i])
+	}
+	k.Revenue = revenue.ToFloat64()
+	k.Expenses = expenses.ToFloat64()
+	k.NetIncome = revenue.Sub(expenses).ToFloat64()
+	k.Cash = cash.ToFloat64()
+	k.CashChange = cash.Sub(t.openingCash).ToFloat64()
+
+	k.TopWells = topWells(companyName, t.wells, revenue, req.TopWells, &k.Warnings)
+
+	if bankAccounts == 0 {
+		k.Warnings = append(k.Warnings, "No COA accounts are flagged as bank accounts, so cash is zero")
+	}
+	if t.unplaced > 0 {
+		k.Warnings = append(k.Warnings, fmt.Sprintf("%d revenue or expense record(s) have no period or date and were left out", t.unplaced))
+	}
+	return k, nil
+}
+
+// addSubledgers totals INCOME.dbf revenue and EXPENSE.dbf expenses by the
+// period of their accounting date
+func (s *Service) addSubledgers(companyName string, cal *ledger.Calendar, w window, t *totals) error {
+	income, err := ledger.LoadIncome(companyName)
+	if err != nil {
+		return err
+	}
+	for _, r := range income {
+		p, ok := subledgerPeriod(cal, r.Year, r.Period, r.AcctDate, r.ProdDate)
+		if !ok {
+			t.unplaced++
+			continue
+		}
+		if slot, in := w.slot[p.Index()]; in {
+			t.addRevenue(slot, r.WellID, currency.NewFromFloat(r.Amount))
+		}
+	}
+
+	expenses, err := ledger.LoadExpenses(companyName)
+	if err != nil {
+		return err
+	}
+	for _, r := range expenses {
+		p, ok := subledgerPeriod(cal, r.Year, r.Period, r.AcctDate, r.Date)
+		if !ok {
+			t.unplaced++
+			continue
+		}
+		if slot, in := w.slot[p.Index()]; in {
+			t.addExpense(slot, r.WellID, currency.NewFromFloat(r.Amount))
+		}
+	}
+	return nil
+}
+
+// subledgerPeriod places a subledger record by its accounting year and period,
+// or else by the first date it has
+func subledgerPeriod(cal *ledger.Calendar, year, period string, dates ...time.Time) (ledger.Period, bool) {
+	if p, ok := ledger.ParsePeriod(year, period); ok {
+		return p, true
+	}
+	for _, d := range dates {
+		if !d.IsZero() {
+			return cal.PeriodForDate(d), true
+		}
+	}
+	return ledger.Period{}, false
+}
+
+// topWells ranks wells by revenue over the window
+func topWells(companyName string, wells map[string]*wellTotals, totalRevenue currency.Currency, limit int, warnings *[]string) []WellRevenue {
+	names := map[string]string{}
+	if len(wells) > 0 {
+		if list, err := ledger.LoadWells(companyName); err == nil {
+			names = ledger.WellNames(list)
+		} else {
+			*warnings = append(*warnings, "Well names are unavailable: "+err.Error())
+		}
+	}
+
+	ranked := make([]WellRevenue, 0, len(wells))
+	for id, w := range wells {
+		if !w.revenue.IsPositive() {
+			continue
+		}
+		share := 0.0
+		if totalRevenue.IsPositive() {
+			share = math.Round(w.revenue.ToFloat64()/totalRevenue.ToFloat64()*10000) / 100
+		}
+		ranked = append(ranked, WellRevenue{
+			WellID:   id,
+			Name:     names[id],
+			Revenue:  w.revenue.ToFloat64(),
+			Expenses: w.expenses.ToFloat64(),
+			Net:      w.revenue.Sub(w.expenses).ToFloat64(),
+			Share:    share,
+		})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Revenue != ranked[j].Revenue {
+			return ranked[i].Revenue > ranked[j].Revenue
+		}
+		return ranked[i].WellID < ranked[j].WellID
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// sourceSignature fingerprints the files and calendar the figures depend on, so
+// a change to any of them invalidates the cache
+func sourceSignature(companyName, source string, cal *ledger.Calendar) string {
+	files := []string{"COA.dbf", "GLMASTER.dbf", "WELLS.dbf"}
+	if source == SourceSubledger {
+		files = append(files, "INCOME.dbf", "EXPENSE.dbf")
+	}
+	parts := make([]string, 0, len(files)+1)
+	for _, f := range files {
+		if info, err := company.StatDBFFile(companyName, f); err == nil {
+			parts = append(parts, fmt.Sprintf("%s:%d:%d", f, info.Size(), info.ModTime().UnixNano()))
+		} else {
+			parts = append(parts, f+":-")
+		}
+	}
+	calJSON, _ := json.Marshal(cal)
+	parts = append(parts, string(calJSON))
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// cached returns stored figures built from the same files, or nil
+func (s *Service) cached(companyName, key, signature string) (*KPIs, error) {
+	var payload string
+	err := s.db.QueryRow(`
+		SELECT payload FROM dashboard_kpi_cache
+		WHERE company_name = ? AND cache_key = ? AND signature = ?
+	`, companyName, key, signature).Scan(&payload)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var k KPIs
+	if err := json.Unmarshal([]byte(payload), &k); err != nil {
+		return nil, err
+	}
+	k.Cached = true
+	return &k, nil
+}
+
+// store saves figures for reuse until their files change
+func (s *Service) store(companyName, key, signature string, k *KPIs) error {
+	payload, err := json.Marshal(k)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO dashboard_kpi_cache (company_name, cache_key, signature, payload, built_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(company_name, cache_key) DO UPDATE SET
+			signature = excluded.signature,
+			payload = excluded.payload,
+			built_at = CURRENT_TIMESTAMP
+	`, companyName, key, signature, string(payload))
+	return err
+}
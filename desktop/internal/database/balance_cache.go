@@ -4,12 +4,14 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/pivoten/financialsx/desktop/internal/company"
 	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
 )
 
 type CachedBalance struct {
@@ -18,6 +20,7 @@ type CachedBalance struct {
 	AccountNumber         string    `json:"account_number" db:"account_number"`
 	AccountName           string    `json:"account_name" db:"account_name"`
 	AccountType           int       `json:"account_type" db:"account_type"`
+	AccountTypeName       string    `json:"account_type_name"`
 	GLBalance             float64   `json:"gl_balance" db:"gl_balance"`
 	GLLastUpdated         time.Time `json:"gl_last_updated" db:"gl_last_updated"`
 	GLRecordCount         int       `json:"gl_record_count" db:"gl_record_count"`
@@ -66,7 +69,7 @@ type BalanceHistory struct {
 func InitializeBalanceCache(db *DB) error {
 	// Read and execute the schema SQL
 	schemaSQL := `
-	-- Bank Account Balance Caching System
+	-- Account Balance Caching System (every COA account; bank accounts also track outstanding checks)
 	CREATE TABLE IF NOT EXISTS account_balances (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		company_name TEXT NOT NULL,
@@ -81,7 +84,7 @@ func InitializeBalanceCache(db *DB) error {
 		outstanding_checks_last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		bank_balance DECIMAL(15,2) NOT NULL DEFAULT 0.00,
 		is_active BOOLEAN NOT NULL DEFAULT TRUE,
-		is_bank_account BOOLEAN NOT NULL DEFAULT TRUE,
+		is_bank_account BOOLEAN NOT NULL DEFAULT FALSE,
 		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		metadata TEXT DEFAULT '{}',
@@ -109,6 +112,7 @@ func InitializeBalanceCache(db *DB) error {
 	
 	CREATE INDEX IF NOT EXISTS idx_account_balances_company_account ON account_balances(company_name, account_number);
 	CREATE INDEX IF NOT EXISTS idx_account_balances_company_active ON account_balances(company_name, is_active, is_bank_account);
+	CREATE INDEX IF NOT EXISTS idx_account_balances_company_type ON account_balances(company_name, account_type);
 	CREATE INDEX IF NOT EXISTS idx_balance_history_account_timestamp ON balance_history(account_balance_id, change_timestamp);
 	
 	CREATE TRIGGER IF NOT EXISTS update_account_balances_timestamp 
@@ -153,8 +157,95 @@ func InitializeBalanceCache(db *DB) error {
 	WHERE ab.is_active = TRUE;
 	`
 	
-	_, err := db.Exec(schemaSQL)
-	return err
+	if _, err := db.Exec(schemaSQL); err != nil {
+		return err
+	}
+	
+	// Period-end and daily balances for as-of lookups
+	if _, err := db.Exec(periodBalanceSchema); err != nil {
+		return err
+	}
+	
+	return backfillBankAccountFlags(db)
+}
+
+// backfillBankAccountFlags sets is_bank_account from COA.LBANKACCT once. Tables
+// created before every COA account was cached defaulted the column to TRUE, so
+// rows written without it were all flagged as bank accounts. Companies whose COA
+// cannot be read are retried on the next start.
+func backfillBankAccountFlags(db *DB) error {
+	const migration = "account_balances_is_bank_account"
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			name TEXT PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return err
+	}
+	var applied int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE name = ?`, migration).Scan(&applied); err != nil {
+		return err
+	}
+	if applied > 0 {
+		return nil
+	}
+	
+	rows, err := db.Query(`SELECT DISTINCT company_name FROM account_balances`)
+	if err != nil {
+		return err
+	}
+	var companies []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		companies = append(companies, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	
+	bank := make(map[string]map[string]bool, len(companies))
+	for _, companyName := range companies {
+		accounts, err := ledger.LoadAccounts(companyName)
+		if err != nil {
+			fmt.Printf("backfillBankAccountFlags: Cannot read COA for %s, will retry: %v\n", companyName, err)
+			return nil
+		}
+		bank[companyName] = make(map[string]bool)
+		for _, a := range accounts {
+			if a.IsBank {
+				bank[companyName][a.AccountNo] = true
+			}
+		}
+	}
+	
+	tx, err := db.GetConn().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for companyName, accounts := range bank {
+		if _, err := tx.Exec(`UPDATE account_balances SET is_bank_account = FALSE WHERE company_name = ?`, companyName); err != nil {
+			return err
+		}
+		for accountNumber := range accounts {
+			if _, err := tx.Exec(`
+				UPDATE account_balances SET is_bank_account = TRUE
+				WHERE company_name = ? AND account_number = ?
+			`, companyName, accountNumber); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (name) VALUES (?)`, migration); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetCachedBalance retrieves the cached balance for an account
@@ -181,6 +272,7 @@ func GetCachedBalance(db *DB, companyName, accountNumber string) (*CachedBalance
 	if err != nil {
 		return nil, err
 	}
+	balance.AccountTypeName = ledger.AccountTypeName(balance.AccountType)
 	
 	// Parse metadata JSON to populate detailed breakdown fields
 	if balance.Metadata != "" {
@@ -204,15 +296,35 @@ func GetCachedBalance(db *DB, companyName, accountNumber string) (*CachedBalance
 	return &balance, nil
 }
 
-// GetAllCachedBalances retrieves all cached balances for a company
+// GetAllCachedBalances retrieves all cached bank account balances for a company
 func GetAllCachedBalances(db *DB, companyName string) ([]CachedBalance, error) {
-	query := `
+	return queryCachedBalances(db, `
 		SELECT * FROM account_balance_summary 
 		WHERE company_name = ? AND is_active = TRUE AND is_bank_account = TRUE
 		ORDER BY account_number
-	`
-	
-	rows, err := db.Query(query, companyName)
+	`, companyName)
+}
+
+// GetCachedBalancesByType retrieves the cached balances of every active account
+// of the given COA types, or of all types when none are given
+func GetCachedBalancesByType(db *DB, companyName string, accountTypes ...int) ([]CachedBalance, error) {
+	query := `
+		SELECT * FROM account_balance_summary 
+		WHERE company_name = ? AND is_active = TRUE`
+	args := []interface{}{companyName}
+	if len(accountTypes) > 0 {
+		query += ` AND account_type IN (?` + strings.Repeat(", ?", len(accountTypes)-1) + `)`
+		for _, t := range accountTypes {
+			args = append(args, t)
+		}
+	}
+	query += ` ORDER BY account_type, account_number`
+	return queryCachedBalances(db, query, args...)
+}
+
+// queryCachedBalances scans account_balance_summary rows
+func queryCachedBalances(db *DB, query string, args ...interface{}) ([]CachedBalance, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -232,6 +344,7 @@ func GetAllCachedBalances(db *DB, companyName string) ([]CachedBalance, error) {
 		if err != nil {
 			return nil, err
 		}
+		balance.AccountTypeName = ledger.AccountTypeName(balance.AccountType)
 		
 		// Parse metadata JSON to populate detailed breakdown fields
 		if balance.Metadata != "" {
@@ -255,201 +368,205 @@ func GetAllCachedBalances(db *DB, companyName string) ([]CachedBalance, error) {
 		balances = append(balances, balance)
 	}
 	
-	return balances, nil
+	return balances, rows.Err()
 }
 
-// RefreshGLBalance updates the GL balance by scanning GLMASTER.dbf
+// GLBalanceRefresh summarizes a single-pass refresh of cached GL balances
+type GLBalanceRefresh struct {
+	Records          int            `json:"records"`
+	Accounts         int            `json:"accounts"`
+	Changed          int            `json:"changed"`
+	ByType           map[string]int `json:"by_type"`
+	UnlistedAccounts []string       `json:"unlisted_accounts"` // Accounts in GLMASTER with no COA record
+	Duration         string         `json:"duration"`
+}
+
+// RefreshGLBalance updates the cached GL balance of one account
 func RefreshGLBalance(db *DB, companyName, accountNumber, username string) error {
-	fmt.Printf("RefreshGLBalance: Starting for account %s in company %s\n", accountNumber, companyName)
-	
-	// Get current cached balance
-	currentBalance, err := GetCachedBalance(db, companyName, accountNumber)
-	if currentBalance != nil {
-		fmt.Printf("RefreshGLBalance: Found existing balance - GL: %.2f, Outstanding: %.2f\n", 
-			currentBalance.GLBalance, currentBalance.OutstandingTotal)
-	} else {
-		fmt.Printf("RefreshGLBalance: No existing balance found, will create new\n")
+	_, err := refreshGLBalances(db, companyName, username, accountNumber)
+	return err
+}
+
+// RefreshAllGLBalances updates the cached GL balance of every COA account, plus any
+// account that has GLMASTER activity but no COA record, in one streaming pass over
+// GLMASTER. Each account is classified by its COA type and bank flag.
+func RefreshAllGLBalances(db *DB, companyName, username string) (*GLBalanceRefresh, error) {
+	return refreshGLBalances(db, companyName, username, "")
+}
+
+// refreshGLBalances totals GLMASTER by account and upserts account_balances,
+// limited to one account when only is set. Balances carry the account's normal
+// sign: debits minus credits for assets, expenses and other accounts, credits
+// minus debits for liabilities, equity and revenue.
+func refreshGLBalances(db *DB, companyName, username, only string) (*GLBalanceRefresh, error) {
+	started := time.Now()
+	fmt.Printf("RefreshGLBalances: Starting for company %s (account: %q)\n", companyName, only)
+	
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read COA.dbf: %w", err)
 	}
 	
-	// First, get the account type from COA.dbf
-	fmt.Printf("RefreshGLBalance: Reading COA.dbf to get account type...\n")
-	// Read ALL COA records to ensure we find the account
-	coaData, err := company.ReadDBFFile(companyName, "COA.dbf", "", 0, 0, "", "")
+	// IMPORTANT: every GLMASTER record is visited - never sample or limit here
+	result := &GLBalanceRefresh{ByType: make(map[string]int)}
+	totals := make(map[string]*activity)
+	err = ledger.ScanGLEntries(companyName, func(e ledger.GLEntry) error {
+		result.Records++
+		if e.AccountNo == "" || (only != "" && e.AccountNo != only) {
+			return nil
+		}
+		if totals[e.AccountNo] == nil {
+			totals[e.AccountNo] = &activity{}
+		}
+		totals[e.AccountNo].add(e)
+		return nil
+	})
 	if err != nil {
-		fmt.Printf("RefreshGLBalance: ERROR reading COA.dbf: %v\n", err)
-		return fmt.Errorf("failed to read COA.dbf: %w", err)
+		return nil, err
 	}
 	
-	// Get column indices for COA.dbf
-	coaColumns, ok := coaData["columns"].([]string)
-	if !ok {
-		return fmt.Errorf("invalid COA.dbf structure")
+	// Every COA account is cached, with or without activity
+	var targets []ledger.Account
+	listed := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		listed[account.AccountNo] = true
+		if only == "" || account.AccountNo == only {
+			targets = append(targets, account)
+		}
 	}
-	
-	var coaAccountIdx, accountTypeIdx int = -1, -1
-	for i, col := range coaColumns {
-		colUpper := strings.ToUpper(col)
-		if colUpper == "CACCTNO" {
-			coaAccountIdx = i
-		} else if colUpper == "NACCTTYPE" {
-			accountTypeIdx = i
+	var unlisted []string
+	for accountNumber := range totals {
+		if !listed[accountNumber] {
+			unlisted = append(unlisted, accountNumber)
 		}
 	}
-	
-	if coaAccountIdx == -1 || accountTypeIdx == -1 {
-		return fmt.Errorf("required COA columns not found")
+	if only != "" && len(targets) == 0 && len(unlisted) == 0 {
+		unlisted = append(unlisted, only)
 	}
-	
-	// Find the account type
-	var accountType int = 1 // Default to asset if not found
-	coaRows, _ := coaData["rows"].([][]interface{})
-	for _, row := range coaRows {
-		if len(row) > coaAccountIdx && len(row) > accountTypeIdx {
-			rowAccount := strings.TrimSpace(fmt.Sprintf("%v", row[coaAccountIdx]))
-			if rowAccount == accountNumber {
-				if typeVal := parseFloat(row[accountTypeIdx]); typeVal != 0 {
-					accountType = int(typeVal)
-					fmt.Printf("RefreshGLBalance: Account %s has type %d\n", accountNumber, accountType)
-					break
-				}
-			}
-		}
+	sort.Strings(unlisted)
+	for _, accountNumber := range unlisted {
+		targets = append(targets, ledger.Account{
+			AccountNo: accountNumber,
+			Type:      ledger.TypeOther,
+			TypeName:  ledger.AccountTypeName(ledger.TypeOther),
+		})
 	}
+	result.UnlistedAccounts = unlisted
 	
-	// Calculate new GL balance
-	fmt.Printf("RefreshGLBalance: Reading GLMASTER.dbf...\n")
-	// IMPORTANT: Read ALL records (0, 0) to ensure we don't miss any transactions
-	glData, err := company.ReadDBFFile(companyName, "GLMASTER.dbf", "", 0, 0, "", "")
-	if err != nil {
-		fmt.Printf("RefreshGLBalance: ERROR reading GLMASTER.dbf: %v\n", err)
-		return fmt.Errorf("failed to read GLMASTER.dbf: %w", err)
+	type cachedRow struct {
+		id          int
+		glBalance   float64
+		outstanding float64
 	}
-	
-	// Get column indices for GLMASTER.dbf
-	glColumns, ok := glData["columns"].([]string)
-	if !ok {
-		return fmt.Errorf("invalid GLMASTER.dbf structure")
+	existing := make(map[string]cachedRow)
+	rows, err := db.Query(`
+		SELECT id, account_number, gl_balance, outstanding_checks_total
+		FROM account_balances WHERE company_name = ?
+	`, companyName)
+	if err != nil {
+		return nil, err
 	}
-	
-	var accountIdx, debitIdx, creditIdx int = -1, -1, -1
-	for i, col := range glColumns {
-		colUpper := strings.ToUpper(col)
-		if colUpper == "CACCTNO" || colUpper == "ACCOUNT" || colUpper == "ACCTNO" {
-			accountIdx = i
-		} else if colUpper == "NDEBITS" || colUpper == "DEBIT" || colUpper == "NDEBIT" {
-			debitIdx = i
-		} else if colUpper == "NCREDITS" || colUpper == "CREDIT" || colUpper == "NCREDIT" {
-			creditIdx = i
+	for rows.Next() {
+		var accountNumber string
+		var r cachedRow
+		if err := rows.Scan(&r.id, &accountNumber, &r.glBalance, &r.outstanding); err != nil {
+			rows.Close()
+			return nil, err
 		}
+		existing[accountNumber] = r
 	}
+	rows.Close()
 	
-	if accountIdx == -1 || (debitIdx == -1 && creditIdx == -1) {
-		return fmt.Errorf("required GL columns not found")
-	}
-	
-	// Process GL entries with account type-specific logic using decimal arithmetic
-	totalDebits := currency.Zero()
-	totalCredits := currency.Zero()
-	var recordCount int
-	glRows, _ := glData["rows"].([][]interface{})
-	
-	for _, row := range glRows {
-		if len(row) <= accountIdx {
-			continue
-		}
-		
-		rowAccount := strings.TrimSpace(fmt.Sprintf("%v", row[accountIdx]))
-		if rowAccount == accountNumber {
-			recordCount++
-			
-			// Sum debits using decimal arithmetic
-			if debitIdx != -1 && len(row) > debitIdx && row[debitIdx] != nil {
-				debit := currency.ParseFromDBF(row[debitIdx])
-				if !debit.IsZero() {
-					totalDebits = totalDebits.Add(debit)
-				}
-			}
-			
-			// Sum credits using decimal arithmetic
-			if creditIdx != -1 && len(row) > creditIdx && row[creditIdx] != nil {
-				credit := currency.ParseFromDBF(row[creditIdx])
-				if !credit.IsZero() {
-					totalCredits = totalCredits.Add(credit)
-				}
-			}
-		}
+	tx, err := db.GetConn().Begin()
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 	
-	// Apply correct formula based on account type using decimal arithmetic
-	// Account Types (standard):
-	// 1 = Assets (Debit normal balance)
-	// 2 = Liabilities (Credit normal balance)
-	// 3 = Equity (Credit normal balance)
-	// 4 = Revenue/Income (Credit normal balance)
-	// 5 = Expenses (Debit normal balance)
-	var totalBalance currency.Currency
-	switch accountType {
-	case 1, 5: // Assets and Expenses (Debit normal balance)
-		totalBalance = totalDebits.Sub(totalCredits)
-		fmt.Printf("RefreshGLBalance: Asset/Expense account - Debits: %s, Credits: %s, Balance: %s\n", 
-			totalDebits.ToString(), totalCredits.ToString(), totalBalance.ToString())
-	case 2, 3, 4: // Liabilities, Equity, Revenue (Credit normal balance)
-		totalBalance = totalCredits.Sub(totalDebits)
-		fmt.Printf("RefreshGLBalance: Liability/Equity/Revenue account - Credits: %s, Debits: %s, Balance: %s\n", 
-			totalCredits.ToString(), totalDebits.ToString(), totalBalance.ToString())
-	default:
-		// Default to asset behavior if unknown type
-		totalBalance = totalDebits.Sub(totalCredits)
-		fmt.Printf("RefreshGLBalance: Unknown account type %d, using asset formula - Balance: %s\n", 
-			accountType, totalBalance.ToString())
-	}
-	
-	fmt.Printf("RefreshGLBalance: Processed %d records, total balance: %s\n", recordCount, totalBalance.ToString())
-	
-	// Update the cached balance - use UPSERT to handle race conditions
-	// Note: bank_balance is GENERATED and will auto-calculate as gl_balance + outstanding_checks_total
-	// Convert Currency to float64 for database storage (SQLite stores as REAL)
-	// Store as string to preserve decimal precision
-	_, err = db.Exec(`
+	upsert, err := tx.Prepare(`
 		INSERT INTO account_balances 
 		(company_name, account_number, account_name, account_type, 
-		 gl_balance, gl_record_count, gl_last_updated)
-		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		 gl_balance, gl_record_count, gl_last_updated, is_bank_account, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, ?, ?)
 		ON CONFLICT(company_name, account_number) 
 		DO UPDATE SET 
+			account_name = excluded.account_name,
 			account_type = excluded.account_type,
 			gl_balance = excluded.gl_balance, 
 			gl_record_count = excluded.gl_record_count, 
-			gl_last_updated = CURRENT_TIMESTAMP
-	`, companyName, accountNumber, "", accountType, totalBalance.ToString(), recordCount)
-	
+			gl_last_updated = CURRENT_TIMESTAMP,
+			is_bank_account = excluded.is_bank_account,
+			is_active = excluded.is_active
+	`)
 	if err != nil {
-		fmt.Printf("RefreshGLBalance: UPSERT error: %v, trying fallback UPDATE\n", err)
-		// Fallback to simple UPDATE if INSERT OR REPLACE is not working
-		_, err = db.Exec(`
-			UPDATE account_balances 
-			SET account_type = ?, gl_balance = ?, gl_record_count = ?, gl_last_updated = CURRENT_TIMESTAMP
-			WHERE company_name = ? AND account_number = ?
-		`, accountType, totalBalance.ToString(), recordCount, companyName, accountNumber)
+		return nil, err
+	}
+	defer upsert.Close()
+	
+	for _, account := range targets {
+		a := activity{debits: currency.Zero(), credits: currency.Zero()}
+		if t := totals[account.AccountNo]; t != nil {
+			a = *t
+		}
+		balance := a.debits.Sub(a.credits)
+		if !ledger.IsDebitNormal(account.Type) {
+			balance = balance.Neg()
+		}
+		
+		if _, err := upsert.Exec(companyName, account.AccountNo, account.Description, account.Type,
+			balance.ToString(), a.count, account.IsBank, !account.IsInactive); err != nil {
+			return nil, fmt.Errorf("failed to cache balance for %s: %w", account.AccountNo, err)
+		}
+		result.Accounts++
+		result.ByType[account.TypeName]++
 		
 		// Record the change in history
-		if err == nil {
-			// Calculate new bank balance using Currency type to avoid precision loss
-			newBankBalance := totalBalance.Add(currency.NewFromFloat(currentBalance.OutstandingTotal))
-			_, err = db.Exec(`
-				INSERT INTO balance_history 
-				(account_balance_id, company_name, account_number, change_type,
-				 old_gl_balance, new_gl_balance, old_available_balance, new_available_balance,
-				 change_reason, changed_by)
-				VALUES (?, ?, ?, 'gl_refresh', ?, ?, ?, ?, 'GL balance refresh', ?)
-			`, currentBalance.ID, companyName, accountNumber, 
-				currentBalance.GLBalance, totalBalance.ToString(),
-				currentBalance.BankBalance, newBankBalance.ToString(),
-				username)
+		old, ok := existing[account.AccountNo]
+		if !ok || currency.NewFromFloat(old.glBalance).Equal(balance) {
+			continue
+		}
+		result.Changed++
+		outstanding := currency.NewFromFloat(old.outstanding)
+		if _, err := tx.Exec(`
+			INSERT INTO balance_history 
+			(account_balance_id, company_name, account_number, change_type,
+			 old_gl_balance, new_gl_balance, old_available_balance, new_available_balance,
+			 change_reason, changed_by)
+			VALUES (?, ?, ?, 'gl_refresh', ?, ?, ?, ?, 'GL balance refresh', ?)
+		`, old.id, companyName, account.AccountNo,
+			old.glBalance, balance.ToString(),
+			currency.NewFromFloat(old.glBalance).Add(outstanding).ToString(), balance.Add(outstanding).ToString(),
+			username); err != nil {
+			return nil, err
 		}
 	}
 	
-	return err
+	// A full refresh retires cached accounts that are no longer in COA and have no
+	// GLMASTER activity. Nothing refreshes them again, so left active they would
+	// look stale forever.
+	if only == "" {
+		refreshed := make(map[string]bool, len(targets))
+		for _, account := range targets {
+			refreshed[account.AccountNo] = true
+		}
+		for accountNumber, r := range existing {
+			if refreshed[accountNumber] {
+				continue
+			}
+			if _, err := tx.Exec(`UPDATE account_balances SET is_active = FALSE WHERE id = ?`, r.id); err != nil {
+				return nil, fmt.Errorf("failed to retire cached balance for %s: %w", accountNumber, err)
+			}
+		}
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	
+	result.Duration = time.Since(started).Round(time.Millisecond).String()
+	fmt.Printf("RefreshGLBalances: Processed %d records, cached %d accounts (%d changed) in %s\n",
+		result.Records, result.Accounts, result.Changed, result.Duration)
+	return result, nil
 }
 
 // RefreshOutstandingChecks updates the outstanding checks/deposits total for proper bank reconciliation
@@ -628,8 +745,8 @@ func RefreshOutstandingChecks(db *DB, companyName, accountNumber, username strin
 	_, err = db.Exec(`
 		INSERT INTO account_balances 
 		(company_name, account_number, account_name, account_type, 
-		 outstanding_checks_total, outstanding_checks_count, outstanding_checks_last_updated, metadata)
-		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, ?)
+		 outstanding_checks_total, outstanding_checks_count, outstanding_checks_last_updated, metadata, is_bank_account)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, ?, TRUE)
 		ON CONFLICT(company_name, account_number) 
 		DO UPDATE SET 
 			outstanding_checks_total = excluded.outstanding_checks_total, 
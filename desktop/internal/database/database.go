@@ -305,6 +305,545 @@ func (db *DB) initSchema() error {
 	CREATE INDEX IF NOT EXISTS idx_bank_transactions_date ON bank_transactions(transaction_date);
 	CREATE INDEX IF NOT EXISTS idx_bank_transactions_matched ON bank_transactions(is_matched);
 	CREATE INDEX IF NOT EXISTS idx_bank_transactions_reconciled ON bank_transactions(is_reconciled);
+
+	-- Paid items files from the bank (check number, amount, paid date), one row per import
+	CREATE TABLE IF NOT EXISTS paid_item_imports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		account_number TEXT NOT NULL,
+		import_batch_id TEXT UNIQUE NOT NULL,
+		file_name TEXT,
+		imported_by TEXT NOT NULL,
+		import_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		item_count INTEGER DEFAULT 0,
+		cleared_count INTEGER DEFAULT 0,
+		exception_count INTEGER DEFAULT 0
+	);
+
+	-- Individual paid items and how they were applied to CHECKS.dbf
+	CREATE TABLE IF NOT EXISTS paid_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		account_number TEXT NOT NULL,
+		import_batch_id TEXT NOT NULL,
+		check_number TEXT NOT NULL,
+		paid_amount DECIMAL(15,2) NOT NULL,
+		paid_date DATE NOT NULL,
+		cidchec TEXT, -- Matched CHECKS.dbf CIDCHEC (NULL when the item was never issued)
+		issued_amount DECIMAL(15,2),
+		status TEXT NOT NULL, -- cleared, already_cleared, amount_mismatch, not_issued, paid_void, duplicate
+		is_exception BOOLEAN DEFAULT FALSE,
+		dbf_updated BOOLEAN DEFAULT FALSE,
+		resolved_by TEXT,
+		resolved_at TIMESTAMP NULL,
+		resolution_note TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (import_batch_id) REFERENCES paid_item_imports(import_batch_id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_paid_items_company_account ON paid_items(company_name, account_number);
+	CREATE INDEX IF NOT EXISTS idx_paid_items_cidchec ON paid_items(cidchec);
+	CREATE INDEX IF NOT EXISTS idx_paid_items_exception ON paid_items(is_exception);
+
+	-- Interbank transfers: the two bank_transactions legs of money moved between company accounts
+	CREATE TABLE IF NOT EXISTS bank_transfers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		from_account TEXT NOT NULL,
+		to_account TEXT NOT NULL,
+		from_transaction_id INTEGER NOT NULL,
+		to_transaction_id INTEGER NOT NULL,
+		amount DECIMAL(15,2) NOT NULL,
+		from_date DATE NOT NULL,
+		to_date DATE NOT NULL,
+		confidence DECIMAL(3,2),
+		auto_detected BOOLEAN DEFAULT FALSE,
+		matched_by TEXT NOT NULL,
+		matched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (from_transaction_id) REFERENCES bank_transactions(id) ON DELETE CASCADE,
+		FOREIGN KEY (to_transaction_id) REFERENCES bank_transactions(id) ON DELETE CASCADE,
+		UNIQUE(from_transaction_id),
+		UNIQUE(to_transaction_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_bank_transfers_company ON bank_transfers(company_name);
+	CREATE INDEX IF NOT EXISTS idx_bank_transfers_from_account ON bank_transfers(company_name, from_account);
+	CREATE INDEX IF NOT EXISTS idx_bank_transfers_to_account ON bank_transfers(company_name, to_account);
+
+	-- Cash position settings per bank account (minimum balance alerts, which account pays AP)
+	CREATE TABLE IF NOT EXISTS cash_account_settings (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		account_number TEXT NOT NULL,
+		minimum_balance DECIMAL(15,2) DEFAULT 0.00,
+		pays_ap BOOLEAN DEFAULT FALSE,
+		updated_by TEXT,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(company_name, account_number)
+	);
+
+	-- Daily cash position history, one row per account per day
+	CREATE TABLE IF NOT EXISTS cash_position_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		snapshot_date DATE NOT NULL,
+		account_number TEXT NOT NULL,
+		account_name TEXT,
+		gl_balance DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+		bank_balance DECIMAL(15,2),
+		bank_balance_date DATE,
+		outstanding_checks DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+		deposits_in_transit DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+		upcoming_ap DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+		projected_balance DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+		minimum_balance DECIMAL(15,2) DEFAULT 0.00,
+		below_minimum BOOLEAN DEFAULT FALSE,
+		created_by TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(company_name, snapshot_date, account_number)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_cash_position_snapshots_company_date ON cash_position_snapshots(company_name, snapshot_date);
+
+	-- Check stock received from the printer, one row per range of check numbers
+	CREATE TABLE IF NOT EXISTS check_stock_ranges (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		account_number TEXT NOT NULL,
+		start_number INTEGER NOT NULL,
+		end_number INTEGER NOT NULL,
+		received_date DATE,
+		description TEXT,
+		status TEXT NOT NULL DEFAULT 'active', -- active, exhausted, destroyed
+		notes TEXT,
+		created_by TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		CHECK (end_number >= start_number)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_check_stock_ranges_company_account ON check_stock_ranges(company_name, account_number);
+
+	-- Financial statement line-item layouts (JSON definition per statement type)
+	CREATE TABLE IF NOT EXISTS statement_layouts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		statement_type TEXT NOT NULL, -- balance_sheet, income_statement
+		name TEXT NOT NULL,
+		definition TEXT NOT NULL,
+		is_default BOOLEAN DEFAULT FALSE,
+		created_by TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_by TEXT,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(company_name, statement_type, name)
+	);
+
+
+	-- Accounting period locks; periods without a row are open
+	CREATE TABLE IF NOT EXISTS accounting_periods (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		fiscal_year INTEGER NOT NULL,
+		period INTEGER NOT NULL,
+		start_date DATE NOT NULL,
+		end_date DATE NOT NULL,
+		status TEXT NOT NULL DEFAULT 'open', -- open, soft_closed, hard_closed
+		closed_by TEXT,
+		closed_at TIMESTAMP,
+		closing_date DATE,
+		close_reason TEXT,
+		forced BOOLEAN DEFAULT FALSE,
+		checklist_json TEXT DEFAULT '[]',
+		reopened_by TEXT,
+		reopened_at TIMESTAMP,
+		reopen_reason TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(company_name, fiscal_year, period)
+	);
+
+	-- Every period close, hard close and reopen
+	CREATE TABLE IF NOT EXISTS period_audit_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		fiscal_year INTEGER NOT NULL,
+		period INTEGER NOT NULL,
+		action TEXT NOT NULL, -- close, hard_close, reopen
+		from_status TEXT,
+		to_status TEXT NOT NULL,
+		username TEXT NOT NULL,
+		reason TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_period_audit_log_company_period ON period_audit_log(company_name, fiscal_year, period);
+
+
+	-- Year-end close configuration per company
+	CREATE TABLE IF NOT EXISTS year_end_settings (
+		company_name TEXT PRIMARY KEY,
+		retained_earnings_account TEXT NOT NULL,
+		updated_by TEXT,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Year-end closes; reversed closes are kept for the audit trail
+	CREATE TABLE IF NOT EXISTS year_end_closes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		fiscal_year INTEGER NOT NULL,
+		status TEXT NOT NULL, -- posted, exported, reversed
+		mode TEXT NOT NULL, -- post, export
+		retained_earnings_account TEXT NOT NULL,
+		closing_year INTEGER NOT NULL,
+		closing_period INTEGER NOT NULL,
+		closing_date DATE NOT NULL,
+		revenue DECIMAL(15,2) DEFAULT 0,
+		expenses DECIMAL(15,2) DEFAULT 0,
+		net_income DECIMAL(15,2) DEFAULT 0,
+		batch TEXT NOT NULL,
+		entries_json TEXT DEFAULT '[]',
+		locked_periods_json TEXT DEFAULT '[]',
+		closed_by TEXT NOT NULL,
+		closed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		reversal_batch TEXT,
+		reversed_by TEXT,
+		reversed_at TIMESTAMP,
+		reversal_reason TEXT
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_year_end_closes_company_year ON year_end_closes(company_name, fiscal_year);
+
+
+	-- Manual journal entries; drafts are posted to GLMASTER.dbf as a new CBATCH
+	CREATE TABLE IF NOT EXISTS journal_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		entry_date DATE NOT NULL,
+		fiscal_year INTEGER NOT NULL,
+		period INTEGER NOT NULL,
+		description TEXT,
+		reference TEXT,
+		source TEXT NOT NULL DEFAULT 'GJ',
+		status TEXT NOT NULL DEFAULT 'draft', -- draft, posting, posted
+		batch TEXT,
+		total_debits DECIMAL(15,2) DEFAULT 0,
+		total_credits DECIMAL(15,2) DEFAULT 0,
+		created_by TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_by TEXT,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		posted_by TEXT,
+		posted_at TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS journal_entry_lines (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entry_id INTEGER NOT NULL,
+		line_no INTEGER NOT NULL,
+		account_number TEXT NOT NULL,
+		unit_number TEXT,
+		dept_number TEXT,
+		afe_number TEXT,
+		description TEXT,
+		debit DECIMAL(15,2) DEFAULT 0,
+		credit DECIMAL(15,2) DEFAULT 0,
+		FOREIGN KEY (entry_id) REFERENCES journal_entries(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_journal_entries_company_status ON journal_entries(company_name, status);
+	CREATE INDEX IF NOT EXISTS idx_journal_entry_lines_entry ON journal_entry_lines(entry_id);
+
+
+	-- Recurring journal entry templates and what they generated each period
+	CREATE TABLE IF NOT EXISTS journal_templates (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		name TEXT NOT NULL,
+		description TEXT,
+		reference TEXT,
+		frequency TEXT NOT NULL DEFAULT 'monthly',
+		day_of_period INTEGER NOT NULL DEFAULT 0,
+		start_year INTEGER NOT NULL,
+		start_period INTEGER NOT NULL,
+		end_year INTEGER,
+		end_period INTEGER,
+		auto_reverse BOOLEAN DEFAULT 0,
+		active BOOLEAN DEFAULT 1,
+		lines_json TEXT,
+		created_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_by TEXT,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS journal_template_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		template_id INTEGER NOT NULL,
+		company_name TEXT NOT NULL,
+		fiscal_year INTEGER NOT NULL,
+		period INTEGER NOT NULL,
+		entry_id INTEGER NOT NULL,
+		reversal_entry_id INTEGER,
+		generated_by TEXT,
+		generated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(template_id, fiscal_year, period)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_journal_templates_company ON journal_templates(company_name);
+
+
+	-- Saved column mappings for spreadsheet journal imports
+	CREATE TABLE IF NOT EXISTS journal_import_profiles (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		name TEXT NOT NULL,
+		mapping_json TEXT,
+		created_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_by TEXT,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(company_name, name)
+	);
+
+
+	-- Budget versions per fiscal year and their amounts by account, unit, department and period
+	CREATE TABLE IF NOT EXISTS budget_versions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		fiscal_year INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		description TEXT,
+		created_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_by TEXT,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(company_name, fiscal_year, name)
+	);
+
+	CREATE TABLE IF NOT EXISTS budget_amounts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		version_id INTEGER NOT NULL,
+		account_number TEXT NOT NULL,
+		unit_number TEXT NOT NULL DEFAULT '',
+		dept_number TEXT NOT NULL DEFAULT '',
+		period INTEGER NOT NULL,
+		amount DECIMAL(15,2) DEFAULT 0,
+		FOREIGN KEY (version_id) REFERENCES budget_versions(id) ON DELETE CASCADE,
+		UNIQUE(version_id, account_number, unit_number, dept_number, period)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_budget_versions_company_year ON budget_versions(company_name, fiscal_year);
+
+	-- Fiscal calendar per company; companies without a row use GLOPT's fiscal year begin month
+	CREATE TABLE IF NOT EXISTS fiscal_calendars (
+		company_name TEXT PRIMARY KEY,
+		start_month INTEGER NOT NULL DEFAULT 1,
+		periods_per_year INTEGER NOT NULL DEFAULT 12,
+		named_by_start_year BOOLEAN DEFAULT 0,
+		years_json TEXT, -- Explicit period dates by fiscal year
+		updated_by TEXT,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- AFEs (authorities for expenditure) by CAFENO, with gross estimates by cost category
+	CREATE TABLE IF NOT EXISTS afes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		afe_number TEXT NOT NULL,
+		well_id TEXT,
+		description TEXT,
+		approval_date DATE,
+		gross_estimate DECIMAL(15,2) DEFAULT 0,
+		working_interest DECIMAL(9,6) DEFAULT 100, -- Percent
+		status TEXT NOT NULL DEFAULT 'open', -- open, closed
+		final_actual DECIMAL(15,2), -- Actual cost recorded at close-out
+		actual_source TEXT,
+		closed_by TEXT,
+		closed_at DATETIME,
+		close_notes TEXT,
+		created_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_by TEXT,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(company_name, afe_number)
+	);
+
+	CREATE TABLE IF NOT EXISTS afe_lines (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		afe_id INTEGER NOT NULL,
+		line_order INTEGER NOT NULL DEFAULT 0,
+		category TEXT NOT NULL,
+		description TEXT,
+		gross_estimate DECIMAL(15,2) DEFAULT 0,
+		FOREIGN KEY (afe_id) REFERENCES afes(id) ON DELETE CASCADE,
+		UNIQUE(afe_id, category)
+	);
+
+	-- Background balance cache refresh cadence per company
+	CREATE TABLE IF NOT EXISTS balance_refresh_settings (
+		company_name TEXT PRIMARY KEY,
+		enabled BOOLEAN NOT NULL DEFAULT TRUE,
+		interval_minutes INTEGER NOT NULL DEFAULT 15,
+		watch_files BOOLEAN NOT NULL DEFAULT TRUE,
+		poll_seconds INTEGER NOT NULL DEFAULT 30,
+		updated_by TEXT,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Cached dashboard figures, keyed by source, period and ranking size; the
+	-- signature fingerprints the DBF files they were computed from
+	CREATE TABLE IF NOT EXISTS dashboard_kpi_cache (
+		company_name TEXT NOT NULL,
+		cache_key TEXT NOT NULL,
+		signature TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		built_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (company_name, cache_key)
+	);
+
+	-- Chart of accounts maintenance history (add, update, deactivate, reactivate, delete, merge, import, map)
+	CREATE TABLE IF NOT EXISTS coa_audit_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		action TEXT NOT NULL,
+		account_number TEXT NOT NULL,
+		target_account TEXT, -- Merge target
+		username TEXT NOT NULL,
+		reason TEXT,
+		details TEXT, -- JSON: before/after values or merge totals
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_coa_audit_log_company_account ON coa_audit_log(company_name, account_number);
+
+	-- Custom standard (reporting) charts; the built-in COPAS chart is not stored
+	CREATE TABLE IF NOT EXISTS standard_charts (
+		company_name TEXT NOT NULL,
+		code TEXT NOT NULL,
+		name TEXT NOT NULL,
+		description TEXT,
+		updated_by TEXT,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (company_name, code)
+	);
+
+	CREATE TABLE IF NOT EXISTS standard_chart_accounts (
+		company_name TEXT NOT NULL,
+		chart_code TEXT NOT NULL,
+		account_number TEXT NOT NULL,
+		description TEXT NOT NULL,
+		account_type INTEGER NOT NULL,
+		parent TEXT,
+		is_title BOOLEAN NOT NULL DEFAULT FALSE,
+		is_total BOOLEAN NOT NULL DEFAULT FALSE,
+		sort_order INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (company_name, chart_code, account_number)
+	);
+
+	-- Company account to standard chart account mappings
+	CREATE TABLE IF NOT EXISTS coa_mappings (
+		company_name TEXT NOT NULL,
+		chart_code TEXT NOT NULL,
+		account_number TEXT NOT NULL,
+		standard_account TEXT NOT NULL,
+		mapped_by TEXT,
+		mapped_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (company_name, chart_code, account_number)
+	);
+
+	-- Consolidation groups: companies reported together, owned by the reporting company
+	CREATE TABLE IF NOT EXISTS consolidation_groups (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		name TEXT NOT NULL,
+		description TEXT,
+		chart_code TEXT NOT NULL DEFAULT '', -- Standard chart the companies are restated on; empty when they share a chart
+		companies_json TEXT NOT NULL,
+		created_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_by TEXT,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(company_name, name)
+	);
+
+	CREATE TABLE IF NOT EXISTS consolidation_eliminations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		group_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		accounts_json TEXT NOT NULL,
+		companies_json TEXT, -- Empty applies the rule to every company in the group
+		offset_account TEXT,
+		sort_order INTEGER NOT NULL DEFAULT 0,
+		FOREIGN KEY (group_id) REFERENCES consolidation_groups(id) ON DELETE CASCADE
+	);
+
+	-- Balance sheet accounts other than bank accounts that are reconciled each period
+	CREATE TABLE IF NOT EXISTS account_rec_accounts (
+		company_name TEXT NOT NULL,
+		account_number TEXT NOT NULL,
+		category TEXT NOT NULL, -- prepaid, accrual, suspense, clearing, other
+		tolerance DECIMAL(15,2) DEFAULT 0, -- Largest difference that can be signed off without an explanation
+		instructions TEXT,
+		updated_by TEXT,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (company_name, account_number)
+	);
+
+	-- Account reconciliation workpapers, one per account and fiscal period
+	CREATE TABLE IF NOT EXISTS account_recs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		account_number TEXT NOT NULL,
+		fiscal_year INTEGER NOT NULL,
+		period INTEGER NOT NULL,
+		period_end DATE,
+		status TEXT NOT NULL DEFAULT 'open', -- open, prepared, reviewed
+		gl_balance DECIMAL(15,2), -- Recorded when prepared
+		schedule_total DECIMAL(15,2),
+		difference DECIMAL(15,2),
+		explanation TEXT, -- Why a difference over the tolerance was accepted
+		notes TEXT,
+		rolled_from_id INTEGER,
+		created_by TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		prepared_by TEXT,
+		prepared_at TIMESTAMP,
+		reviewed_by TEXT,
+		reviewed_at TIMESTAMP,
+		UNIQUE(company_name, account_number, fiscal_year, period)
+	);
+
+	-- Supporting schedule of open items; amounts are debit-positive like the GL
+	CREATE TABLE IF NOT EXISTS account_rec_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		rec_id INTEGER NOT NULL,
+		source TEXT NOT NULL, -- manual, gl, rollforward
+		item_date DATE,
+		reference TEXT,
+		description TEXT,
+		amount DECIMAL(15,2) NOT NULL,
+		gl_key TEXT, -- GL line, reference or batch the item was pulled from
+		origin_item_id INTEGER, -- The item as first entered, for rolled-forward items
+		cleared BOOLEAN DEFAULT 0,
+		cleared_note TEXT,
+		added_by TEXT,
+		added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (rec_id) REFERENCES account_recs(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_account_rec_items_rec ON account_rec_items(rec_id);
+
+	-- Sign-off history of account reconciliations
+	CREATE TABLE IF NOT EXISTS account_rec_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		rec_id INTEGER NOT NULL,
+		action TEXT NOT NULL, -- start, prepare, review, reopen
+		username TEXT,
+		note TEXT,
+		logged_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
 	`
 
 	if _, err := db.conn.Exec(schema); err != nil {
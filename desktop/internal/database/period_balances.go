@@ -0,0 +1,527 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/company"
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// PeriodBalance is the activity and closing balance of one account for one fiscal
+// period. Balances are debits minus credits accumulated from the first entry on
+// file; callers flip the sign for credit-normal accounts. Year-end closing entries
+// are kept in ledger.ClosingPeriod so period 12 still shows the pre-close balance.
+type PeriodBalance struct {
+	AccountNumber string    `json:"account_number"`
+	Year          int       `json:"year"`
+	Period        int       `json:"period"`
+	PeriodEnd     time.Time `json:"period_end"`
+	Debits        float64   `json:"debits"`
+	Credits       float64   `json:"credits"`
+	Net           float64   `json:"net"`
+	Balance       float64   `json:"balance"`
+	RecordCount   int       `json:"record_count"`
+}
+
+// AsOfBalance is an account's balance at the end of a given day
+type AsOfBalance struct {
+	AccountNumber string    `json:"account_number"`
+	AsOf          time.Time `json:"as_of"`
+	Debits        float64   `json:"debits"`
+	Credits       float64   `json:"credits"`
+	Balance       float64   `json:"balance"`
+	RecordCount   int       `json:"record_count"`
+}
+
+// PeriodBalanceRefresh describes what a rebuild of the period balance cache changed
+type PeriodBalanceRefresh struct {
+	Records         int       `json:"records"`
+	Accounts        int       `json:"accounts"`
+	PeriodRows      int       `json:"period_rows"`
+	DailyRows       int       `json:"daily_rows"`
+	Changed         bool      `json:"changed"`
+	InvalidatedFrom string    `json:"invalidated_from,omitempty"` // Earliest period whose rows were rewritten
+	InvalidatedDate string    `json:"invalidated_date,omitempty"` // Earliest day whose activity was rewritten
+	BuiltAt         time.Time `json:"built_at"`
+}
+
+// periodBalanceSchema holds the period-end and daily activity caches. The state
+// row records the GLMASTER size, modification time and calendar the cache was
+// built from so lookups can tell when it needs rebuilding.
+const periodBalanceSchema = `
+	CREATE TABLE IF NOT EXISTS account_period_balances (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		account_number TEXT NOT NULL,
+		fiscal_year INTEGER NOT NULL,
+		period INTEGER NOT NULL,
+		period_end DATE,
+		debits DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+		credits DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+		balance DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+		record_count INTEGER NOT NULL DEFAULT 0,
+		UNIQUE(company_name, account_number, fiscal_year, period)
+	);
+
+	CREATE TABLE IF NOT EXISTS account_daily_activity (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_name TEXT NOT NULL,
+		account_number TEXT NOT NULL,
+		activity_date DATE NOT NULL,
+		debits DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+		credits DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+		record_count INTEGER NOT NULL DEFAULT 0,
+		UNIQUE(company_name, account_number, activity_date)
+	);
+
+	CREATE TABLE IF NOT EXISTS period_balance_state (
+		company_name TEXT PRIMARY KEY,
+		gl_size INTEGER NOT NULL DEFAULT 0,
+		gl_modified TEXT NOT NULL DEFAULT '',
+		calendar TEXT NOT NULL DEFAULT '',
+		record_count INTEGER NOT NULL DEFAULT 0,
+		invalidated_from TEXT,
+		built_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_period_balances_lookup ON account_period_balances(company_name, account_number, fiscal_year, period);
+	CREATE INDEX IF NOT EXISTS idx_daily_activity_lookup ON account_daily_activity(company_name, account_number, activity_date);
+`
+
+// dateLayout is the form dates are stored in so they compare as strings
+const dateLayout = "2006-01-02"
+
+// undatedActivity is the day entries with neither a period nor a date are filed
+// under, so every as-of lookup includes them as the all-time GL balance does
+const undatedActivity = "0001-01-01"
+
+// activity accumulates debits and credits for one cache row
+type activity struct {
+	debits  currency.Currency
+	credits currency.Currency
+	count   int
+}
+
+func (a *activity) add(e ledger.GLEntry) {
+	a.debits = a.debits.Add(currency.NewFromFloat(e.Debit))
+	a.credits = a.credits.Add(currency.NewFromFloat(e.Credit))
+	a.count++
+}
+
+func (a activity) equal(o activity) bool {
+	return a.count == o.count && a.debits.Equal(o.debits) && a.credits.Equal(o.credits)
+}
+
+// periodKey identifies one account period row
+type periodKey struct {
+	account string
+	period  ledger.Period
+}
+
+// dayKey identifies one account day row
+type dayKey struct {
+	account string
+	date    string
+}
+
+// glSignature identifies the GLMASTER contents and calendar a cache was built from
+type glSignature struct {
+	size     int64
+	modified string
+	calendar string
+}
+
+func currentGLSignature(companyName string, cal *ledger.Calendar) (glSignature, error) {
+	info, err := company.StatDBFFile(companyName, "GLMASTER.dbf")
+	if err != nil {
+		return glSignature{}, fmt.Errorf("failed to stat GLMASTER.dbf: %w", err)
+	}
+	calJSON, _ := json.Marshal(cal)
+	return glSignature{
+		size:     info.Size(),
+		modified: info.ModTime().UTC().Format(time.RFC3339Nano),
+		calendar: string(calJSON),
+	}, nil
+}
+
+// PeriodBalancesStale reports whether the period balance cache for a company is
+// missing or was built from a different GLMASTER or fiscal calendar
+func PeriodBalancesStale(db *DB, companyName string, cal *ledger.Calendar) (bool, error) {
+	current, err := currentGLSignature(companyName, cal)
+	if err != nil {
+		return false, err
+	}
+	var cached glSignature
+	err = db.QueryRow(`
+		SELECT gl_size, gl_modified, calendar FROM period_balance_state WHERE company_name = ?
+	`, companyName).Scan(&cached.size, &cached.modified, &cached.calendar)
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return cached != current, nil
+}
+
+// InvalidatePeriodBalances marks a company's period balance cache stale so the
+// next lookup rebuilds it. Rows are kept and rewritten only from the earliest
+// period that actually changed.
+func InvalidatePeriodBalances(db *DB, companyName string) error {
+	_, err := db.Exec(`UPDATE period_balance_state SET gl_modified = '' WHERE company_name = ?`, companyName)
+	return err
+}
+
+// EnsurePeriodBalances rebuilds the period balance cache when it is stale. It
+// returns nil when the cache was already current.
+func EnsurePeriodBalances(db *DB, companyName string, cal *ledger.Calendar) (*PeriodBalanceRefresh, error) {
+	stale, err := PeriodBalancesStale(db, companyName, cal)
+	if err != nil {
+		return nil, err
+	}
+	if !stale {
+		return nil, nil
+	}
+	return RefreshPeriodBalances(db, companyName, cal)
+}
+
+// RefreshPeriodBalances recomputes period and daily account activity from
+// GLMASTER in one pass. Rows before the earliest period (and day) whose activity
+// differs from the cache are left alone; everything from there on is rewritten
+// because each closing balance carries forward into the periods after it.
+func RefreshPeriodBalances(db *DB, companyName string, cal *ledger.Calendar) (*PeriodBalanceRefresh, error) {
+	signature, err := currentGLSignature(companyName, cal)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ledger.LoadGLEntries(companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GLMASTER.dbf: %w", err)
+	}
+
+	periods := make(map[periodKey]*activity)
+	days := make(map[dayKey]*activity)
+	accounts := make(map[string]bool)
+	for _, e := range entries {
+		if e.AccountNo == "" {
+			continue
+		}
+		accounts[e.AccountNo] = true
+
+		// Entries with no CYEAR/CPERIOD and no date land in the zero period,
+		// which sorts before every real period
+		p, ok := e.FiscalPeriodIn(cal)
+		if ok && e.IsClosingEntry() {
+			p.Period = ledger.ClosingPeriod
+		}
+		pk := periodKey{e.AccountNo, p}
+		if periods[pk] == nil {
+			periods[pk] = &activity{}
+		}
+		periods[pk].add(e)
+
+		day := undatedActivity
+		if !e.Date.IsZero() {
+			day = e.Date.Format(dateLayout)
+		} else if ok {
+			_, end := cal.PeriodDates(p)
+			day = end.Format(dateLayout)
+		}
+		dk := dayKey{e.AccountNo, day}
+		if days[dk] == nil {
+			days[dk] = &activity{}
+		}
+		days[dk].add(e)
+	}
+
+	cachedPeriods, err := loadCachedPeriodActivity(db, companyName)
+	if err != nil {
+		return nil, err
+	}
+	cachedDays, err := loadCachedDailyActivity(db, companyName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the earliest period and day that differ in either direction
+	var fromPeriod *ledger.Period
+	for k, a := range periods {
+		if c, ok := cachedPeriods[k]; !ok || !c.equal(*a) {
+			fromPeriod = earlierPeriod(fromPeriod, k.period)
+		}
+	}
+	for k := range cachedPeriods {
+		if _, ok := periods[k]; !ok {
+			fromPeriod = earlierPeriod(fromPeriod, k.period)
+		}
+	}
+	fromDay := ""
+	for k, a := range days {
+		if c, ok := cachedDays[k]; !ok || !c.equal(*a) {
+			fromDay = earlierDay(fromDay, k.date)
+		}
+	}
+	for k := range cachedDays {
+		if _, ok := days[k]; !ok {
+			fromDay = earlierDay(fromDay, k.date)
+		}
+	}
+
+	result := &PeriodBalanceRefresh{
+		Records:  len(entries),
+		Accounts: len(accounts),
+		BuiltAt:  time.Now(),
+	}
+
+	tx, err := db.GetConn().Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if fromPeriod != nil {
+		result.Changed = true
+		result.InvalidatedFrom = fromPeriod.String()
+		if _, err := tx.Exec(`
+			DELETE FROM account_period_balances
+			WHERE company_name = ? AND fiscal_year * 100 + period >= ?
+		`, companyName, fromPeriod.Index()); err != nil {
+			return nil, err
+		}
+
+		keys := make([]periodKey, 0, len(periods))
+		for k := range periods {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].account != keys[j].account {
+				return keys[i].account < keys[j].account
+			}
+			return keys[i].period.Before(keys[j].period)
+		})
+
+		stmt, err := tx.Prepare(`
+			INSERT INTO account_period_balances
+			(company_name, account_number, fiscal_year, period, period_end, debits, credits, balance, record_count)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`)
+		if err != nil {
+			return nil, err
+		}
+		defer stmt.Close()
+
+		account := ""
+		balance := currency.Zero()
+		for _, k := range keys {
+			if k.account != account {
+				account = k.account
+				balance = currency.Zero()
+			}
+			a := periods[k]
+			balance = balance.Add(a.debits.Sub(a.credits))
+			if k.period.Before(*fromPeriod) {
+				continue
+			}
+			var periodEnd interface{}
+			if !k.period.IsZero() {
+				_, end := cal.PeriodDates(k.period)
+				periodEnd = end.Format(dateLayout)
+			}
+			if _, err := stmt.Exec(companyName, k.account, k.period.Year, k.period.Period, periodEnd,
+				a.debits.ToString(), a.credits.ToString(), balance.ToString(), a.count); err != nil {
+				return nil, err
+			}
+			result.PeriodRows++
+		}
+	}
+
+	if fromDay != "" {
+		result.Changed = true
+		result.InvalidatedDate = fromDay
+		if _, err := tx.Exec(`
+			DELETE FROM account_daily_activity WHERE company_name = ? AND activity_date >= ?
+		`, companyName, fromDay); err != nil {
+			return nil, err
+		}
+
+		stmt, err := tx.Prepare(`
+			INSERT INTO account_daily_activity
+			(company_name, account_number, activity_date, debits, credits, record_count)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`)
+		if err != nil {
+			return nil, err
+		}
+		defer stmt.Close()
+
+		for k, a := range days {
+			if k.date < fromDay {
+				continue
+			}
+			if _, err := stmt.Exec(companyName, k.account, k.date,
+				a.debits.ToString(), a.credits.ToString(), a.count); err != nil {
+				return nil, err
+			}
+			result.DailyRows++
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO period_balance_state
+		(company_name, gl_size, gl_modified, calendar, record_count, invalidated_from, built_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(company_name) DO UPDATE SET
+			gl_size = excluded.gl_size,
+			gl_modified = excluded.gl_modified,
+			calendar = excluded.calendar,
+			record_count = excluded.record_count,
+			invalidated_from = COALESCE(excluded.invalidated_from, period_balance_state.invalidated_from),
+			built_at = CURRENT_TIMESTAMP
+	`, companyName, signature.size, signature.modified, signature.calendar, len(entries),
+		nullIfEmpty(result.InvalidatedFrom)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("RefreshPeriodBalances: %s - %d records, %d accounts, rewrote %d period rows from %s and %d daily rows from %s\n",
+		companyName, result.Records, result.Accounts, result.PeriodRows, result.InvalidatedFrom, result.DailyRows, result.InvalidatedDate)
+	return result, nil
+}
+
+// GetPeriodBalances returns the cached period rows for an account, optionally
+// limited to one fiscal year
+func GetPeriodBalances(db *DB, companyName, accountNumber string, year int) ([]PeriodBalance, error) {
+	query := `
+		SELECT fiscal_year, period, COALESCE(period_end, ''), debits, credits, balance, record_count
+		FROM account_period_balances
+		WHERE company_name = ? AND account_number = ?`
+	args := []interface{}{companyName, accountNumber}
+	if year != 0 {
+		query += ` AND fiscal_year = ?`
+		args = append(args, year)
+	}
+	query += ` ORDER BY fiscal_year, period`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []PeriodBalance
+	for rows.Next() {
+		var b PeriodBalance
+		var periodEnd string
+		if err := rows.Scan(&b.Year, &b.Period, &periodEnd, &b.Debits, &b.Credits, &b.Balance, &b.RecordCount); err != nil {
+			return nil, err
+		}
+		b.AccountNumber = accountNumber
+		b.PeriodEnd, _ = time.Parse(dateLayout, periodEnd)
+		b.Net = currency.NewFromFloat(b.Debits).Sub(currency.NewFromFloat(b.Credits)).ToFloat64()
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
+// GetBalanceAsOf returns an account's cached balance at the end of asOf
+func GetBalanceAsOf(db *DB, companyName, accountNumber string, asOf time.Time) (*AsOfBalance, error) {
+	b := &AsOfBalance{AccountNumber: accountNumber, AsOf: asOf}
+	err := db.QueryRow(`
+		SELECT COALESCE(SUM(debits), 0), COALESCE(SUM(credits), 0), COALESCE(SUM(record_count), 0)
+		FROM account_daily_activity
+		WHERE company_name = ? AND account_number = ? AND activity_date <= ?
+	`, companyName, accountNumber, asOf.Format(dateLayout)).Scan(&b.Debits, &b.Credits, &b.RecordCount)
+	if err != nil {
+		return nil, err
+	}
+	debits := currency.NewFromFloat(b.Debits)
+	credits := currency.NewFromFloat(b.Credits)
+	b.Debits = debits.ToFloat64()
+	b.Credits = credits.ToFloat64()
+	b.Balance = debits.Sub(credits).ToFloat64()
+	return b, nil
+}
+
+func loadCachedPeriodActivity(db *DB, companyName string) (map[periodKey]activity, error) {
+	rows, err := db.Query(`
+		SELECT account_number, fiscal_year, period, debits, credits, record_count
+		FROM account_period_balances WHERE company_name = ?
+	`, companyName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cached := make(map[periodKey]activity)
+	for rows.Next() {
+		var k periodKey
+		var debits, credits float64
+		var count int
+		if err := rows.Scan(&k.account, &k.period.Year, &k.period.Period, &debits, &credits, &count); err != nil {
+			return nil, err
+		}
+		cached[k] = activity{currency.NewFromFloat(debits), currency.NewFromFloat(credits), count}
+	}
+	return cached, rows.Err()
+}
+
+func loadCachedDailyActivity(db *DB, companyName string) (map[dayKey]activity, error) {
+	rows, err := db.Query(`
+		SELECT account_number, activity_date, debits, credits, record_count
+		FROM account_daily_activity WHERE company_name = ?
+	`, companyName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cached := make(map[dayKey]activity)
+	for rows.Next() {
+		var k dayKey
+		var debits, credits float64
+		var count int
+		if err := rows.Scan(&k.account, &k.date, &debits, &credits, &count); err != nil {
+			return nil, err
+		}
+		// SQLite may hand DATE columns back as timestamps
+		if len(k.date) > len(dateLayout) {
+			if t, err := time.Parse(time.RFC3339, k.date); err == nil {
+				k.date = t.Format(dateLayout)
+			} else {
+				k.date = strings.TrimSpace(k.date[:len(dateLayout)])
+			}
+		}
+		cached[k] = activity{currency.NewFromFloat(debits), currency.NewFromFloat(credits), count}
+	}
+	return cached, rows.Err()
+}
+
+func earlierPeriod(current *ledger.Period, p ledger.Period) *ledger.Period {
+	if current == nil || p.Before(*current) {
+		return &p
+	}
+	return current
+}
+
+func earlierDay(current, day string) string {
+	if current == "" || day < current {
+		return day
+	}
+	return current
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+package financials
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pivoten/financialsx/desktop/internal/coa"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// chartInfo describes the chart a report was restated on
+type chartInfo struct {
+	code, name   string
+	unmapped     []string        // Company accounts with GL activity and no mapping
+	unmappedKeys map[string]bool // Their account numbers in the restated ledger
+}
+
+// warnings returns the report warnings for accounts missing from the mapping
+func (c *chartInfo) warnings() []string {
+	if c == nil || len(c.unmapped) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("%d account(s) with activity are not mapped to the %s chart and are shown under their own numbers: %s",
+		len(c.unmapped), c.name, strings.Join(c.unmapped, ", "))}
+}
+
+// loadLedger loads the period ledger on the company's chart, or restated on a
+// standard chart when chartCode is set
+func (s *Service) loadLedger(companyName, chartCode string) (*periodLedger, *chartInfo, error) {
+	pl, err := s.loadPeriodLedger(companyName)
+	if err != nil || chartCode == "" {
+		return pl, nil, err
+	}
+	mc, err := s.charts.MappedChart(companyName, chartCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	restated, info := restate(pl, mc)
+	return restated, info, nil
+}
+
+// restate rolls company accounts up to the standard accounts they map to.
+// Unmapped accounts keep their own number and type so nothing drops out of the
+// totals; one that collides with a standard account number is prefixed with *.
+func restate(pl *periodLedger, mc *coa.MappedChart) (*periodLedger, *chartInfo) {
+	standard := mc.Chart.AccountMap()
+	info := &chartInfo{code: mc.Chart.Code, name: mc.Chart.Name, unmapped: []string{}, unmappedKeys: map[string]bool{}}
+
+	keyFor := func(account string) string {
+		if target, ok := mc.Targets[account]; ok {
+			return target
+		}
+		key := account
+		if _, clash := standard[key]; clash {
+			key = "*" + account
+		}
+		if !info.unmappedKeys[key] {
+			info.unmappedKeys[key] = true
+			info.unmapped = append(info.unmapped, account)
+		}
+		return key
+	}
+
+	out := &periodLedger{
+		byAccount:  make(map[string]map[int]amounts),
+		unperiod:   make(map[string]amounts),
+		accounts:   append([]ledger.Account{}, mc.Chart.Accounts...),
+		entryCount: pl.entryCount,
+		cal:        pl.cal,
+	}
+	for account, periods := range pl.byAccount {
+		key := keyFor(account)
+		dst, ok := out.byAccount[key]
+		if !ok {
+			dst = make(map[int]amounts)
+			out.byAccount[key] = dst
+		}
+		for idx, amt := range periods {
+			if existing, found := dst[idx]; found {
+				dst[idx] = existing.add(amt)
+			} else {
+				dst[idx] = amt
+			}
+		}
+	}
+	for account, amt := range pl.unperiod {
+		key := keyFor(account)
+		if existing, found := out.unperiod[key]; found {
+			out.unperiod[key] = existing.add(amt)
+		} else {
+			out.unperiod[key] = amt
+		}
+	}
+
+	sort.Strings(info.unmapped)
+	for _, account := range info.unmapped {
+		a, ok := pl.accountMap[account]
+		if !ok {
+			continue // Reported as not in the chart of accounts
+		}
+		if _, clash := standard[account]; clash {
+			a.AccountNo = "*" + account
+		}
+		a.Description += " (unmapped)"
+		a.Parent = ""
+		out.accounts = append(out.accounts, a)
+	}
+	out.accountMap = ledger.AccountMap(out.accounts)
+	return out, info
+}
@@ -0,0 +1,361 @@
+package financials
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/reports"
+)
+
+// EliminationsColumn names the column holding the intercompany eliminations
+const EliminationsColumn = "Eliminations"
+
+// Elimination reverses intercompany balances. Each company's activity in
+// Accounts is reversed period by period; whatever does not net to zero across
+// the companies is posted to OffsetAccount, or reported when there is none.
+type Elimination struct {
+	Name          string   `json:"name"`
+	Accounts      []string `json:"accounts"`       // Accounts on the consolidation chart
+	Companies     []string `json:"companies"`      // Empty applies the rule to every company
+	OffsetAccount string   `json:"offset_account"` // Takes any difference between the companies' balances
+}
+
+// Consolidation is a group of companies reported as one. With a Chart each
+// company is restated through its own account mappings; without one the
+// companies must share account numbers.
+type Consolidation struct {
+	Name         string        `json:"name"`
+	Companies    []string      `json:"companies"`
+	Chart        string        `json:"chart"`
+	Eliminations []Elimination `json:"eliminations"`
+}
+
+// column is one ledger combined into a consolidated report
+type column struct {
+	name string
+	pl   *periodLedger
+}
+
+func zeros(n int) []currency.Currency {
+	z := make([]currency.Currency, n)
+	for i := range z {
+		z[i] = currency.Zero()
+	}
+	return z
+}
+
+func allZero(values []currency.Currency) bool {
+	for _, v := range values {
+		if !v.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// addAll adds src into dst element by element
+func addAll(dst, src []currency.Currency) {
+	for i := range src {
+		dst[i] = dst[i].Add(src[i])
+	}
+}
+
+// post adds an amount to an account's period
+func (pl *periodLedger) post(account string, idx int, amt amounts) {
+	periods, ok := pl.byAccount[account]
+	if !ok {
+		periods = make(map[int]amounts)
+		pl.byAccount[account] = periods
+	}
+	if existing, found := periods[idx]; found {
+		periods[idx] = existing.add(amt)
+	} else {
+		periods[idx] = amt
+	}
+}
+
+// ConsolidatedTrialBalance is the trial balance of a group of companies, with
+// each company's closing balances and the eliminations in Columns. The calendar
+// of companyName, the reporting company, numbers the periods.
+func (s *Service) ConsolidatedTrialBalance(companyName string, c Consolidation, req TrialBalanceRequest) (*TrialBalance, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	pl, cols, chart, warnings, err := s.loadConsolidation(companyName, c)
+	if err != nil {
+		return nil, err
+	}
+	req.Chart = c.Chart
+	tb, err := buildTrialBalance(companyName, req, pl, chart, cols)
+	if err != nil {
+		return nil, err
+	}
+	tb.Warnings = append(tb.Warnings, warnings...)
+	return tb, nil
+}
+
+// ConsolidatedStatement is a financial statement of a group of companies on the
+// reporting company's layout, with each company's amounts and the eliminations
+// in Columns
+func (s *Service) ConsolidatedStatement(companyName string, c Consolidation, req StatementRequest) (*Statement, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	layout, err := s.GetLayout(companyName, req.StatementType, req.LayoutID)
+	if err != nil {
+		return nil, err
+	}
+	pl, cols, chart, warnings, err := s.loadConsolidation(companyName, c)
+	if err != nil {
+		return nil, err
+	}
+	req.Chart = c.Chart
+	st, err := buildStatement(companyName, req, layout, pl, chart, cols)
+	if err != nil {
+		return nil, err
+	}
+	st.Title = "Consolidated " + st.Title
+	st.Warnings = append(st.Warnings, warnings...)
+	return st, nil
+}
+
+// companyLedger loads one company's ledger from its own database, where its
+// calendar and account mappings are kept
+func (s *Service) companyLedger(companyName, member, chartCode string) (*periodLedger, *chartInfo, error) {
+	if member == companyName {
+		return s.loadLedger(member, chartCode)
+	}
+	db, err := database.New(member)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", member, err)
+	}
+	defer db.Close()
+	return NewService(db).loadLedger(member, chartCode)
+}
+
+// loadConsolidation loads every company's ledger, builds the eliminations and
+// returns the combined ledger with a column for each
+func (s *Service) loadConsolidation(companyName string, c Consolidation) (*periodLedger, []column, *chartInfo, []string, error) {
+	if len(c.Companies) == 0 {
+		return nil, nil, nil, nil, fmt.Errorf("a consolidation needs at least one company")
+	}
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	var cols []column
+	var chart *chartInfo
+	if c.Chart != "" {
+		chart = &chartInfo{code: c.Chart, unmapped: []string{}, unmappedKeys: map[string]bool{}}
+	}
+	for _, member := range c.Companies {
+		pl, info, err := s.companyLedger(companyName, member, c.Chart)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("%s: %w", member, err)
+		}
+		if !pl.cal.SameFiscalYear(cal) {
+			return nil, nil, nil, nil, fmt.Errorf("%s uses a different fiscal year from %s, so its periods cannot be combined", member, companyName)
+		}
+		if info != nil {
+			chart.name = info.name
+			for _, account := range info.unmapped {
+				chart.unmapped = append(chart.unmapped, member+": "+account)
+			}
+			for key := range info.unmappedKeys {
+				chart.unmappedKeys[key] = true
+			}
+		}
+		cols = append(cols, column{name: member, pl: pl})
+	}
+
+	combined := &periodLedger{
+		byAccount: make(map[string]map[int]amounts),
+		unperiod:  make(map[string]amounts),
+		cal:       cal,
+	}
+	seen := make(map[string]bool)
+	for _, col := range cols {
+		for _, a := range col.pl.accounts {
+			if !seen[a.AccountNo] {
+				seen[a.AccountNo] = true
+				combined.accounts = append(combined.accounts, a)
+			}
+		}
+	}
+	combined.accountMap = ledger.AccountMap(combined.accounts)
+
+	var warnings []string
+	if len(c.Eliminations) > 0 {
+		elim, elimWarnings, err := eliminate(cols, c.Eliminations, combined.accountMap, cal)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		warnings = elimWarnings
+		cols = append(cols, column{name: EliminationsColumn, pl: elim})
+	}
+
+	for _, col := range cols {
+		for account, periods := range col.pl.byAccount {
+			for idx, amt := range periods {
+				combined.post(account, idx, amt)
+			}
+		}
+		for account, amt := range col.pl.unperiod {
+			if existing, found := combined.unperiod[account]; found {
+				combined.unperiod[account] = existing.add(amt)
+			} else {
+				combined.unperiod[account] = amt
+			}
+		}
+		combined.entryCount += col.pl.entryCount
+	}
+	return combined, cols, chart, warnings, nil
+}
+
+// eliminate builds the eliminations ledger: each rule's accounts reversed for
+// the companies it covers, with the difference posted to its offset account
+func eliminate(cols []column, rules []Elimination, accounts map[string]ledger.Account, cal *ledger.Calendar) (*periodLedger, []string, error) {
+	elim := &periodLedger{
+		byAccount: make(map[string]map[int]amounts),
+		unperiod:  make(map[string]amounts),
+		cal:       cal,
+	}
+	byName := make(map[string]*periodLedger, len(cols))
+	for _, col := range cols {
+		byName[col.name] = col.pl
+	}
+
+	var warnings []string
+	for _, rule := range rules {
+		companies := rule.Companies
+		if len(companies) == 0 {
+			companies = make([]string, 0, len(cols))
+			for _, col := range cols {
+				companies = append(companies, col.name)
+			}
+		}
+		for _, account := range append(append([]string{}, rule.Accounts...), rule.OffsetAccount) {
+			if _, ok := accounts[account]; !ok && account != "" {
+				warnings = append(warnings, fmt.Sprintf("Elimination %q: account %s is not in the chart of accounts", rule.Name, account))
+			}
+		}
+
+		difference := make(map[int]currency.Currency)
+		for _, name := range companies {
+			pl, ok := byName[name]
+			if !ok {
+				return nil, nil, fmt.Errorf("elimination %q covers %s, which is not in the consolidation", rule.Name, name)
+			}
+			for _, account := range rule.Accounts {
+				for idx, amt := range pl.byAccount[account] {
+					elim.post(account, idx, amounts{debits: amt.credits, credits: amt.debits})
+					if d, found := difference[idx]; found {
+						difference[idx] = d.Add(amt.net())
+					} else {
+						difference[idx] = amt.net()
+					}
+				}
+			}
+		}
+
+		unmatched := currency.Zero()
+		for idx, d := range difference {
+			if d.IsZero() {
+				continue
+			}
+			unmatched = unmatched.Add(d)
+			if rule.OffsetAccount == "" {
+				continue
+			}
+			if d.IsPositive() {
+				elim.post(rule.OffsetAccount, idx, amounts{debits: d, credits: currency.Zero()})
+			} else {
+				elim.post(rule.OffsetAccount, idx, amounts{debits: currency.Zero(), credits: d.Neg()})
+			}
+		}
+		if !unmatched.IsZero() && rule.OffsetAccount == "" {
+			warnings = append(warnings, fmt.Sprintf("Elimination %q leaves %s unmatched between the companies and has no offset account",
+				rule.Name, reports.FormatAmount(unmatched.ToFloat64())))
+		}
+	}
+	return elim, warnings, nil
+}
+
+// columnHeader is how a consolidation column is titled on printed reports
+func columnHeader(name string) string {
+	if name == EliminationsColumn {
+		return name
+	}
+	return reports.CompanyDisplayName(name)
+}
+
+// consolidatedColumns shares the landscape page between the leading columns, an
+// amount column for each consolidation column, the consolidated amount and any
+// trailing columns
+func consolidatedColumns(leading []reports.Column, names []string, trailing ...string) []reports.Column {
+	used := 0.0
+	for _, c := range leading {
+		used += c.Width
+	}
+	headers := make([]string, 0, len(names)+1+len(trailing))
+	for _, name := range names {
+		headers = append(headers, columnHeader(name))
+	}
+	headers = append(headers, "Consolidated")
+	headers = append(headers, trailing...)
+
+	width := (259 - used) / float64(len(headers))
+	columns := append([]reports.Column{}, leading...)
+	for _, h := range headers {
+		columns = append(columns, reports.Column{Header: h, Width: width, Align: "R"})
+	}
+	return columns
+}
+
+// consolidatedTrialBalanceTable prints closing balances by company
+func consolidatedTrialBalanceTable(tb *TrialBalance, displayName string) *reports.Table {
+	subtitle := fmt.Sprintf("Closing balances, periods %s through %s", tb.From, tb.To)
+	if tb.From == tb.To {
+		subtitle = fmt.Sprintf("Closing balances, period %s", tb.From)
+	}
+	t := &reports.Table{
+		CompanyName: displayName,
+		Title:       "Consolidated Trial Balance",
+		Subtitles:   []string{subtitle, "Companies: " + strings.Join(memberHeaders(tb.Columns), ", ")},
+	}
+	if tb.ChartName != "" {
+		t.Subtitles = append(t.Subtitles, "Restated on the "+tb.ChartName+" chart")
+	}
+	t.Columns = consolidatedColumns([]reports.Column{{Header: "Account", Width: 20}, {Header: "Description", Width: 50}}, tb.Columns)
+
+	for _, l := range tb.Lines {
+		cells := []string{l.AccountNumber, l.Description}
+		for _, bal := range l.Columns {
+			cells = append(cells, reports.FormatAmount(bal))
+		}
+		t.AddRow(append(cells, reports.FormatAmount(l.ClosingBalance))...)
+	}
+	totals := []string{"", "Total"}
+	for _, total := range tb.ColumnTotals {
+		totals = append(totals, reports.FormatAmount(total))
+	}
+	t.AddTotal(append(totals, reports.FormatAmount(tb.TotalClosing))...)
+
+	t.Notes = append(t.Notes, tb.Warnings...)
+	return t
+}
+
+// memberHeaders returns the titles of the company columns, leaving out the eliminations
+func memberHeaders(names []string) []string {
+	var headers []string
+	for _, name := range names {
+		if name != EliminationsColumn {
+			headers = append(headers, columnHeader(name))
+		}
+	}
+	return headers
+}
@@ -0,0 +1,154 @@
+// Package financials produces the general ledger reports - trial balance,
+// financial statements and GL detail - from GLMASTER.dbf and COA.dbf. Trial
+// balances and statements can also be restated on a standard chart through
+// the company's account mappings.
+package financials
+
+import (
+	"github.com/pivoten/financialsx/desktop/internal/coa"
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/periods"
+)
+
+// Service provides financial reporting operations
+type Service struct {
+	db      *database.DB
+	periods *periods.Service
+	charts  *coa.Service
+}
+
+// NewService creates a new financials service
+func NewService(db *database.DB) *Service {
+	return &Service{db: db, periods: periods.NewService(db), charts: coa.NewService(db)}
+}
+
+// amounts holds debit and credit totals
+type amounts struct {
+	debits  currency.Currency
+	credits currency.Currency
+}
+
+func (a amounts) net() currency.Currency {
+	return a.debits.Sub(a.credits)
+}
+
+func (a amounts) add(o amounts) amounts {
+	return amounts{debits: a.debits.Add(o.debits), credits: a.credits.Add(o.credits)}
+}
+
+func zeroAmounts() amounts {
+	return amounts{debits: currency.Zero(), credits: currency.Zero()}
+}
+
+// periodLedger is GLMASTER summarized by account and fiscal period so that any
+// period range can be totalled without rescanning the DBF
+type periodLedger struct {
+	byAccount  map[string]map[int]amounts // account -> period index -> totals
+	unperiod   map[string]amounts         // entries with no year/period or date
+	accounts   []ledger.Account
+	accountMap map[string]ledger.Account
+	entryCount int
+	cal        *ledger.Calendar
+}
+
+// loadPeriodLedger reads COA.dbf and GLMASTER.dbf (all records) and summarizes
+// the GL by account and fiscal period of the company's calendar
+func (s *Service) loadPeriodLedger(companyName string) (*periodLedger, error) {
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ledger.LoadGLEntries(companyName)
+	if err != nil {
+		return nil, err
+	}
+
+	pl := &periodLedger{
+		byAccount:  make(map[string]map[int]amounts),
+		unperiod:   make(map[string]amounts),
+		accounts:   accounts,
+		accountMap: ledger.AccountMap(accounts),
+		entryCount: len(entries),
+		cal:        cal,
+	}
+	for _, e := range entries {
+		if e.AccountNo == "" {
+			continue
+		}
+		amt := amounts{debits: currency.NewFromFloat(e.Debit), credits: currency.NewFromFloat(e.Credit)}
+		p, ok := e.FiscalPeriodIn(cal)
+		if !ok {
+			if existing, found := pl.unperiod[e.AccountNo]; found {
+				pl.unperiod[e.AccountNo] = existing.add(amt)
+			} else {
+				pl.unperiod[e.AccountNo] = amt
+			}
+			continue
+		}
+		// Closing entries must not zero out the closed year's own statements
+		if e.IsClosingEntry() {
+			p.Period = ledger.ClosingPeriod
+		}
+		periods, found := pl.byAccount[e.AccountNo]
+		if !found {
+			periods = make(map[int]amounts)
+			pl.byAccount[e.AccountNo] = periods
+		}
+		if existing, found := periods[p.Index()]; found {
+			periods[p.Index()] = existing.add(amt)
+		} else {
+			periods[p.Index()] = amt
+		}
+	}
+	return pl, nil
+}
+
+// sum totals an account's activity for periods in [from, to]. A zero from means
+// "since the beginning".
+func (pl *periodLedger) sum(account string, from, to ledger.Period) amounts {
+	total := zeroAmounts()
+	for idx, amt := range pl.byAccount[account] {
+		if (!from.IsZero() && idx < from.Index()) || idx > to.Index() {
+			continue
+		}
+		total = total.add(amt)
+	}
+	return total
+}
+
+// before totals an account's activity for every period before p, including
+// closing entries of the years that ended before it
+func (pl *periodLedger) before(account string, p ledger.Period) amounts {
+	total := zeroAmounts()
+	for idx, amt := range pl.byAccount[account] {
+		if idx < p.Index() {
+			total = total.add(amt)
+		}
+	}
+	return total
+}
+
+// accountNumbers returns every account in COA plus any GL account missing from COA
+func (pl *periodLedger) accountNumbers() []string {
+	seen := make(map[string]bool)
+	var numbers []string
+	for _, a := range pl.accounts {
+		if !seen[a.AccountNo] {
+			seen[a.AccountNo] = true
+			numbers = append(numbers, a.AccountNo)
+		}
+	}
+	for acct := range pl.byAccount {
+		if !seen[acct] {
+			seen[acct] = true
+			numbers = append(numbers, acct)
+		}
+	}
+	return numbers
+}
@@ -0,0 +1,418 @@
+package financials
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/reports"
+)
+
+// GLDetailRequest selects GL activity. Use either the date range or the period
+// range; the period range wins when both are set. Empty filters match everything.
+type GLDetailRequest struct {
+	AccountFrom string        `json:"account_from"`
+	AccountTo   string        `json:"account_to"`
+	StartDate   time.Time     `json:"start_date"`
+	EndDate     time.Time     `json:"end_date"`
+	FromPeriod  ledger.Period `json:"from_period"`
+	ToPeriod    ledger.Period `json:"to_period"`
+	UnitNo      string        `json:"unit_no"`
+	DeptNo      string        `json:"dept_no"`
+	AFENo       string        `json:"afe_no"`
+}
+
+func (r GLDetailRequest) byPeriod() bool {
+	return !r.FromPeriod.IsZero() || !r.ToPeriod.IsZero()
+}
+
+// GLDetailLine is one GLMASTER row with the running balance after it
+type GLDetailLine struct {
+	RowIndex       int           `json:"row_index"`
+	Date           *time.Time    `json:"date"`
+	Period         ledger.Period `json:"period"`
+	Batch          string        `json:"batch"`
+	Source         string        `json:"source"`
+	Reference      string        `json:"reference"`
+	Description    string        `json:"description"`
+	UnitNo         string        `json:"unit_no"`
+	DeptNo         string        `json:"dept_no"`
+	AFENo          string        `json:"afe_no"`
+	Debit          float64       `json:"debit"`
+	Credit         float64       `json:"credit"`
+	RunningBalance float64       `json:"running_balance"`
+	CIDCHEC        string        `json:"cidchec,omitempty"`
+	CheckNumber    string        `json:"check_number,omitempty"` // From CHECKS.dbf when CIDCHEC links to a check
+	Payee          string        `json:"payee,omitempty"`
+}
+
+// GLDetailAccount is the activity for one account
+type GLDetailAccount struct {
+	AccountNumber  string         `json:"account_number"`
+	Description    string         `json:"description"`
+	AccountType    int            `json:"account_type"`
+	OpeningBalance float64        `json:"opening_balance"`
+	TotalDebits    float64        `json:"total_debits"`
+	TotalCredits   float64        `json:"total_credits"`
+	ClosingBalance float64        `json:"closing_balance"`
+	Lines          []GLDetailLine `json:"lines"`
+}
+
+// GLDetail is the GL detail report
+type GLDetail struct {
+	CompanyName  string            `json:"company_name"`
+	Request      GLDetailRequest   `json:"request"`
+	Accounts     []GLDetailAccount `json:"accounts"`
+	LineCount    int               `json:"line_count"`
+	TotalDebits  float64           `json:"total_debits"`
+	TotalCredits float64           `json:"total_credits"`
+	Warnings     []string          `json:"warnings"`
+	GeneratedAt  time.Time         `json:"generated_at"`
+}
+
+// glPosition classifies an entry against the requested range
+type glPosition int
+
+const (
+	glBefore glPosition = iota
+	glInRange
+	glAfter
+	glUnplaced // No date (date range) or no period (period range)
+)
+
+func (r GLDetailRequest) position(cal *ledger.Calendar, e ledger.GLEntry) glPosition {
+	if r.byPeriod() {
+		p, ok := e.FiscalPeriodIn(cal)
+		if !ok {
+			return glUnplaced
+		}
+		if !r.FromPeriod.IsZero() && p.Before(r.FromPeriod) {
+			return glBefore
+		}
+		if !r.ToPeriod.IsZero() && r.ToPeriod.Before(p) {
+			return glAfter
+		}
+		return glInRange
+	}
+	if e.Date.IsZero() {
+		return glUnplaced
+	}
+	if !r.StartDate.IsZero() && e.Date.Before(r.StartDate) {
+		return glBefore
+	}
+	if !r.EndDate.IsZero() && e.Date.After(r.EndDate) {
+		return glAfter
+	}
+	return glInRange
+}
+
+// inStartYear reports whether an entry before the range falls in the same
+// fiscal year as the range start. Income statement accounts only carry that
+// part of their history into the opening balance.
+func (r GLDetailRequest) inStartYear(cal *ledger.Calendar, e ledger.GLEntry) bool {
+	if r.byPeriod() {
+		p, ok := e.FiscalPeriodIn(cal)
+		return ok && p.Year == r.FromPeriod.Year
+	}
+	return cal.PeriodForDate(e.Date).Year == cal.PeriodForDate(r.StartDate).Year
+}
+
+// GLDetail lists GLMASTER activity per account with opening and running balances
+func (s *Service) GLDetail(companyName string, req GLDetailRequest) (*GLDetail, error) {
+	if req.AccountTo == "" {
+		req.AccountTo = req.AccountFrom
+	}
+	if req.AccountFrom != "" && req.AccountTo < req.AccountFrom {
+		return nil, fmt.Errorf("account range %s - %s is reversed", req.AccountFrom, req.AccountTo)
+	}
+	if req.byPeriod() && !req.FromPeriod.IsZero() && !req.ToPeriod.IsZero() && req.ToPeriod.Before(req.FromPeriod) {
+		return nil, fmt.Errorf("period %s is after %s", req.FromPeriod, req.ToPeriod)
+	}
+	if !req.byPeriod() && !req.StartDate.IsZero() && !req.EndDate.IsZero() && req.EndDate.Before(req.StartDate) {
+		return nil, fmt.Errorf("start date is after end date")
+	}
+
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, err
+	}
+	accountMap := ledger.AccountMap(accounts)
+	entries, err := ledger.LoadGLEntries(companyName)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+
+	opening := make(map[string]currency.Currency)
+	inRange := make(map[string][]ledger.GLEntry)
+	unplaced := 0
+	for _, e := range entries {
+		if e.AccountNo == "" {
+			continue
+		}
+		if req.AccountFrom != "" && (e.AccountNo < req.AccountFrom || e.AccountNo > req.AccountTo) {
+			continue
+		}
+		if (req.UnitNo != "" && !strings.EqualFold(e.UnitNo, req.UnitNo)) ||
+			(req.DeptNo != "" && !strings.EqualFold(e.DeptNo, req.DeptNo)) ||
+			(req.AFENo != "" && !strings.EqualFold(e.AFENo, req.AFENo)) {
+			continue
+		}
+
+		switch req.position(cal, e) {
+		case glBefore:
+			// Accounts missing from COA are treated as TypeOther, as in the trial balance
+			if !ledger.IsBalanceSheet(accountMap[e.AccountNo].Type) && !req.inStartYear(cal, e) {
+				continue
+			}
+			if bal, found := opening[e.AccountNo]; found {
+				opening[e.AccountNo] = bal.Add(e.Net())
+			} else {
+				opening[e.AccountNo] = e.Net()
+			}
+		case glInRange:
+			inRange[e.AccountNo] = append(inRange[e.AccountNo], e)
+		case glUnplaced:
+			unplaced++
+		}
+	}
+
+	// CIDCHEC links GL lines back to CHECKS.dbf
+	checksByID := make(map[string]ledger.Check)
+	for _, lines := range inRange {
+		hasLink := false
+		for _, e := range lines {
+			if e.CIDCHEC != "" {
+				hasLink = true
+				break
+			}
+		}
+		if hasLink {
+			if checks, err := ledger.LoadChecks(companyName); err == nil {
+				for _, c := range checks {
+					if c.CIDCHEC != "" {
+						checksByID[c.CIDCHEC] = c
+					}
+				}
+			}
+			break
+		}
+	}
+
+	numbers := make([]string, 0, len(inRange))
+	for number := range inRange {
+		numbers = append(numbers, number)
+	}
+	for number, bal := range opening {
+		if _, ok := inRange[number]; !ok && !bal.IsZero() {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Strings(numbers)
+
+	detail := &GLDetail{
+		CompanyName: companyName,
+		Request:     req,
+		Accounts:    []GLDetailAccount{},
+		Warnings:    []string{},
+		GeneratedAt: time.Now(),
+	}
+	grandDebits, grandCredits := currency.Zero(), currency.Zero()
+
+	for _, number := range numbers {
+		acct, ok := accountMap[number]
+		if !ok {
+			acct = ledger.Account{AccountNo: number, Type: ledger.TypeOther, Description: "(not in chart of accounts)"}
+		}
+		lines := inRange[number]
+		sort.SliceStable(lines, func(i, j int) bool {
+			if req.byPeriod() {
+				pi, _ := lines[i].FiscalPeriodIn(cal)
+				pj, _ := lines[j].FiscalPeriodIn(cal)
+				if pi != pj {
+					return pi.Before(pj)
+				}
+			}
+			if !lines[i].Date.Equal(lines[j].Date) {
+				return lines[i].Date.Before(lines[j].Date)
+			}
+			if lines[i].Batch != lines[j].Batch {
+				return lines[i].Batch < lines[j].Batch
+			}
+			return lines[i].RowIndex < lines[j].RowIndex
+		})
+
+		balance, ok := opening[number]
+		if !ok {
+			balance = currency.Zero()
+		}
+		da := GLDetailAccount{
+			AccountNumber:  number,
+			Description:    acct.Description,
+			AccountType:    acct.Type,
+			OpeningBalance: balance.ToFloat64(),
+			Lines:          make([]GLDetailLine, 0, len(lines)),
+		}
+		debits, credits := currency.Zero(), currency.Zero()
+		for _, e := range lines {
+			balance = balance.Add(e.Net())
+			debits = debits.Add(currency.NewFromFloat(e.Debit))
+			credits = credits.Add(currency.NewFromFloat(e.Credit))
+
+			line := GLDetailLine{
+				RowIndex:       e.RowIndex,
+				Batch:          e.Batch,
+				Source:         e.Source,
+				Reference:      e.Reference,
+				Description:    e.Description,
+				UnitNo:         e.UnitNo,
+				DeptNo:         e.DeptNo,
+				AFENo:          e.AFENo,
+				Debit:          e.Debit,
+				Credit:         e.Credit,
+				RunningBalance: balance.ToFloat64(),
+				CIDCHEC:        e.CIDCHEC,
+			}
+			if !e.Date.IsZero() {
+				d := e.Date
+				line.Date = &d
+			}
+			line.Period, _ = e.FiscalPeriodIn(cal)
+			if c, ok := checksByID[e.CIDCHEC]; ok && e.CIDCHEC != "" {
+				line.CheckNumber, line.Payee = c.CheckNumber, c.Payee
+			}
+			da.Lines = append(da.Lines, line)
+		}
+		da.TotalDebits = debits.ToFloat64()
+		da.TotalCredits = credits.ToFloat64()
+		da.ClosingBalance = balance.ToFloat64()
+
+		detail.Accounts = append(detail.Accounts, da)
+		detail.LineCount += len(da.Lines)
+		grandDebits, grandCredits = grandDebits.Add(debits), grandCredits.Add(credits)
+	}
+	detail.TotalDebits = grandDebits.ToFloat64()
+	detail.TotalCredits = grandCredits.ToFloat64()
+
+	if unplaced > 0 {
+		what := "date"
+		if req.byPeriod() {
+			what = "year/period or date"
+		}
+		detail.Warnings = append(detail.Warnings, fmt.Sprintf("%d matching GL entries have no %s and are excluded", unplaced, what))
+	}
+	return detail, nil
+}
+
+// GLDetailTable converts GL detail for PDF/CSV output
+func GLDetailTable(d *GLDetail, displayName string) *reports.Table {
+	req := d.Request
+	var subtitles []string
+	switch {
+	case req.AccountFrom == "":
+		subtitles = append(subtitles, "All accounts")
+	case req.AccountFrom == req.AccountTo:
+		subtitles = append(subtitles, "Account "+req.AccountFrom)
+	default:
+		subtitles = append(subtitles, fmt.Sprintf("Accounts %s through %s", req.AccountFrom, req.AccountTo))
+	}
+	if req.byPeriod() {
+		subtitles = append(subtitles, fmt.Sprintf("Periods %s through %s", periodLabel(req.FromPeriod, "beginning"), periodLabel(req.ToPeriod, "latest")))
+	} else {
+		subtitles = append(subtitles, fmt.Sprintf("%s through %s", dateLabel(req.StartDate, "Beginning"), dateLabel(req.EndDate, "latest")))
+	}
+	var filters []string
+	if req.UnitNo != "" {
+		filters = append(filters, "Unit "+req.UnitNo)
+	}
+	if req.DeptNo != "" {
+		filters = append(filters, "Dept "+req.DeptNo)
+	}
+	if req.AFENo != "" {
+		filters = append(filters, "AFE "+req.AFENo)
+	}
+	if len(filters) > 0 {
+		subtitles = append(subtitles, strings.Join(filters, ", "))
+	}
+
+	t := &reports.Table{
+		CompanyName: displayName,
+		Title:       "General Ledger Detail",
+		Subtitles:   subtitles,
+		Columns: []reports.Column{
+			{Header: "Date", Width: 18, Align: "C"},
+			{Header: "Period", Width: 15, Align: "C"},
+			{Header: "Batch", Width: 22},
+			{Header: "Src", Width: 10},
+			{Header: "Reference", Width: 22},
+			{Header: "Description", Width: 60},
+			{Header: "Unit/Dept/AFE", Width: 26},
+			{Header: "Debit", Width: 28, Align: "R"},
+			{Header: "Credit", Width: 28, Align: "R"},
+			{Header: "Balance", Width: 30, Align: "R"},
+		},
+	}
+
+	for _, a := range d.Accounts {
+		t.AddHeading(fmt.Sprintf("%s  %s", a.AccountNumber, a.Description))
+		t.AddRow("", "", "", "", "", "Opening balance", "", "", "", reports.FormatAmount(a.OpeningBalance))
+		for _, l := range a.Lines {
+			date := ""
+			if l.Date != nil {
+				date = l.Date.Format("01/02/2006")
+			}
+			period := ""
+			if !l.Period.IsZero() {
+				period = l.Period.String()
+			}
+			reference := l.Reference
+			if l.CheckNumber != "" {
+				reference = "Chk " + l.CheckNumber
+			}
+			description := l.Description
+			if description == "" {
+				description = l.Payee
+			}
+			var segments []string
+			for _, s := range []string{l.UnitNo, l.DeptNo, l.AFENo} {
+				if s != "" {
+					segments = append(segments, s)
+				}
+			}
+			t.AddRow(date, period, l.Batch, l.Source, reference, description, strings.Join(segments, "/"),
+				amountOrBlank(l.Debit), amountOrBlank(l.Credit), reports.FormatAmount(l.RunningBalance))
+		}
+		t.AddTotal("", "", "", "", "", "Total "+a.AccountNumber, "", reports.FormatAmount(a.TotalDebits),
+			reports.FormatAmount(a.TotalCredits), reports.FormatAmount(a.ClosingBalance))
+	}
+	t.AddTotal("", "", "", "", "", "Report total", "", reports.FormatAmount(d.TotalDebits), reports.FormatAmount(d.TotalCredits), "")
+	t.Notes = append(t.Notes, d.Warnings...)
+	return t
+}
+
+func amountOrBlank(f float64) string {
+	if f == 0 {
+		return ""
+	}
+	return reports.FormatAmount(f)
+}
+
+func periodLabel(p ledger.Period, empty string) string {
+	if p.IsZero() {
+		return empty
+	}
+	return p.String()
+}
+
+func dateLabel(d time.Time, empty string) string {
+	if d.IsZero() {
+		return empty
+	}
+	return d.Format("01/02/2006")
+}
@@ -0,0 +1,344 @@
+package financials
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Statement types
+const (
+	StatementBalanceSheet    = "balance_sheet"
+	StatementIncomeStatement = "income_statement"
+)
+
+// Layout line kinds
+const (
+	LineHeading          = "heading"           // Label only
+	LineAccounts         = "accounts"          // Accounts selected by type and/or number range
+	LineTotal            = "total"             // Sum of other lines
+	LineNetIncome        = "net_income"        // Current fiscal year net income (balance sheet)
+	LineRetainedEarnings = "retained_earnings" // Selected accounts plus prior years' income not closed
+	LineBlank            = "blank"
+)
+
+// Account display modes for accounts lines
+const (
+	DisplayDetail  = "detail"  // One row per account
+	DisplayParent  = "parent"  // Accounts rolled up to their top CPARENT
+	DisplaySummary = "summary" // The line total only
+)
+
+// AccountRange selects account numbers between From and To inclusive (string compare)
+type AccountRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Contains reports whether an account number is in the range
+func (r AccountRange) Contains(account string) bool {
+	to := r.To
+	if to == "" {
+		to = r.From
+	}
+	return account >= r.From && account <= to
+}
+
+// LayoutLine is one line of a statement layout. Amounts are shown with the
+// line's normal sign: "debit" lines show debit balances as positive, "credit"
+// lines show credit balances as positive. Total lines add the displayed amounts
+// of the lines named in Sum; an ID prefixed with "-" is subtracted.
+type LayoutLine struct {
+	ID           string         `json:"id"`
+	Label        string         `json:"label"`
+	Kind         string         `json:"kind"`
+	AccountTypes []int          `json:"account_types,omitempty"`
+	Ranges       []AccountRange `json:"ranges,omitempty"`
+	Display      string         `json:"display,omitempty"`
+	Sign         string         `json:"sign,omitempty"`
+	Sum          []string       `json:"sum,omitempty"`
+	Indent       int            `json:"indent,omitempty"`
+}
+
+// matches reports whether an accounts or retained earnings line selects an account
+func (l LayoutLine) matches(a ledger.Account) bool {
+	if len(l.AccountTypes) == 0 && len(l.Ranges) == 0 {
+		return false
+	}
+	if len(l.AccountTypes) > 0 {
+		found := false
+		for _, t := range l.AccountTypes {
+			if t == a.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(l.Ranges) > 0 {
+		for _, r := range l.Ranges {
+			if r.Contains(a.AccountNo) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Layout is a stored statement format definition
+type Layout struct {
+	ID            int          `json:"id"` // 0 for the built-in default
+	CompanyName   string       `json:"company_name"`
+	StatementType string       `json:"statement_type"`
+	Name          string       `json:"name"`
+	Lines         []LayoutLine `json:"lines"`
+	IsDefault     bool         `json:"is_default"`
+	CreatedBy     string       `json:"created_by"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// Validate checks line kinds, unique IDs and that totals only reference earlier lines
+func (l *Layout) Validate() error {
+	if l.StatementType != StatementBalanceSheet && l.StatementType != StatementIncomeStatement {
+		return fmt.Errorf("invalid statement type: %s", l.StatementType)
+	}
+	if strings.TrimSpace(l.Name) == "" {
+		return fmt.Errorf("layout name is required")
+	}
+	if len(l.Lines) == 0 {
+		return fmt.Errorf("layout has no lines")
+	}
+
+	seen := make(map[string]bool)
+	for i, line := range l.Lines {
+		where := fmt.Sprintf("line %d (%s)", i+1, line.Label)
+		switch line.Kind {
+		case LineHeading, LineBlank:
+		case LineAccounts:
+			if len(line.AccountTypes) == 0 && len(line.Ranges) == 0 {
+				return fmt.Errorf("%s selects no accounts", where)
+			}
+		case LineRetainedEarnings, LineNetIncome:
+			if l.StatementType != StatementBalanceSheet {
+				return fmt.Errorf("%s: %s lines are only allowed on the balance sheet", where, line.Kind)
+			}
+		case LineTotal:
+			if len(line.Sum) == 0 {
+				return fmt.Errorf("%s does not sum any lines", where)
+			}
+			for _, ref := range line.Sum {
+				if !seen[strings.TrimPrefix(ref, "-")] {
+					return fmt.Errorf("%s references %q which is not an earlier line", where, ref)
+				}
+			}
+		default:
+			return fmt.Errorf("%s has unknown kind %q", where, line.Kind)
+		}
+		switch line.Display {
+		case "", DisplayDetail, DisplayParent, DisplaySummary:
+		default:
+			return fmt.Errorf("%s has unknown display %q", where, line.Display)
+		}
+		switch line.Sign {
+		case "", "debit", "credit":
+		default:
+			return fmt.Errorf("%s has unknown sign %q", where, line.Sign)
+		}
+		if line.ID != "" {
+			if seen[line.ID] {
+				return fmt.Errorf("%s: duplicate line id %q", where, line.ID)
+			}
+			seen[line.ID] = true
+		}
+	}
+	return nil
+}
+
+// DefaultLayout returns the built-in layout for a statement type
+func DefaultLayout(statementType string) *Layout {
+	if statementType == StatementIncomeStatement {
+		return &Layout{
+			StatementType: StatementIncomeStatement,
+			Name:          "Standard",
+			IsDefault:     true,
+			Lines: []LayoutLine{
+				{Label: "Revenue", Kind: LineHeading},
+				{ID: "revenue", Label: "Total Revenue", Kind: LineAccounts, AccountTypes: []int{ledger.TypeRevenue}, Display: DisplayDetail, Sign: "credit"},
+				{Kind: LineBlank},
+				{Label: "Expenses", Kind: LineHeading},
+				{ID: "expenses", Label: "Operating Expenses", Kind: LineAccounts, AccountTypes: []int{ledger.TypeExpense}, Display: DisplayDetail, Sign: "debit"},
+				{ID: "other", Label: "Other", Kind: LineAccounts, AccountTypes: []int{ledger.TypeOther}, Display: DisplayDetail, Sign: "debit"},
+				{ID: "total_expenses", Label: "Total Expenses", Kind: LineTotal, Sum: []string{"expenses", "other"}},
+				{Kind: LineBlank},
+				{ID: "net_income", Label: "Net Income", Kind: LineTotal, Sum: []string{"revenue", "-total_expenses"}},
+			},
+		}
+	}
+	return &Layout{
+		StatementType: StatementBalanceSheet,
+		Name:          "Standard",
+		IsDefault:     true,
+		Lines: []LayoutLine{
+			{Label: "Assets", Kind: LineHeading},
+			{ID: "assets", Label: "Total Assets", Kind: LineAccounts, AccountTypes: []int{ledger.TypeAsset}, Display: DisplayDetail, Sign: "debit"},
+			{Kind: LineBlank},
+			{Label: "Liabilities", Kind: LineHeading},
+			{ID: "liabilities", Label: "Total Liabilities", Kind: LineAccounts, AccountTypes: []int{ledger.TypeLiability}, Display: DisplayDetail, Sign: "credit"},
+			{Kind: LineBlank},
+			{Label: "Equity", Kind: LineHeading},
+			{ID: "equity", Label: "Equity Accounts", Kind: LineAccounts, AccountTypes: []int{ledger.TypeEquity}, Display: DisplayDetail, Sign: "credit"},
+			{ID: "retained_earnings", Label: "Retained Earnings - Prior Years", Kind: LineRetainedEarnings, Sign: "credit", Indent: 1},
+			{ID: "net_income", Label: "Current Year Net Income", Kind: LineNetIncome, Sign: "credit", Indent: 1},
+			{ID: "total_equity", Label: "Total Equity", Kind: LineTotal, Sum: []string{"equity", "retained_earnings", "net_income"}},
+			{Kind: LineBlank},
+			{ID: "total_liabilities_equity", Label: "Total Liabilities and Equity", Kind: LineTotal, Sum: []string{"liabilities", "total_equity"}},
+		},
+	}
+}
+
+// GetLayouts returns the stored layouts for a statement type, built-in default first
+func (s *Service) GetLayouts(companyName, statementType string) ([]Layout, error) {
+	layouts := []Layout{*DefaultLayout(statementType)}
+
+	rows, err := s.db.Query(`
+		SELECT id, company_name, statement_type, name, definition, is_default, created_by, updated_at
+		FROM statement_layouts
+		WHERE company_name = ? AND statement_type = ?
+		ORDER BY name
+	`, companyName, statementType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement layouts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		layout, err := scanLayout(rows)
+		if err != nil {
+			return nil, err
+		}
+		if layout.IsDefault {
+			layouts[0].IsDefault = false
+		}
+		layouts = append(layouts, *layout)
+	}
+	return layouts, rows.Err()
+}
+
+// GetLayout returns a stored layout, or the company's default when id is 0
+func (s *Service) GetLayout(companyName, statementType string, id int) (*Layout, error) {
+	query := `
+		SELECT id, company_name, statement_type, name, definition, is_default, created_by, updated_at
+		FROM statement_layouts
+		WHERE company_name = ? AND statement_type = ? AND `
+	args := []interface{}{companyName, statementType}
+	if id > 0 {
+		query += `id = ?`
+		args = append(args, id)
+	} else {
+		query += `is_default = 1 LIMIT 1`
+	}
+
+	layout, err := scanLayout(s.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		if id > 0 {
+			return nil, fmt.Errorf("statement layout %d not found", id)
+		}
+		return DefaultLayout(statementType), nil
+	}
+	return layout, err
+}
+
+// SaveLayout creates or updates a layout. Marking a layout default clears the
+// flag on the company's other layouts of the same type.
+func (s *Service) SaveLayout(layout *Layout, username string) (*Layout, error) {
+	if err := layout.Validate(); err != nil {
+		return nil, err
+	}
+	definition, err := json.Marshal(layout.Lines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode layout: %w", err)
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if layout.IsDefault {
+		if _, err := tx.Exec(`UPDATE statement_layouts SET is_default = 0 WHERE company_name = ? AND statement_type = ?`,
+			layout.CompanyName, layout.StatementType); err != nil {
+			return nil, fmt.Errorf("failed to clear default layout: %w", err)
+		}
+	}
+
+	if layout.ID > 0 {
+		result, err := tx.Exec(`
+			UPDATE statement_layouts
+			SET name = ?, definition = ?, is_default = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND company_name = ?
+		`, layout.Name, string(definition), layout.IsDefault, username, layout.ID, layout.CompanyName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update layout: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return nil, fmt.Errorf("statement layout %d not found", layout.ID)
+		}
+	} else {
+		result, err := tx.Exec(`
+			INSERT INTO statement_layouts (company_name, statement_type, name, definition, is_default, created_by, updated_by)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, layout.CompanyName, layout.StatementType, layout.Name, string(definition), layout.IsDefault, username, username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save layout: %w", err)
+		}
+		id, _ := result.LastInsertId()
+		layout.ID = int(id)
+		layout.CreatedBy = username
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit layout: %w", err)
+	}
+	layout.UpdatedAt = time.Now()
+	return layout, nil
+}
+
+// DeleteLayout removes a stored layout
+func (s *Service) DeleteLayout(companyName string, id int) error {
+	result, err := s.db.Exec(`DELETE FROM statement_layouts WHERE id = ? AND company_name = ?`, id, companyName)
+	if err != nil {
+		return fmt.Errorf("failed to delete layout: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("statement layout %d not found", id)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLayout(row rowScanner) (*Layout, error) {
+	var layout Layout
+	var definition string
+	if err := row.Scan(&layout.ID, &layout.CompanyName, &layout.StatementType, &layout.Name, &definition,
+		&layout.IsDefault, &layout.CreatedBy, &layout.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan statement layout: %w", err)
+	}
+	if err := json.Unmarshal([]byte(definition), &layout.Lines); err != nil {
+		return nil, fmt.Errorf("layout %q has an invalid definition: %w", layout.Name, err)
+	}
+	return &layout, nil
+}
@@ -0,0 +1,571 @@
+package financials
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/reports"
+)
+
+// Segment dimensions. GLMASTER carries CUNITNO (well/unit), CDEPTNO and CAFENO
+// on every line.
+const (
+	DimAccount = "account"
+	DimUnit    = "unit"
+	DimDept    = "dept"
+	DimAFE     = "afe"
+	DimPeriod  = "period" // Cross-tab columns only
+)
+
+// NoSegment labels GL lines with a blank unit, department or AFE
+const NoSegment = "(none)"
+
+var dimensionNames = map[string]string{
+	DimAccount: "Account",
+	DimUnit:    "Unit",
+	DimDept:    "Department",
+	DimAFE:     "AFE",
+	DimPeriod:  "Period",
+}
+
+// SegmentRequest pivots GL activity for a period range. Rows are grouped by
+// GroupBy in order, with subtotals at every level but the last; CrossTab spreads
+// the amounts over one more dimension as columns. Filters match exactly; an
+// empty filter matches everything.
+type SegmentRequest struct {
+	From           ledger.Period `json:"from"`
+	To             ledger.Period `json:"to"`
+	GroupBy        []string      `json:"group_by"`
+	CrossTab       string        `json:"cross_tab"`
+	AccountFrom    string        `json:"account_from"`
+	AccountTo      string        `json:"account_to"`
+	AccountTypes   []int         `json:"account_types"` // Empty for all types
+	UnitNo         string        `json:"unit_no"`
+	DeptNo         string        `json:"dept_no"`
+	AFENo          string        `json:"afe_no"`
+	IncludeClosing bool          `json:"include_closing"` // Include year-end closing entries
+}
+
+// SegmentRow is a heading, detail or subtotal row. Each group above the last
+// GroupBy level opens with a heading row and closes with its subtotal. Keys holds the dimension values that
+// identify it, which is what SegmentLines takes to drill down; a blank value
+// stands for GL lines with no unit, department or AFE.
+type SegmentRow struct {
+	Level       int               `json:"level"`
+	Heading     bool              `json:"heading"`
+	Subtotal    bool              `json:"subtotal"`
+	Keys        map[string]string `json:"keys"`
+	Label       string            `json:"label"`
+	Description string            `json:"description"`
+	Debits      float64           `json:"debits"`
+	Credits     float64           `json:"credits"`
+	Net         float64           `json:"net"`               // Debits minus credits
+	Columns     []float64         `json:"columns,omitempty"` // Net per cross-tab column
+	LineCount   int               `json:"line_count"`
+}
+
+// SegmentReport is GL activity pivoted by segment
+type SegmentReport struct {
+	CompanyName string         `json:"company_name"`
+	Request     SegmentRequest `json:"request"`
+	ColumnKeys  []string       `json:"column_keys,omitempty"` // Cross-tab column values
+	Rows        []SegmentRow   `json:"rows"`
+	Total       SegmentRow     `json:"total"`
+	LineCount   int            `json:"line_count"`
+	Warnings    []string       `json:"warnings"`
+	GeneratedAt time.Time      `json:"generated_at"`
+}
+
+// SegmentDrillRequest selects the GL lines behind one segment row
+type SegmentDrillRequest struct {
+	From           ledger.Period     `json:"from"`
+	To             ledger.Period     `json:"to"`
+	Keys           map[string]string `json:"keys"`
+	AccountFrom    string            `json:"account_from"`
+	AccountTo      string            `json:"account_to"`
+	AccountTypes   []int             `json:"account_types"`
+	UnitNo         string            `json:"unit_no"`
+	DeptNo         string            `json:"dept_no"`
+	AFENo          string            `json:"afe_no"`
+	IncludeClosing bool              `json:"include_closing"`
+}
+
+// SegmentDrillDown is the GL lines behind a segment row
+type SegmentDrillDown struct {
+	CompanyName  string              `json:"company_name"`
+	Request      SegmentDrillRequest `json:"request"`
+	Lines        []GLDetailLine      `json:"lines"`
+	AccountNos   []string            `json:"account_numbers"` // Account of each line
+	TotalDebits  float64             `json:"total_debits"`
+	TotalCredits float64             `json:"total_credits"`
+	Net          float64             `json:"net"`
+}
+
+// Validate checks the period range and dimensions
+func (r *SegmentRequest) Validate() error {
+	if r.From.IsZero() || r.To.IsZero() {
+		return fmt.Errorf("a period range is required")
+	}
+	if r.To.Before(r.From) {
+		return fmt.Errorf("period %s is after %s", r.From, r.To)
+	}
+	if len(r.GroupBy) == 0 {
+		return fmt.Errorf("choose at least one dimension to group by")
+	}
+	seen := make(map[string]bool)
+	for i, dim := range r.GroupBy {
+		dim = strings.ToLower(strings.TrimSpace(dim))
+		r.GroupBy[i] = dim
+		if _, ok := dimensionNames[dim]; !ok || dim == DimPeriod {
+			return fmt.Errorf("unknown segment dimension: %s", dim)
+		}
+		if seen[dim] {
+			return fmt.Errorf("%s is grouped more than once", dimensionNames[dim])
+		}
+		seen[dim] = true
+	}
+	r.CrossTab = strings.ToLower(strings.TrimSpace(r.CrossTab))
+	if r.CrossTab != "" {
+		if _, ok := dimensionNames[r.CrossTab]; !ok {
+			return fmt.Errorf("unknown cross-tab dimension: %s", r.CrossTab)
+		}
+		if seen[r.CrossTab] {
+			return fmt.Errorf("%s cannot be both a row and a column", dimensionNames[r.CrossTab])
+		}
+	}
+	return nil
+}
+
+// segmentFilter holds what SegmentReport and SegmentLines share for selecting lines
+type segmentFilter struct {
+	from, to               ledger.Period
+	accountFrom, accountTo string
+	types                  map[int]bool
+	unit, dept, afe        string
+	includeClosing         bool
+}
+
+func (f segmentFilter) match(cal *ledger.Calendar, e ledger.GLEntry, accountMap map[string]ledger.Account) bool {
+	if e.AccountNo == "" || (!f.includeClosing && e.IsClosingEntry()) {
+		return false
+	}
+	p, ok := e.FiscalPeriodIn(cal)
+	if !ok || p.Before(f.from) || f.to.Before(p) {
+		return false
+	}
+	if f.accountFrom != "" && e.AccountNo < f.accountFrom {
+		return false
+	}
+	if f.accountTo != "" && e.AccountNo > f.accountTo {
+		return false
+	}
+	if len(f.types) > 0 {
+		accountType := ledger.TypeOther
+		if a, found := accountMap[e.AccountNo]; found {
+			accountType = a.Type
+		}
+		if !f.types[accountType] {
+			return false
+		}
+	}
+	return (f.unit == "" || strings.EqualFold(e.UnitNo, f.unit)) &&
+		(f.dept == "" || strings.EqualFold(e.DeptNo, f.dept)) &&
+		(f.afe == "" || strings.EqualFold(e.AFENo, f.afe))
+}
+
+func newSegmentFilter(from, to ledger.Period, accountFrom, accountTo string, types []int, unit, dept, afe string, includeClosing bool) segmentFilter {
+	f := segmentFilter{from: from, to: to, accountFrom: strings.TrimSpace(accountFrom), accountTo: strings.TrimSpace(accountTo),
+		unit: strings.TrimSpace(unit), dept: strings.TrimSpace(dept), afe: strings.TrimSpace(afe), includeClosing: includeClosing}
+	if len(types) > 0 {
+		f.types = make(map[int]bool)
+		for _, t := range types {
+			f.types[t] = true
+		}
+	}
+	return f
+}
+
+// dimensionValue returns an entry's value for a dimension
+func dimensionValue(cal *ledger.Calendar, e ledger.GLEntry, dim string) string {
+	switch dim {
+	case DimAccount:
+		return e.AccountNo
+	case DimUnit:
+		return strings.TrimSpace(e.UnitNo)
+	case DimDept:
+		return strings.TrimSpace(e.DeptNo)
+	case DimAFE:
+		return strings.TrimSpace(e.AFENo)
+	case DimPeriod:
+		p, _ := e.FiscalPeriodIn(cal)
+		return p.String()
+	}
+	return ""
+}
+
+// segmentLeaf totals the lines of one combination of the GroupBy values
+type segmentLeaf struct {
+	values  []string
+	debits  currency.Currency
+	credits currency.Currency
+	columns map[string]currency.Currency
+	lines   int
+}
+
+// SegmentReport pivots GL activity over a period range by account, unit,
+// department and AFE
+func (s *Service) SegmentReport(companyName string, req SegmentRequest) (*SegmentReport, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, err
+	}
+	accountMap := ledger.AccountMap(accounts)
+	entries, err := ledger.LoadGLEntries(companyName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := newSegmentFilter(req.From, req.To, req.AccountFrom, req.AccountTo, req.AccountTypes, req.UnitNo, req.DeptNo, req.AFENo, req.IncludeClosing)
+	leaves := make(map[string]*segmentLeaf)
+	columnSet := make(map[string]bool)
+	for _, e := range entries {
+		if !filter.match(cal, e, accountMap) {
+			continue
+		}
+		values := make([]string, len(req.GroupBy))
+		for i, dim := range req.GroupBy {
+			values[i] = dimensionValue(cal, e, dim)
+		}
+		k := strings.Join(values, "\x00")
+		leaf, ok := leaves[k]
+		if !ok {
+			leaf = &segmentLeaf{values: values, debits: currency.Zero(), credits: currency.Zero(), columns: make(map[string]currency.Currency)}
+			leaves[k] = leaf
+		}
+		leaf.debits = leaf.debits.Add(currency.NewFromFloat(e.Debit))
+		leaf.credits = leaf.credits.Add(currency.NewFromFloat(e.Credit))
+		leaf.lines++
+		if req.CrossTab != "" {
+			col := dimensionValue(cal, e, req.CrossTab)
+			columnSet[col] = true
+			if amt, found := leaf.columns[col]; found {
+				leaf.columns[col] = amt.Add(e.Net())
+			} else {
+				leaf.columns[col] = e.Net()
+			}
+		}
+	}
+
+	report := &SegmentReport{
+		CompanyName: companyName,
+		Request:     req,
+		Rows:        []SegmentRow{},
+		Warnings:    []string{},
+		GeneratedAt: time.Now(),
+	}
+	for col := range columnSet {
+		report.ColumnKeys = append(report.ColumnKeys, col)
+	}
+	sort.Strings(report.ColumnKeys)
+
+	sorted := make([]*segmentLeaf, 0, len(leaves))
+	for _, leaf := range leaves {
+		sorted = append(sorted, leaf)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		for n := range req.GroupBy {
+			if sorted[i].values[n] != sorted[j].values[n] {
+				return sorted[i].values[n] < sorted[j].values[n]
+			}
+		}
+		return false
+	})
+
+	var names map[string]string
+	for _, dim := range req.GroupBy {
+		if dim == DimUnit {
+			if wells, err := ledger.LoadWells(companyName); err == nil {
+				names = ledger.WellNames(wells)
+			}
+		}
+	}
+	describe := func(dim, value string) string {
+		switch {
+		case value == "":
+			return ""
+		case dim == DimAccount:
+			if a, ok := accountMap[value]; ok {
+				return a.Description
+			}
+			return "(not in chart of accounts)"
+		case dim == DimUnit:
+			return names[value]
+		}
+		return ""
+	}
+
+	missing := make(map[string]bool)
+	var build func(level int, group []*segmentLeaf) SegmentRow
+	build = func(level int, group []*segmentLeaf) SegmentRow {
+		total := newSegmentTotal(len(report.ColumnKeys))
+		for start := 0; start < len(group); {
+			end := start + 1
+			for end < len(group) && group[end].values[level] == group[start].values[level] {
+				end++
+			}
+			value := group[start].values[level]
+			keys := make(map[string]string, level+1)
+			for n := 0; n <= level; n++ {
+				keys[req.GroupBy[n]] = group[start].values[n]
+			}
+			label := value
+			if label == "" {
+				label = NoSegment
+			}
+			dim := req.GroupBy[level]
+			if dim == DimAccount && value != "" {
+				if _, ok := accountMap[value]; !ok {
+					missing[value] = true
+				}
+			}
+
+			var row SegmentRow
+			if level == len(req.GroupBy)-1 {
+				row = newSegmentTotal(len(report.ColumnKeys))
+				for _, leaf := range group[start:end] {
+					row.addLeaf(leaf, report.ColumnKeys)
+				}
+				row.Level, row.Keys, row.Label, row.Description = level, keys, label, describe(dim, value)
+				report.Rows = append(report.Rows, row)
+			} else {
+				report.Rows = append(report.Rows, SegmentRow{Level: level, Heading: true, Keys: keys,
+					Label: fmt.Sprintf("%s %s", dimensionNames[dim], label), Description: describe(dim, value)})
+				row = build(level+1, group[start:end])
+				row.Level, row.Keys, row.Subtotal = level, keys, true
+				row.Label = fmt.Sprintf("Total %s %s", dimensionNames[dim], label)
+				row.Description = describe(dim, value)
+				report.Rows = append(report.Rows, row)
+			}
+			total.add(row)
+			start = end
+		}
+		return total
+	}
+	if len(sorted) > 0 {
+		report.Total = build(0, sorted)
+	} else {
+		report.Total = newSegmentTotal(len(report.ColumnKeys))
+	}
+	report.Total.Label, report.Total.Subtotal, report.Total.Keys = "Total", true, map[string]string{}
+	report.LineCount = report.Total.LineCount
+
+	if len(missing) > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d account(s) with activity are not in the chart of accounts", len(missing)))
+	}
+	return report, nil
+}
+
+func newSegmentTotal(columns int) SegmentRow {
+	row := SegmentRow{}
+	if columns > 0 {
+		row.Columns = make([]float64, columns)
+	}
+	return row
+}
+
+func (r *SegmentRow) addLeaf(leaf *segmentLeaf, columnKeys []string) {
+	r.Debits = currency.NewFromFloat(r.Debits).Add(leaf.debits).ToFloat64()
+	r.Credits = currency.NewFromFloat(r.Credits).Add(leaf.credits).ToFloat64()
+	r.Net = currency.NewFromFloat(r.Debits).Sub(currency.NewFromFloat(r.Credits)).ToFloat64()
+	for i, col := range columnKeys {
+		if amt, ok := leaf.columns[col]; ok {
+			r.Columns[i] = currency.NewFromFloat(r.Columns[i]).Add(amt).ToFloat64()
+		}
+	}
+	r.LineCount += leaf.lines
+}
+
+func (r *SegmentRow) add(o SegmentRow) {
+	r.Debits = currency.NewFromFloat(r.Debits).Add(currency.NewFromFloat(o.Debits)).ToFloat64()
+	r.Credits = currency.NewFromFloat(r.Credits).Add(currency.NewFromFloat(o.Credits)).ToFloat64()
+	r.Net = currency.NewFromFloat(r.Debits).Sub(currency.NewFromFloat(r.Credits)).ToFloat64()
+	for i := range r.Columns {
+		if i < len(o.Columns) {
+			r.Columns[i] = currency.NewFromFloat(r.Columns[i]).Add(currency.NewFromFloat(o.Columns[i])).ToFloat64()
+		}
+	}
+	r.LineCount += o.LineCount
+}
+
+// SegmentLines returns the GL lines behind a segment row, in date order. Keys
+// match exactly, so a blank unit selects the lines that have no unit.
+func (s *Service) SegmentLines(companyName string, req SegmentDrillRequest) (*SegmentDrillDown, error) {
+	if req.From.IsZero() || req.To.IsZero() {
+		return nil, fmt.Errorf("a period range is required")
+	}
+	for dim := range req.Keys {
+		if _, ok := dimensionNames[dim]; !ok {
+			return nil, fmt.Errorf("unknown segment dimension: %s", dim)
+		}
+	}
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, err
+	}
+	accountMap := ledger.AccountMap(accounts)
+	entries, err := ledger.LoadGLEntries(companyName)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := newSegmentFilter(req.From, req.To, req.AccountFrom, req.AccountTo, req.AccountTypes, req.UnitNo, req.DeptNo, req.AFENo, req.IncludeClosing)
+	var matched []ledger.GLEntry
+	for _, e := range entries {
+		if !filter.match(cal, e, accountMap) {
+			continue
+		}
+		ok := true
+		for dim, value := range req.Keys {
+			if !strings.EqualFold(dimensionValue(cal, e, dim), strings.TrimSpace(value)) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, e)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if !matched[i].Date.Equal(matched[j].Date) {
+			return matched[i].Date.Before(matched[j].Date)
+		}
+		if matched[i].Batch != matched[j].Batch {
+			return matched[i].Batch < matched[j].Batch
+		}
+		return matched[i].RowIndex < matched[j].RowIndex
+	})
+
+	drill := &SegmentDrillDown{CompanyName: companyName, Request: req, Lines: make([]GLDetailLine, 0, len(matched)), AccountNos: make([]string, 0, len(matched))}
+	debits, credits, balance := currency.Zero(), currency.Zero(), currency.Zero()
+	for _, e := range matched {
+		balance = balance.Add(e.Net())
+		debits = debits.Add(currency.NewFromFloat(e.Debit))
+		credits = credits.Add(currency.NewFromFloat(e.Credit))
+		line := GLDetailLine{
+			RowIndex:       e.RowIndex,
+			Batch:          e.Batch,
+			Source:         e.Source,
+			Reference:      e.Reference,
+			Description:    e.Description,
+			UnitNo:         e.UnitNo,
+			DeptNo:         e.DeptNo,
+			AFENo:          e.AFENo,
+			Debit:          e.Debit,
+			Credit:         e.Credit,
+			RunningBalance: balance.ToFloat64(),
+			CIDCHEC:        e.CIDCHEC,
+		}
+		if !e.Date.IsZero() {
+			d := e.Date
+			line.Date = &d
+		}
+		line.Period, _ = e.FiscalPeriodIn(cal)
+		drill.Lines = append(drill.Lines, line)
+		drill.AccountNos = append(drill.AccountNos, e.AccountNo)
+	}
+	drill.TotalDebits = debits.ToFloat64()
+	drill.TotalCredits = credits.ToFloat64()
+	drill.Net = debits.Sub(credits).ToFloat64()
+	return drill, nil
+}
+
+// SegmentTable converts a segment report for PDF/CSV/XLSX output. Cross-tab
+// reports show net activity per column; others show debits, credits and net.
+func SegmentTable(r *SegmentReport, displayName string) *reports.Table {
+	req := r.Request
+	dims := make([]string, len(req.GroupBy))
+	for i, dim := range req.GroupBy {
+		dims[i] = dimensionNames[dim]
+	}
+	subtitle := fmt.Sprintf("Periods %s through %s", req.From, req.To)
+	if req.From == req.To {
+		subtitle = fmt.Sprintf("Period %s", req.From)
+	}
+	t := &reports.Table{
+		CompanyName: displayName,
+		Title:       "Segment Report",
+		Subtitles:   []string{subtitle, "By " + strings.Join(dims, ", ")},
+		Columns:     []reports.Column{{Header: strings.Join(dims, " / "), Width: 40}, {Header: "Description", Width: 60}},
+	}
+	var filters []string
+	for _, f := range []struct{ name, value string }{{"Unit", req.UnitNo}, {"Department", req.DeptNo}, {"AFE", req.AFENo}} {
+		if f.value != "" {
+			filters = append(filters, f.name+" "+f.value)
+		}
+	}
+	if req.AccountFrom != "" || req.AccountTo != "" {
+		filters = append(filters, fmt.Sprintf("Accounts %s through %s", req.AccountFrom, req.AccountTo))
+	}
+	if len(filters) > 0 {
+		t.Subtitles = append(t.Subtitles, strings.Join(filters, ", "))
+	}
+
+	crossTab := req.CrossTab != ""
+	if crossTab {
+		t.Subtitles[1] += " across " + dimensionNames[req.CrossTab]
+		width := 159.0 / float64(len(r.ColumnKeys)+1)
+		if width > 26 {
+			width = 26
+		}
+		for _, col := range r.ColumnKeys {
+			header := col
+			if header == "" {
+				header = NoSegment
+			}
+			t.Columns = append(t.Columns, reports.Column{Header: header, Width: width, Align: "R"})
+		}
+		t.Columns = append(t.Columns, reports.Column{Header: "Total", Width: width, Align: "R"})
+	} else {
+		t.Columns = append(t.Columns,
+			reports.Column{Header: "Debits", Width: 30, Align: "R"},
+			reports.Column{Header: "Credits", Width: 30, Align: "R"},
+			reports.Column{Header: "Net", Width: 30, Align: "R"},
+		)
+	}
+
+	cells := func(row SegmentRow) []string {
+		c := []string{row.Label, row.Description}
+		if crossTab {
+			for _, amt := range row.Columns {
+				c = append(c, reports.FormatAmount(amt))
+			}
+			return append(c, reports.FormatAmount(row.Net))
+		}
+		return append(c, reports.FormatAmount(row.Debits), reports.FormatAmount(row.Credits), reports.FormatAmount(row.Net))
+	}
+	for _, row := range r.Rows {
+		if row.Heading {
+			t.Rows = append(t.Rows, reports.Row{Cells: []string{row.Label, row.Description}, Bold: true, Indent: row.Level})
+			continue
+		}
+		t.Rows = append(t.Rows, reports.Row{Cells: cells(row), Bold: row.Subtotal, Indent: row.Level})
+	}
+	t.AddTotal(cells(r.Total)...)
+	t.Notes = append(t.Notes, r.Warnings...)
+	return t
+}
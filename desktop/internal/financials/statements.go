@@ -0,0 +1,540 @@
+package financials
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/reports"
+)
+
+// Statement bases
+const (
+	BasisMonth   = "month"
+	BasisQuarter = "quarter"
+	BasisYTD     = "ytd"
+)
+
+// StatementRequest selects a financial statement
+type StatementRequest struct {
+	StatementType string        `json:"statement_type"`
+	Period        ledger.Period `json:"period"` // Last period included
+	Basis         string        `json:"basis"`  // Income statement range; the balance sheet is always as of Period
+	Comparative   string        `json:"comparative"`
+	LayoutID      int           `json:"layout_id"` // 0 uses the company's default layout
+	Chart         string        `json:"chart"`     // Standard chart to restate on; empty uses the company's chart
+}
+
+// Statement row kinds
+const (
+	RowHeading = "heading"
+	RowAccount = "account"
+	RowLine    = "line"
+	RowTotal   = "total"
+	RowBlank   = "blank"
+)
+
+// StatementRow is one printed line of a statement
+type StatementRow struct {
+	LineID        string   `json:"line_id,omitempty"`
+	Kind          string   `json:"kind"`
+	Label         string   `json:"label"`
+	AccountNumber string   `json:"account_number,omitempty"`
+	Indent        int      `json:"indent"`
+	Amount        *float64 `json:"amount,omitempty"`
+	Comparative   *float64 `json:"comparative,omitempty"`
+	Change        *float64 `json:"change,omitempty"`
+	ChangePercent *float64 `json:"change_percent,omitempty"`
+
+	Columns []float64 `json:"columns,omitempty"` // Consolidation: the amount in each of Statement.Columns
+}
+
+// Statement is a rendered balance sheet or income statement
+type Statement struct {
+	CompanyName     string         `json:"company_name"`
+	StatementType   string         `json:"statement_type"`
+	Title           string         `json:"title"`
+	LayoutName      string         `json:"layout_name"`
+	Basis           string         `json:"basis"`
+	From            ledger.Period  `json:"from"`
+	To              ledger.Period  `json:"to"`
+	ComparativeFrom *ledger.Period `json:"comparative_from,omitempty"`
+	ComparativeTo   *ledger.Period `json:"comparative_to,omitempty"`
+	Rows            []StatementRow `json:"rows"`
+	NetIncome       float64        `json:"net_income"` // Current fiscal year through To (balance sheet) or for the range (income statement)
+	IsBalanced      bool           `json:"is_balanced"`
+	Unassigned      []string       `json:"unassigned_accounts"` // Accounts with amounts that no layout line selects
+	Chart           string         `json:"chart,omitempty"`
+	ChartName       string         `json:"chart_name,omitempty"`
+	Unmapped        []string       `json:"unmapped_accounts,omitempty"` // Company accounts with activity not mapped to Chart
+	Columns         []string       `json:"columns,omitempty"`           // Consolidation: member companies, then eliminations
+	Warnings        []string       `json:"warnings"`
+	GeneratedAt     time.Time      `json:"generated_at"`
+}
+
+// columnValues are the amounts for one statement column, debit-positive
+type columnValues struct {
+	accounts      map[string]currency.Currency
+	netIncome     currency.Currency // Credit-positive
+	priorEarnings currency.Currency // Credit-positive, prior years' income not closed
+	outOfBalance  currency.Currency
+}
+
+// statementRange returns the income statement period range for a basis
+func statementRange(cal *ledger.Calendar, period ledger.Period, basis string) (ledger.Period, error) {
+	switch basis {
+	case BasisMonth, "":
+		return period, nil
+	case BasisQuarter:
+		return cal.QuarterStart(period), nil
+	case BasisYTD:
+		return ledger.Period{Year: period.Year, Period: 1}, nil
+	}
+	return ledger.Period{}, fmt.Errorf("unknown basis: %s", basis)
+}
+
+// comparativeRange returns the comparative range for [from, to]
+func comparativeRange(cal *ledger.Calendar, from, to ledger.Period, basis, comparative string) (ledger.Period, ledger.Period, bool, error) {
+	switch comparative {
+	case ComparativeNone:
+		return ledger.Period{}, ledger.Period{}, false, nil
+	case ComparativePriorPeriod:
+		if basis != BasisYTD {
+			span := from.Span(to, cal.PeriodCount())
+			return from.Add(-span, cal.PeriodCount()), from.Add(-1, cal.PeriodCount()), true, nil
+		}
+		// The period before a year-to-date range is the same range last year
+		fallthrough
+	case ComparativePriorYear:
+		return ledger.Period{Year: from.Year - 1, Period: from.Period}, ledger.Period{Year: to.Year - 1, Period: to.Period}, true, nil
+	}
+	return ledger.Period{}, ledger.Period{}, false, fmt.Errorf("unknown comparative option: %s", comparative)
+}
+
+// computeColumn totals every account for one column
+func computeColumn(pl *periodLedger, accounts []ledger.Account, statementType string, from, to ledger.Period) columnValues {
+	cv := columnValues{
+		accounts:      make(map[string]currency.Currency),
+		netIncome:     currency.Zero(),
+		priorEarnings: currency.Zero(),
+		outOfBalance:  currency.Zero(),
+	}
+	yearStart := ledger.Period{Year: to.Year, Period: 1}
+
+	for _, a := range accounts {
+		if ledger.IsBalanceSheet(a.Type) {
+			if statementType == StatementBalanceSheet {
+				bal := pl.sum(a.AccountNo, ledger.Period{}, to).net()
+				cv.accounts[a.AccountNo] = bal
+				cv.outOfBalance = cv.outOfBalance.Add(bal)
+			}
+			continue
+		}
+		if statementType == StatementIncomeStatement {
+			activity := pl.sum(a.AccountNo, from, to).net()
+			cv.accounts[a.AccountNo] = activity
+			cv.netIncome = cv.netIncome.Sub(activity)
+			continue
+		}
+		current := pl.sum(a.AccountNo, yearStart, to).net()
+		prior := pl.before(a.AccountNo, yearStart).net()
+		cv.netIncome = cv.netIncome.Sub(current)
+		cv.priorEarnings = cv.priorEarnings.Sub(prior)
+		cv.outOfBalance = cv.outOfBalance.Add(current).Add(prior)
+	}
+	return cv
+}
+
+// signFor returns the display multiplier for a line: 1 shows debit balances as
+// positive, -1 shows credit balances as positive
+func signFor(line LayoutLine) int {
+	switch line.Sign {
+	case "debit":
+		return 1
+	case "credit":
+		return -1
+	}
+	if len(line.AccountTypes) > 0 && !ledger.IsDebitNormal(line.AccountTypes[0]) {
+		return -1
+	}
+	if line.Kind == LineNetIncome || line.Kind == LineRetainedEarnings {
+		return -1
+	}
+	return 1
+}
+
+func signed(c currency.Currency, sign int) currency.Currency {
+	if sign < 0 {
+		return c.Neg()
+	}
+	return c
+}
+
+// topParent follows CPARENT to the highest ancestor in the chart
+func topParent(a ledger.Account, accounts map[string]ledger.Account) ledger.Account {
+	seen := map[string]bool{a.AccountNo: true}
+	for a.HasParent() {
+		parent, ok := accounts[a.Parent]
+		if !ok || seen[parent.AccountNo] {
+			break
+		}
+		seen[parent.AccountNo] = true
+		a = parent
+	}
+	return a
+}
+
+// GenerateStatement builds a balance sheet or income statement from the layout
+func (s *Service) GenerateStatement(companyName string, req StatementRequest) (*Statement, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	layout, err := s.GetLayout(companyName, req.StatementType, req.LayoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	pl, chart, err := s.loadLedger(companyName, req.Chart)
+	if err != nil {
+		return nil, err
+	}
+	return buildStatement(companyName, req, layout, pl, chart, nil)
+}
+
+func (req StatementRequest) validate() error {
+	if req.StatementType != StatementBalanceSheet && req.StatementType != StatementIncomeStatement {
+		return fmt.Errorf("invalid statement type: %s", req.StatementType)
+	}
+	if req.Period.IsZero() {
+		return fmt.Errorf("a period is required")
+	}
+	return nil
+}
+
+// buildStatement renders a ledger through a layout. cols adds an amount column
+// for each ledger that was combined into pl.
+func buildStatement(companyName string, req StatementRequest, layout *Layout, pl *periodLedger, chart *chartInfo, cols []column) (*Statement, error) {
+	from, err := statementRange(pl.cal, req.Period, req.Basis)
+	if err != nil {
+		return nil, err
+	}
+	if req.StatementType == StatementBalanceSheet {
+		from = req.Period
+	}
+	compFrom, compTo, hasComp, err := comparativeRange(pl.cal, from, req.Period, req.Basis, req.Comparative)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts in the chart plus GL accounts missing from it
+	accounts := append([]ledger.Account{}, pl.accounts...)
+	for _, number := range pl.accountNumbers() {
+		if _, ok := pl.accountMap[number]; !ok {
+			accounts = append(accounts, ledger.Account{AccountNo: number, Type: ledger.TypeOther, Description: "(not in chart of accounts)"})
+		}
+	}
+
+	current := computeColumn(pl, accounts, req.StatementType, from, req.Period)
+	var comp columnValues
+	if hasComp {
+		comp = computeColumn(pl, accounts, req.StatementType, compFrom, compTo)
+	}
+	colValues := make([]columnValues, len(cols))
+	for i, c := range cols {
+		colValues[i] = computeColumn(c.pl, accounts, req.StatementType, from, req.Period)
+	}
+	// colAmounts returns an account's amount in each column, signed for display
+	colAmounts := func(account string, sign int) []currency.Currency {
+		amounts := make([]currency.Currency, len(colValues))
+		for i, cv := range colValues {
+			amounts[i] = signed(cv.accounts[account], sign)
+		}
+		return amounts
+	}
+
+	st := &Statement{
+		CompanyName:   companyName,
+		StatementType: req.StatementType,
+		LayoutName:    layout.Name,
+		Basis:         req.Basis,
+		From:          from,
+		To:            req.Period,
+		Rows:          []StatementRow{},
+		NetIncome:     current.netIncome.ToFloat64(),
+		IsBalanced:    current.outOfBalance.IsZero(),
+		Unassigned:    []string{},
+		Warnings:      []string{},
+		GeneratedAt:   time.Now(),
+	}
+	if req.StatementType == StatementBalanceSheet {
+		st.Title = "Balance Sheet"
+		st.Basis = ""
+	} else {
+		st.Title = "Income Statement"
+	}
+	if hasComp {
+		st.ComparativeFrom, st.ComparativeTo = &compFrom, &compTo
+	}
+	if chart != nil {
+		st.Chart, st.ChartName, st.Unmapped = chart.code, chart.name, chart.unmapped
+	}
+	for _, c := range cols {
+		st.Columns = append(st.Columns, c.name)
+	}
+
+	// Assign each account with an amount to the first line that selects it
+	assigned := make(map[int][]ledger.Account)
+	for _, a := range accounts {
+		_, inCurrent := current.accounts[a.AccountNo]
+		if !inCurrent {
+			continue
+		}
+		lineIdx := -1
+		for i, line := range layout.Lines {
+			if (line.Kind == LineAccounts || line.Kind == LineRetainedEarnings) && line.matches(a) {
+				lineIdx = i
+				break
+			}
+		}
+		if lineIdx < 0 {
+			cur := current.accounts[a.AccountNo]
+			if !cur.IsZero() || (hasComp && !comp.accounts[a.AccountNo].IsZero()) || !allZero(colAmounts(a.AccountNo, 1)) {
+				st.Unassigned = append(st.Unassigned, a.AccountNo)
+			}
+			continue
+		}
+		assigned[lineIdx] = append(assigned[lineIdx], a)
+	}
+
+	lineTotals := make(map[string]currency.Currency)
+	compTotals := make(map[string]currency.Currency)
+	colTotals := make(map[string][]currency.Currency)
+	addRow := func(row StatementRow, cur, prev currency.Currency, extra []currency.Currency) {
+		for _, c := range extra {
+			row.Columns = append(row.Columns, c.ToFloat64())
+		}
+		c := cur.ToFloat64()
+		row.Amount = &c
+		if hasComp {
+			p, change := prev.ToFloat64(), cur.Sub(prev)
+			ch := change.ToFloat64()
+			row.Comparative, row.Change = &p, &ch
+			if !prev.IsZero() {
+				pct := ch / abs(p)
+				row.ChangePercent = &pct
+			}
+		}
+		st.Rows = append(st.Rows, row)
+	}
+
+	for i, line := range layout.Lines {
+		sign := signFor(line)
+		switch line.Kind {
+		case LineHeading:
+			st.Rows = append(st.Rows, StatementRow{Kind: RowHeading, Label: line.Label, Indent: line.Indent})
+			continue
+		case LineBlank:
+			st.Rows = append(st.Rows, StatementRow{Kind: RowBlank, Indent: line.Indent})
+			continue
+		}
+
+		total, compTotal, colTotal := currency.Zero(), currency.Zero(), zeros(len(cols))
+		switch line.Kind {
+		case LineAccounts, LineRetainedEarnings:
+			type group struct {
+				number, label string
+				cur, prev     currency.Currency
+				cols          []currency.Currency
+			}
+			var groups []*group
+			byKey := make(map[string]*group)
+			for _, a := range assigned[i] {
+				cur := signed(current.accounts[a.AccountNo], sign)
+				prev := currency.Zero()
+				if hasComp {
+					prev = signed(comp.accounts[a.AccountNo], sign)
+				}
+				total, compTotal = total.Add(cur), compTotal.Add(prev)
+				colCur := colAmounts(a.AccountNo, sign)
+				addAll(colTotal, colCur)
+
+				key, label := a.AccountNo, a.Description
+				if line.Display == DisplayParent {
+					top := topParent(a, pl.accountMap)
+					key, label = top.AccountNo, top.Description
+				}
+				g, ok := byKey[key]
+				if !ok {
+					g = &group{number: key, label: label, cur: currency.Zero(), prev: currency.Zero(), cols: zeros(len(cols))}
+					byKey[key] = g
+					groups = append(groups, g)
+				}
+				g.cur, g.prev = g.cur.Add(cur), g.prev.Add(prev)
+				addAll(g.cols, colCur)
+			}
+			if line.Kind == LineRetainedEarnings {
+				total = total.Add(signed(current.priorEarnings, -sign))
+				if hasComp {
+					compTotal = compTotal.Add(signed(comp.priorEarnings, -sign))
+				}
+				for i, cv := range colValues {
+					colTotal[i] = colTotal[i].Add(signed(cv.priorEarnings, -sign))
+				}
+			}
+			switch {
+			case len(assigned[i]) == 0 && line.Kind == LineAccounts:
+				// Nothing selected - keep the line out of the printed statement
+			case line.Display != DisplaySummary && line.Kind == LineAccounts:
+				for _, g := range groups {
+					if g.cur.IsZero() && g.prev.IsZero() && allZero(g.cols) {
+						continue
+					}
+					addRow(StatementRow{LineID: line.ID, Kind: RowAccount, Label: g.label, AccountNumber: g.number, Indent: line.Indent + 1}, g.cur, g.prev, g.cols)
+				}
+				addRow(StatementRow{LineID: line.ID, Kind: RowTotal, Label: line.Label, Indent: line.Indent}, total, compTotal, colTotal)
+			default:
+				addRow(StatementRow{LineID: line.ID, Kind: RowLine, Label: line.Label, Indent: line.Indent}, total, compTotal, colTotal)
+			}
+		case LineNetIncome:
+			total = signed(current.netIncome, -sign)
+			if hasComp {
+				compTotal = signed(comp.netIncome, -sign)
+			}
+			for i, cv := range colValues {
+				colTotal[i] = signed(cv.netIncome, -sign)
+			}
+			addRow(StatementRow{LineID: line.ID, Kind: RowLine, Label: line.Label, Indent: line.Indent}, total, compTotal, colTotal)
+		case LineTotal:
+			for _, ref := range line.Sum {
+				id := strings.TrimPrefix(ref, "-")
+				if strings.HasPrefix(ref, "-") {
+					total, compTotal = total.Sub(lineTotals[id]), compTotal.Sub(compTotals[id])
+					for i, c := range colTotals[id] {
+						colTotal[i] = colTotal[i].Sub(c)
+					}
+				} else {
+					total, compTotal = total.Add(lineTotals[id]), compTotal.Add(compTotals[id])
+					addAll(colTotal, colTotals[id])
+				}
+			}
+			addRow(StatementRow{LineID: line.ID, Kind: RowTotal, Label: line.Label, Indent: line.Indent}, total, compTotal, colTotal)
+		}
+		if line.ID != "" {
+			lineTotals[line.ID], compTotals[line.ID], colTotals[line.ID] = total, compTotal, colTotal
+		}
+	}
+
+	if !st.IsBalanced {
+		st.Warnings = append(st.Warnings, fmt.Sprintf("The general ledger is out of balance by %s through %s",
+			reports.FormatAmount(current.outOfBalance.ToFloat64()), req.Period))
+	}
+	if len(st.Unassigned) > 0 {
+		st.Warnings = append(st.Warnings, fmt.Sprintf("%d account(s) with amounts are not on layout %q: %s",
+			len(st.Unassigned), layout.Name, strings.Join(st.Unassigned, ", ")))
+	}
+	st.Warnings = append(st.Warnings, chart.warnings()...)
+	return st, nil
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+// StatementTable converts a statement for PDF/CSV output
+func StatementTable(st *Statement, displayName string) *reports.Table {
+	var subtitle string
+	switch {
+	case st.StatementType == StatementBalanceSheet:
+		subtitle = fmt.Sprintf("As of the end of period %s", st.To)
+	case st.Basis == BasisQuarter:
+		subtitle = fmt.Sprintf("Quarter ended period %s", st.To)
+	case st.Basis == BasisYTD:
+		subtitle = fmt.Sprintf("Year to date through period %s", st.To)
+	default:
+		subtitle = fmt.Sprintf("Period %s", st.To)
+	}
+
+	t := &reports.Table{
+		CompanyName: displayName,
+		Title:       st.Title,
+		Subtitles:   []string{subtitle},
+		Portrait:    true,
+		Columns: []reports.Column{
+			{Header: "", Width: 140},
+			{Header: "Current", Width: 55, Align: "R"},
+		},
+	}
+	hasComp := st.ComparativeTo != nil
+	if hasComp {
+		compLabel := st.ComparativeTo.String()
+		if st.StatementType == StatementIncomeStatement && *st.ComparativeFrom != *st.ComparativeTo {
+			compLabel = fmt.Sprintf("%s - %s", *st.ComparativeFrom, *st.ComparativeTo)
+		}
+		t.Subtitles = append(t.Subtitles, "Compared with "+compLabel)
+		t.Columns = []reports.Column{
+			{Header: "", Width: 83},
+			{Header: "Current", Width: 30, Align: "R"},
+			{Header: "Comparative", Width: 30, Align: "R"},
+			{Header: "Change", Width: 30, Align: "R"},
+			{Header: "%", Width: 22, Align: "R"},
+		}
+	}
+	if len(st.Columns) > 0 {
+		t.Portrait = false
+		t.Subtitles = append(t.Subtitles, "Companies: "+strings.Join(memberHeaders(st.Columns), ", "))
+		label := []reports.Column{{Header: "", Width: 70}}
+		if hasComp {
+			t.Columns = consolidatedColumns(label, st.Columns, "Comparative", "Change", "%")
+		} else {
+			t.Columns = consolidatedColumns(label, st.Columns)
+		}
+	}
+
+	for _, r := range st.Rows {
+		switch r.Kind {
+		case RowHeading:
+			t.Rows = append(t.Rows, reports.Row{Cells: []string{r.Label}, Bold: true, Indent: r.Indent})
+			continue
+		case RowBlank:
+			t.Rows = append(t.Rows, reports.Row{Cells: []string{""}})
+			continue
+		}
+		label := r.Label
+		if r.AccountNumber != "" {
+			label = r.AccountNumber + "  " + r.Label
+		}
+		cells := []string{label}
+		for _, amount := range r.Columns {
+			cells = append(cells, reports.FormatAmount(amount))
+		}
+		cells = append(cells, formatOptional(r.Amount))
+		if hasComp {
+			pct := ""
+			if r.ChangePercent != nil {
+				pct = reports.FormatPercent(*r.ChangePercent)
+			}
+			cells = append(cells, formatOptional(r.Comparative), formatOptional(r.Change), pct)
+		}
+		t.Rows = append(t.Rows, reports.Row{Cells: cells, Bold: r.Kind == RowTotal, Indent: r.Indent})
+	}
+
+	if st.ChartName != "" {
+		t.Subtitles = append(t.Subtitles, "Restated on the "+st.ChartName+" chart")
+	}
+	t.Notes = append(t.Notes, fmt.Sprintf("Layout: %s", st.LayoutName))
+	t.Notes = append(t.Notes, st.Warnings...)
+	return t
+}
+
+func formatOptional(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return reports.FormatAmount(*f)
+}
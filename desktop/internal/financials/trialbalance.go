@@ -0,0 +1,346 @@
+package financials
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/reports"
+)
+
+// Comparative column options
+const (
+	ComparativeNone        = ""
+	ComparativePriorPeriod = "prior_period" // The same number of periods immediately before the range
+	ComparativePriorYear   = "prior_year"   // The same periods one year earlier
+)
+
+// TrialBalanceRequest selects the period range of a trial balance
+type TrialBalanceRequest struct {
+	From        ledger.Period `json:"from"`
+	To          ledger.Period `json:"to"`
+	Comparative string        `json:"comparative"`
+	IncludeZero bool          `json:"include_zero"` // Include accounts with no balance and no activity
+	Chart       string        `json:"chart"`        // Standard chart to restate on; empty uses the company's chart
+}
+
+// TrialBalanceLine is one account. Balances are signed debit-positive.
+type TrialBalanceLine struct {
+	AccountNumber  string  `json:"account_number"`
+	Description    string  `json:"description"`
+	AccountType    int     `json:"account_type"`
+	TypeName       string  `json:"account_type_name"`
+	InChart        bool    `json:"in_chart"`
+	OpeningBalance float64 `json:"opening_balance"`
+	Debits         float64 `json:"debits"`
+	Credits        float64 `json:"credits"`
+	ClosingBalance float64 `json:"closing_balance"`
+
+	ComparativeBalance *float64 `json:"comparative_balance,omitempty"`
+	Change             *float64 `json:"change,omitempty"`
+
+	Columns []float64 `json:"columns,omitempty"` // Consolidation: closing balance in each of TrialBalance.Columns
+}
+
+// TrialBalance is the trial balance for a period range
+type TrialBalance struct {
+	CompanyName      string             `json:"company_name"`
+	From             ledger.Period      `json:"from"`
+	To               ledger.Period      `json:"to"`
+	Comparative      string             `json:"comparative"`
+	ComparativeFrom  *ledger.Period     `json:"comparative_from,omitempty"`
+	ComparativeTo    *ledger.Period     `json:"comparative_to,omitempty"`
+	Lines            []TrialBalanceLine `json:"lines"`
+	TotalOpening     float64            `json:"total_opening"`
+	TotalDebits      float64            `json:"total_debits"`
+	TotalCredits     float64            `json:"total_credits"`
+	TotalClosing     float64            `json:"total_closing"`
+	TotalComparative *float64           `json:"total_comparative,omitempty"`
+	IsBalanced       bool               `json:"is_balanced"`      // Period debits equal period credits
+	ClosingBalanced  bool               `json:"closing_balanced"` // Closing balances net to zero
+	OutOfBalance     float64            `json:"out_of_balance"`
+	Chart            string             `json:"chart,omitempty"`
+	ChartName        string             `json:"chart_name,omitempty"`
+	Unmapped         []string           `json:"unmapped_accounts,omitempty"` // Company accounts with activity not mapped to Chart
+	Columns          []string           `json:"columns,omitempty"`           // Consolidation: member companies, then eliminations
+	ColumnTotals     []float64          `json:"column_totals,omitempty"`
+	Warnings         []string           `json:"warnings"`
+	GeneratedAt      time.Time          `json:"generated_at"`
+}
+
+// priorEarningsAccount labels the line that carries income statement activity
+// from earlier fiscal years that has not been closed to retained earnings
+const priorEarningsAccount = "PRIOR-NI"
+
+// TrialBalance aggregates GLMASTER by account for the requested period range.
+// Balance sheet accounts open with all activity before the range; income
+// statement accounts open with activity since the start of the fiscal year.
+// Income statement activity from earlier years that was never closed appears
+// on a separate prior-years net income line so the report still balances.
+func (s *Service) TrialBalance(companyName string, req TrialBalanceRequest) (*TrialBalance, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	pl, chart, err := s.loadLedger(companyName, req.Chart)
+	if err != nil {
+		return nil, err
+	}
+	return buildTrialBalance(companyName, req, pl, chart, nil)
+}
+
+func (req TrialBalanceRequest) validate() error {
+	if req.From.IsZero() || req.To.IsZero() {
+		return fmt.Errorf("a period range is required")
+	}
+	if req.To.Before(req.From) {
+		return fmt.Errorf("period %s is after %s", req.From, req.To)
+	}
+	return nil
+}
+
+// buildTrialBalance lays out the trial balance of a ledger. cols adds a closing
+// balance column for each ledger that was combined into pl.
+func buildTrialBalance(companyName string, req TrialBalanceRequest, pl *periodLedger, chart *chartInfo, cols []column) (*TrialBalance, error) {
+	tb := &TrialBalance{
+		CompanyName: companyName,
+		From:        req.From,
+		To:          req.To,
+		Comparative: req.Comparative,
+		Lines:       []TrialBalanceLine{},
+		Warnings:    []string{},
+		GeneratedAt: time.Now(),
+	}
+	if chart != nil {
+		tb.Chart, tb.ChartName, tb.Unmapped = chart.code, chart.name, chart.unmapped
+	}
+
+	var compFrom, compTo ledger.Period
+	switch req.Comparative {
+	case ComparativeNone:
+	case ComparativePriorPeriod:
+		span := req.From.Span(req.To, pl.cal.PeriodCount())
+		compTo = req.From.Add(-1, pl.cal.PeriodCount())
+		compFrom = req.From.Add(-span, pl.cal.PeriodCount())
+	case ComparativePriorYear:
+		compFrom = ledger.Period{Year: req.From.Year - 1, Period: req.From.Period}
+		compTo = ledger.Period{Year: req.To.Year - 1, Period: req.To.Period}
+	default:
+		return nil, fmt.Errorf("unknown comparative option: %s", req.Comparative)
+	}
+	hasComp := !compTo.IsZero()
+	if hasComp {
+		tb.ComparativeFrom = &compFrom
+		tb.ComparativeTo = &compTo
+	}
+
+	totalOpening, totalDebits, totalCredits, totalClosing := currency.Zero(), currency.Zero(), currency.Zero(), currency.Zero()
+	totalComp := currency.Zero()
+	priorNI, priorNIComp := currency.Zero(), currency.Zero()
+	var missing []string
+	colTotals, colPriorNI := zeros(len(cols)), zeros(len(cols))
+	for _, c := range cols {
+		tb.Columns = append(tb.Columns, c.name)
+	}
+
+	numbers := pl.accountNumbers()
+	sort.Strings(numbers)
+	for _, number := range numbers {
+		acct, inChart := pl.accountMap[number]
+		if !inChart {
+			acct = ledger.Account{AccountNo: number, Type: ledger.TypeOther, Description: "(not in chart of accounts)"}
+			missing = append(missing, number)
+		}
+
+		opening, activity, prior := balancesFor(pl, number, acct.Type, req.From, req.To)
+		closing := opening.Add(activity.net())
+		priorNI = priorNI.Add(prior)
+
+		var comp currency.Currency
+		if hasComp {
+			compOpening, compActivity, compPrior := balancesFor(pl, number, acct.Type, compFrom, compTo)
+			comp = compOpening.Add(compActivity.net())
+			priorNIComp = priorNIComp.Add(compPrior)
+		}
+
+		colClosing := make([]currency.Currency, len(cols))
+		colsZero := true
+		for i, c := range cols {
+			colOpening, colActivity, colPrior := balancesFor(c.pl, number, acct.Type, req.From, req.To)
+			colClosing[i] = colOpening.Add(colActivity.net())
+			colPriorNI[i] = colPriorNI[i].Add(colPrior)
+			colsZero = colsZero && colClosing[i].IsZero()
+		}
+
+		if !req.IncludeZero && opening.IsZero() && activity.debits.IsZero() && activity.credits.IsZero() && closing.IsZero() &&
+			(!hasComp || comp.IsZero()) && colsZero {
+			continue
+		}
+
+		line := TrialBalanceLine{
+			AccountNumber:  number,
+			Description:    acct.Description,
+			AccountType:    acct.Type,
+			TypeName:       ledger.AccountTypeName(acct.Type),
+			InChart:        inChart && (chart == nil || !chart.unmappedKeys[number]),
+			OpeningBalance: opening.ToFloat64(),
+			Debits:         activity.debits.ToFloat64(),
+			Credits:        activity.credits.ToFloat64(),
+			ClosingBalance: closing.ToFloat64(),
+		}
+		if hasComp {
+			c, change := comp.ToFloat64(), closing.Sub(comp).ToFloat64()
+			line.ComparativeBalance, line.Change = &c, &change
+			totalComp = totalComp.Add(comp)
+		}
+		for i, bal := range colClosing {
+			line.Columns = append(line.Columns, bal.ToFloat64())
+			colTotals[i] = colTotals[i].Add(bal)
+		}
+		tb.Lines = append(tb.Lines, line)
+
+		totalOpening = totalOpening.Add(opening)
+		totalDebits = totalDebits.Add(activity.debits)
+		totalCredits = totalCredits.Add(activity.credits)
+		totalClosing = totalClosing.Add(closing)
+	}
+
+	if !priorNI.IsZero() || (hasComp && !priorNIComp.IsZero()) || !allZero(colPriorNI) {
+		line := TrialBalanceLine{
+			AccountNumber:  priorEarningsAccount,
+			Description:    "Prior years' net income not closed to retained earnings",
+			AccountType:    ledger.TypeEquity,
+			TypeName:       ledger.AccountTypeName(ledger.TypeEquity),
+			OpeningBalance: priorNI.ToFloat64(),
+			ClosingBalance: priorNI.ToFloat64(),
+		}
+		if hasComp {
+			c, change := priorNIComp.ToFloat64(), priorNI.Sub(priorNIComp).ToFloat64()
+			line.ComparativeBalance, line.Change = &c, &change
+			totalComp = totalComp.Add(priorNIComp)
+		}
+		for i, bal := range colPriorNI {
+			line.Columns = append(line.Columns, bal.ToFloat64())
+			colTotals[i] = colTotals[i].Add(bal)
+		}
+		tb.Lines = append(tb.Lines, line)
+		totalOpening = totalOpening.Add(priorNI)
+		totalClosing = totalClosing.Add(priorNI)
+		tb.Warnings = append(tb.Warnings, fmt.Sprintf(
+			"Income statement activity of %s from prior fiscal years has not been closed to retained earnings",
+			reports.FormatAmount(priorNI.Neg().ToFloat64())))
+	}
+
+	tb.TotalOpening = totalOpening.ToFloat64()
+	tb.TotalDebits = totalDebits.ToFloat64()
+	tb.TotalCredits = totalCredits.ToFloat64()
+	tb.TotalClosing = totalClosing.ToFloat64()
+	if hasComp {
+		c := totalComp.ToFloat64()
+		tb.TotalComparative = &c
+	}
+	for _, t := range colTotals {
+		tb.ColumnTotals = append(tb.ColumnTotals, t.ToFloat64())
+	}
+	tb.IsBalanced = totalDebits.Equal(totalCredits)
+	tb.ClosingBalanced = totalClosing.IsZero()
+	tb.OutOfBalance = totalDebits.Sub(totalCredits).ToFloat64()
+
+	if !tb.IsBalanced {
+		tb.Warnings = append(tb.Warnings, fmt.Sprintf("Period debits %s do not equal credits %s (difference %s)",
+			reports.FormatAmount(tb.TotalDebits), reports.FormatAmount(tb.TotalCredits), reports.FormatAmount(tb.OutOfBalance)))
+	}
+	if !tb.ClosingBalanced {
+		tb.Warnings = append(tb.Warnings, fmt.Sprintf("Closing balances are out of balance by %s", reports.FormatAmount(tb.TotalClosing)))
+	}
+	if len(missing) > 0 {
+		tb.Warnings = append(tb.Warnings, fmt.Sprintf("%d GL account(s) are not in COA.dbf: %v", len(missing), missing))
+	}
+	tb.Warnings = append(tb.Warnings, chart.warnings()...)
+	if len(pl.unperiod) > 0 {
+		tb.Warnings = append(tb.Warnings, fmt.Sprintf("%d account(s) have GL entries with no year, period or date; they are excluded", len(pl.unperiod)))
+	}
+	return tb, nil
+}
+
+// balancesFor returns an account's opening balance and activity for [from, to],
+// plus the income statement activity from years before from's fiscal year that
+// belongs on the prior-years net income line
+func balancesFor(pl *periodLedger, account string, accountType int, from, to ledger.Period) (currency.Currency, amounts, currency.Currency) {
+	activity := pl.sum(account, from, to)
+	if ledger.IsBalanceSheet(accountType) {
+		return pl.before(account, from).net(), activity, currency.Zero()
+	}
+
+	yearStart := ledger.Period{Year: from.Year, Period: 1}
+	opening := currency.Zero()
+	if yearStart.Before(from) {
+		opening = pl.sum(account, yearStart, from.Add(-1, pl.cal.PeriodCount())).net()
+	}
+	prior := pl.before(account, yearStart).net()
+	return opening, activity, prior
+}
+
+// TrialBalanceTable converts a trial balance for PDF/CSV output
+func TrialBalanceTable(tb *TrialBalance, displayName string) *reports.Table {
+	if len(tb.Columns) > 0 {
+		return consolidatedTrialBalanceTable(tb, displayName)
+	}
+	subtitle := fmt.Sprintf("Periods %s through %s", tb.From, tb.To)
+	if tb.From == tb.To {
+		subtitle = fmt.Sprintf("Period %s", tb.From)
+	}
+	t := &reports.Table{
+		CompanyName: displayName,
+		Title:       "Trial Balance",
+		Subtitles:   []string{subtitle},
+		Columns: []reports.Column{
+			{Header: "Account", Width: 22},
+			{Header: "Description", Width: 62},
+			{Header: "Type", Width: 17},
+			{Header: "Opening", Width: 26, Align: "R"},
+			{Header: "Debits", Width: 26, Align: "R"},
+			{Header: "Credits", Width: 26, Align: "R"},
+			{Header: "Closing", Width: 26, Align: "R"},
+		},
+	}
+	if tb.ChartName != "" {
+		t.Subtitles = append(t.Subtitles, "Restated on the "+tb.ChartName+" chart")
+	}
+	hasComp := tb.ComparativeTo != nil
+	if hasComp {
+		t.Subtitles = append(t.Subtitles, fmt.Sprintf("Compared with periods %s through %s", *tb.ComparativeFrom, *tb.ComparativeTo))
+		t.Columns[1].Width = 44
+		t.Columns = append(t.Columns,
+			reports.Column{Header: "Comparative", Width: 26, Align: "R"},
+			reports.Column{Header: "Change", Width: 26, Align: "R"},
+		)
+		for i := range t.Columns[3:] {
+			t.Columns[3+i].Width = 22
+		}
+	}
+
+	for _, l := range tb.Lines {
+		cells := []string{l.AccountNumber, l.Description, l.TypeName,
+			reports.FormatAmount(l.OpeningBalance), reports.FormatAmount(l.Debits),
+			reports.FormatAmount(l.Credits), reports.FormatAmount(l.ClosingBalance)}
+		if hasComp {
+			cells = append(cells, reports.FormatAmount(*l.ComparativeBalance), reports.FormatAmount(*l.Change))
+		}
+		t.AddRow(cells...)
+	}
+
+	totals := []string{"", "Total", "", reports.FormatAmount(tb.TotalOpening), reports.FormatAmount(tb.TotalDebits),
+		reports.FormatAmount(tb.TotalCredits), reports.FormatAmount(tb.TotalClosing)}
+	if hasComp {
+		totals = append(totals, reports.FormatAmount(*tb.TotalComparative), "")
+	}
+	t.AddTotal(totals...)
+
+	if tb.IsBalanced {
+		t.Notes = append(t.Notes, "Period debits equal credits.")
+	}
+	t.Notes = append(t.Notes, tb.Warnings...)
+	return t
+}
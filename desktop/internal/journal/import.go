@@ -0,0 +1,534 @@
+package journal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Import fields a spreadsheet column can be mapped to
+const (
+	FieldDate        = "date"
+	FieldAccount     = "account"
+	FieldUnit        = "unit"
+	FieldDept        = "dept"
+	FieldAFE         = "afe"
+	FieldDescription = "description"
+	FieldDebit       = "debit"
+	FieldCredit      = "credit"
+)
+
+// importFields lists the mappable fields with the headers recognised when a
+// field is not mapped explicitly
+var importFields = []struct {
+	Field   string
+	Headers []string
+}{
+	{FieldDate, []string{"date", "entry date", "trans date", "ddate"}},
+	{FieldAccount, []string{"account", "account number", "account no", "acct", "acct no", "acctno", "gl account", "cacctno"}},
+	{FieldUnit, []string{"unit", "unit number", "unit no", "well", "cunitno"}},
+	{FieldDept, []string{"dept", "department", "dept no", "cdeptno"}},
+	{FieldAFE, []string{"afe", "afe number", "afe no", "cafeno"}},
+	{FieldDescription, []string{"description", "desc", "memo", "cdesc"}},
+	{FieldDebit, []string{"debit", "debits", "dr", "ndebits"}},
+	{FieldCredit, []string{"credit", "credits", "cr", "ncredits"}},
+}
+
+// ImportMapping says which spreadsheet column feeds each field. A column is a
+// header name, a column letter (A, B, ...) or a 1-based column number. With a
+// header row, unmapped fields are matched to common header names.
+type ImportMapping struct {
+	HasHeader   bool              `json:"has_header"`
+	Columns     map[string]string `json:"columns"`
+	DefaultDate string            `json:"default_date,omitempty"` // Used for rows without a date
+}
+
+// ImportProfile is a saved, reusable mapping
+type ImportProfile struct {
+	ID          int           `json:"id"`
+	CompanyName string        `json:"company_name"`
+	Name        string        `json:"name"`
+	Mapping     ImportMapping `json:"mapping"`
+	CreatedBy   string        `json:"created_by,omitempty"`
+	UpdatedBy   string        `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time    `json:"updated_at,omitempty"`
+}
+
+// ImportRequest is a spreadsheet to import as a journal batch
+type ImportRequest struct {
+	CompanyName string        `json:"company_name"`
+	FileName    string        `json:"file_name"`
+	Content     string        `json:"content"` // CSV text, or base64 for .xlsx
+	Mapping     ImportMapping `json:"mapping"`
+	Description string        `json:"description"`
+	Reference   string        `json:"reference"`
+}
+
+// ImportRow is one spreadsheet row and the problems found on it
+type ImportRow struct {
+	Row      int       `json:"row"` // Row number in the file
+	Date     string    `json:"date"`
+	Line     Line      `json:"line"`
+	Problems []Problem `json:"problems"`
+}
+
+// ImportResult is the line-by-line validation report for an import, and the
+// entries it created once the batch was valid
+type ImportResult struct {
+	FileName     string      `json:"file_name"`
+	Valid        bool        `json:"valid"`
+	Rows         []ImportRow `json:"rows"`
+	Problems     []Problem   `json:"problems"` // Problems with the batch as a whole
+	RowCount     int         `json:"row_count"`
+	ErrorCount   int         `json:"error_count"` // Rows with problems
+	TotalDebits  float64     `json:"total_debits"`
+	TotalCredits float64     `json:"total_credits"`
+	Entries      []*Entry    `json:"entries"`
+	Batch        string      `json:"batch,omitempty"`
+	Posted       bool        `json:"posted"`
+}
+
+// GetImportProfiles returns a company's saved import mappings
+func (s *Service) GetImportProfiles(companyName string) ([]ImportProfile, error) {
+	rows, err := s.db.Query(importProfileSelect+` WHERE company_name = ? ORDER BY name`, companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query import profiles: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := []ImportProfile{}
+	for rows.Next() {
+		p, err := scanImportProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *p)
+	}
+	return profiles, rows.Err()
+}
+
+// GetImportProfile returns one saved mapping
+func (s *Service) GetImportProfile(companyName string, id int) (*ImportProfile, error) {
+	p, err := scanImportProfile(s.db.QueryRow(importProfileSelect+` WHERE company_name = ? AND id = ?`, companyName, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("import profile %d not found", id)
+	}
+	return p, err
+}
+
+// SaveImportProfile creates or updates a mapping; names are unique per company
+func (s *Service) SaveImportProfile(p *ImportProfile, username string) (*ImportProfile, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return nil, fmt.Errorf("profile name is required")
+	}
+	for field := range p.Mapping.Columns {
+		if !isImportField(field) {
+			return nil, fmt.Errorf("unknown import field: %s", field)
+		}
+	}
+	mapping, err := json.Marshal(p.Mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode mapping: %w", err)
+	}
+
+	if p.ID > 0 {
+		result, err := s.db.Exec(`
+			UPDATE journal_import_profiles
+			SET name = ?, mapping_json = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND company_name = ?
+		`, p.Name, string(mapping), username, p.ID, p.CompanyName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update import profile: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return nil, fmt.Errorf("import profile %d not found", p.ID)
+		}
+		return s.GetImportProfile(p.CompanyName, p.ID)
+	}
+
+	if _, err := s.db.Exec(`
+		INSERT INTO journal_import_profiles (company_name, name, mapping_json, created_by, updated_by)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(company_name, name) DO UPDATE SET
+			mapping_json = excluded.mapping_json,
+			updated_by = excluded.updated_by,
+			updated_at = CURRENT_TIMESTAMP
+	`, p.CompanyName, p.Name, string(mapping), username, username); err != nil {
+		return nil, fmt.Errorf("failed to save import profile: %w", err)
+	}
+	saved, err := scanImportProfile(s.db.QueryRow(importProfileSelect+` WHERE company_name = ? AND name = ?`, p.CompanyName, p.Name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import profile: %w", err)
+	}
+	return saved, nil
+}
+
+// DeleteImportProfile removes a saved mapping
+func (s *Service) DeleteImportProfile(companyName string, id int) error {
+	result, err := s.db.Exec(`DELETE FROM journal_import_profiles WHERE id = ? AND company_name = ?`, id, companyName)
+	if err != nil {
+		return fmt.Errorf("failed to delete import profile: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("import profile %d not found", id)
+	}
+	return nil
+}
+
+// ValidateImport reads and checks a spreadsheet without saving anything
+func (s *Service) ValidateImport(req ImportRequest, isAdmin bool) (*ImportResult, error) {
+	result, _, err := s.checkImport(req, isAdmin)
+	return result, err
+}
+
+// ImportEntries saves a valid spreadsheet as draft journal entries, one per
+// entry date, and posts them to GLMASTER.dbf under a single CBATCH when post is
+// set. Nothing is saved unless every row is valid and the batch balances.
+func (s *Service) ImportEntries(req ImportRequest, username string, isAdmin, post bool) (*ImportResult, error) {
+	result, entries, err := s.checkImport(req, isAdmin)
+	if err != nil || !result.Valid {
+		return result, err
+	}
+
+	for _, e := range entries {
+		saved, err := s.SaveEntry(e, username)
+		if err != nil {
+			for _, done := range result.Entries {
+				s.DeleteEntry(req.CompanyName, done.ID)
+			}
+			return nil, err
+		}
+		result.Entries = append(result.Entries, saved)
+	}
+	if !post {
+		return result, nil
+	}
+
+	batch, err := s.postEntries(req.CompanyName, result.Entries, username)
+	if err != nil {
+		return nil, fmt.Errorf("imported entries were saved as drafts but not posted: %w", err)
+	}
+	result.Batch, result.Posted = batch, true
+	for i, e := range result.Entries {
+		if posted, err := s.GetEntry(req.CompanyName, e.ID); err == nil {
+			result.Entries[i] = posted
+		}
+	}
+	return result, nil
+}
+
+// checkImport parses the rows, groups them into one entry per date and
+// validates each entry, reporting problems against the spreadsheet rows
+func (s *Service) checkImport(req ImportRequest, isAdmin bool) (*ImportResult, []*Entry, error) {
+	sheet, err := readSpreadsheet(req.FileName, req.Content)
+	if err != nil {
+		return nil, nil, err
+	}
+	columns, data, err := resolveColumns(req.Mapping, sheet)
+	if err != nil {
+		return nil, nil, err
+	}
+	defaultDate, hasDefault := ledger.ParseDate(req.Mapping.DefaultDate)
+	if _, ok := columns[FieldDate]; !ok && !hasDefault {
+		return nil, nil, fmt.Errorf("map a date column or give a default date")
+	}
+	cal, err := s.periods.Calendar(req.CompanyName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &ImportResult{FileName: req.FileName, Rows: []ImportRow{}, Problems: []Problem{}, Entries: []*Entry{}}
+	cell := func(r sheetRow, field string) string {
+		if col, ok := columns[field]; ok && col < len(r.Cells) {
+			return strings.TrimSpace(r.Cells[col])
+		}
+		return ""
+	}
+
+	type group struct {
+		entry *Entry
+		rows  []int // Index into result.Rows for each entry line
+	}
+	groups := map[string]*group{}
+	for _, r := range data {
+		if rowIsBlank(r, columns) {
+			continue
+		}
+		row := ImportRow{
+			Row:  r.Number,
+			Date: cell(r, FieldDate),
+			Line: Line{
+				AccountNo:   cell(r, FieldAccount),
+				UnitNo:      cell(r, FieldUnit),
+				DeptNo:      cell(r, FieldDept),
+				AFENo:       cell(r, FieldAFE),
+				Description: cell(r, FieldDescription),
+			},
+			Problems: []Problem{},
+		}
+		for _, f := range []struct {
+			field string
+			dest  *float64
+		}{{FieldDebit, &row.Line.Debit}, {FieldCredit, &row.Line.Credit}} {
+			v, ok := parseImportAmount(cell(r, f.field))
+			if !ok {
+				row.Problems = append(row.Problems, Problem{Line: r.Number, Field: "amount",
+					Message: fmt.Sprintf("%s amount %q is not a number", f.field, cell(r, f.field))})
+			}
+			*f.dest = v
+		}
+
+		date := defaultDate
+		if row.Date != "" {
+			d, ok := parseImportDate(row.Date)
+			if !ok {
+				row.Problems = append(row.Problems, Problem{Line: r.Number, Field: FieldDate,
+					Message: fmt.Sprintf("date %q is not a valid date", row.Date)})
+			}
+			date = d
+		}
+		if !date.IsZero() {
+			row.Date = date.Format("2006-01-02")
+		}
+		result.Rows = append(result.Rows, row)
+		if date.IsZero() {
+			continue
+		}
+
+		g, ok := groups[row.Date]
+		if !ok {
+			g = &group{entry: &Entry{
+				CompanyName: req.CompanyName,
+				Date:        date,
+				Period:      cal.PeriodForDate(date),
+				Description: firstNonEmpty(req.Description, "Import "+req.FileName),
+				Reference:   req.Reference,
+				Source:      ledger.SourceJournal,
+			}}
+			groups[row.Date] = g
+		}
+		g.entry.Lines = append(g.entry.Lines, row.Line)
+		g.rows = append(g.rows, len(result.Rows)-1)
+	}
+	if len(result.Rows) == 0 {
+		return nil, nil, fmt.Errorf("%s has no rows to import", req.FileName)
+	}
+
+	accounts, err := ledger.LoadAccounts(req.CompanyName)
+	if err != nil {
+		return nil, nil, err
+	}
+	accountMap := ledger.AccountMap(accounts)
+
+	dates := make([]string, 0, len(groups))
+	for d := range groups {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+	entries := make([]*Entry, 0, len(dates))
+	debits, credits := currency.Zero(), currency.Zero()
+	for _, d := range dates {
+		g := groups[d]
+		check := s.validate(g.entry, cal, accountMap, isAdmin)
+		for _, p := range check.Problems {
+			if p.Line > 0 {
+				row := &result.Rows[g.rows[p.Line-1]]
+				if !hasProblem(row.Problems, p.Field) {
+					p.Line = row.Row
+					row.Problems = append(row.Problems, p)
+				}
+				continue
+			}
+			p.Message = fmt.Sprintf("%s: %s", d, p.Message)
+			result.Problems = append(result.Problems, p)
+		}
+		dr, cr := g.entry.totals()
+		debits, credits = debits.Add(dr), credits.Add(cr)
+		entries = append(entries, g.entry)
+	}
+
+	result.RowCount = len(result.Rows)
+	result.TotalDebits, result.TotalCredits = debits.ToFloat64(), credits.ToFloat64()
+	if !debits.Equal(credits) {
+		result.Problems = append(result.Problems, Problem{Field: "lines",
+			Message: fmt.Sprintf("batch debits %.2f do not equal credits %.2f", result.TotalDebits, result.TotalCredits)})
+	}
+	for _, r := range result.Rows {
+		if len(r.Problems) > 0 {
+			result.ErrorCount++
+		}
+	}
+	result.Valid = result.ErrorCount == 0 && len(result.Problems) == 0
+	return result, entries, nil
+}
+
+// resolveColumns turns the mapping into column indexes and returns the data
+// rows (everything after the header row, when there is one)
+func resolveColumns(m ImportMapping, sheet []sheetRow) (map[string]int, []sheetRow, error) {
+	var header []string
+	data := sheet
+	if m.HasHeader {
+		for i, r := range sheet {
+			if !rowIsBlank(r, nil) {
+				header, data = r.Cells, sheet[i+1:]
+				break
+			}
+		}
+	}
+	headerIndex := func(name string) (int, bool) {
+		name = normalizeHeader(name)
+		for i, h := range header {
+			if normalizeHeader(h) == name {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	columns := map[string]int{}
+	for field, ref := range m.Columns {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+		if !isImportField(field) {
+			return nil, nil, fmt.Errorf("unknown import field: %s", field)
+		}
+		if i, ok := headerIndex(ref); ok {
+			columns[field] = i
+		} else if n, err := strconv.Atoi(ref); err == nil && n > 0 {
+			columns[field] = n - 1
+		} else if i, ok := columnIndex(ref); ok {
+			columns[field] = i
+		} else {
+			return nil, nil, fmt.Errorf("column %q for %s was not found in the file", ref, field)
+		}
+	}
+	if header != nil {
+		for _, f := range importFields {
+			if _, mapped := columns[f.Field]; mapped {
+				continue
+			}
+			for _, h := range f.Headers {
+				if i, ok := headerIndex(h); ok {
+					columns[f.Field] = i
+					break
+				}
+			}
+		}
+	}
+
+	if _, ok := columns[FieldAccount]; !ok {
+		return nil, nil, fmt.Errorf("map a column to the account")
+	}
+	_, hasDebit := columns[FieldDebit]
+	_, hasCredit := columns[FieldCredit]
+	if !hasDebit && !hasCredit {
+		return nil, nil, fmt.Errorf("map a column to the debit or credit amount")
+	}
+	return columns, data, nil
+}
+
+// parseImportAmount reads a spreadsheet amount, allowing currency symbols,
+// thousands separators and accounting-style (negative) amounts. Blank is zero.
+func parseImportAmount(s string) (float64, bool) {
+	s = strings.NewReplacer("$", "", ",", "", " ", "").Replace(strings.TrimSpace(s))
+	if s == "" || s == "-" {
+		return 0, true
+	}
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	if negative {
+		s = s[1 : len(s)-1]
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		v = -v
+	}
+	return v, true
+}
+
+// parseImportDate reads a date cell. Workbook dates arrive as Excel serial
+// day numbers (days since 1899-12-30).
+func parseImportDate(s string) (time.Time, bool) {
+	if d, ok := ledger.ParseDate(s); ok {
+		return d, true
+	}
+	if serial, err := strconv.ParseFloat(s, 64); err == nil && serial > 0 && serial < 2958466 {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(serial)), true
+	}
+	return time.Time{}, false
+}
+
+// rowIsBlank reports whether every mapped cell (every cell when columns is nil) is empty
+func rowIsBlank(r sheetRow, columns map[string]int) bool {
+	for i, c := range r.Cells {
+		if strings.TrimSpace(c) == "" {
+			continue
+		}
+		if columns == nil {
+			return false
+		}
+		for _, col := range columns {
+			if col == i {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func normalizeHeader(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.NewReplacer("#", " no", ".", "", "_", " ").Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func isImportField(field string) bool {
+	for _, f := range importFields {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func hasProblem(problems []Problem, field string) bool {
+	for _, p := range problems {
+		if p.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+const importProfileSelect = `
+	SELECT id, company_name, name, COALESCE(mapping_json, '{}'), COALESCE(created_by, ''), COALESCE(updated_by, ''), updated_at
+	FROM journal_import_profiles`
+
+func scanImportProfile(row rowScanner) (*ImportProfile, error) {
+	var p ImportProfile
+	var mapping string
+	var updatedAt interface{}
+	if err := row.Scan(&p.ID, &p.CompanyName, &p.Name, &mapping, &p.CreatedBy, &p.UpdatedBy, &updatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan import profile: %w", err)
+	}
+	if err := json.Unmarshal([]byte(mapping), &p.Mapping); err != nil {
+		return nil, fmt.Errorf("invalid mapping in import profile %d: %w", p.ID, err)
+	}
+	p.UpdatedAt = timestamp(updatedAt)
+	return &p, nil
+}
@@ -0,0 +1,424 @@
+// Package journal manages manual journal entries: drafts are kept in SQLite,
+// validated against COA.dbf and the period locks, and posted to GLMASTER.dbf as
+// a new CBATCH.
+package journal
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/periods"
+)
+
+// Entry statuses
+const (
+	StatusDraft   = "draft"
+	StatusPosting = "posting" // Claimed by a post that is writing GLMASTER.dbf
+	StatusPosted  = "posted"
+)
+
+// Line is one debit or credit of a journal entry
+type Line struct {
+	ID          int     `json:"id"`
+	LineNo      int     `json:"line_no"`
+	AccountNo   string  `json:"account_number"`
+	UnitNo      string  `json:"unit_number"`
+	DeptNo      string  `json:"dept_number"`
+	AFENo       string  `json:"afe_number"`
+	Description string  `json:"description"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+}
+
+// Entry is a journal entry and its lines
+type Entry struct {
+	ID           int           `json:"id"`
+	CompanyName  string        `json:"company_name"`
+	Date         time.Time     `json:"date"`
+	Period       ledger.Period `json:"period"` // Defaults to the period of Date
+	Description  string        `json:"description"`
+	Reference    string        `json:"reference"`
+	Source       string        `json:"source"`
+	Status       string        `json:"status"`
+	Batch        string        `json:"batch,omitempty"`
+	Lines        []Line        `json:"lines"`
+	TotalDebits  float64       `json:"total_debits"`
+	TotalCredits float64       `json:"total_credits"`
+	CreatedBy    string        `json:"created_by"`
+	CreatedAt    *time.Time    `json:"created_at,omitempty"`
+	UpdatedBy    string        `json:"updated_by,omitempty"`
+	UpdatedAt    *time.Time    `json:"updated_at,omitempty"`
+	PostedBy     string        `json:"posted_by,omitempty"`
+	PostedAt     *time.Time    `json:"posted_at,omitempty"`
+}
+
+// totals recomputes the entry's debit and credit totals
+func (e *Entry) totals() (currency.Currency, currency.Currency) {
+	debits, credits := currency.Zero(), currency.Zero()
+	for _, l := range e.Lines {
+		debits = debits.Add(currency.NewFromFloat(l.Debit))
+		credits = credits.Add(currency.NewFromFloat(l.Credit))
+	}
+	e.TotalDebits, e.TotalCredits = debits.ToFloat64(), credits.ToFloat64()
+	return debits, credits
+}
+
+// Service provides journal entry operations
+type Service struct {
+	db      *database.DB
+	periods *periods.Service
+}
+
+// NewService creates a new journal service
+func NewService(db *database.DB) *Service {
+	return &Service{db: db, periods: periods.NewService(db)}
+}
+
+// GetEntries returns a company's journal entries without their lines, newest
+// first. An empty status returns every entry.
+func (s *Service) GetEntries(companyName, status string) ([]Entry, error) {
+	query := entrySelect + ` WHERE e.company_name = ?`
+	args := []interface{}{companyName}
+	if status != "" {
+		query += ` AND e.status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY e.entry_date DESC, e.id DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query journal entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, rows.Err()
+}
+
+// GetEntry returns one journal entry with its lines
+func (s *Service) GetEntry(companyName string, id int) (*Entry, error) {
+	e, err := scanEntry(s.db.QueryRow(entrySelect+` WHERE e.company_name = ? AND e.id = ?`, companyName, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("journal entry %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, line_no, account_number, COALESCE(unit_number, ''), COALESCE(dept_number, ''),
+		       COALESCE(afe_number, ''), COALESCE(description, ''), debit, credit
+		FROM journal_entry_lines WHERE entry_id = ? ORDER BY line_no, id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query journal entry lines: %w", err)
+	}
+	defer rows.Close()
+
+	e.Lines = []Line{}
+	for rows.Next() {
+		var l Line
+		if err := rows.Scan(&l.ID, &l.LineNo, &l.AccountNo, &l.UnitNo, &l.DeptNo, &l.AFENo, &l.Description, &l.Debit, &l.Credit); err != nil {
+			return nil, fmt.Errorf("failed to scan journal entry line: %w", err)
+		}
+		e.Lines = append(e.Lines, l)
+	}
+	return e, rows.Err()
+}
+
+// SaveEntry creates a draft, or replaces an existing draft's header and lines.
+// Drafts are saved even when they do not validate so work is not lost.
+func (s *Service) SaveEntry(e *Entry, username string) (*Entry, error) {
+	if err := s.prepareEntry(e); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveEntry(tx, e, username); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit journal entry: %w", err)
+	}
+	return s.GetEntry(e.CompanyName, e.ID)
+}
+
+// prepareEntry checks the date and fills in the period, source and totals
+func (s *Service) prepareEntry(e *Entry) error {
+	if e.Date.IsZero() {
+		return fmt.Errorf("an entry date is required")
+	}
+	if e.Period.IsZero() {
+		p, err := s.periods.PeriodForDate(e.CompanyName, e.Date)
+		if err != nil {
+			return err
+		}
+		e.Period = p
+	}
+	if e.Source == "" {
+		e.Source = ledger.SourceJournal
+	}
+	e.Source = strings.ToUpper(strings.TrimSpace(e.Source))
+	e.totals()
+	return nil
+}
+
+// saveEntry writes a prepared draft and its lines in tx, setting e.ID for a new entry
+func saveEntry(tx *sql.Tx, e *Entry, username string) error {
+	if e.ID > 0 {
+		var status string
+		err := tx.QueryRow(`SELECT status FROM journal_entries WHERE id = ? AND company_name = ?`, e.ID, e.CompanyName).Scan(&status)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("journal entry %d not found", e.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load journal entry: %w", err)
+		}
+		if status != StatusDraft {
+			return fmt.Errorf("journal entry %d is %s and can no longer be edited", e.ID, status)
+		}
+		if _, err := tx.Exec(`
+			UPDATE journal_entries
+			SET entry_date = ?, fiscal_year = ?, period = ?, description = ?, reference = ?, source = ?,
+			    total_debits = ?, total_credits = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, e.Date.Format("2006-01-02"), e.Period.Year, e.Period.Period, e.Description, e.Reference, e.Source,
+			e.TotalDebits, e.TotalCredits, username, e.ID); err != nil {
+			return fmt.Errorf("failed to update journal entry: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM journal_entry_lines WHERE entry_id = ?`, e.ID); err != nil {
+			return fmt.Errorf("failed to replace journal entry lines: %w", err)
+		}
+	} else {
+		result, err := tx.Exec(`
+			INSERT INTO journal_entries (
+				company_name, entry_date, fiscal_year, period, description, reference, source, status,
+				total_debits, total_credits, created_by, updated_by
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, e.CompanyName, e.Date.Format("2006-01-02"), e.Period.Year, e.Period.Period, e.Description, e.Reference,
+			e.Source, StatusDraft, e.TotalDebits, e.TotalCredits, username, username)
+		if err != nil {
+			return fmt.Errorf("failed to create journal entry: %w", err)
+		}
+		id, _ := result.LastInsertId()
+		e.ID = int(id)
+	}
+
+	for i, l := range e.Lines {
+		if _, err := tx.Exec(`
+			INSERT INTO journal_entry_lines (
+				entry_id, line_no, account_number, unit_number, dept_number, afe_number, description, debit, credit
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, e.ID, i+1, strings.TrimSpace(l.AccountNo), strings.TrimSpace(l.UnitNo), strings.TrimSpace(l.DeptNo),
+			strings.TrimSpace(l.AFENo), l.Description, l.Debit, l.Credit); err != nil {
+			return fmt.Errorf("failed to save journal entry line %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// DeleteEntry removes a draft
+func (s *Service) DeleteEntry(companyName string, id int) error {
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM journal_entries WHERE id = ? AND company_name = ? AND status = ?`, id, companyName, StatusDraft)
+	if err != nil {
+		return fmt.Errorf("failed to delete journal entry: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("journal entry %d not found or already posted", id)
+	}
+	if _, err := tx.Exec(`DELETE FROM journal_entry_lines WHERE entry_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete journal entry lines: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit journal entry delete: %w", err)
+	}
+	return nil
+}
+
+// PostEntry validates a draft and writes it to GLMASTER.dbf under a new CBATCH.
+// The entry is only marked posted once the rows are written.
+func (s *Service) PostEntry(companyName string, id int, username string, isAdmin bool) (*Entry, error) {
+	e, err := s.GetEntry(companyName, id)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != StatusDraft {
+		return nil, fmt.Errorf("journal entry %d is already %s", id, e.Status)
+	}
+
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	if result := s.validate(e, cal, ledger.AccountMap(accounts), isAdmin); !result.Valid {
+		return nil, &ValidationError{Result: result}
+	}
+
+	if _, err := s.postEntries(companyName, []*Entry{e}, username); err != nil {
+		return nil, err
+	}
+	return s.GetEntry(companyName, id)
+}
+
+// postEntries writes validated drafts to GLMASTER.dbf under one new CBATCH and
+// marks them posted. The drafts are claimed first so a concurrent post of the
+// same entry fails instead of writing it twice.
+func (s *Service) postEntries(companyName string, entries []*Entry, username string) (string, error) {
+	if err := s.claimEntries(companyName, entries); err != nil {
+		return "", err
+	}
+
+	var records []ledger.GLEntry
+	for _, e := range entries {
+		records = append(records, glEntries(e, username)...)
+	}
+	batch, err := ledger.PostGLEntries(companyName, nil, records)
+	if err != nil {
+		s.releaseEntries(companyName, entries)
+		return "", fmt.Errorf("failed to post journal entry: %w", err)
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return "", fmt.Errorf("journal entries posted as batch %s but could not be marked posted: %w", batch, err)
+	}
+	defer tx.Rollback()
+	for _, e := range entries {
+		if _, err := tx.Exec(`
+			UPDATE journal_entries
+			SET status = ?, batch = ?, posted_by = ?, posted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND status = ?
+		`, StatusPosted, batch, username, e.ID, StatusPosting); err != nil {
+			return "", fmt.Errorf("journal entries posted as batch %s but could not be marked posted: %w", batch, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("journal entries posted as batch %s but could not be marked posted: %w", batch, err)
+	}
+	return batch, nil
+}
+
+// claimEntries moves drafts to posting, failing if any of them is no longer a draft
+func (s *Service) claimEntries(companyName string, entries []*Entry) error {
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+	for _, e := range entries {
+		result, err := tx.Exec(`
+			UPDATE journal_entries SET status = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND company_name = ? AND status = ?
+		`, StatusPosting, e.ID, companyName, StatusDraft)
+		if err != nil {
+			return fmt.Errorf("failed to claim journal entry %d: %w", e.ID, err)
+		}
+		if n, _ := result.RowsAffected(); n != 1 {
+			return fmt.Errorf("journal entry %d is no longer a draft", e.ID)
+		}
+	}
+	return tx.Commit()
+}
+
+// releaseEntries returns claimed entries to draft after a failed post
+func (s *Service) releaseEntries(companyName string, entries []*Entry) {
+	for _, e := range entries {
+		s.db.Exec(`
+			UPDATE journal_entries SET status = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND company_name = ? AND status = ?
+		`, StatusDraft, e.ID, companyName, StatusPosting)
+	}
+}
+
+// glEntries converts a journal entry to GLMASTER records
+func glEntries(e *Entry, username string) []ledger.GLEntry {
+	entries := make([]ledger.GLEntry, 0, len(e.Lines))
+	for _, l := range e.Lines {
+		desc := l.Description
+		if desc == "" {
+			desc = e.Description
+		}
+		entries = append(entries, ledger.GLEntry{
+			Year:        e.Period.CYear(),
+			Period:      e.Period.CPeriod(),
+			Source:      e.Source,
+			Reference:   e.Reference,
+			Date:        e.Date,
+			Description: desc,
+			AccountNo:   l.AccountNo,
+			UnitNo:      l.UnitNo,
+			DeptNo:      l.DeptNo,
+			AFENo:       l.AFENo,
+			Debit:       l.Debit,
+			Credit:      l.Credit,
+			AddedBy:     username,
+		})
+	}
+	return entries
+}
+
+const entrySelect = `
+	SELECT e.id, e.company_name, e.entry_date, e.fiscal_year, e.period, COALESCE(e.description, ''),
+	       COALESCE(e.reference, ''), e.source, e.status, COALESCE(e.batch, ''), e.total_debits, e.total_credits,
+	       e.created_by, e.created_at, COALESCE(e.updated_by, ''), e.updated_at, COALESCE(e.posted_by, ''), e.posted_at
+	FROM journal_entries e`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(row rowScanner) (*Entry, error) {
+	var e Entry
+	var date, createdAt, updatedAt, postedAt interface{}
+	if err := row.Scan(&e.ID, &e.CompanyName, &date, &e.Period.Year, &e.Period.Period, &e.Description,
+		&e.Reference, &e.Source, &e.Status, &e.Batch, &e.TotalDebits, &e.TotalCredits,
+		&e.CreatedBy, &createdAt, &e.UpdatedBy, &updatedAt, &e.PostedBy, &postedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan journal entry: %w", err)
+	}
+	e.Date, _ = ledger.AsDate(date)
+	e.CreatedAt = timestamp(createdAt)
+	e.UpdatedAt = timestamp(updatedAt)
+	e.PostedAt = timestamp(postedAt)
+	return &e, nil
+}
+
+// timestamp keeps the time of day that ledger.AsDate drops
+func timestamp(v interface{}) *time.Time {
+	if t, ok := v.(time.Time); ok && !t.IsZero() {
+		return &t
+	}
+	if t, ok := ledger.AsDate(v); ok {
+		return &t
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package journal
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sheetRow is one spreadsheet row with its 1-based row number in the file
+type sheetRow struct {
+	Number int
+	Cells  []string
+}
+
+// readSpreadsheet reads the rows of an import file. Excel workbooks (.xlsx) are
+// sent base64 encoded and only the first worksheet is read; anything else is
+// read as CSV, or tab-delimited text when the first line has more tabs than
+// commas (Excel "Text (Tab delimited)" or a paste from a sheet).
+func readSpreadsheet(fileName, content string) ([]sheetRow, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".xlsx", ".xlsm":
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(content))
+		if err != nil {
+			return nil, fmt.Errorf("workbook content must be base64 encoded: %w", err)
+		}
+		return readXLSX(data)
+	case ".xls":
+		return nil, fmt.Errorf("legacy .xls workbooks are not supported; save the file as .xlsx or CSV")
+	}
+
+	content = strings.TrimPrefix(content, "\ufeff")
+	firstLine := content
+	if i := strings.IndexByte(content, '\n'); i >= 0 {
+		firstLine = content[:i]
+	}
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if strings.Count(firstLine, "\t") > strings.Count(firstLine, ",") {
+		reader.Comma = '\t'
+		reader.LazyQuotes = true
+		reader.TrimLeadingSpace = false // Would swallow empty leading cells
+	}
+
+	var rows []sheetRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", fileName, err)
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, sheetRow{Number: line, Cells: record})
+	}
+	return rows, nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []struct {
+		T    string `xml:"t"`
+		Runs []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline struct {
+				T string `xml:"t"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX reads the cell text of the first worksheet of a workbook. Dates
+// come back as Excel serial numbers; parseImportDate converts them.
+func readXLSX(data []byte) ([]sheetRow, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a valid .xlsx workbook: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	readXML := func(name string, v interface{}) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("workbook is missing %s", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return xml.NewDecoder(rc).Decode(v)
+	}
+
+	sheetPath := "xl/worksheets/sheet1.xml"
+	var wb xlsxWorkbook
+	var rels xlsxRelationships
+	if readXML("xl/workbook.xml", &wb) == nil && len(wb.Sheets) > 0 && readXML("xl/_rels/workbook.xml.rels", &rels) == nil {
+		for _, r := range rels.Relationships {
+			if r.ID == wb.Sheets[0].RID {
+				if strings.HasPrefix(r.Target, "/") {
+					sheetPath = strings.TrimPrefix(r.Target, "/")
+				} else {
+					sheetPath = path.Join("xl", r.Target)
+				}
+			}
+		}
+	}
+
+	var shared []string
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst xlsxSharedStrings
+		if err := readXML("xl/sharedStrings.xml", &sst); err != nil {
+			return nil, fmt.Errorf("failed to read shared strings: %w", err)
+		}
+		for _, si := range sst.Items {
+			text := si.T
+			for _, r := range si.Runs {
+				text += r.T
+			}
+			shared = append(shared, text)
+		}
+	}
+
+	var ws xlsxWorksheet
+	if err := readXML(sheetPath, &ws); err != nil {
+		return nil, fmt.Errorf("failed to read worksheet: %w", err)
+	}
+	rows := make([]sheetRow, 0, len(ws.Rows))
+	for i, r := range ws.Rows {
+		row := sheetRow{Number: r.Number}
+		if row.Number == 0 {
+			row.Number = i + 1
+		}
+		for j, c := range r.Cells {
+			col := j
+			if c.Ref != "" {
+				if n, ok := columnIndex(strings.TrimRight(c.Ref, "0123456789")); ok {
+					col = n
+				}
+			}
+			for len(row.Cells) <= col {
+				row.Cells = append(row.Cells, "")
+			}
+			switch c.Type {
+			case "s":
+				if n, err := strconv.Atoi(c.Value); err == nil && n >= 0 && n < len(shared) {
+					row.Cells[col] = shared[n]
+				}
+			case "inlineStr":
+				row.Cells[col] = c.Inline.T
+			case "b":
+				row.Cells[col] = map[string]string{"1": "TRUE", "0": "FALSE"}[c.Value]
+			default:
+				row.Cells[col] = c.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// columnIndex converts a column letter (A, B, ... AA) to a 0-based index
+func columnIndex(letters string) (int, bool) {
+	letters = strings.ToUpper(strings.TrimSpace(letters))
+	if letters == "" || len(letters) > 3 {
+		return 0, false
+	}
+	n := 0
+	for _, r := range letters {
+		if r < 'A' || r > 'Z' {
+			return 0, false
+		}
+		n = n*26 + int(r-'A'+1)
+	}
+	return n - 1, true
+}
@@ -0,0 +1,538 @@
+package journal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/shopspring/decimal"
+)
+
+// Template schedules
+const (
+	FrequencyMonthly   = "monthly"
+	FrequencyQuarterly = "quarterly"
+	FrequencyAnnual    = "annual"
+)
+
+// Template line amount types
+const (
+	AmountFixed   = "fixed"   // Amount as entered
+	AmountPercent = "percent" // Percent of another account's balance or activity
+	AmountPrompt  = "prompt"  // Entered when the entry is generated
+)
+
+// Percent bases
+const (
+	BasisBalance  = "balance"  // Account balance at the end of the period
+	BasisActivity = "activity" // Net activity within the period
+)
+
+// TemplateLine is one line of a recurring entry. Side is "debit" or "credit";
+// a negative computed amount is posted to the other side.
+type TemplateLine struct {
+	AccountNo    string  `json:"account_number"`
+	UnitNo       string  `json:"unit_number,omitempty"`
+	DeptNo       string  `json:"dept_number,omitempty"`
+	AFENo        string  `json:"afe_number,omitempty"`
+	Description  string  `json:"description,omitempty"`
+	Side         string  `json:"side"`
+	AmountType   string  `json:"amount_type"`
+	Amount       float64 `json:"amount,omitempty"`
+	Percent      float64 `json:"percent,omitempty"`
+	BasisAccount string  `json:"basis_account,omitempty"`
+	Basis        string  `json:"basis,omitempty"`
+	PromptLabel  string  `json:"prompt_label,omitempty"`
+}
+
+// Template is a recurring journal entry. DayOfPeriod is the day of the month
+// the entry is dated; 0 or a day past the month's end uses the last day.
+type Template struct {
+	ID          int            `json:"id"`
+	CompanyName string         `json:"company_name"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Reference   string         `json:"reference"`
+	Frequency   string         `json:"frequency"`
+	DayOfPeriod int            `json:"day_of_period"`
+	StartPeriod ledger.Period  `json:"start_period"`
+	EndPeriod   *ledger.Period `json:"end_period,omitempty"`
+	AutoReverse bool           `json:"auto_reverse"` // Reverse on the first day of the next period
+	Active      bool           `json:"active"`
+	Lines       []TemplateLine `json:"lines"`
+	CreatedBy   string         `json:"created_by,omitempty"`
+	UpdatedAt   *time.Time     `json:"updated_at,omitempty"`
+}
+
+// Validate checks the template definition
+func (t *Template) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("template name is required")
+	}
+	switch t.Frequency {
+	case FrequencyMonthly, FrequencyQuarterly, FrequencyAnnual:
+	default:
+		return fmt.Errorf("invalid frequency: %s", t.Frequency)
+	}
+	if t.StartPeriod.IsZero() {
+		return fmt.Errorf("a start period is required")
+	}
+	if t.EndPeriod != nil && t.EndPeriod.Before(t.StartPeriod) {
+		return fmt.Errorf("end period %s is before start period %s", t.EndPeriod, t.StartPeriod)
+	}
+	if t.DayOfPeriod < 0 || t.DayOfPeriod > 31 {
+		return fmt.Errorf("invalid day of period: %d", t.DayOfPeriod)
+	}
+	if len(t.Lines) < 2 {
+		return fmt.Errorf("a template needs at least two lines")
+	}
+	for i, l := range t.Lines {
+		n := i + 1
+		if strings.TrimSpace(l.AccountNo) == "" {
+			return fmt.Errorf("line %d: an account is required", n)
+		}
+		if l.Side != "debit" && l.Side != "credit" {
+			return fmt.Errorf("line %d: side must be debit or credit", n)
+		}
+		switch l.AmountType {
+		case AmountFixed, AmountPrompt:
+		case AmountPercent:
+			if l.BasisAccount == "" {
+				return fmt.Errorf("line %d: a basis account is required for a percent amount", n)
+			}
+			if l.Basis != BasisBalance && l.Basis != BasisActivity {
+				return fmt.Errorf("line %d: basis must be balance or activity", n)
+			}
+		default:
+			return fmt.Errorf("line %d: invalid amount type: %s", n, l.AmountType)
+		}
+	}
+	return nil
+}
+
+// DueIn reports whether the template generates an entry for a period of the
+// company's fiscal calendar
+func (t *Template) DueIn(cal *ledger.Calendar, p ledger.Period) bool {
+	if !t.Active || p.Before(t.StartPeriod) || (t.EndPeriod != nil && t.EndPeriod.Before(p)) {
+		return false
+	}
+	elapsed := t.StartPeriod.Span(p, cal.PeriodCount()) - 1
+	switch t.Frequency {
+	case FrequencyQuarterly:
+		return elapsed%3 == 0
+	case FrequencyAnnual:
+		return elapsed%cal.PeriodCount() == 0
+	}
+	return true
+}
+
+// entryDate returns the template's date within a period
+func (t *Template) entryDate(cal *ledger.Calendar, p ledger.Period) time.Time {
+	start, end := cal.PeriodDates(p)
+	days := int(end.Sub(start).Hours()/24) + 1
+	if t.DayOfPeriod <= 0 || t.DayOfPeriod > days {
+		return end
+	}
+	return start.AddDate(0, 0, t.DayOfPeriod-1)
+}
+
+// TemplateRun records what a template generated for a period
+type TemplateRun struct {
+	TemplateID      int           `json:"template_id"`
+	TemplateName    string        `json:"template_name"`
+	Period          ledger.Period `json:"period"`
+	EntryID         int           `json:"entry_id"`
+	ReversalEntryID int           `json:"reversal_entry_id,omitempty"`
+	Skipped         bool          `json:"skipped"` // Already generated for the period
+	GeneratedBy     string        `json:"generated_by"`
+	GeneratedAt     *time.Time    `json:"generated_at,omitempty"`
+}
+
+// DueTemplate is a template due in a period with the amounts it will prompt for
+type DueTemplate struct {
+	Template  Template       `json:"template"`
+	Prompts   []TemplateLine `json:"prompts"` // Lines whose amounts must be supplied, by position in Template.Lines
+	Generated *TemplateRun   `json:"generated,omitempty"`
+}
+
+// GetTemplates returns a company's recurring entry templates
+func (s *Service) GetTemplates(companyName string) ([]Template, error) {
+	rows, err := s.db.Query(templateSelect+` WHERE company_name = ? ORDER BY name`, companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query journal templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []Template{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *t)
+	}
+	return templates, rows.Err()
+}
+
+// GetTemplate returns one template
+func (s *Service) GetTemplate(companyName string, id int) (*Template, error) {
+	t, err := scanTemplate(s.db.QueryRow(templateSelect+` WHERE company_name = ? AND id = ?`, companyName, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("journal template %d not found", id)
+	}
+	return t, err
+}
+
+// SaveTemplate creates or updates a template
+func (s *Service) SaveTemplate(t *Template, username string) (*Template, error) {
+	for i := range t.Lines {
+		t.Lines[i].AccountNo = strings.TrimSpace(t.Lines[i].AccountNo)
+		t.Lines[i].Side = strings.ToLower(strings.TrimSpace(t.Lines[i].Side))
+		t.Lines[i].AmountType = strings.ToLower(strings.TrimSpace(t.Lines[i].AmountType))
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	lines, err := json.Marshal(t.Lines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode template lines: %w", err)
+	}
+	var endYear, endPeriod interface{}
+	if t.EndPeriod != nil {
+		endYear, endPeriod = t.EndPeriod.Year, t.EndPeriod.Period
+	}
+
+	if t.ID > 0 {
+		result, err := s.db.Exec(`
+			UPDATE journal_templates
+			SET name = ?, description = ?, reference = ?, frequency = ?, day_of_period = ?, start_year = ?, start_period = ?,
+			    end_year = ?, end_period = ?, auto_reverse = ?, active = ?, lines_json = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND company_name = ?
+		`, t.Name, t.Description, t.Reference, t.Frequency, t.DayOfPeriod, t.StartPeriod.Year, t.StartPeriod.Period,
+			endYear, endPeriod, t.AutoReverse, t.Active, string(lines), username, t.ID, t.CompanyName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update journal template: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return nil, fmt.Errorf("journal template %d not found", t.ID)
+		}
+	} else {
+		result, err := s.db.Exec(`
+			INSERT INTO journal_templates (
+				company_name, name, description, reference, frequency, day_of_period, start_year, start_period,
+				end_year, end_period, auto_reverse, active, lines_json, created_by, updated_by
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, t.CompanyName, t.Name, t.Description, t.Reference, t.Frequency, t.DayOfPeriod, t.StartPeriod.Year,
+			t.StartPeriod.Period, endYear, endPeriod, t.AutoReverse, t.Active, string(lines), username, username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save journal template: %w", err)
+		}
+		id, _ := result.LastInsertId()
+		t.ID = int(id)
+	}
+	return s.GetTemplate(t.CompanyName, t.ID)
+}
+
+// DeleteTemplate removes a template. Entries it already generated are kept.
+func (s *Service) DeleteTemplate(companyName string, id int) error {
+	result, err := s.db.Exec(`DELETE FROM journal_templates WHERE id = ? AND company_name = ?`, id, companyName)
+	if err != nil {
+		return fmt.Errorf("failed to delete journal template: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("journal template %d not found", id)
+	}
+	return nil
+}
+
+// GetDueTemplates lists the active templates due in a period, the prompted
+// amounts each needs and whether it has already been generated
+func (s *Service) GetDueTemplates(companyName string, p ledger.Period) ([]DueTemplate, error) {
+	templates, err := s.GetTemplates(companyName)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	due := []DueTemplate{}
+	for _, t := range templates {
+		if !t.DueIn(cal, p) {
+			continue
+		}
+		d := DueTemplate{Template: t, Prompts: []TemplateLine{}}
+		for _, l := range t.Lines {
+			if l.AmountType == AmountPrompt {
+				d.Prompts = append(d.Prompts, l)
+			}
+		}
+		run, err := s.templateRun(t.ID, p)
+		if err != nil {
+			return nil, err
+		}
+		d.Generated = run
+		due = append(due, d)
+	}
+	return due, nil
+}
+
+// GenerateFromTemplates creates draft entries for every template due in the
+// period, plus a reversing draft dated the first day of the next period for
+// auto-reversing templates. Templates already generated for the period are
+// skipped, so running it twice creates nothing new. prompts supplies the
+// prompted amounts by template ID and line position (1-based).
+func (s *Service) GenerateFromTemplates(companyName string, p ledger.Period, prompts map[int]map[int]float64, username string) ([]TemplateRun, error) {
+	due, err := s.GetDueTemplates(companyName, p)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+
+	var glEntries []ledger.GLEntry
+	needsGL := false
+	for _, d := range due {
+		for _, l := range d.Template.Lines {
+			if l.AmountType == AmountPercent {
+				needsGL = true
+			}
+		}
+	}
+	if needsGL {
+		if glEntries, err = ledger.LoadGLEntries(companyName); err != nil {
+			return nil, err
+		}
+	}
+
+	runs := []TemplateRun{}
+	for _, d := range due {
+		t := d.Template
+		if d.Generated != nil {
+			d.Generated.Skipped = true
+			runs = append(runs, *d.Generated)
+			continue
+		}
+
+		lines, err := templateAmounts(cal, &t, p, prompts[t.ID], glEntries)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", t.Name, err)
+		}
+		entry := &Entry{
+			CompanyName: companyName,
+			Date:        t.entryDate(cal, p),
+			Period:      p,
+			Description: firstNonEmpty(t.Description, t.Name),
+			Reference:   t.Reference,
+			Lines:       lines,
+		}
+		if err := s.prepareEntry(entry); err != nil {
+			return nil, fmt.Errorf("template %s: %w", t.Name, err)
+		}
+		var reversal *Entry
+		if t.AutoReverse {
+			next := p.Add(1, cal.PeriodCount())
+			reverseDate, _ := cal.PeriodDates(next)
+			reversed := make([]Line, len(lines))
+			for i, l := range lines {
+				l.Debit, l.Credit = l.Credit, l.Debit
+				reversed[i] = l
+			}
+			reversal = &Entry{
+				CompanyName: companyName,
+				Date:        reverseDate,
+				Period:      next,
+				Description: "Reverse " + firstNonEmpty(t.Description, t.Name),
+				Reference:   t.Reference,
+				Lines:       reversed,
+			}
+			if err := s.prepareEntry(reversal); err != nil {
+				return nil, fmt.Errorf("template %s reversal: %w", t.Name, err)
+			}
+		}
+
+		run, err := s.saveTemplateRun(t, p, entry, reversal, username)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		run.GeneratedAt = &now
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// saveTemplateRun saves a template's entry, its reversal (if any) and the run
+// record together, so a failure leaves no orphaned drafts
+func (s *Service) saveTemplateRun(t Template, p ledger.Period, entry, reversal *Entry, username string) (TemplateRun, error) {
+	run := TemplateRun{TemplateID: t.ID, TemplateName: t.Name, Period: p, GeneratedBy: username}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return run, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveEntry(tx, entry, username); err != nil {
+		return run, fmt.Errorf("template %s: %w", t.Name, err)
+	}
+	run.EntryID = entry.ID
+
+	var reversalID interface{}
+	if reversal != nil {
+		if err := saveEntry(tx, reversal, username); err != nil {
+			return run, fmt.Errorf("template %s reversal: %w", t.Name, err)
+		}
+		run.ReversalEntryID = reversal.ID
+		reversalID = reversal.ID
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO journal_template_runs (template_id, company_name, fiscal_year, period, entry_id, reversal_entry_id, generated_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(template_id, fiscal_year, period) DO UPDATE SET
+			entry_id = excluded.entry_id,
+			reversal_entry_id = excluded.reversal_entry_id,
+			generated_by = excluded.generated_by,
+			generated_at = CURRENT_TIMESTAMP
+	`, t.ID, entry.CompanyName, p.Year, p.Period, run.EntryID, reversalID, username); err != nil {
+		return run, fmt.Errorf("failed to record template run: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return run, fmt.Errorf("failed to commit template run: %w", err)
+	}
+	return run, nil
+}
+
+// templateRun returns the run for a template and period, or nil when it has not
+// been generated or its entry has since been deleted
+func (s *Service) templateRun(templateID int, p ledger.Period) (*TemplateRun, error) {
+	run := TemplateRun{TemplateID: templateID, Period: p}
+	var reversalID sql.NullInt64
+	var generatedAt interface{}
+	err := s.db.QueryRow(`
+		SELECT r.entry_id, r.reversal_entry_id, r.generated_by, r.generated_at, COALESCE(t.name, '')
+		FROM journal_template_runs r
+		JOIN journal_entries e ON e.id = r.entry_id
+		LEFT JOIN journal_templates t ON t.id = r.template_id
+		WHERE r.template_id = ? AND r.fiscal_year = ? AND r.period = ?
+	`, templateID, p.Year, p.Period).Scan(&run.EntryID, &reversalID, &run.GeneratedBy, &generatedAt, &run.TemplateName)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template run: %w", err)
+	}
+	run.ReversalEntryID = int(reversalID.Int64)
+	run.GeneratedAt = timestamp(generatedAt)
+	return &run, nil
+}
+
+// templateAmounts computes the entry lines for a period
+func templateAmounts(cal *ledger.Calendar, t *Template, p ledger.Period, prompted map[int]float64, glEntries []ledger.GLEntry) ([]Line, error) {
+	lines := make([]Line, 0, len(t.Lines))
+	for i, tl := range t.Lines {
+		n := i + 1
+		var amount currency.Currency
+		switch tl.AmountType {
+		case AmountFixed:
+			amount = currency.NewFromFloat(tl.Amount)
+		case AmountPrompt:
+			v, ok := prompted[n]
+			if !ok {
+				label := tl.PromptLabel
+				if label == "" {
+					label = "account " + tl.AccountNo
+				}
+				return nil, fmt.Errorf("line %d: an amount is required for %s", n, label)
+			}
+			amount = currency.NewFromFloat(v)
+		case AmountPercent:
+			basis := basisAmount(cal, glEntries, tl.BasisAccount, tl.Basis, p)
+			amount = basis.Abs().Mul(decimal.NewFromFloat(tl.Percent).Div(decimal.NewFromInt(100)))
+		}
+
+		line := Line{
+			AccountNo:   tl.AccountNo,
+			UnitNo:      tl.UnitNo,
+			DeptNo:      tl.DeptNo,
+			AFENo:       tl.AFENo,
+			Description: tl.Description,
+		}
+		debit := tl.Side == "debit"
+		if amount.IsNegative() {
+			debit = !debit
+			amount = amount.Neg()
+		}
+		if debit {
+			line.Debit = amount.ToFloat64()
+		} else {
+			line.Credit = amount.ToFloat64()
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// basisAmount is an account's balance at the end of a period, or its net
+// activity within the period
+func basisAmount(cal *ledger.Calendar, glEntries []ledger.GLEntry, account, basis string, p ledger.Period) currency.Currency {
+	total := currency.Zero()
+	for _, e := range glEntries {
+		if e.AccountNo != account {
+			continue
+		}
+		fp, ok := e.FiscalPeriodIn(cal)
+		if !ok {
+			continue
+		}
+		if (basis == BasisActivity && fp == p) || (basis == BasisBalance && !p.Before(fp)) {
+			total = total.Add(e.Net())
+		}
+	}
+	return total
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+const templateSelect = `
+	SELECT id, company_name, name, COALESCE(description, ''), COALESCE(reference, ''), frequency, day_of_period,
+	       start_year, start_period, end_year, end_period, auto_reverse, active, COALESCE(lines_json, '[]'),
+	       COALESCE(created_by, ''), updated_at
+	FROM journal_templates`
+
+func scanTemplate(row rowScanner) (*Template, error) {
+	var t Template
+	var endYear, endPeriod sql.NullInt64
+	var lines string
+	var updatedAt interface{}
+	if err := row.Scan(&t.ID, &t.CompanyName, &t.Name, &t.Description, &t.Reference, &t.Frequency, &t.DayOfPeriod,
+		&t.StartPeriod.Year, &t.StartPeriod.Period, &endYear, &endPeriod, &t.AutoReverse, &t.Active, &lines,
+		&t.CreatedBy, &updatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan journal template: %w", err)
+	}
+	if endYear.Valid && endPeriod.Valid {
+		t.EndPeriod = &ledger.Period{Year: int(endYear.Int64), Period: int(endPeriod.Int64)}
+	}
+	if err := json.Unmarshal([]byte(lines), &t.Lines); err != nil {
+		return nil, fmt.Errorf("invalid lines in journal template %d: %w", t.ID, err)
+	}
+	t.UpdatedAt = timestamp(updatedAt)
+	return &t, nil
+}
@@ -0,0 +1,146 @@
+package journal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Problem is one validation failure. Line is the 1-based line number, or 0 for
+// the entry as a whole.
+type Problem struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationResult lists every problem that blocks posting
+type ValidationResult struct {
+	Valid        bool      `json:"valid"`
+	Problems     []Problem `json:"problems"`
+	TotalDebits  float64   `json:"total_debits"`
+	TotalCredits float64   `json:"total_credits"`
+}
+
+// ValidationError is returned when posting is blocked by validation
+type ValidationError struct {
+	Result *ValidationResult
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Result.Problems))
+	for _, p := range e.Result.Problems {
+		if p.Line > 0 {
+			messages = append(messages, fmt.Sprintf("line %d: %s", p.Line, p.Message))
+		} else {
+			messages = append(messages, p.Message)
+		}
+	}
+	return "journal entry is not valid: " + strings.Join(messages, "; ")
+}
+
+// ValidateEntry checks a saved entry without posting it
+func (s *Service) ValidateEntry(companyName string, id int, isAdmin bool) (*ValidationResult, error) {
+	e, err := s.GetEntry(companyName, id)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := s.periods.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	return s.validate(e, cal, ledger.AccountMap(accounts), isAdmin), nil
+}
+
+// validate checks that the entry balances, every account exists in COA and is
+// active and postable, unit and department are given where COA.LACCTUNIT and
+// LACCTDEPT require them, the date falls in the entry's period of the fiscal
+// calendar, and both that period and the date's own period are open to the user
+func (s *Service) validate(e *Entry, cal *ledger.Calendar, accountMap map[string]ledger.Account, isAdmin bool) *ValidationResult {
+	result := &ValidationResult{Problems: []Problem{}}
+	add := func(line int, field, format string, args ...interface{}) {
+		result.Problems = append(result.Problems, Problem{Line: line, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if e.Date.IsZero() {
+		add(0, "date", "an entry date is required")
+	}
+	if len(e.Lines) < 2 {
+		add(0, "lines", "an entry needs at least two lines")
+	}
+
+	for i, l := range e.Lines {
+		n := i + 1
+		debit, credit := currency.NewFromFloat(l.Debit), currency.NewFromFloat(l.Credit)
+		switch {
+		case debit.IsNegative() || credit.IsNegative():
+			add(n, "amount", "amounts cannot be negative")
+		case debit.IsZero() && credit.IsZero():
+			add(n, "amount", "a debit or credit amount is required")
+		case !debit.IsZero() && !credit.IsZero():
+			add(n, "amount", "a line cannot have both a debit and a credit")
+		}
+
+		if l.AccountNo == "" {
+			add(n, "account_number", "an account is required")
+			continue
+		}
+		a, ok := accountMap[l.AccountNo]
+		switch {
+		case !ok:
+			add(n, "account_number", "account %s is not in the chart of accounts", l.AccountNo)
+			continue
+		case a.IsInactive:
+			add(n, "account_number", "account %s is inactive", l.AccountNo)
+		case a.IsTitle || a.IsTotal:
+			add(n, "account_number", "account %s is a title or total account and cannot be posted to", l.AccountNo)
+		}
+		if a.RequiresUnit && l.UnitNo == "" {
+			add(n, "unit_number", "account %s requires a unit", l.AccountNo)
+		}
+		if a.RequiresDept && l.DeptNo == "" {
+			add(n, "dept_number", "account %s requires a department", l.AccountNo)
+		}
+	}
+
+	debits, credits := e.totals()
+	result.TotalDebits, result.TotalCredits = e.TotalDebits, e.TotalCredits
+	if !debits.Equal(credits) {
+		add(0, "lines", "debits %.2f do not equal credits %.2f", e.TotalDebits, e.TotalCredits)
+	} else if debits.IsZero() && len(e.Lines) >= 2 {
+		add(0, "lines", "the entry has no amounts")
+	}
+
+	locked := []ledger.Period{}
+	if !e.Period.IsZero() {
+		locked = append(locked, e.Period)
+		if !e.Date.IsZero() {
+			start, end := cal.PeriodDates(e.Period)
+			d := time.Date(e.Date.Year(), e.Date.Month(), e.Date.Day(), 0, 0, 0, 0, time.UTC)
+			if d.Before(start) || d.After(end) {
+				add(0, "date", "the entry date %s is not in period %s (%s to %s)", e.Date.Format("2006-01-02"),
+					e.Period, start.Format("2006-01-02"), end.Format("2006-01-02"))
+			}
+		}
+	}
+	if !e.Date.IsZero() {
+		if p := cal.PeriodForDate(e.Date); p != e.Period {
+			locked = append(locked, p)
+		}
+	}
+	for _, p := range locked {
+		if err := s.periods.EnsureOpen(e.CompanyName, p, isAdmin); err != nil {
+			add(0, "period", "%s", err.Error())
+		}
+	}
+
+	result.Valid = len(result.Problems) == 0
+	return result
+}
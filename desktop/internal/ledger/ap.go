@@ -0,0 +1,61 @@
+package ledger
+
+import "time"
+
+// Payable is an AP invoice header from APPURCHH.dbf
+type Payable struct {
+	RowIndex      int       `json:"row_index"`
+	VendorID      string    `json:"vendor_id"`
+	InvoiceNumber string    `json:"invoice_number"`
+	InvoiceDate   time.Time `json:"invoice_date"`
+	DueDate       time.Time `json:"due_date"`
+	DiscountDate  time.Time `json:"discount_date"`
+	Total         float64   `json:"total"`
+	Payments      float64   `json:"payments"`
+	Balance       float64   `json:"balance"`
+	APAccount     string    `json:"ap_account"`
+	Batch         string    `json:"batch"`
+	Reference     string    `json:"reference"`
+	Approved      bool      `json:"approved"`
+}
+
+// LoadPayables reads every active invoice header from APPURCHH.dbf
+func LoadPayables(companyName string) ([]Payable, error) {
+	t, err := loadTable(companyName, "APPURCHH.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	vendorIdx := t.col("CVENDORID")
+	invNumIdx := t.col("CINVNUM")
+	invDateIdx := t.col("DINVDATE")
+	dueIdx := t.col("DDUEDATE")
+	discIdx := t.col("DDISCDATE")
+	totalIdx := t.col("NINVTOT")
+	paymentsIdx := t.col("NPAYMENTS")
+	balanceIdx := t.col("NINVBAL")
+	apAcctIdx := t.col("CAPACCT")
+	batchIdx := t.col("CBATCH")
+	refIdx := t.col("CREFERENCE")
+	approvedIdx := t.col("LAPPROVED")
+
+	payables := make([]Payable, 0, len(t.rows))
+	for i, row := range t.rows {
+		payables = append(payables, Payable{
+			RowIndex:      i,
+			VendorID:      stringValue(row, vendorIdx),
+			InvoiceNumber: stringValue(row, invNumIdx),
+			InvoiceDate:   dateValue(row, invDateIdx),
+			DueDate:       dateValue(row, dueIdx),
+			DiscountDate:  dateValue(row, discIdx),
+			Total:         floatValue(row, totalIdx),
+			Payments:      floatValue(row, paymentsIdx),
+			Balance:       floatValue(row, balanceIdx),
+			APAccount:     stringValue(row, apAcctIdx),
+			Batch:         stringValue(row, batchIdx),
+			Reference:     stringValue(row, refIdx),
+			Approved:      boolValue(row, approvedIdx),
+		})
+	}
+	return payables, nil
+}
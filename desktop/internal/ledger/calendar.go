@@ -0,0 +1,241 @@
+package ledger
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Calendar maps dates to fiscal periods. The default is twelve calendar-month
+// periods starting in January. Companies with another year end set StartMonth;
+// a thirteenth period is either a year-end adjustment period dated the last day
+// of the year or, for 4-4-5 and four-week calendars, given explicit dates in
+// Years.
+type Calendar struct {
+	StartMonth       int                   `json:"start_month"`         // First month of the fiscal year, 1-12
+	PeriodsPerYear   int                   `json:"periods_per_year"`    // 12 or 13
+	NamedByStartYear bool                  `json:"named_by_start_year"` // FY is named for the year it starts in rather than ends in
+	Years            map[int][]PeriodRange `json:"years,omitempty"`     // Explicit period dates by fiscal year
+}
+
+// PeriodRange is the first and last day of one fiscal period
+type PeriodRange struct {
+	Period    int       `json:"period"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+// DefaultCalendar returns twelve calendar-month periods starting in January
+func DefaultCalendar() *Calendar {
+	return &Calendar{StartMonth: 1, PeriodsPerYear: DefaultPeriodsPerYear}
+}
+
+// CalendarFromOptions returns a monthly calendar starting at GLOPT's CFYBEGIN
+func CalendarFromOptions(opts *GLOptions) *Calendar {
+	c := DefaultCalendar()
+	if opts != nil && opts.FiscalYearBegin >= 1 && opts.FiscalYearBegin <= 12 {
+		c.StartMonth = opts.FiscalYearBegin
+	}
+	return c
+}
+
+// orDefault lets a nil calendar stand for the default one
+func (c *Calendar) orDefault() *Calendar {
+	if c == nil {
+		return DefaultCalendar()
+	}
+	return c
+}
+
+// PeriodCount returns the number of periods in a fiscal year
+func (c *Calendar) PeriodCount() int {
+	c = c.orDefault()
+	if c.PeriodsPerYear == 13 {
+		return 13
+	}
+	return DefaultPeriodsPerYear
+}
+
+// startMonth returns StartMonth, treating an unset value as January
+func (c *Calendar) startMonth() int {
+	if c.StartMonth < 1 || c.StartMonth > 12 {
+		return 1
+	}
+	return c.StartMonth
+}
+
+// IsCalendarYear reports whether periods are the calendar months of January
+// through December, so that CPERIOD equals the month of the entry date
+func (c *Calendar) IsCalendarYear() bool {
+	c = c.orDefault()
+	return c.startMonth() == 1 && len(c.Years) == 0
+}
+
+// SameFiscalYear reports whether two calendars number fiscal years and periods
+// the same way, so that their ledgers can be combined period by period
+func (c *Calendar) SameFiscalYear(o *Calendar) bool {
+	c, o = c.orDefault(), o.orDefault()
+	return c.PeriodCount() == o.PeriodCount() && c.startMonth() == o.startMonth() && c.NamedByStartYear == o.NamedByStartYear
+}
+
+// explicitYear returns the explicit period dates of a fiscal year, in order
+func (c *Calendar) explicitYear(year int) []PeriodRange {
+	ranges := c.Years[year]
+	if len(ranges) == 0 {
+		return nil
+	}
+	sorted := append([]PeriodRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Period < sorted[j].Period })
+	return sorted
+}
+
+// monthlyYearStart returns the first day of a fiscal year of calendar months
+func (c *Calendar) monthlyYearStart(year int) time.Time {
+	start := c.startMonth()
+	if start != 1 && !c.NamedByStartYear {
+		year--
+	}
+	return time.Date(year, time.Month(start), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// YearStart returns the first day of a fiscal year
+func (c *Calendar) YearStart(year int) time.Time {
+	c = c.orDefault()
+	if ranges := c.explicitYear(year); ranges != nil {
+		return dateOnly(ranges[0].StartDate)
+	}
+	return c.monthlyYearStart(year)
+}
+
+// YearEnd returns the last day of a fiscal year
+func (c *Calendar) YearEnd(year int) time.Time {
+	c = c.orDefault()
+	if ranges := c.explicitYear(year); ranges != nil {
+		return dateOnly(ranges[len(ranges)-1].EndDate)
+	}
+	return c.monthlyYearStart(year).AddDate(1, 0, -1)
+}
+
+// PeriodDates returns the first and last day of a period. Periods after the
+// twelfth month of a monthly calendar (the adjustment period, or the closing
+// pseudo period) are dated the last day of the year.
+func (c *Calendar) PeriodDates(p Period) (time.Time, time.Time) {
+	c = c.orDefault()
+	if ranges := c.explicitYear(p.Year); ranges != nil {
+		for _, r := range ranges {
+			if r.Period == p.Period {
+				return dateOnly(r.StartDate), dateOnly(r.EndDate)
+			}
+		}
+		end := dateOnly(ranges[len(ranges)-1].EndDate)
+		return end, end
+	}
+	if p.Period < 1 || p.Period > DefaultPeriodsPerYear {
+		end := c.YearEnd(p.Year)
+		return end, end
+	}
+	start := c.monthlyYearStart(p.Year).AddDate(0, p.Period-1, 0)
+	return start, start.AddDate(0, 1, -1)
+}
+
+// PeriodForDate returns the period a date falls in. A monthly calendar never
+// returns the adjustment period; entries reach it only by CPERIOD.
+func (c *Calendar) PeriodForDate(d time.Time) Period {
+	c = c.orDefault()
+	d = dateOnly(d)
+	for year := range c.Years {
+		ranges := c.explicitYear(year)
+		if d.Before(dateOnly(ranges[0].StartDate)) || d.After(dateOnly(ranges[len(ranges)-1].EndDate)) {
+			continue
+		}
+		for _, r := range ranges {
+			if !d.Before(dateOnly(r.StartDate)) && !d.After(dateOnly(r.EndDate)) {
+				return Period{Year: year, Period: r.Period}
+			}
+		}
+	}
+
+	months := d.Year()*12 + int(d.Month()) - c.startMonth()
+	p := Period{Year: months / 12, Period: months%12 + 1}
+	if c.startMonth() != 1 && !c.NamedByStartYear {
+		p.Year++
+	}
+	// A date next to a year with explicit dates belongs to its neighbour
+	if ranges := c.explicitYear(p.Year); ranges != nil {
+		if d.After(dateOnly(ranges[len(ranges)-1].EndDate)) {
+			return Period{Year: p.Year + 1, Period: 1}
+		}
+		return Period{Year: p.Year - 1, Period: c.PeriodCount()}
+	}
+	return p
+}
+
+// YearPeriods returns the dates of every period of a fiscal year
+func (c *Calendar) YearPeriods(year int) []PeriodRange {
+	c = c.orDefault()
+	ranges := make([]PeriodRange, 0, c.PeriodCount())
+	for n := 1; n <= c.PeriodCount(); n++ {
+		start, end := c.PeriodDates(Period{Year: year, Period: n})
+		ranges = append(ranges, PeriodRange{Period: n, StartDate: start, EndDate: end})
+	}
+	return ranges
+}
+
+// QuarterStart returns the first period of the quarter containing p. Periods
+// are grouped in threes; a thirteenth period belongs to the fourth quarter.
+func (c *Calendar) QuarterStart(p Period) Period {
+	q := (p.Period - 1) / 3
+	if q > 3 {
+		q = 3
+	}
+	return Period{Year: p.Year, Period: q*3 + 1}
+}
+
+// Validate checks the start month, the period count and that explicit period
+// dates run day after day with no gaps or overlaps
+func (c *Calendar) Validate() error {
+	if c.StartMonth < 1 || c.StartMonth > 12 {
+		return fmt.Errorf("fiscal year start month must be 1-12")
+	}
+	if c.PeriodsPerYear != 12 && c.PeriodsPerYear != 13 {
+		return fmt.Errorf("a fiscal year must have 12 or 13 periods")
+	}
+
+	years := make([]int, 0, len(c.Years))
+	for year := range c.Years {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+	for i, year := range years {
+		if year <= 0 {
+			return fmt.Errorf("invalid fiscal year: %d", year)
+		}
+		ranges := c.explicitYear(year)
+		if len(ranges) != c.PeriodsPerYear {
+			return fmt.Errorf("fiscal year %d: %d periods given, the calendar has %d", year, len(ranges), c.PeriodsPerYear)
+		}
+		for j, r := range ranges {
+			if r.Period != j+1 {
+				return fmt.Errorf("fiscal year %d: periods must be numbered 1-%d", year, c.PeriodsPerYear)
+			}
+			if r.StartDate.IsZero() || r.EndDate.IsZero() || dateOnly(r.EndDate).Before(dateOnly(r.StartDate)) {
+				return fmt.Errorf("fiscal year %d period %d: end date is before the start date", year, r.Period)
+			}
+			if j > 0 && !dateOnly(r.StartDate).Equal(dateOnly(ranges[j-1].EndDate).AddDate(0, 0, 1)) {
+				return fmt.Errorf("fiscal year %d period %d must start the day after period %d ends", year, r.Period, r.Period-1)
+			}
+		}
+		if i > 0 && years[i-1] == year-1 {
+			prevEnd := dateOnly(c.explicitYear(year - 1)[c.PeriodsPerYear-1].EndDate)
+			if !dateOnly(ranges[0].StartDate).Equal(prevEnd.AddDate(0, 0, 1)) {
+				return fmt.Errorf("fiscal year %d must start the day after fiscal year %d ends", year, year-1)
+			}
+		}
+	}
+	return nil
+}
+
+// dateOnly drops the time of day so period boundaries compare by date
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
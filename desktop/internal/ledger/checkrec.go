@@ -0,0 +1,49 @@
+package ledger
+
+import (
+	"sort"
+	"time"
+)
+
+// CheckRec is a typed CHECKREC.dbf record - one legacy bank reconciliation
+type CheckRec struct {
+	AccountNo    string    `json:"account_number"`
+	RecDate      time.Time `json:"rec_date"`
+	BeginBalance float64   `json:"beginning_balance"`
+	EndBalance   float64   `json:"ending_balance"` // Zero on interim or unfinished reconciliations
+}
+
+// LoadCheckRecs reads CHECKREC.dbf sorted by account and reconciliation date
+func LoadCheckRecs(companyName string) ([]CheckRec, error) {
+	t, err := loadTable(companyName, "CHECKREC.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	accountIdx := t.col("CACCTNO")
+	dateIdx := t.col("DRECDATE")
+	begIdx := t.col("NOPENBAL", "NBEGBAL")
+	endIdx := t.col("NENDBAL")
+
+	var recs []CheckRec
+	for _, row := range t.rows {
+		rec := CheckRec{
+			AccountNo:    stringValue(row, accountIdx),
+			RecDate:      dateValue(row, dateIdx),
+			BeginBalance: floatValue(row, begIdx),
+			EndBalance:   floatValue(row, endIdx),
+		}
+		if rec.RecDate.IsZero() {
+			continue
+		}
+		recs = append(recs, rec)
+	}
+
+	sort.Slice(recs, func(i, j int) bool {
+		if recs[i].AccountNo != recs[j].AccountNo {
+			return recs[i].AccountNo < recs[j].AccountNo
+		}
+		return recs[i].RecDate.Before(recs[j].RecDate)
+	})
+	return recs, nil
+}
@@ -0,0 +1,135 @@
+package ledger
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/company"
+)
+
+// Check is a typed CHECKS.dbf record. CHECKS.dbf holds both checks (CENTRYTYPE=C)
+// and deposits (CENTRYTYPE=D).
+type Check struct {
+	RowIndex    int       `json:"row_index"` // Position among active rows, as returned by ReadDBFFile
+	CheckNumber string    `json:"check_number"`
+	CID         string    `json:"cid"`
+	CIDCHEC     string    `json:"cidchec"`
+	Payee       string    `json:"payee"`
+	CheckDate   time.Time `json:"check_date"`
+	Amount      float64   `json:"amount"`
+	Year        string    `json:"year"`
+	Period      string    `json:"period"`
+	AccountNo   string    `json:"account_number"`
+	EntryType   string    `json:"entry_type"`
+	Batch       string    `json:"batch"`
+	Memo        string    `json:"memo"`
+	Source      string    `json:"source"`
+	IsVoid      bool      `json:"is_void"`
+	VoidAmount  float64   `json:"void_amount"`
+	IsCleared   bool      `json:"is_cleared"`
+	PostDate    time.Time `json:"post_date"`
+	RecDate     time.Time `json:"rec_date"`
+}
+
+// IsDeposit reports whether the entry is a deposit rather than a check
+func (c Check) IsDeposit() bool {
+	return c.EntryType == "D"
+}
+
+// IsOutstanding mirrors GetOutstandingChecks: not cleared and not voided
+func (c Check) IsOutstanding() bool {
+	return !c.IsCleared && !c.IsVoid
+}
+
+// LoadChecks reads every active record from CHECKS.dbf
+func LoadChecks(companyName string) ([]Check, error) {
+	t, err := loadTable(companyName, "checks.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	checkNumIdx := t.col("CCHECKNO")
+	cidIdx := t.col("CID")
+	cidchecIdx := t.col("CIDCHEC")
+	payeeIdx := t.col("CPAYEE")
+	dateIdx := t.col("DCHECKDATE")
+	amountIdx := t.col("NAMOUNT")
+	yearIdx := t.col("CYEAR")
+	periodIdx := t.col("CPERIOD")
+	accountIdx := t.col("CACCTNO")
+	entryTypeIdx := t.col("CENTRYTYPE")
+	batchIdx := t.col("CBATCH")
+	memoIdx := t.col("CMEMO")
+	sourceIdx := t.col("CSOURCE")
+	voidIdx := t.col("LVOID")
+	voidAmtIdx := t.col("NVOIDAMT")
+	clearedIdx := t.col("LCLEARED")
+	postDateIdx := t.col("DPOSTDATE")
+	recDateIdx := t.col("DRECDATE")
+
+	checks := make([]Check, 0, len(t.rows))
+	for i, row := range t.rows {
+		checks = append(checks, Check{
+			RowIndex:    i,
+			CheckNumber: stringValue(row, checkNumIdx),
+			CID:         stringValue(row, cidIdx),
+			CIDCHEC:     stringValue(row, cidchecIdx),
+			Payee:       stringValue(row, payeeIdx),
+			CheckDate:   dateValue(row, dateIdx),
+			Amount:      floatValue(row, amountIdx),
+			Year:        stringValue(row, yearIdx),
+			Period:      stringValue(row, periodIdx),
+			AccountNo:   stringValue(row, accountIdx),
+			EntryType:   strings.ToUpper(stringValue(row, entryTypeIdx)),
+			Batch:       stringValue(row, batchIdx),
+			Memo:        stringValue(row, memoIdx),
+			Source:      stringValue(row, sourceIdx),
+			IsVoid:      boolValue(row, voidIdx),
+			VoidAmount:  floatValue(row, voidAmtIdx),
+			IsCleared:   boolValue(row, clearedIdx),
+			PostDate:    dateValue(row, postDateIdx),
+			RecDate:     dateValue(row, recDateIdx),
+		})
+	}
+	return checks, nil
+}
+
+// NormalizeCheckNumber strips formatting and leading zeros so that bank files
+// ("0001234", "1234*") compare equal to CHECKS.dbf values
+func NormalizeCheckNumber(checkNumber string) string {
+	n := strings.TrimSpace(checkNumber)
+	n = strings.Trim(n, "*#")
+	n = strings.TrimLeft(n, "0")
+	return n
+}
+
+// MarkChecksCleared sets LCLEARED and the DRECDATE clear date on the CHECKS.dbf
+// rows identified by CIDCHEC, keyed to the date each check cleared. Checks that
+// are missing or already cleared are left alone. Returns the CIDCHECs written.
+func MarkChecksCleared(companyName string, cleared map[string]time.Time) ([]string, error) {
+	if len(cleared) == 0 {
+		return nil, nil
+	}
+	checks, err := LoadChecks(companyName)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]map[string]interface{}, len(cleared))
+	var written []string
+	for _, c := range checks {
+		date, ok := cleared[c.CIDCHEC]
+		if !ok || c.CIDCHEC == "" || c.IsCleared {
+			continue
+		}
+		if _, dup := updates[c.CIDCHEC]; dup {
+			continue
+		}
+		updates[c.CIDCHEC] = map[string]interface{}{"LCLEARED": true, "DRECDATE": date}
+		written = append(written, c.CIDCHEC)
+	}
+	if _, err := company.UpdateDBFRowsByKey(companyName, "checks.dbf", "CIDCHEC", updates); err != nil {
+		return nil, err
+	}
+	return written, nil
+}
@@ -0,0 +1,125 @@
+package ledger
+
+import (
+	"sort"
+	"strings"
+)
+
+// COA.NACCTTYPE values
+const (
+	TypeAsset     = 1
+	TypeLiability = 2
+	TypeEquity    = 3
+	TypeRevenue   = 4
+	TypeExpense   = 5
+	TypeOther     = 6
+)
+
+// AccountTypeName returns the display name GetChartOfAccounts uses for a NACCTTYPE
+func AccountTypeName(accountType int) string {
+	switch accountType {
+	case TypeAsset:
+		return "Asset"
+	case TypeLiability:
+		return "Liability"
+	case TypeEquity:
+		return "Equity"
+	case TypeRevenue:
+		return "Revenue"
+	case TypeExpense:
+		return "Expense"
+	}
+	return "Other"
+}
+
+// IsDebitNormal reports whether an account type carries a debit balance
+// (assets and expenses). Liabilities, equity and revenue are credit-normal.
+func IsDebitNormal(accountType int) bool {
+	return accountType == TypeAsset || accountType == TypeExpense || accountType == TypeOther
+}
+
+// IsBalanceSheet reports whether an account type rolls forward year to year
+func IsBalanceSheet(accountType int) bool {
+	return accountType == TypeAsset || accountType == TypeLiability || accountType == TypeEquity
+}
+
+// Account is a typed COA.dbf record
+type Account struct {
+	RowIndex     int    `json:"row_index"`
+	AccountNo    string `json:"account_number"`
+	Type         int    `json:"account_type"`
+	TypeName     string `json:"account_type_name"`
+	Description  string `json:"description"`
+	Parent       string `json:"parent"`
+	RequiresUnit bool   `json:"requires_unit"`
+	RequiresDept bool   `json:"requires_dept"`
+	IsBank       bool   `json:"is_bank_account"`
+	IsInactive   bool   `json:"is_inactive"`
+	IsTitle      bool   `json:"is_title"`
+	IsTotal      bool   `json:"is_total"`
+}
+
+// HasParent reports whether CPARENT points at another account. The legacy data uses
+// blanks and zero strings for "no parent".
+func (a Account) HasParent() bool {
+	p := strings.Trim(a.Parent, "0 ")
+	return p != "" && a.Parent != a.AccountNo
+}
+
+// LoadAccounts reads every active record from COA.dbf sorted by account number
+func LoadAccounts(companyName string) ([]Account, error) {
+	t, err := loadTable(companyName, "COA.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	acctIdx := t.col("CACCTNO")
+	typeIdx := t.col("NACCTTYPE", "CACCTTYPE")
+	descIdx := t.col("CACCTDESC")
+	parentIdx := t.col("CPARENT")
+	unitIdx := t.col("LACCTUNIT")
+	deptIdx := t.col("LACCTDEPT")
+	bankIdx := t.col("LBANKACCT")
+	inactiveIdx := t.col("LINACTIVE")
+	titleIdx := t.col("LTITLE")
+	totalIdx := t.col("LTOTALACCT")
+
+	accounts := make([]Account, 0, len(t.rows))
+	for i, row := range t.rows {
+		acct := Account{
+			RowIndex:     i,
+			AccountNo:    stringValue(row, acctIdx),
+			Type:         int(floatValue(row, typeIdx)),
+			Description:  stringValue(row, descIdx),
+			Parent:       stringValue(row, parentIdx),
+			RequiresUnit: boolValue(row, unitIdx),
+			RequiresDept: boolValue(row, deptIdx),
+			IsBank:       boolValue(row, bankIdx),
+			IsInactive:   boolValue(row, inactiveIdx),
+			IsTitle:      boolValue(row, titleIdx),
+			IsTotal:      boolValue(row, totalIdx),
+		}
+		if acct.AccountNo == "" {
+			continue
+		}
+		if acct.Type < TypeAsset || acct.Type > TypeOther {
+			acct.Type = TypeOther
+		}
+		acct.TypeName = AccountTypeName(acct.Type)
+		accounts = append(accounts, acct)
+	}
+
+	sort.SliceStable(accounts, func(i, j int) bool {
+		return accounts[i].AccountNo < accounts[j].AccountNo
+	})
+	return accounts, nil
+}
+
+// AccountMap indexes accounts by account number
+func AccountMap(accounts []Account) map[string]Account {
+	m := make(map[string]Account, len(accounts))
+	for _, a := range accounts {
+		m[a.AccountNo] = a
+	}
+	return m
+}
@@ -0,0 +1,169 @@
+// Package ledger provides typed views over the company DBF tables (CHECKS, GLMASTER, COA)
+// so that services can work with structs instead of raw column indexes.
+package ledger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/company"
+)
+
+// table is a fully loaded DBF file with a case-insensitive column lookup
+type table struct {
+	columns []string
+	index   map[string]int
+	rows    [][]interface{}
+}
+
+// loadTable reads ALL records of a DBF file (offset=0, limit=0) for financial accuracy
+func loadTable(companyName, fileName string) (*table, error) {
+	data, err := company.ReadDBFFile(companyName, fileName, "", 0, 0, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fileName, err)
+	}
+
+	columns, ok := data["columns"].([]string)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s structure", fileName)
+	}
+	rows, _ := data["rows"].([][]interface{})
+
+	return newTable(columns, rows), nil
+}
+
+// newTable indexes columns for case-insensitive lookup
+func newTable(columns []string, rows [][]interface{}) *table {
+	t := &table{columns: columns, index: make(map[string]int), rows: rows}
+	for i, col := range columns {
+		t.index[strings.ToUpper(col)] = i
+	}
+	return t
+}
+
+// col returns the index of the first column found among names, or -1
+func (t *table) col(names ...string) int {
+	for _, name := range names {
+		if idx, ok := t.index[strings.ToUpper(name)]; ok {
+			return idx
+		}
+	}
+	return -1
+}
+
+// value safely returns the value at idx, or nil if the column is missing
+func value(row []interface{}, idx int) interface{} {
+	if idx < 0 || idx >= len(row) {
+		return nil
+	}
+	return row[idx]
+}
+
+// stringValue returns a trimmed string for a DBF field
+func stringValue(row []interface{}, idx int) string {
+	v := value(row, idx)
+	if v == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%v", v))
+}
+
+// floatValue returns a numeric DBF field as float64
+func floatValue(row []interface{}, idx int) float64 {
+	switch v := value(row, idx).(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f
+		}
+	}
+	return 0
+}
+
+// boolValue parses a DBF logical field. Empty strings are FALSE.
+func boolValue(row []interface{}, idx int) bool {
+	switch v := value(row, idx).(type) {
+	case bool:
+		return v
+	case string:
+		lowerVal := strings.ToLower(strings.TrimSpace(v))
+		return lowerVal == "t" || lowerVal == ".t." || lowerVal == "true" || lowerVal == "1" || lowerVal == "y"
+	}
+	return false
+}
+
+// dateValue parses a DBF date field. The dbase library returns time.Time directly,
+// but older exports may carry strings.
+func dateValue(row []interface{}, idx int) time.Time {
+	t, _ := AsDate(value(row, idx))
+	return t
+}
+
+// AsDate converts a date from a DBF field or a SQLite DATE column (time.Time,
+// string or []byte) to the calendar day at midnight UTC
+func AsDate(v interface{}) (time.Time, bool) {
+	switch d := v.(type) {
+	case time.Time:
+		if d.IsZero() {
+			return time.Time{}, false
+		}
+		return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC), true
+	case string:
+		return ParseDate(d)
+	case []byte:
+		return ParseDate(string(d))
+	}
+	return time.Time{}, false
+}
+
+// ParseDate parses the date formats found in DBF exports and bank files and
+// returns the calendar day at midnight UTC
+func ParseDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	formats := []string{
+		"2006-01-02",
+		"01/02/2006",
+		"1/2/2006",
+		"01/02/06",
+		"1/2/06",
+		"20060102",
+		"2006/01/02",
+		"01-02-2006",
+		"2006-01-02 15:04:05",
+		time.RFC3339,
+	}
+	for _, format := range formats {
+		if t, err := time.Parse(format, s); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// LoadRecord reads one active record by its position, as used by UpdateDBFRecord
+func LoadRecord(companyName, fileName string, rowIndex int) ([]string, []interface{}, error) {
+	data, err := company.ReadDBFFile(companyName, fileName, "", rowIndex, 1, "", "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", fileName, err)
+	}
+	columns, _ := data["columns"].([]string)
+	rows, _ := data["rows"].([][]interface{})
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("row %d not found in %s", rowIndex, fileName)
+	}
+	return columns, rows[0], nil
+}
@@ -0,0 +1,97 @@
+package ledger
+
+import "time"
+
+// Expense is a well expense record from EXPENSE.dbf
+type Expense struct {
+	RowIndex    int       `json:"row_index"`
+	ExpenseID   string    `json:"expense_id"`
+	WellID      string    `json:"well_id"`
+	AFENo       string    `json:"afe_number"`
+	CatCode     string    `json:"cat_code"`
+	Date        time.Time `json:"date"`
+	AcctDate    time.Time `json:"acct_date"`
+	Year        string    `json:"year"`
+	Period      string    `json:"period"`
+	VendorID    string    `json:"vendor_id"`
+	Payee       string    `json:"payee"`
+	Description string    `json:"description"`
+	Batch       string    `json:"batch"`
+	Amount      float64   `json:"amount"`
+}
+
+// ExpenseCategory is a cost category from EXPCAT.dbf
+type ExpenseCategory struct {
+	Code        string `json:"code"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+}
+
+// LoadExpenses reads every active record from EXPENSE.dbf
+func LoadExpenses(companyName string) ([]Expense, error) {
+	t, err := loadTable(companyName, "EXPENSE.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	idIdx := t.col("CIDEXPE")
+	wellIdx := t.col("CWELLID")
+	afeIdx := t.col("CAFENO")
+	catIdx := t.col("CCATCODE")
+	dateIdx := t.col("DEXPDATE")
+	acctDateIdx := t.col("DACCTDATE")
+	yearIdx := t.col("CACCTYEAR", "CYEAR")
+	periodIdx := t.col("CACCTPRD", "CPERIOD")
+	vendorIdx := t.col("CVENDORID")
+	payeeIdx := t.col("CPAYEE")
+	memoIdx := t.col("CMEMO")
+	batchIdx := t.col("CBATCH")
+	amountIdx := t.col("NAMOUNT")
+
+	expenses := make([]Expense, 0, len(t.rows))
+	for i, row := range t.rows {
+		expenses = append(expenses, Expense{
+			RowIndex:    i,
+			ExpenseID:   stringValue(row, idIdx),
+			WellID:      stringValue(row, wellIdx),
+			AFENo:       stringValue(row, afeIdx),
+			CatCode:     stringValue(row, catIdx),
+			Date:        dateValue(row, dateIdx),
+			AcctDate:    dateValue(row, acctDateIdx),
+			Year:        stringValue(row, yearIdx),
+			Period:      stringValue(row, periodIdx),
+			VendorID:    stringValue(row, vendorIdx),
+			Payee:       stringValue(row, payeeIdx),
+			Description: stringValue(row, memoIdx),
+			Batch:       stringValue(row, batchIdx),
+			Amount:      floatValue(row, amountIdx),
+		})
+	}
+	return expenses, nil
+}
+
+// LoadExpenseCategories reads the cost categories in EXPCAT.dbf
+func LoadExpenseCategories(companyName string) ([]ExpenseCategory, error) {
+	t, err := loadTable(companyName, "EXPCAT.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	codeIdx := t.col("CCATCODE")
+	categIdx := t.col("CCATEG")
+	descIdx := t.col("CDESCRIP")
+
+	categories := make([]ExpenseCategory, 0, len(t.rows))
+	for _, row := range t.rows {
+		code := stringValue(row, codeIdx)
+		if code == "" {
+			continue
+		}
+		categories = append(categories, ExpenseCategory{
+			Code:        code,
+			Category:    stringValue(row, categIdx),
+			Description: stringValue(row, descIdx),
+		})
+	}
+	return categories, nil
+}
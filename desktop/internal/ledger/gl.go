@@ -0,0 +1,253 @@
+package ledger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/company"
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+)
+
+// GLMASTER CSOURCE codes written by this application
+const (
+	SourceJournal = "GJ" // General journal entries
+	SourceYearEnd = "YE" // Entries that close income statement accounts to retained earnings
+)
+
+// GLEntry is a typed GLMASTER.dbf record
+type GLEntry struct {
+	RowIndex    int       `json:"row_index"`
+	CIDGLMA     string    `json:"cidglma"`
+	Batch       string    `json:"batch"`
+	Year        string    `json:"year"`
+	Period      string    `json:"period"`
+	Source      string    `json:"source"`
+	Reference   string    `json:"reference"`
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	AccountNo   string    `json:"account_number"`
+	UnitNo      string    `json:"unit_number"`
+	DeptNo      string    `json:"dept_number"`
+	Debit       float64   `json:"debit"`
+	Credit      float64   `json:"credit"`
+	CID         string    `json:"cid"`
+	CIDCHEC     string    `json:"cidchec"`
+	AFENo       string    `json:"afe_number"`
+	CatCode     string    `json:"cat_code"`
+	AddedBy     string    `json:"added_by"`
+	DateAdded   time.Time `json:"date_added"`
+}
+
+// Net returns debits minus credits for the entry
+func (e GLEntry) Net() currency.Currency {
+	return currency.NewFromFloat(e.Debit).Sub(currency.NewFromFloat(e.Credit))
+}
+
+// IsClosingEntry reports whether the entry is a year-end closing entry
+func (e GLEntry) IsClosingEntry() bool {
+	return strings.EqualFold(e.Source, SourceYearEnd)
+}
+
+// LoadGLEntries reads every active record from GLMASTER.dbf
+func LoadGLEntries(companyName string) ([]GLEntry, error) {
+	t, err := loadTable(companyName, "GLMASTER.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	f := newGLFields(t)
+	entries := make([]GLEntry, 0, len(t.rows))
+	for i, row := range t.rows {
+		entries = append(entries, f.entry(i, row))
+	}
+	return entries, nil
+}
+
+// ScanGLEntries streams every active GLMASTER.dbf record to fn in file order
+// without loading the table, for single-pass totals over every account
+func ScanGLEntries(companyName string, fn func(GLEntry) error) error {
+	var f *glFields
+	i := 0
+	err := company.ScanDBFFile(companyName, "GLMASTER.dbf", func(columns []string, row []interface{}) error {
+		if f == nil {
+			fields := newGLFields(newTable(columns, nil))
+			f = &fields
+		}
+		e := f.entry(i, row)
+		i++
+		return fn(e)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read GLMASTER.dbf: %w", err)
+	}
+	return nil
+}
+
+// glFields holds the GLMASTER column indexes, resolving the alternate names
+// older files use for the account and amount columns
+type glFields struct {
+	id, batch, year, period, source, ref, date, desc, account, unit, dept int
+	debit, credit, cid, cidchec, afe, cat, addedBy, added                 int
+}
+
+func newGLFields(t *table) glFields {
+	return glFields{
+		id:      t.col("CIDGLMA"),
+		batch:   t.col("CBATCH"),
+		year:    t.col("CYEAR"),
+		period:  t.col("CPERIOD"),
+		source:  t.col("CSOURCE"),
+		ref:     t.col("CREF"),
+		date:    t.col("DDATE"),
+		desc:    t.col("CDESC"),
+		account: t.col("CACCTNO", "ACCOUNT", "ACCTNO"),
+		unit:    t.col("CUNITNO"),
+		dept:    t.col("CDEPTNO"),
+		debit:   t.col("NDEBITS", "DEBIT", "NDEBIT"),
+		credit:  t.col("NCREDITS", "CREDIT", "NCREDIT"),
+		cid:     t.col("CID"),
+		cidchec: t.col("CIDCHEC"),
+		afe:     t.col("CAFENO"),
+		cat:     t.col("CCATCODE"),
+		addedBy: t.col("CADDEDBY"),
+		added:   t.col("DADDED"),
+	}
+}
+
+func (f glFields) entry(i int, row []interface{}) GLEntry {
+	return GLEntry{
+		RowIndex:    i,
+		CIDGLMA:     stringValue(row, f.id),
+		Batch:       stringValue(row, f.batch),
+		Year:        stringValue(row, f.year),
+		Period:      stringValue(row, f.period),
+		Source:      stringValue(row, f.source),
+		Reference:   stringValue(row, f.ref),
+		Date:        dateValue(row, f.date),
+		Description: stringValue(row, f.desc),
+		AccountNo:   stringValue(row, f.account),
+		UnitNo:      stringValue(row, f.unit),
+		DeptNo:      stringValue(row, f.dept),
+		Debit:       floatValue(row, f.debit),
+		Credit:      floatValue(row, f.credit),
+		CID:         stringValue(row, f.cid),
+		CIDCHEC:     stringValue(row, f.cidchec),
+		AFENo:       stringValue(row, f.afe),
+		CatCode:     stringValue(row, f.cat),
+		AddedBy:     stringValue(row, f.addedBy),
+		DateAdded:   dateValue(row, f.added),
+	}
+}
+
+// AccountBalanceAsOf sums debits minus credits for an account on entries dated on
+// or before asOf. Entries without a date are included, matching the all-time GL
+// balance calculation used by the balance cache.
+func AccountBalanceAsOf(entries []GLEntry, accountNumber string, asOf time.Time) (currency.Currency, int) {
+	balance := currency.Zero()
+	count := 0
+	for _, e := range entries {
+		if e.AccountNo != accountNumber {
+			continue
+		}
+		if !e.Date.IsZero() && e.Date.After(asOf) {
+			continue
+		}
+		balance = balance.Add(e.Net())
+		count++
+	}
+	return balance, count
+}
+
+// NextBatchNumber returns the CBATCH that follows the highest numeric batch on file
+func NextBatchNumber(entries []GLEntry) string {
+	return fmt.Sprintf("%08d", maxNumericKey(entries, func(e GLEntry) string { return e.Batch })+1)
+}
+
+// PostGLEntries appends entries to GLMASTER.dbf under one new CBATCH, which is
+// returned. CIDGLMA and CBATCH are numbered through VFP after the highest keys
+// on file while GLMASTER is locked, so concurrent posts cannot share keys, and
+// are zero-padded to their column widths. CYEAR/CPERIOD default to the period of
+// the entry date in the company's calendar (nil for calendar months). Blank
+// optional fields are not written so older GLMASTER layouts still accept the rows.
+func PostGLEntries(companyName string, cal *Calendar, entries []GLEntry) (string, error) {
+	if len(entries) == 0 {
+		return "", fmt.Errorf("there are no entries to post")
+	}
+	widths, err := company.DBFColumnWidths(companyName, "GLMASTER.dbf")
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+
+	var batch string
+	build := func(next map[string]int64) ([]map[string]interface{}, error) {
+		batch = fmt.Sprintf("%0*d", widths["CBATCH"], next["CBATCH"])
+		records := make([]map[string]interface{}, 0, len(entries))
+		for i, e := range entries {
+			if (e.Year == "" || e.Period == "") && !e.Date.IsZero() {
+				p := cal.PeriodForDate(e.Date)
+				if e.Year == "" {
+					e.Year = p.CYear()
+				}
+				if e.Period == "" {
+					e.Period = p.CPeriod()
+				}
+			}
+			if e.DateAdded.IsZero() {
+				e.DateAdded = now
+			}
+			record := map[string]interface{}{
+				"CIDGLMA":  fmt.Sprintf("%0*d", widths["CIDGLMA"], next["CIDGLMA"]+int64(i)),
+				"CBATCH":   batch,
+				"CYEAR":    e.Year,
+				"CPERIOD":  e.Period,
+				"DDATE":    e.Date,
+				"CACCTNO":  e.AccountNo,
+				"CDESC":    e.Description,
+				"NDEBITS":  e.Debit,
+				"NCREDITS": e.Credit,
+				"DADDED":   e.DateAdded,
+			}
+			optional := map[string]string{
+				"CSOURCE":  e.Source,
+				"CREF":     e.Reference,
+				"CUNITNO":  e.UnitNo,
+				"CDEPTNO":  e.DeptNo,
+				"CID":      e.CID,
+				"CIDCHEC":  e.CIDCHEC,
+				"CAFENO":   e.AFENo,
+				"CCATCODE": e.CatCode,
+				"CADDEDBY": e.AddedBy,
+			}
+			for name, v := range optional {
+				if v != "" {
+					record[name] = v
+				}
+			}
+			records = append(records, record)
+		}
+		return records, nil
+	}
+
+	dbf := company.NewDBFBatch(companyName)
+	if err := dbf.AppendKeyed("GLMASTER.dbf", []string{"CIDGLMA", "CBATCH"}, build); err != nil {
+		return "", err
+	}
+	if err := dbf.Commit(); err != nil {
+		return "", err
+	}
+	return batch, nil
+}
+
+// maxNumericKey returns the largest key that parses as an integer, or 0
+func maxNumericKey(entries []GLEntry, key func(GLEntry) string) int64 {
+	var max int64
+	for _, e := range entries {
+		if n, err := strconv.ParseInt(strings.TrimSpace(key(e)), 10, 64); err == nil && n > max {
+			max = n
+		}
+	}
+	return max
+}
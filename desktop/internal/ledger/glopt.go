@@ -0,0 +1,37 @@
+package ledger
+
+// GLOptions holds the general ledger control accounts from GLOPT.dbf
+type GLOptions struct {
+	RetainedEarnings string `json:"retained_earnings"`
+	CurrentEarnings  string `json:"current_earnings"`
+	RevenueClearing  string `json:"revenue_clearing"`
+	ExpenseClearing  string `json:"expense_clearing"`
+	Suspense         string `json:"suspense"`
+	FiscalYearBegin  int    `json:"fiscal_year_begin"` // Month the fiscal year starts, 1 when blank
+	YearEnd          bool   `json:"year_end"`
+}
+
+// LoadGLOptions reads the single GLOPT.dbf record
+func LoadGLOptions(companyName string) (*GLOptions, error) {
+	t, err := loadTable(companyName, "GLOPT.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &GLOptions{FiscalYearBegin: 1}
+	if len(t.rows) == 0 {
+		return opts, nil
+	}
+	row := t.rows[0]
+	opts.RetainedEarnings = stringValue(row, t.col("CRETEARN"))
+	opts.CurrentEarnings = stringValue(row, t.col("CCUREARN"))
+	opts.RevenueClearing = stringValue(row, t.col("CREVCLEAR"))
+	opts.ExpenseClearing = stringValue(row, t.col("CEXPCLEAR"))
+	opts.Suspense = stringValue(row, t.col("CSUSPENSE"))
+	opts.YearEnd = boolValue(row, t.col("LFYEND"))
+
+	if m := int(floatValue(row, t.col("CFYBEGIN"))); m >= 1 && m <= 12 {
+		opts.FiscalYearBegin = m
+	}
+	return opts, nil
+}
@@ -0,0 +1,55 @@
+package ledger
+
+import "time"
+
+// Income is a well revenue record from INCOME.dbf
+type Income struct {
+	RowIndex    int       `json:"row_index"`
+	IncomeID    string    `json:"income_id"`
+	WellID      string    `json:"well_id"`
+	ProdDate    time.Time `json:"prod_date"`
+	AcctDate    time.Time `json:"acct_date"`
+	Year        string    `json:"year"`
+	Period      string    `json:"period"`
+	Purchaser   string    `json:"purchaser"`
+	Description string    `json:"description"`
+	Batch       string    `json:"batch"`
+	Amount      float64   `json:"amount"`
+}
+
+// LoadIncome reads every active record from INCOME.dbf
+func LoadIncome(companyName string) ([]Income, error) {
+	t, err := loadTable(companyName, "INCOME.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	idIdx := t.col("CIDINCO", "CIDINCOME")
+	wellIdx := t.col("CWELLID")
+	prodDateIdx := t.col("DPRODDATE", "DREVDATE")
+	acctDateIdx := t.col("DACCTDATE", "DDATE")
+	yearIdx := t.col("CACCTYEAR", "CYEAR")
+	periodIdx := t.col("CACCTPRD", "CPERIOD")
+	purchaserIdx := t.col("CPURCHASER", "CPAYOR", "CPAYEE")
+	memoIdx := t.col("CMEMO", "CDESC")
+	batchIdx := t.col("CBATCH")
+	amountIdx := t.col("NTOTAL", "NGROSS", "NAMOUNT")
+
+	income := make([]Income, 0, len(t.rows))
+	for i, row := range t.rows {
+		income = append(income, Income{
+			RowIndex:    i,
+			IncomeID:    stringValue(row, idIdx),
+			WellID:      stringValue(row, wellIdx),
+			ProdDate:    dateValue(row, prodDateIdx),
+			AcctDate:    dateValue(row, acctDateIdx),
+			Year:        stringValue(row, yearIdx),
+			Period:      stringValue(row, periodIdx),
+			Purchaser:   stringValue(row, purchaserIdx),
+			Description: stringValue(row, memoIdx),
+			Batch:       stringValue(row, batchIdx),
+			Amount:      floatValue(row, amountIdx),
+		})
+	}
+	return income, nil
+}
@@ -0,0 +1,120 @@
+package ledger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultPeriodsPerYear is the number of accounting periods in a fiscal year
+const DefaultPeriodsPerYear = 12
+
+// ClosingPeriod is the pseudo period that year-end closing entries report in:
+// after the last regular period of their year and before the next year begins
+const ClosingPeriod = 99
+
+// Period is a fiscal year and period number as stored in GLMASTER CYEAR/CPERIOD
+type Period struct {
+	Year   int `json:"year"`
+	Period int `json:"period"`
+}
+
+// Index orders periods: 202503 for period 3 of 2025
+func (p Period) Index() int {
+	return p.Year*100 + p.Period
+}
+
+// Before reports whether p is earlier than o
+func (p Period) Before(o Period) bool {
+	return p.Index() < o.Index()
+}
+
+// IsZero reports whether the period is unset
+func (p Period) IsZero() bool {
+	return p.Year == 0 && p.Period == 0
+}
+
+// String formats the period as YYYY-PP
+func (p Period) String() string {
+	return fmt.Sprintf("%04d-%02d", p.Year, p.Period)
+}
+
+// CYear and CPeriod return the GLMASTER string forms
+func (p Period) CYear() string   { return fmt.Sprintf("%04d", p.Year) }
+func (p Period) CPeriod() string { return fmt.Sprintf("%02d", p.Period) }
+
+// Add moves n periods forward (or back when negative)
+func (p Period) Add(n, periodsPerYear int) Period {
+	if periodsPerYear <= 0 {
+		periodsPerYear = DefaultPeriodsPerYear
+	}
+	idx := p.Year*periodsPerYear + (p.Period - 1) + n
+	return Period{Year: idx / periodsPerYear, Period: idx%periodsPerYear + 1}
+}
+
+// Span returns the number of periods from p to o inclusive
+func (p Period) Span(o Period, periodsPerYear int) int {
+	if periodsPerYear <= 0 {
+		periodsPerYear = DefaultPeriodsPerYear
+	}
+	return (o.Year*periodsPerYear + o.Period) - (p.Year*periodsPerYear + p.Period) + 1
+}
+
+// ParsePeriod parses GLMASTER CYEAR and CPERIOD values. Two digit years are
+// taken as 20xx.
+func ParsePeriod(year, period string) (Period, bool) {
+	y, err := strconv.Atoi(strings.TrimSpace(year))
+	if err != nil || y <= 0 {
+		return Period{}, false
+	}
+	if y < 100 {
+		y += 2000
+	}
+	p, err := strconv.Atoi(strings.TrimSpace(period))
+	if err != nil || p <= 0 {
+		return Period{}, false
+	}
+	return Period{Year: y, Period: p}, true
+}
+
+// FiscalPeriod returns the period an entry is posted to: CYEAR/CPERIOD when set,
+// otherwise the calendar month of DDATE
+func (e GLEntry) FiscalPeriod() (Period, bool) {
+	return e.FiscalPeriodIn(nil)
+}
+
+// FiscalPeriodIn is FiscalPeriod with DDATE placed by a company's fiscal calendar
+func (e GLEntry) FiscalPeriodIn(c *Calendar) (Period, bool) {
+	if p, ok := ParsePeriod(e.Year, e.Period); ok {
+		return p, true
+	}
+	if e.Date.IsZero() {
+		return Period{}, false
+	}
+	return c.PeriodForDate(e.Date), true
+}
+
+// periodDateColumns are the date fields that place a record in a period when it
+// has no CYEAR/CPERIOD, in order of preference
+var periodDateColumns = []string{"DDATE", "DACCTDATE", "DCHECKDATE", "DPOSTDATE", "DINVDATE"}
+
+// RecordPeriod returns the period of a raw DBF record: CYEAR/CPERIOD when present,
+// otherwise the calendar month of its accounting date
+func RecordPeriod(columns []string, row []interface{}) (Period, bool) {
+	return RecordPeriodIn(nil, columns, row)
+}
+
+// RecordPeriodIn is RecordPeriod with the accounting date placed by a company's
+// fiscal calendar
+func RecordPeriodIn(c *Calendar, columns []string, row []interface{}) (Period, bool) {
+	t := newTable(columns, nil)
+	if p, ok := ParsePeriod(stringValue(row, t.col("CYEAR")), stringValue(row, t.col("CPERIOD"))); ok {
+		return p, true
+	}
+	for _, name := range periodDateColumns {
+		if d := dateValue(row, t.col(name)); !d.IsZero() {
+			return c.PeriodForDate(d), true
+		}
+	}
+	return Period{}, false
+}
@@ -0,0 +1,55 @@
+package ledger
+
+import "time"
+
+// DistributionRun is a revenue distribution run control record from SYSCTL.dbf
+type DistributionRun struct {
+	RowIndex   int       `json:"row_index"`
+	RunNo      int       `json:"run_no"`
+	RunYear    string    `json:"run_year"`
+	Year       string    `json:"year"`
+	Period     string    `json:"period"`
+	Group      string    `json:"group"`
+	TypeClose  string    `json:"type_close"`
+	Posted     bool      `json:"posted"`
+	YearClose  bool      `json:"year_close"`
+	AcctDate   time.Time `json:"acct_date"`
+	DateClosed time.Time `json:"date_closed"`
+}
+
+// LoadDistributionRuns reads every active record from SYSCTL.dbf
+func LoadDistributionRuns(companyName string) ([]DistributionRun, error) {
+	t, err := loadTable(companyName, "SYSCTL.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	runNoIdx := t.col("NRUNNO")
+	runYearIdx := t.col("CRUNYEAR")
+	yearIdx := t.col("CYEAR")
+	periodIdx := t.col("CPERIOD")
+	groupIdx := t.col("CGROUP")
+	typeIdx := t.col("CTYPECLOSE")
+	postedIdx := t.col("LPOSTED")
+	yearCloseIdx := t.col("LYEARCLOSE")
+	acctDateIdx := t.col("DACCTDATE")
+	closeIdx := t.col("DDATECLOSE")
+
+	runs := make([]DistributionRun, 0, len(t.rows))
+	for i, row := range t.rows {
+		runs = append(runs, DistributionRun{
+			RowIndex:   i,
+			RunNo:      int(floatValue(row, runNoIdx)),
+			RunYear:    stringValue(row, runYearIdx),
+			Year:       stringValue(row, yearIdx),
+			Period:     stringValue(row, periodIdx),
+			Group:      stringValue(row, groupIdx),
+			TypeClose:  stringValue(row, typeIdx),
+			Posted:     boolValue(row, postedIdx),
+			YearClose:  boolValue(row, yearCloseIdx),
+			AcctDate:   dateValue(row, acctDateIdx),
+			DateClosed: dateValue(row, closeIdx),
+		})
+	}
+	return runs, nil
+}
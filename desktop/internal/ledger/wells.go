@@ -0,0 +1,43 @@
+package ledger
+
+// Well is a WELLS.dbf record. GLMASTER CUNITNO holds the well ID.
+type Well struct {
+	RowIndex int    `json:"row_index"`
+	WellID   string `json:"well_id"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+}
+
+// LoadWells reads every active record from WELLS.dbf
+func LoadWells(companyName string) ([]Well, error) {
+	t, err := loadTable(companyName, "WELLS.dbf")
+	if err != nil {
+		return nil, err
+	}
+
+	idIdx := t.col("CWELLID", "CUNITNO")
+	nameIdx := t.col("CWELLNAME", "WELLNAME", "CNAME")
+	statusIdx := t.col("CWELLSTAT", "CSTATUS")
+
+	wells := make([]Well, 0, len(t.rows))
+	for i, row := range t.rows {
+		wells = append(wells, Well{
+			RowIndex: i,
+			WellID:   stringValue(row, idIdx),
+			Name:     stringValue(row, nameIdx),
+			Status:   stringValue(row, statusIdx),
+		})
+	}
+	return wells, nil
+}
+
+// WellNames maps well ID to name, skipping wells without an ID
+func WellNames(wells []Well) map[string]string {
+	names := make(map[string]string, len(wells))
+	for _, w := range wells {
+		if w.WellID != "" {
+			names[w.WellID] = w.Name
+		}
+	}
+	return names
+}
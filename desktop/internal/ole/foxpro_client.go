@@ -78,7 +78,7 @@ func NewDbApiClient() (*DbApiClient, error) {
 		} else {
 			errMsg := fmt.Sprintf("Failed to initialize COM: %v", err)
 			writeLog(errMsg)
-			return nil, fmt.Errorf(errMsg)
+			return nil, fmt.Errorf("%s", errMsg)
 		}
 	} else {
 		writeLog("COM initialized successfully")
@@ -107,7 +107,7 @@ func NewDbApiClient() (*DbApiClient, error) {
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to get IDispatch interface: %v", err)
 		writeLog(errMsg)
-		return nil, fmt.Errorf(errMsg)
+		return nil, fmt.Errorf("%s", errMsg)
 	}
 	
 	writeLog("IDispatch interface obtained successfully")
@@ -223,7 +223,7 @@ func (c *DbApiClient) QueryToJson(sql string) (string, error) {
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to execute QueryToJson: %v", err)
 		writeLog(errMsg)
-		return "", fmt.Errorf(errMsg)
+		return "", fmt.Errorf("%s", errMsg)
 	}
 	
 	if result.Value() == nil {
@@ -249,7 +249,7 @@ func (c *DbApiClient) GetTableListSimple() (string, error) {
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to execute GetTableListSimple: %v", err)
 		writeLog(errMsg)
-		return "[]", fmt.Errorf(errMsg)
+		return "[]", fmt.Errorf("%s", errMsg)
 	}
 	
 	if result.Value() == nil {
@@ -275,7 +275,7 @@ func (c *DbApiClient) GetTableCount() (string, error) {
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to execute GetTableCount: %v", err)
 		writeLog(errMsg)
-		return "", fmt.Errorf(errMsg)
+		return "", fmt.Errorf("%s", errMsg)
 	}
 	
 	if result.Value() == nil {
@@ -309,6 +309,32 @@ func (c *DbApiClient) ExecNonQuery(sql string) error {
 	return nil
 }
 
+// LockTable locks a table of the open DBC until UnlockTable, waiting up to
+// seconds for other users to release it
+func (c *DbApiClient) LockTable(table string, seconds int) error {
+	writeLog(fmt.Sprintf("Locking table: %s", table))
+	result, err := oleutil.CallMethod(c.oleObject, "LockTable", table, seconds)
+	if err != nil {
+		writeLog(fmt.Sprintf("LockTable failed: %v", err))
+		return fmt.Errorf("failed to lock table: %w", err)
+	}
+	
+	if locked, _ := result.Value().(bool); !locked {
+		lastError := c.GetLastError()
+		writeLog(fmt.Sprintf("LockTable returned false: %s", lastError))
+		return fmt.Errorf("failed to lock %s: %s", table, lastError)
+	}
+	return nil
+}
+
+// UnlockTable releases a lock taken by LockTable
+func (c *DbApiClient) UnlockTable(table string) error {
+	if _, err := oleutil.CallMethod(c.oleObject, "UnlockTable", table); err != nil {
+		return fmt.Errorf("failed to unlock table: %w", err)
+	}
+	return nil
+}
+
 // GetLastError returns the last error from DbApi
 func (c *DbApiClient) GetLastError() string {
 	result, err := oleutil.CallMethod(c.oleObject, "GetLastError")
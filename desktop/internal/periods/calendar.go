@@ -0,0 +1,157 @@
+package periods
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// CalendarSettings is a company's fiscal calendar and where it came from
+type CalendarSettings struct {
+	CompanyName string `json:"company_name"`
+	ledger.Calendar
+	Source    string     `json:"source"` // "settings", "glopt" or "default"
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// GetCalendarSettings returns the stored fiscal calendar, falling back to a
+// monthly calendar starting at GLOPT.dbf's fiscal year begin month
+func (s *Service) GetCalendarSettings(companyName string) (*CalendarSettings, error) {
+	settings := &CalendarSettings{CompanyName: companyName}
+	var yearsJSON string
+	var updatedAt interface{}
+	err := s.db.QueryRow(`
+		SELECT start_month, periods_per_year, named_by_start_year, COALESCE(years_json, ''),
+		       COALESCE(updated_by, ''), updated_at
+		FROM fiscal_calendars WHERE company_name = ?
+	`, companyName).Scan(&settings.StartMonth, &settings.PeriodsPerYear, &settings.NamedByStartYear, &yearsJSON,
+		&settings.UpdatedBy, &updatedAt)
+	switch {
+	case err == nil:
+		if yearsJSON != "" {
+			if err := json.Unmarshal([]byte(yearsJSON), &settings.Years); err != nil {
+				return nil, fmt.Errorf("failed to decode fiscal calendar periods: %w", err)
+			}
+		}
+		settings.Source = "settings"
+		if t, ok := asTimestamp(updatedAt); ok {
+			settings.UpdatedAt = &t
+		}
+		return settings, nil
+	case err != sql.ErrNoRows:
+		return nil, fmt.Errorf("failed to load fiscal calendar: %w", err)
+	}
+
+	settings.Calendar = *ledger.DefaultCalendar()
+	settings.Source = "default"
+	if opts, err := ledger.LoadGLOptions(companyName); err == nil && opts.FiscalYearBegin != 1 {
+		settings.Calendar = *ledger.CalendarFromOptions(opts)
+		settings.Source = "glopt"
+	}
+	return settings, nil
+}
+
+// Calendar returns the company's fiscal calendar
+func (s *Service) Calendar(companyName string) (*ledger.Calendar, error) {
+	settings, err := s.GetCalendarSettings(companyName)
+	if err != nil {
+		return nil, err
+	}
+	return &settings.Calendar, nil
+}
+
+// SaveCalendar stores the company's fiscal calendar. Closed periods keep the
+// dates they were closed with, so a change that would move them is refused
+// until they are reopened.
+func (s *Service) SaveCalendar(companyName string, cal ledger.Calendar, username string) (*CalendarSettings, error) {
+	if err := cal.Validate(); err != nil {
+		return nil, err
+	}
+
+	closed, err := s.closedPeriods(companyName)
+	if err != nil {
+		return nil, err
+	}
+	var moved []string
+	for _, ap := range closed {
+		start, end := cal.PeriodDates(ap.Period)
+		if start.Format("2006-01-02") != ap.StartDate.Format("2006-01-02") || end.Format("2006-01-02") != ap.EndDate.Format("2006-01-02") {
+			moved = append(moved, fmt.Sprintf("%s (%s - %s)", ap.Period,
+				ap.StartDate.Format("01/02/2006"), ap.EndDate.Format("01/02/2006")))
+		}
+	}
+	if len(moved) > 0 {
+		if len(moved) > 5 {
+			moved = append(moved[:5], fmt.Sprintf("and %d more", len(moved)-5))
+		}
+		return nil, fmt.Errorf("the new calendar changes the dates of closed periods %s; reopen them first", strings.Join(moved, ", "))
+	}
+
+	yearsJSON := ""
+	if len(cal.Years) > 0 {
+		data, err := json.Marshal(cal.Years)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode fiscal calendar periods: %w", err)
+		}
+		yearsJSON = string(data)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO fiscal_calendars (company_name, start_month, periods_per_year, named_by_start_year, years_json, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(company_name) DO UPDATE SET
+			start_month = excluded.start_month,
+			periods_per_year = excluded.periods_per_year,
+			named_by_start_year = excluded.named_by_start_year,
+			years_json = excluded.years_json,
+			updated_by = excluded.updated_by,
+			updated_at = CURRENT_TIMESTAMP
+	`, companyName, cal.StartMonth, cal.PeriodsPerYear, cal.NamedByStartYear, yearsJSON, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save fiscal calendar: %w", err)
+	}
+	return s.GetCalendarSettings(companyName)
+}
+
+// closedPeriods returns the soft- and hard-closed periods of a company
+func (s *Service) closedPeriods(companyName string) ([]AccountingPeriod, error) {
+	rows, err := s.db.Query(periodSelect+` WHERE company_name = ? AND status != ? ORDER BY fiscal_year, period`,
+		companyName, StatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query closed periods: %w", err)
+	}
+	defer rows.Close()
+
+	var closed []AccountingPeriod
+	for rows.Next() {
+		ap, err := scanPeriod(rows)
+		if err != nil {
+			return nil, err
+		}
+		closed = append(closed, *ap)
+	}
+	return closed, rows.Err()
+}
+
+// PeriodForDate returns the company's fiscal period containing a date
+func (s *Service) PeriodForDate(companyName string, d time.Time) (ledger.Period, error) {
+	cal, err := s.Calendar(companyName)
+	if err != nil {
+		return ledger.Period{}, err
+	}
+	return cal.PeriodForDate(d), nil
+}
+
+// ParsePeriod reads a period argument against the company's calendar; see
+// ParsePeriodEnd
+func (s *Service) ParsePeriod(companyName, value string) (ledger.Period, error) {
+	cal, err := s.Calendar(companyName)
+	if err != nil {
+		return ledger.Period{}, err
+	}
+	return ParsePeriodEnd(cal, value)
+}
@@ -0,0 +1,275 @@
+package periods
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Checklist item keys
+const (
+	CheckReconciliations = "reconciliations"
+	CheckGLBatches       = "gl_batches"
+	CheckDistribution    = "distribution"
+	CheckAccountRecs     = "account_reconciliations"
+)
+
+// Checklist item statuses
+const (
+	ItemPassed  = "passed"
+	ItemFailed  = "failed"
+	ItemWarning = "warning" // Shown but does not block the close
+)
+
+// ChecklistItem is one pre-close check
+type ChecklistItem struct {
+	Key     string   `json:"key"`
+	Label   string   `json:"label"`
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Details []string `json:"details,omitempty"`
+}
+
+// ChecklistResult is the full pre-close checklist for a period
+type ChecklistResult struct {
+	CompanyName string          `json:"company_name"`
+	Period      ledger.Period   `json:"period"`
+	Items       []ChecklistItem `json:"items"`
+	Passed      bool            `json:"passed"`
+	CheckedAt   time.Time       `json:"checked_at"`
+}
+
+// ChecklistFailedError is returned when a close is blocked by the checklist
+type ChecklistFailedError struct {
+	Result *ChecklistResult
+}
+
+func (e *ChecklistFailedError) Error() string {
+	var failed []string
+	for _, item := range e.Result.Items {
+		if item.Status == ItemFailed {
+			failed = append(failed, item.Label)
+		}
+	}
+	return fmt.Sprintf("period %s cannot be closed: %s", e.Result.Period, strings.Join(failed, "; "))
+}
+
+// RunChecklist checks that bank reconciliations are committed through the
+// period, that every GL batch in the period balances, and that the period's
+// distribution run has been closed. Account reconciliations that have not been
+// reviewed are reported without blocking the close.
+func (s *Service) RunChecklist(companyName string, p ledger.Period) (*ChecklistResult, error) {
+	entries, err := ledger.LoadGLEntries(companyName)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := s.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ChecklistResult{CompanyName: companyName, Period: p, CheckedAt: time.Now()}
+	recItem, err := s.checkReconciliations(companyName, cal, p, entries)
+	if err != nil {
+		return nil, err
+	}
+	acctRecItem, err := s.checkAccountRecs(companyName, p)
+	if err != nil {
+		return nil, err
+	}
+	result.Items = append(result.Items, recItem, checkGLBatches(cal, p, entries), checkDistribution(companyName, p), acctRecItem)
+
+	result.Passed = true
+	for _, item := range result.Items {
+		if item.Status == ItemFailed {
+			result.Passed = false
+		}
+	}
+	return result, nil
+}
+
+// checkAccountRecs lists the accounts set up for reconciliation that have no
+// reviewed reconciliation for the period
+func (s *Service) checkAccountRecs(companyName string, p ledger.Period) (ChecklistItem, error) {
+	item := ChecklistItem{Key: CheckAccountRecs, Label: "Account reconciliations reviewed"}
+	rows, err := s.db.Query(`
+		SELECT a.account_number, COALESCE(r.status, '')
+		FROM account_rec_accounts a
+		LEFT JOIN account_recs r ON r.company_name = a.company_name AND r.account_number = a.account_number
+			AND r.fiscal_year = ? AND r.period = ?
+		WHERE a.company_name = ?
+		ORDER BY a.account_number
+	`, p.Year, p.Period, companyName)
+	if err != nil {
+		return item, fmt.Errorf("failed to query account reconciliations: %w", err)
+	}
+	defer rows.Close()
+
+	var missing []string
+	checked := 0
+	for rows.Next() {
+		var account, status string
+		if err := rows.Scan(&account, &status); err != nil {
+			return item, fmt.Errorf("failed to scan account reconciliation: %w", err)
+		}
+		checked++
+		switch status {
+		case "reviewed":
+		case "":
+			missing = append(missing, account+": not started")
+		default:
+			missing = append(missing, account+": "+status)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return item, err
+	}
+
+	switch {
+	case checked == 0:
+		item.Status, item.Message = ItemPassed, "No accounts set up for reconciliation"
+	case len(missing) > 0:
+		item.Status = ItemWarning
+		item.Message = fmt.Sprintf("%d of %d account reconciliation(s) are not reviewed for %s", len(missing), checked, p)
+		item.Details = missing
+	default:
+		item.Status, item.Message = ItemPassed, fmt.Sprintf("%d account reconciliation(s) reviewed", checked)
+	}
+	return item, nil
+}
+
+// checkReconciliations requires a committed reconciliation with a statement date
+// in or after the period for every active bank account with GL activity
+func (s *Service) checkReconciliations(companyName string, cal *ledger.Calendar, p ledger.Period, entries []ledger.GLEntry) (ChecklistItem, error) {
+	item := ChecklistItem{Key: CheckReconciliations, Label: "Bank reconciliations committed"}
+	start, end := cal.PeriodDates(p)
+
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return item, err
+	}
+	active := make(map[string]bool)
+	for _, e := range entries {
+		if fp, ok := e.FiscalPeriodIn(cal); ok && !p.Before(fp) {
+			active[e.AccountNo] = true
+		}
+	}
+
+	var missing []string
+	checked := 0
+	for _, a := range accounts {
+		if !a.IsBank || a.IsInactive || !active[a.AccountNo] {
+			continue
+		}
+		checked++
+		var latest interface{}
+		err := s.db.QueryRow(`
+			SELECT MAX(statement_date) FROM reconciliations
+			WHERE company_name = ? AND account_number = ? AND status = 'committed'
+		`, companyName, a.AccountNo).Scan(&latest)
+		if err != nil {
+			return item, fmt.Errorf("failed to query reconciliations: %w", err)
+		}
+		d, ok := ledger.AsDate(latest)
+		switch {
+		case !ok:
+			missing = append(missing, fmt.Sprintf("%s %s: never reconciled", a.AccountNo, a.Description))
+		case d.Before(start):
+			missing = append(missing, fmt.Sprintf("%s %s: last reconciled %s", a.AccountNo, a.Description, d.Format("01/02/2006")))
+		}
+	}
+
+	switch {
+	case checked == 0:
+		item.Status, item.Message = ItemPassed, "No active bank accounts"
+	case len(missing) > 0:
+		item.Status = ItemFailed
+		item.Message = fmt.Sprintf("%d of %d bank account(s) are not reconciled for the period ending %s", len(missing), checked, end.Format("01/02/2006"))
+		item.Details = missing
+	default:
+		item.Status, item.Message = ItemPassed, fmt.Sprintf("%d bank account(s) reconciled", checked)
+	}
+	return item, nil
+}
+
+// checkGLBatches requires debits to equal credits within each CBATCH posted to the period
+func checkGLBatches(cal *ledger.Calendar, p ledger.Period, entries []ledger.GLEntry) ChecklistItem {
+	item := ChecklistItem{Key: CheckGLBatches, Label: "GL batches balanced"}
+
+	type totals struct{ debits, credits currency.Currency }
+	batches := make(map[string]*totals)
+	for _, e := range entries {
+		if fp, ok := e.FiscalPeriodIn(cal); !ok || fp != p {
+			continue
+		}
+		t, ok := batches[e.Batch]
+		if !ok {
+			t = &totals{debits: currency.Zero(), credits: currency.Zero()}
+			batches[e.Batch] = t
+		}
+		t.debits = t.debits.Add(currency.NewFromFloat(e.Debit))
+		t.credits = t.credits.Add(currency.NewFromFloat(e.Credit))
+	}
+
+	var unbalanced []string
+	for batch, t := range batches {
+		if !t.debits.Equal(t.credits) {
+			name := batch
+			if name == "" {
+				name = "(no batch)"
+			}
+			unbalanced = append(unbalanced, fmt.Sprintf("%s: debits %.2f, credits %.2f", name, t.debits.ToFloat64(), t.credits.ToFloat64()))
+		}
+	}
+	sort.Strings(unbalanced)
+
+	if len(unbalanced) > 0 {
+		item.Status = ItemFailed
+		item.Message = fmt.Sprintf("%d of %d batch(es) are out of balance", len(unbalanced), len(batches))
+		item.Details = unbalanced
+	} else {
+		item.Status, item.Message = ItemPassed, fmt.Sprintf("%d batch(es) balanced", len(batches))
+	}
+	return item
+}
+
+// checkDistribution requires every SYSCTL.dbf run for the period to be posted.
+// Companies that do not run distribution have no SYSCTL records, which is only a warning.
+func checkDistribution(companyName string, p ledger.Period) ChecklistItem {
+	item := ChecklistItem{Key: CheckDistribution, Label: "Distribution run closed"}
+
+	runs, err := ledger.LoadDistributionRuns(companyName)
+	if err != nil {
+		item.Status, item.Message = ItemWarning, "SYSCTL.dbf could not be read; distribution status unknown"
+		return item
+	}
+
+	var found int
+	var open []string
+	for _, r := range runs {
+		rp, ok := ledger.ParsePeriod(r.Year, r.Period)
+		if !ok || rp != p {
+			continue
+		}
+		found++
+		if !r.Posted {
+			open = append(open, fmt.Sprintf("Run %d/%s group %s is not posted", r.RunNo, r.RunYear, r.Group))
+		}
+	}
+
+	switch {
+	case found == 0:
+		item.Status, item.Message = ItemWarning, "No distribution run recorded for the period"
+	case len(open) > 0:
+		item.Status = ItemFailed
+		item.Message = fmt.Sprintf("%d of %d distribution run(s) are still open", len(open), found)
+		item.Details = open
+	default:
+		item.Status, item.Message = ItemPassed, fmt.Sprintf("%d distribution run(s) closed", found)
+	}
+	return item
+}
@@ -0,0 +1,415 @@
+// Package periods manages the accounting period lock: each company's periods are
+// open, soft-closed or hard-closed, closing runs a checklist first, and every
+// change of state is written to an audit log. Write paths call EnsureOpen before
+// posting into a period. The year-end close, which closes income statement
+// accounts to retained earnings and locks the year, lives here as well, as does
+// each company's fiscal calendar that maps dates to periods.
+package periods
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/database"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Period statuses
+const (
+	StatusOpen       = "open"
+	StatusSoftClosed = "soft_closed" // Only administrators may post
+	StatusHardClosed = "hard_closed" // Nobody may post until reopened
+)
+
+// Audit log actions
+const (
+	ActionClose     = "close"
+	ActionHardClose = "hard_close"
+	ActionReopen    = "reopen"
+)
+
+// AccountingPeriod is the lock record for one fiscal period. Periods without a
+// row are open.
+type AccountingPeriod struct {
+	ID           int             `json:"id"`
+	CompanyName  string          `json:"company_name"`
+	Period       ledger.Period   `json:"period"`
+	StartDate    time.Time       `json:"start_date"`
+	EndDate      time.Time       `json:"end_date"`
+	Status       string          `json:"status"`
+	ClosedBy     string          `json:"closed_by,omitempty"`
+	ClosedAt     *time.Time      `json:"closed_at,omitempty"`
+	ClosingDate  *time.Time      `json:"closing_date,omitempty"`
+	CloseReason  string          `json:"close_reason,omitempty"`
+	Forced       bool            `json:"forced"` // Closed with failing checklist items
+	Checklist    []ChecklistItem `json:"checklist,omitempty"`
+	ReopenedBy   string          `json:"reopened_by,omitempty"`
+	ReopenedAt   *time.Time      `json:"reopened_at,omitempty"`
+	ReopenReason string          `json:"reopen_reason,omitempty"`
+}
+
+// AuditEntry is one period state change
+type AuditEntry struct {
+	ID          int           `json:"id"`
+	CompanyName string        `json:"company_name"`
+	Period      ledger.Period `json:"period"`
+	Action      string        `json:"action"`
+	FromStatus  string        `json:"from_status"`
+	ToStatus    string        `json:"to_status"`
+	Username    string        `json:"username"`
+	Reason      string        `json:"reason"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// ClosedPeriodError is returned when a write targets a locked period
+type ClosedPeriodError struct {
+	Period ledger.Period
+	Status string
+}
+
+func (e *ClosedPeriodError) Error() string {
+	if e.Status == StatusSoftClosed {
+		return fmt.Sprintf("period %s is soft-closed; only an administrator can post to it", e.Period)
+	}
+	return fmt.Sprintf("period %s is closed; reopen it before posting", e.Period)
+}
+
+// Service provides period management operations
+type Service struct {
+	db *database.DB
+}
+
+// NewService creates a new period service
+func NewService(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// ParsePeriodEnd reads the period argument used by the closing methods: a
+// period-end date (2025-03-31) or a month (2025-03), both placed by the fiscal
+// calendar, or a fiscal period (2025/03)
+func ParsePeriodEnd(cal *ledger.Calendar, s string) (ledger.Period, error) {
+	s = strings.TrimSpace(s)
+	if d, ok := ledger.ParseDate(s); ok {
+		return cal.PeriodForDate(d), nil
+	}
+	if parts := strings.Split(s, "-"); len(parts) == 2 {
+		if m, ok := ledger.ParsePeriod(parts[0], parts[1]); ok && m.Period <= 12 {
+			monthEnd := time.Date(m.Year, time.Month(m.Period)+1, 0, 0, 0, 0, 0, time.UTC)
+			return cal.PeriodForDate(monthEnd), nil
+		}
+	}
+	if parts := strings.Split(s, "/"); len(parts) == 2 {
+		if p, ok := ledger.ParsePeriod(parts[0], parts[1]); ok && p.Period <= cal.PeriodCount() {
+			return p, nil
+		}
+	}
+	return ledger.Period{}, fmt.Errorf("invalid period: %s", s)
+}
+
+// GetPeriod returns the lock record for a period; open periods without a row
+// are returned with ID 0
+func (s *Service) GetPeriod(companyName string, p ledger.Period) (*AccountingPeriod, error) {
+	ap, err := scanPeriod(s.db.QueryRow(periodSelect+` WHERE company_name = ? AND fiscal_year = ? AND period = ?`,
+		companyName, p.Year, p.Period))
+	if err == sql.ErrNoRows {
+		cal, err := s.Calendar(companyName)
+		if err != nil {
+			return nil, err
+		}
+		start, end := cal.PeriodDates(p)
+		return &AccountingPeriod{CompanyName: companyName, Period: p, StartDate: start, EndDate: end, Status: StatusOpen}, nil
+	}
+	return ap, err
+}
+
+// GetPeriods returns the lock records for a fiscal year, one per period of the
+// company's calendar
+func (s *Service) GetPeriods(companyName string, year int) ([]AccountingPeriod, error) {
+	cal, err := s.Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(periodSelect+` WHERE company_name = ? AND fiscal_year = ? ORDER BY period`, companyName, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query periods: %w", err)
+	}
+	defer rows.Close()
+
+	stored := make(map[int]AccountingPeriod)
+	for rows.Next() {
+		ap, err := scanPeriod(rows)
+		if err != nil {
+			return nil, err
+		}
+		stored[ap.Period.Period] = *ap
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	periods := make([]AccountingPeriod, 0, cal.PeriodCount())
+	for n := 1; n <= cal.PeriodCount(); n++ {
+		if ap, ok := stored[n]; ok {
+			periods = append(periods, ap)
+			continue
+		}
+		p := ledger.Period{Year: year, Period: n}
+		start, end := cal.PeriodDates(p)
+		periods = append(periods, AccountingPeriod{CompanyName: companyName, Period: p, StartDate: start, EndDate: end, Status: StatusOpen})
+	}
+	return periods, nil
+}
+
+// EnsureOpen refuses posting into a closed period. Administrators may still post
+// into soft-closed periods.
+func (s *Service) EnsureOpen(companyName string, p ledger.Period, isAdmin bool) error {
+	var status string
+	err := s.db.QueryRow(`SELECT status FROM accounting_periods WHERE company_name = ? AND fiscal_year = ? AND period = ?`,
+		companyName, p.Year, p.Period).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check period status: %w", err)
+	}
+	switch {
+	case status == StatusHardClosed:
+		return &ClosedPeriodError{Period: p, Status: status}
+	case status == StatusSoftClosed && !isAdmin:
+		return &ClosedPeriodError{Period: p, Status: status}
+	}
+	return nil
+}
+
+// EnsureDateOpen is EnsureOpen for the period containing a date
+func (s *Service) EnsureDateOpen(companyName string, d time.Time, isAdmin bool) error {
+	if d.IsZero() {
+		return nil
+	}
+	p, err := s.PeriodForDate(companyName, d)
+	if err != nil {
+		return err
+	}
+	return s.EnsureOpen(companyName, p, isAdmin)
+}
+
+// ClosePeriod runs the checklist and soft- or hard-closes the period. Failing
+// checklist items block the close unless force is set.
+func (s *Service) ClosePeriod(companyName string, p ledger.Period, hard bool, closingDate time.Time, reason, username string, force bool) (*AccountingPeriod, error) {
+	current, err := s.GetPeriod(companyName, p)
+	if err != nil {
+		return nil, err
+	}
+	target, action := StatusSoftClosed, ActionClose
+	if hard {
+		target, action = StatusHardClosed, ActionHardClose
+	}
+	if current.Status == target {
+		return nil, fmt.Errorf("period %s is already %s", p, strings.ReplaceAll(target, "_", "-"))
+	}
+	if current.Status == StatusHardClosed {
+		return nil, fmt.Errorf("period %s is hard-closed; reopen it first", p)
+	}
+
+	// A soft-closed period already passed (or overrode) the checklist
+	checklist := current.Checklist
+	forced := current.Forced
+	if current.Status == StatusOpen {
+		result, err := s.RunChecklist(companyName, p)
+		if err != nil {
+			return nil, err
+		}
+		checklist = result.Items
+		forced = false
+		if !result.Passed {
+			if !force {
+				return nil, &ChecklistFailedError{Result: result}
+			}
+			forced = true
+		}
+	}
+	checklistJSON, err := json.Marshal(checklist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode checklist: %w", err)
+	}
+	if closingDate.IsZero() {
+		closingDate = time.Now()
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := upsertStatus(tx, current.StartDate, current.EndDate, companyName, p, target, closingDate, reason, username, forced, string(checklistJSON)); err != nil {
+		return nil, err
+	}
+
+	logReason := reason
+	if forced && current.Status == StatusOpen {
+		logReason = strings.TrimSpace(reason + " (closed with failing checklist items)")
+	}
+	if err := logAction(tx, companyName, p, action, current.Status, target, username, logReason); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit period close: %w", err)
+	}
+	return s.GetPeriod(companyName, p)
+}
+
+// ReopenPeriod returns a closed period to open. The caller must have checked
+// that the user is an administrator.
+func (s *Service) ReopenPeriod(companyName string, p ledger.Period, reason, username string) (*AccountingPeriod, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("a reason is required to reopen a period")
+	}
+	current, err := s.GetPeriod(companyName, p)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status == StatusOpen {
+		return nil, fmt.Errorf("period %s is not closed", p)
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE accounting_periods
+		SET status = ?, reopened_by = ?, reopened_at = CURRENT_TIMESTAMP, reopen_reason = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, StatusOpen, username, reason, current.ID); err != nil {
+		return nil, fmt.Errorf("failed to reopen period: %w", err)
+	}
+	if err := logAction(tx, companyName, p, ActionReopen, current.Status, StatusOpen, username, reason); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit period reopen: %w", err)
+	}
+	return s.GetPeriod(companyName, p)
+}
+
+// GetAuditLog returns period state changes, newest first. A zero period returns
+// the whole company history.
+func (s *Service) GetAuditLog(companyName string, p ledger.Period) ([]AuditEntry, error) {
+	query := `
+		SELECT id, company_name, fiscal_year, period, action, COALESCE(from_status, ''), to_status,
+		       username, COALESCE(reason, ''), created_at
+		FROM period_audit_log
+		WHERE company_name = ?`
+	args := []interface{}{companyName}
+	if !p.IsZero() {
+		query += ` AND fiscal_year = ? AND period = ?`
+		args = append(args, p.Year, p.Period)
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query period audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		if err := rows.Scan(&e.ID, &e.CompanyName, &e.Period.Year, &e.Period.Period, &e.Action, &e.FromStatus,
+			&e.ToStatus, &e.Username, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan period audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// upsertStatus writes a closed status for a period, creating its row with the
+// period's calendar dates if needed
+func upsertStatus(tx *sql.Tx, start, end time.Time, companyName string, p ledger.Period, status string, closingDate time.Time, reason, username string, forced bool, checklistJSON string) error {
+	_, err := tx.Exec(`
+		INSERT INTO accounting_periods (
+			company_name, fiscal_year, period, start_date, end_date, status,
+			closed_by, closed_at, closing_date, close_reason, forced, checklist_json, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(company_name, fiscal_year, period) DO UPDATE SET
+			status = excluded.status,
+			closed_by = excluded.closed_by,
+			closed_at = excluded.closed_at,
+			closing_date = excluded.closing_date,
+			close_reason = excluded.close_reason,
+			forced = excluded.forced,
+			checklist_json = excluded.checklist_json,
+			updated_at = CURRENT_TIMESTAMP
+	`, companyName, p.Year, p.Period, start.Format("2006-01-02"), end.Format("2006-01-02"), status,
+		username, closingDate.Format("2006-01-02"), reason, forced, checklistJSON)
+	if err != nil {
+		return fmt.Errorf("failed to close period: %w", err)
+	}
+	return nil
+}
+
+func logAction(tx *sql.Tx, companyName string, p ledger.Period, action, from, to, username, reason string) error {
+	_, err := tx.Exec(`
+		INSERT INTO period_audit_log (company_name, fiscal_year, period, action, from_status, to_status, username, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, companyName, p.Year, p.Period, action, from, to, username, reason)
+	if err != nil {
+		return fmt.Errorf("failed to write period audit log: %w", err)
+	}
+	return nil
+}
+
+const periodSelect = `
+	SELECT id, company_name, fiscal_year, period, start_date, end_date, status,
+	       COALESCE(closed_by, ''), closed_at, closing_date, COALESCE(close_reason, ''), COALESCE(forced, 0),
+	       COALESCE(checklist_json, '[]'), COALESCE(reopened_by, ''), reopened_at, COALESCE(reopen_reason, '')
+	FROM accounting_periods`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPeriod(row rowScanner) (*AccountingPeriod, error) {
+	var ap AccountingPeriod
+	var start, end, closedAt, closingDate, reopenedAt interface{}
+	var checklist string
+	if err := row.Scan(&ap.ID, &ap.CompanyName, &ap.Period.Year, &ap.Period.Period, &start, &end, &ap.Status,
+		&ap.ClosedBy, &closedAt, &closingDate, &ap.CloseReason, &ap.Forced,
+		&checklist, &ap.ReopenedBy, &reopenedAt, &ap.ReopenReason); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan period: %w", err)
+	}
+	ap.StartDate, _ = ledger.AsDate(start)
+	ap.EndDate, _ = ledger.AsDate(end)
+	if t, ok := asTimestamp(closedAt); ok {
+		ap.ClosedAt = &t
+	}
+	if d, ok := ledger.AsDate(closingDate); ok {
+		ap.ClosingDate = &d
+	}
+	if t, ok := asTimestamp(reopenedAt); ok {
+		ap.ReopenedAt = &t
+	}
+	if err := json.Unmarshal([]byte(checklist), &ap.Checklist); err != nil {
+		ap.Checklist = nil
+	}
+	return &ap, nil
+}
+
+// asTimestamp keeps the time of day that ledger.AsDate drops
+func asTimestamp(v interface{}) (time.Time, bool) {
+	if t, ok := v.(time.Time); ok && !t.IsZero() {
+		return t, true
+	}
+	return ledger.AsDate(v)
+}
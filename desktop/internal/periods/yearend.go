@@ -0,0 +1,689 @@
+package periods
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/reports"
+)
+
+// Year-end close statuses
+const (
+	YearEndPreview  = "preview"
+	YearEndPosted   = "posted"   // Closing entries written to GLMASTER.dbf
+	YearEndExported = "exported" // Closing entries produced as a batch for import
+	YearEndReversed = "reversed"
+)
+
+// Year-end close modes
+const (
+	ModePost   = "post"
+	ModeExport = "export"
+)
+
+// ClosingEntry is one line of the year-end closing batch
+type ClosingEntry struct {
+	AccountNo   string  `json:"account_number"`
+	Description string  `json:"description"`
+	AccountType int     `json:"account_type"`
+	UnitNo      string  `json:"unit_number,omitempty"`
+	DeptNo      string  `json:"dept_number,omitempty"`
+	Balance     float64 `json:"balance"` // The year's net activity being closed, debits minus credits
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+}
+
+// YearEndClose is a year-end close, either previewed or recorded
+type YearEndClose struct {
+	ID                      int             `json:"id"`
+	CompanyName             string          `json:"company_name"`
+	FiscalYear              int             `json:"fiscal_year"`
+	Status                  string          `json:"status"`
+	Mode                    string          `json:"mode,omitempty"`
+	RetainedEarningsAccount string          `json:"retained_earnings_account"`
+	ClosingPeriod           ledger.Period   `json:"closing_period"`
+	ClosingDate             time.Time       `json:"closing_date"`
+	Revenue                 float64         `json:"revenue"`
+	Expenses                float64         `json:"expenses"`
+	NetIncome               float64         `json:"net_income"`
+	Entries                 []ClosingEntry  `json:"entries"`
+	TotalDebits             float64         `json:"total_debits"`
+	TotalCredits            float64         `json:"total_credits"`
+	Batch                   string          `json:"batch,omitempty"`
+	LockedPeriods           []ledger.Period `json:"locked_periods,omitempty"`
+	ClosedBy                string          `json:"closed_by,omitempty"`
+	ClosedAt                *time.Time      `json:"closed_at,omitempty"`
+	ReversalBatch           string          `json:"reversal_batch,omitempty"`
+	ReversedBy              string          `json:"reversed_by,omitempty"`
+	ReversedAt              *time.Time      `json:"reversed_at,omitempty"`
+	ReversalReason          string          `json:"reversal_reason,omitempty"`
+	Warnings                []string        `json:"warnings,omitempty"`
+}
+
+// YearEndSettings holds the company's year-end close configuration
+type YearEndSettings struct {
+	CompanyName             string     `json:"company_name"`
+	RetainedEarningsAccount string     `json:"retained_earnings_account"`
+	Source                  string     `json:"source"` // "settings", "glopt" or "" when not configured
+	UpdatedBy               string     `json:"updated_by,omitempty"`
+	UpdatedAt               *time.Time `json:"updated_at,omitempty"`
+}
+
+// GetYearEndSettings returns the stored settings, falling back to the retained
+// earnings account in GLOPT.dbf
+func (s *Service) GetYearEndSettings(companyName string) (*YearEndSettings, error) {
+	settings := &YearEndSettings{CompanyName: companyName}
+	var updatedAt interface{}
+	err := s.db.QueryRow(`
+		SELECT retained_earnings_account, COALESCE(updated_by, ''), updated_at
+		FROM year_end_settings WHERE company_name = ?
+	`, companyName).Scan(&settings.RetainedEarningsAccount, &settings.UpdatedBy, &updatedAt)
+	switch {
+	case err == nil && settings.RetainedEarningsAccount != "":
+		settings.Source = "settings"
+		if t, ok := asTimestamp(updatedAt); ok {
+			settings.UpdatedAt = &t
+		}
+		return settings, nil
+	case err != nil && err != sql.ErrNoRows:
+		return nil, fmt.Errorf("failed to load year-end settings: %w", err)
+	}
+
+	if opts, err := ledger.LoadGLOptions(companyName); err == nil && opts.RetainedEarnings != "" {
+		settings.RetainedEarningsAccount = opts.RetainedEarnings
+		settings.Source = "glopt"
+	}
+	return settings, nil
+}
+
+// SaveYearEndSettings stores the retained earnings account used by the close
+func (s *Service) SaveYearEndSettings(companyName, retainedEarnings, username string) (*YearEndSettings, error) {
+	retainedEarnings = strings.TrimSpace(retainedEarnings)
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := retainedEarningsAccount(ledger.AccountMap(accounts), retainedEarnings); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO year_end_settings (company_name, retained_earnings_account, updated_by, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(company_name) DO UPDATE SET
+			retained_earnings_account = excluded.retained_earnings_account,
+			updated_by = excluded.updated_by,
+			updated_at = CURRENT_TIMESTAMP
+	`, companyName, retainedEarnings, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save year-end settings: %w", err)
+	}
+	return s.GetYearEndSettings(companyName)
+}
+
+// retainedEarningsAccount checks that the account exists and is an equity account
+func retainedEarningsAccount(accountMap map[string]ledger.Account, accountNo string) (ledger.Account, error) {
+	if accountNo == "" {
+		return ledger.Account{}, fmt.Errorf("no retained earnings account is configured")
+	}
+	a, ok := accountMap[accountNo]
+	if !ok {
+		return a, fmt.Errorf("retained earnings account %s is not in the chart of accounts", accountNo)
+	}
+	if a.Type != ledger.TypeEquity {
+		return a, fmt.Errorf("retained earnings account %s is a %s account, not equity", accountNo, ledger.AccountTypeName(a.Type))
+	}
+	return a, nil
+}
+
+// PreviewYearEndClose computes the closing entries for a fiscal year without
+// posting anything. A blank retainedEarnings uses the configured account.
+func (s *Service) PreviewYearEndClose(companyName string, year int, retainedEarnings string) (*YearEndClose, error) {
+	yc, _, err := s.buildYearEndClose(companyName, year, retainedEarnings)
+	return yc, err
+}
+
+// buildYearEndClose returns the preview and the GLMASTER entries it was built from
+func (s *Service) buildYearEndClose(companyName string, year int, retainedEarnings string) (*YearEndClose, []ledger.GLEntry, error) {
+	if year <= 0 {
+		return nil, nil, fmt.Errorf("invalid fiscal year: %d", year)
+	}
+	if strings.TrimSpace(retainedEarnings) == "" {
+		settings, err := s.GetYearEndSettings(companyName)
+		if err != nil {
+			return nil, nil, err
+		}
+		retainedEarnings = settings.RetainedEarningsAccount
+	}
+	retainedEarnings = strings.TrimSpace(retainedEarnings)
+
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, nil, err
+	}
+	accountMap := ledger.AccountMap(accounts)
+	reAccount, err := retainedEarningsAccount(accountMap, retainedEarnings)
+	if err != nil {
+		return nil, nil, err
+	}
+	glEntries, err := ledger.LoadGLEntries(companyName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cal, err := s.Calendar(companyName)
+	if err != nil {
+		return nil, nil, err
+	}
+	closingPeriod := ledger.Period{Year: year, Period: cal.PeriodCount()}
+	_, closingDate := cal.PeriodDates(closingPeriod)
+	yc := &YearEndClose{
+		CompanyName:             companyName,
+		FiscalYear:              year,
+		Status:                  YearEndPreview,
+		RetainedEarningsAccount: retainedEarnings,
+		ClosingPeriod:           closingPeriod,
+		ClosingDate:             closingDate,
+		Entries:                 []ClosingEntry{},
+	}
+
+	// Net each income statement account for the year by unit and department so
+	// segment reports are closed too. Earlier closing and reversal entries for
+	// the year are included, so a close redone after a reversal nets correctly.
+	type key struct{ account, unit, dept string }
+	balances := make(map[key]currency.Currency)
+	priorActivity := false
+	for _, e := range glEntries {
+		p, ok := e.FiscalPeriodIn(cal)
+		if !ok || e.AccountNo == "" {
+			continue
+		}
+		accountType := ledger.TypeOther
+		if a, found := accountMap[e.AccountNo]; found {
+			accountType = a.Type
+		}
+		if ledger.IsBalanceSheet(accountType) {
+			continue
+		}
+		if p.Year == year-1 {
+			priorActivity = true
+		}
+		if p.Year != year {
+			continue
+		}
+		k := key{e.AccountNo, e.UnitNo, e.DeptNo}
+		if b, found := balances[k]; found {
+			balances[k] = b.Add(e.Net())
+		} else {
+			balances[k] = e.Net()
+		}
+	}
+
+	revenue, expenses := currency.Zero(), currency.Zero()
+	totalDebits, totalCredits := currency.Zero(), currency.Zero()
+	for k, balance := range balances {
+		if balance.IsZero() {
+			continue
+		}
+		a, found := accountMap[k.account]
+		if !found {
+			a = ledger.Account{AccountNo: k.account, Type: ledger.TypeOther, Description: "(not in chart of accounts)"}
+			yc.Warnings = append(yc.Warnings, fmt.Sprintf("Account %s has activity but is not in the chart of accounts; it is closed as an expense", k.account))
+		}
+		if a.Type == ledger.TypeRevenue {
+			revenue = revenue.Sub(balance)
+		} else {
+			expenses = expenses.Add(balance)
+		}
+
+		entry := ClosingEntry{
+			AccountNo:   k.account,
+			Description: a.Description,
+			AccountType: a.Type,
+			UnitNo:      k.unit,
+			DeptNo:      k.dept,
+			Balance:     balance.ToFloat64(),
+		}
+		if balance.IsPositive() {
+			entry.Credit = balance.ToFloat64()
+			totalCredits = totalCredits.Add(balance)
+		} else {
+			entry.Debit = balance.Neg().ToFloat64()
+			totalDebits = totalDebits.Sub(balance)
+		}
+		yc.Entries = append(yc.Entries, entry)
+	}
+	sort.Slice(yc.Entries, func(i, j int) bool {
+		a, b := yc.Entries[i], yc.Entries[j]
+		if a.AccountNo != b.AccountNo {
+			return a.AccountNo < b.AccountNo
+		}
+		if a.UnitNo != b.UnitNo {
+			return a.UnitNo < b.UnitNo
+		}
+		return a.DeptNo < b.DeptNo
+	})
+
+	// The offset to retained earnings balances the batch
+	netIncome := revenue.Sub(expenses)
+	if !netIncome.IsZero() {
+		entry := ClosingEntry{
+			AccountNo:   reAccount.AccountNo,
+			Description: reAccount.Description,
+			AccountType: reAccount.Type,
+		}
+		if netIncome.IsPositive() {
+			entry.Credit = netIncome.ToFloat64()
+			totalCredits = totalCredits.Add(netIncome)
+		} else {
+			entry.Debit = netIncome.Neg().ToFloat64()
+			totalDebits = totalDebits.Sub(netIncome)
+		}
+		yc.Entries = append(yc.Entries, entry)
+	}
+
+	yc.Revenue = revenue.ToFloat64()
+	yc.Expenses = expenses.ToFloat64()
+	yc.NetIncome = netIncome.ToFloat64()
+	yc.TotalDebits = totalDebits.ToFloat64()
+	yc.TotalCredits = totalCredits.ToFloat64()
+
+	if reAccount.IsInactive {
+		yc.Warnings = append(yc.Warnings, fmt.Sprintf("Retained earnings account %s is inactive", reAccount.AccountNo))
+	}
+	if len(balances) == 0 || len(yc.Entries) == 0 {
+		yc.Warnings = append(yc.Warnings, fmt.Sprintf("No income statement balances to close for %d", year))
+	}
+	if active, err := s.activeYearEndClose(companyName, year); err != nil {
+		return nil, nil, err
+	} else if active != nil {
+		yc.Warnings = append(yc.Warnings, fmt.Sprintf("%d was already closed by %s (batch %s); reverse that close before closing again",
+			year, active.ClosedBy, active.Batch))
+	}
+	if priorActivity {
+		if prior, err := s.activeYearEndClose(companyName, year-1); err == nil && prior == nil {
+			yc.Warnings = append(yc.Warnings, fmt.Sprintf("%d has not been closed", year-1))
+		}
+	}
+	if current, err := s.GetPeriod(companyName, closingPeriod); err == nil && current.Status != StatusOpen {
+		yc.Warnings = append(yc.Warnings, fmt.Sprintf("The closing period %s is %s", closingPeriod, strings.ReplaceAll(current.Status, "_", "-")))
+	}
+	return yc, glEntries, nil
+}
+
+// RunYearEndClose posts (or exports) the closing entries and locks the year. In
+// export mode the closing period is only soft-closed so an administrator can
+// still import the batch into it.
+func (s *Service) RunYearEndClose(companyName string, year int, retainedEarnings, mode, username string, isAdmin bool) (*YearEndClose, error) {
+	if mode == "" {
+		mode = ModePost
+	}
+	if mode != ModePost && mode != ModeExport {
+		return nil, fmt.Errorf("invalid year-end close mode: %s", mode)
+	}
+	if active, err := s.activeYearEndClose(companyName, year); err != nil {
+		return nil, err
+	} else if active != nil {
+		return nil, fmt.Errorf("%d is already closed (batch %s); reverse that close first", year, active.Batch)
+	}
+
+	yc, glEntries, err := s.buildYearEndClose(companyName, year, retainedEarnings)
+	if err != nil {
+		return nil, err
+	}
+	if len(yc.Entries) == 0 {
+		return nil, fmt.Errorf("no income statement balances to close for %d", year)
+	}
+	if !currency.NewFromFloat(yc.TotalDebits).Equal(currency.NewFromFloat(yc.TotalCredits)) {
+		return nil, fmt.Errorf("closing entries are out of balance: debits %.2f, credits %.2f", yc.TotalDebits, yc.TotalCredits)
+	}
+	if err := s.EnsureOpen(companyName, yc.ClosingPeriod, isAdmin); err != nil {
+		return nil, err
+	}
+
+	yc.Mode = mode
+	yc.Batch = ledger.NextBatchNumber(glEntries)
+	yc.Status = YearEndExported
+	if mode == ModePost {
+		yc.Status = YearEndPosted
+	}
+
+	entriesJSON, err := json.Marshal(yc.Entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode closing entries: %w", err)
+	}
+
+	periods, err := s.GetPeriods(companyName, year)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reason := fmt.Sprintf("Year-end close %d", year)
+	for _, current := range periods {
+		p := current.Period
+		target, action := StatusHardClosed, ActionHardClose
+		if mode == ModeExport && p == yc.ClosingPeriod {
+			target, action = StatusSoftClosed, ActionClose
+		}
+		if current.Status == target || current.Status == StatusHardClosed {
+			continue
+		}
+		checklistJSON, _ := json.Marshal(current.Checklist)
+		if err := upsertStatus(tx, current.StartDate, current.EndDate, companyName, p, target, yc.ClosingDate, reason, username, current.Forced, string(checklistJSON)); err != nil {
+			return nil, err
+		}
+		if err := logAction(tx, companyName, p, action, current.Status, target, username, reason); err != nil {
+			return nil, err
+		}
+		yc.LockedPeriods = append(yc.LockedPeriods, p)
+	}
+	lockedJSON, err := json.Marshal(yc.LockedPeriods)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode locked periods: %w", err)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO year_end_closes (
+			company_name, fiscal_year, status, mode, retained_earnings_account, closing_year, closing_period,
+			closing_date, revenue, expenses, net_income, batch, entries_json, locked_periods_json, closed_by, closed_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, companyName, year, yc.Status, mode, yc.RetainedEarningsAccount, yc.ClosingPeriod.Year, yc.ClosingPeriod.Period,
+		yc.ClosingDate.Format("2006-01-02"), yc.Revenue, yc.Expenses, yc.NetIncome, yc.Batch,
+		string(entriesJSON), string(lockedJSON), username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record year-end close: %w", err)
+	}
+
+	// Post last, so a failed post leaves the year open and unrecorded. The batch
+	// number is assigned as the entries are written.
+	if mode == ModePost {
+		id, _ := result.LastInsertId()
+		gl := closingGLEntries(yc, "", false, username)
+		if yc.Batch, err = ledger.PostGLEntries(companyName, nil, gl); err != nil {
+			return nil, fmt.Errorf("failed to post closing entries: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE year_end_closes SET batch = ? WHERE id = ?`, yc.Batch, id); err != nil {
+			return nil, undoClosingBatch(companyName, yc, username, false,
+				fmt.Errorf("failed to record the closing batch: %w", err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		if mode == ModePost {
+			return nil, undoClosingBatch(companyName, yc, username, false,
+				fmt.Errorf("failed to commit year-end close: %w", err))
+		}
+		return nil, fmt.Errorf("failed to commit year-end close: %w", err)
+	}
+	id, _ := result.LastInsertId()
+	return s.GetYearEndClose(companyName, int(id))
+}
+
+// ReverseYearEndClose undoes a close so it can be redone after audit
+// adjustments: the periods it locked (and the closing period) are reopened and
+// a reversing batch is posted if the closing batch is in GLMASTER.dbf. The
+// caller must have checked that the user is an administrator.
+func (s *Service) ReverseYearEndClose(companyName string, id int, reason, username string) (*YearEndClose, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("a reason is required to reverse a year-end close")
+	}
+	yc, err := s.GetYearEndClose(companyName, id)
+	if err != nil {
+		return nil, err
+	}
+	if yc.Status == YearEndReversed {
+		return nil, fmt.Errorf("the %d year-end close has already been reversed", yc.FiscalYear)
+	}
+
+	// Reopen first so the reversing batch can be posted into the closing period
+	reopen := append([]ledger.Period{}, yc.LockedPeriods...)
+	if !containsPeriod(reopen, yc.ClosingPeriod) {
+		reopen = append(reopen, yc.ClosingPeriod)
+	}
+	logReason := fmt.Sprintf("Year-end close %d reversed: %s", yc.FiscalYear, reason)
+	var closed []*AccountingPeriod
+	for _, p := range reopen {
+		current, err := s.GetPeriod(companyName, p)
+		if err != nil {
+			return nil, err
+		}
+		if current.Status != StatusOpen {
+			closed = append(closed, current)
+		}
+	}
+
+	// The reopen, the reversing batch and the reversal record succeed or fail
+	// together: the periods are reopened in a transaction that is only committed
+	// once the batch has posted
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+	for _, current := range closed {
+		p := current.Period
+		if _, err := tx.Exec(`
+			UPDATE accounting_periods
+			SET status = ?, reopened_by = ?, reopened_at = CURRENT_TIMESTAMP, reopen_reason = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, StatusOpen, username, logReason, current.ID); err != nil {
+			return nil, fmt.Errorf("failed to reopen period: %w", err)
+		}
+		if err := logAction(tx, companyName, p, ActionReopen, current.Status, StatusOpen, username, logReason); err != nil {
+			return nil, err
+		}
+	}
+
+	// An exported batch only needs reversing if it was imported
+	glEntries, err := ledger.LoadGLEntries(companyName)
+	if err != nil {
+		return nil, err
+	}
+	imported := false
+	for _, e := range glEntries {
+		if e.Batch == yc.Batch && e.IsClosingEntry() {
+			imported = true
+			break
+		}
+	}
+
+	reversalBatch := ""
+	if imported {
+		gl := closingGLEntries(yc, "", true, username)
+		if reversalBatch, err = ledger.PostGLEntries(companyName, nil, gl); err != nil {
+			return nil, fmt.Errorf("failed to post reversing entries: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE year_end_closes
+		SET status = ?, reversal_batch = ?, reversed_by = ?, reversed_at = CURRENT_TIMESTAMP, reversal_reason = ?
+		WHERE id = ?
+	`, YearEndReversed, reversalBatch, username, reason, yc.ID); err != nil {
+		if imported {
+			return nil, undoClosingBatch(companyName, yc, username, true,
+				fmt.Errorf("failed to record year-end close reversal: %w", err))
+		}
+		return nil, fmt.Errorf("failed to record year-end close reversal: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		if imported {
+			return nil, undoClosingBatch(companyName, yc, username, true,
+				fmt.Errorf("failed to commit year-end close reversal: %w", err))
+		}
+		return nil, fmt.Errorf("failed to commit year-end close reversal: %w", err)
+	}
+	return s.GetYearEndClose(companyName, yc.ID)
+}
+
+// undoClosingBatch offsets a batch that was posted for a close (or, with
+// reversed set, for its reversal) when recording it in SQLite failed, so
+// GLMASTER.dbf matches the unchanged period state. cause is returned, with the
+// offset's own failure added if it could not be posted.
+func undoClosingBatch(companyName string, yc *YearEndClose, username string, reversed bool, cause error) error {
+	gl := closingGLEntries(yc, "", !reversed, username)
+	if _, err := ledger.PostGLEntries(companyName, nil, gl); err != nil {
+		return fmt.Errorf("%w; the posted batch could not be offset and must be reversed in GLMASTER.dbf: %v", cause, err)
+	}
+	return fmt.Errorf("%w; the posted batch was offset", cause)
+}
+
+// closingGLEntries converts the closing entries to GLMASTER records, swapping
+// debits and credits for a reversal
+func closingGLEntries(yc *YearEndClose, batch string, reverse bool, username string) []ledger.GLEntry {
+	desc := fmt.Sprintf("Year-end close %d", yc.FiscalYear)
+	if reverse {
+		desc = fmt.Sprintf("Reverse year-end close %d", yc.FiscalYear)
+	}
+	entries := make([]ledger.GLEntry, 0, len(yc.Entries))
+	for _, ce := range yc.Entries {
+		debit, credit := ce.Debit, ce.Credit
+		if reverse {
+			debit, credit = credit, debit
+		}
+		entries = append(entries, ledger.GLEntry{
+			Batch:       batch,
+			Year:        yc.ClosingPeriod.CYear(),
+			Period:      yc.ClosingPeriod.CPeriod(),
+			Source:      ledger.SourceYearEnd,
+			Reference:   fmt.Sprintf("FY%d CLOSE", yc.FiscalYear),
+			Date:        yc.ClosingDate,
+			Description: desc,
+			AccountNo:   ce.AccountNo,
+			UnitNo:      ce.UnitNo,
+			DeptNo:      ce.DeptNo,
+			Debit:       debit,
+			Credit:      credit,
+			AddedBy:     username,
+		})
+	}
+	return entries
+}
+
+// YearEndBatchTable lays out a close's entries with GLMASTER column names so the
+// CSV can be imported as a batch
+func YearEndBatchTable(yc *YearEndClose) *reports.Table {
+	t := &reports.Table{
+		CompanyName: reports.CompanyDisplayName(yc.CompanyName),
+		Title:       fmt.Sprintf("Year-End Close %d", yc.FiscalYear),
+		DataOnly:    true,
+		Columns: []reports.Column{
+			{Header: "CBATCH", Width: 20, Align: "L"},
+			{Header: "CYEAR", Width: 12, Align: "L"},
+			{Header: "CPERIOD", Width: 12, Align: "L"},
+			{Header: "DDATE", Width: 20, Align: "L"},
+			{Header: "CACCTNO", Width: 18, Align: "L"},
+			{Header: "CUNITNO", Width: 20, Align: "L"},
+			{Header: "CDEPTNO", Width: 18, Align: "L"},
+			{Header: "CDESC", Width: 55, Align: "L"},
+			{Header: "NDEBITS", Width: 24, Align: "R"},
+			{Header: "NCREDITS", Width: 24, Align: "R"},
+			{Header: "CSOURCE", Width: 12, Align: "L"},
+			{Header: "CREF", Width: 24, Align: "L"},
+		},
+	}
+	for _, e := range closingGLEntries(yc, yc.Batch, false, "") {
+		t.AddRow(e.Batch, e.Year, e.Period, e.Date.Format("01/02/2006"), e.AccountNo, e.UnitNo, e.DeptNo,
+			e.Description, fmt.Sprintf("%.2f", e.Debit), fmt.Sprintf("%.2f", e.Credit), e.Source, e.Reference)
+	}
+	return t
+}
+
+// GetYearEndCloses returns a company's year-end closes, newest first
+func (s *Service) GetYearEndCloses(companyName string) ([]YearEndClose, error) {
+	rows, err := s.db.Query(yearEndSelect+` WHERE company_name = ? ORDER BY fiscal_year DESC, id DESC`, companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query year-end closes: %w", err)
+	}
+	defer rows.Close()
+
+	closes := []YearEndClose{}
+	for rows.Next() {
+		yc, err := scanYearEndClose(rows)
+		if err != nil {
+			return nil, err
+		}
+		closes = append(closes, *yc)
+	}
+	return closes, rows.Err()
+}
+
+// GetYearEndClose returns one year-end close
+func (s *Service) GetYearEndClose(companyName string, id int) (*YearEndClose, error) {
+	yc, err := scanYearEndClose(s.db.QueryRow(yearEndSelect+` WHERE company_name = ? AND id = ?`, companyName, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("year-end close %d not found", id)
+	}
+	return yc, err
+}
+
+// activeYearEndClose returns the close for a year that has not been reversed, or nil
+func (s *Service) activeYearEndClose(companyName string, year int) (*YearEndClose, error) {
+	yc, err := scanYearEndClose(s.db.QueryRow(yearEndSelect+` WHERE company_name = ? AND fiscal_year = ? AND status != ? ORDER BY id DESC LIMIT 1`,
+		companyName, year, YearEndReversed))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return yc, err
+}
+
+func containsPeriod(periods []ledger.Period, p ledger.Period) bool {
+	for _, existing := range periods {
+		if existing == p {
+			return true
+		}
+	}
+	return false
+}
+
+const yearEndSelect = `
+	SELECT id, company_name, fiscal_year, status, mode, retained_earnings_account, closing_year, closing_period,
+	       closing_date, revenue, expenses, net_income, batch, COALESCE(entries_json, '[]'), COALESCE(locked_periods_json, '[]'),
+	       closed_by, closed_at, COALESCE(reversal_batch, ''), COALESCE(reversed_by, ''), reversed_at, COALESCE(reversal_reason, '')
+	FROM year_end_closes`
+
+func scanYearEndClose(row rowScanner) (*YearEndClose, error) {
+	var yc YearEndClose
+	var closingDate, closedAt, reversedAt interface{}
+	var entries, locked string
+	if err := row.Scan(&yc.ID, &yc.CompanyName, &yc.FiscalYear, &yc.Status, &yc.Mode, &yc.RetainedEarningsAccount,
+		&yc.ClosingPeriod.Year, &yc.ClosingPeriod.Period, &closingDate, &yc.Revenue, &yc.Expenses, &yc.NetIncome,
+		&yc.Batch, &entries, &locked, &yc.ClosedBy, &closedAt, &yc.ReversalBatch, &yc.ReversedBy, &reversedAt,
+		&yc.ReversalReason); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan year-end close: %w", err)
+	}
+	yc.ClosingDate, _ = ledger.AsDate(closingDate)
+	if t, ok := asTimestamp(closedAt); ok {
+		yc.ClosedAt = &t
+	}
+	if t, ok := asTimestamp(reversedAt); ok {
+		yc.ReversedAt = &t
+	}
+	if err := json.Unmarshal([]byte(entries), &yc.Entries); err != nil {
+		yc.Entries = []ClosingEntry{}
+	}
+	if err := json.Unmarshal([]byte(locked), &yc.LockedPeriods); err != nil {
+		yc.LockedPeriods = nil
+	}
+	debits, credits := currency.Zero(), currency.Zero()
+	for _, e := range yc.Entries {
+		debits = debits.Add(currency.NewFromFloat(e.Debit))
+		credits = credits.Add(currency.NewFromFloat(e.Credit))
+	}
+	yc.TotalDebits, yc.TotalCredits = debits.ToFloat64(), credits.ToFloat64()
+	return &yc, nil
+}
@@ -0,0 +1,682 @@
+package reconciliation
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/periods"
+)
+
+// Account reconciliation categories
+const (
+	CategoryPrepaid  = "prepaid"
+	CategoryAccrual  = "accrual"
+	CategorySuspense = "suspense"
+	CategoryClearing = "clearing" // e.g. the RevClear/ExpClear accounts of the distribution process
+	CategoryOther    = "other"
+)
+
+var accountRecCategories = map[string]bool{
+	CategoryPrepaid: true, CategoryAccrual: true, CategorySuspense: true, CategoryClearing: true, CategoryOther: true,
+}
+
+// Account reconciliation statuses. A reconciliation is edited while open, signed
+// by the preparer and then by a different reviewer, after which it is locked
+// until reopened.
+const (
+	AccountRecNotStarted = "not_started" // Only reported by GetAccountRecStatus
+	AccountRecOpen       = "open"
+	AccountRecPrepared   = "prepared"
+	AccountRecReviewed   = "reviewed"
+)
+
+// Account reconciliation log actions
+const (
+	RecActionStart   = "start"
+	RecActionPrepare = "prepare"
+	RecActionReview  = "review"
+	RecActionReopen  = "reopen"
+)
+
+// RecAccount is a balance sheet, clearing or suspense account set up for periodic
+// reconciliation
+type RecAccount struct {
+	AccountNumber string     `json:"account_number"`
+	Description   string     `json:"description"`
+	AccountType   int        `json:"account_type"`
+	Category      string     `json:"category"`
+	Tolerance     float64    `json:"tolerance"` // Largest difference that can be signed off without an explanation
+	Instructions  string     `json:"instructions"`
+	UpdatedBy     string     `json:"updated_by,omitempty"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
+
+// AccountRec is the reconciliation workpaper of one account for one fiscal period.
+// Balances and items are debit-positive like the GL.
+type AccountRec struct {
+	ID            int           `json:"id"`
+	CompanyName   string        `json:"company_name"`
+	AccountNumber string        `json:"account_number"`
+	AccountName   string        `json:"account_name"`
+	Category      string        `json:"category"`
+	Period        ledger.Period `json:"period"`
+	PeriodEnd     time.Time     `json:"period_end"`
+	Status        string        `json:"status"`
+	GLBalance     float64       `json:"gl_balance"`     // Through the period end, as the GL stands now
+	ScheduleTotal float64       `json:"schedule_total"` // Items not cleared
+	Difference    float64       `json:"difference"`     // GLBalance - ScheduleTotal, the unreconciled difference
+	Tolerance     float64       `json:"tolerance"`
+	// PreparedGLBalance is the GL balance the preparer signed off on; GLChanged
+	// flags postings to the period since then
+	PreparedGLBalance *float64   `json:"prepared_gl_balance,omitempty"`
+	GLChanged         bool       `json:"gl_changed"`
+	Explanation       string     `json:"explanation"`
+	Notes             string     `json:"notes"`
+	RolledFromID      *int       `json:"rolled_from_id,omitempty"`
+	Items             []RecItem  `json:"items"`
+	CreatedBy         string     `json:"created_by"`
+	CreatedAt         *time.Time `json:"created_at,omitempty"`
+	PreparedBy        string     `json:"prepared_by,omitempty"`
+	PreparedAt        *time.Time `json:"prepared_at,omitempty"`
+	ReviewedBy        string     `json:"reviewed_by,omitempty"`
+	ReviewedAt        *time.Time `json:"reviewed_at,omitempty"`
+	Warnings          []string   `json:"warnings"`
+}
+
+// AccountRecSummary is one reconciled account's standing for a period
+type AccountRecSummary struct {
+	AccountNumber string   `json:"account_number"`
+	Description   string   `json:"description"`
+	Category      string   `json:"category"`
+	RecID         int      `json:"rec_id,omitempty"`
+	Status        string   `json:"status"`
+	GLBalance     float64  `json:"gl_balance"`
+	ScheduleTotal *float64 `json:"schedule_total,omitempty"`
+	Difference    *float64 `json:"difference,omitempty"`
+	OpenItems     int      `json:"open_items"`
+	PreparedBy    string   `json:"prepared_by,omitempty"`
+	ReviewedBy    string   `json:"reviewed_by,omitempty"`
+}
+
+// RecLogEntry is one sign-off event on a reconciliation
+type RecLogEntry struct {
+	Action   string     `json:"action"`
+	Username string     `json:"username"`
+	Note     string     `json:"note"`
+	LoggedAt *time.Time `json:"logged_at,omitempty"`
+}
+
+// GetRecAccounts lists the accounts set up for reconciliation
+func (s *Service) GetRecAccounts(companyName string) ([]RecAccount, error) {
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return nil, err
+	}
+	byNo := ledger.AccountMap(accounts)
+
+	rows, err := s.db.Query(`
+		SELECT account_number, category, COALESCE(tolerance, 0), COALESCE(instructions, ''), COALESCE(updated_by, ''), updated_at
+		FROM account_rec_accounts
+		WHERE company_name = ?
+		ORDER BY account_number`, companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reconciled accounts: %w", err)
+	}
+	defer rows.Close()
+
+	result := []RecAccount{}
+	for rows.Next() {
+		var a RecAccount
+		var updatedAt interface{}
+		if err := rows.Scan(&a.AccountNumber, &a.Category, &a.Tolerance, &a.Instructions, &a.UpdatedBy, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reconciled account: %w", err)
+		}
+		if acct, ok := byNo[a.AccountNumber]; ok {
+			a.Description, a.AccountType = acct.Description, acct.Type
+		} else {
+			a.Description = "(not in chart of accounts)"
+		}
+		a.UpdatedAt = recTime(updatedAt)
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
+
+// SaveRecAccount adds an account to the reconciled accounts or changes its
+// settings. Bank accounts are reconciled against statements instead.
+func (s *Service) SaveRecAccount(companyName string, a RecAccount, username string) (*RecAccount, error) {
+	a.AccountNumber = strings.TrimSpace(a.AccountNumber)
+	a.Category = strings.ToLower(strings.TrimSpace(a.Category))
+	acct, err := s.recAccount(companyName, a.AccountNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !accountRecCategories[a.Category] {
+		return nil, fmt.Errorf("unknown reconciliation category: %s", a.Category)
+	}
+	if a.Tolerance < 0 {
+		return nil, fmt.Errorf("tolerance cannot be negative")
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO account_rec_accounts (company_name, account_number, category, tolerance, instructions, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(company_name, account_number) DO UPDATE SET
+			category = excluded.category, tolerance = excluded.tolerance, instructions = excluded.instructions,
+			updated_by = excluded.updated_by, updated_at = CURRENT_TIMESTAMP
+	`, companyName, a.AccountNumber, a.Category, currency.NewFromFloat(a.Tolerance).ToFloat64(), a.Instructions, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save reconciled account: %w", err)
+	}
+	a.Description, a.AccountType, a.UpdatedBy = acct.Description, acct.Type, username
+	return &a, nil
+}
+
+// RemoveRecAccount stops reconciling an account. Its workpapers are kept.
+func (s *Service) RemoveRecAccount(companyName, accountNumber string) error {
+	result, err := s.db.Exec(`DELETE FROM account_rec_accounts WHERE company_name = ? AND account_number = ?`, companyName, accountNumber)
+	if err != nil {
+		return fmt.Errorf("failed to remove reconciled account: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("account %s is not set up for reconciliation", accountNumber)
+	}
+	return nil
+}
+
+// recAccount checks that an account can be reconciled here: a posting balance
+// sheet or other (clearing and suspense) account that is not a bank account
+func (s *Service) recAccount(companyName, accountNumber string) (ledger.Account, error) {
+	accounts, err := ledger.LoadAccounts(companyName)
+	if err != nil {
+		return ledger.Account{}, err
+	}
+	acct, ok := ledger.AccountMap(accounts)[accountNumber]
+	switch {
+	case !ok:
+		return acct, fmt.Errorf("account %s is not in the chart of accounts", accountNumber)
+	case !ledger.IsBalanceSheet(acct.Type) && acct.Type != ledger.TypeOther:
+		return acct, fmt.Errorf("account %s is not a balance sheet, clearing or suspense account", accountNumber)
+	case acct.IsBank:
+		return acct, fmt.Errorf("account %s is a bank account; use the bank reconciliation", accountNumber)
+	case acct.IsTitle || acct.IsTotal:
+		return acct, fmt.Errorf("account %s is a heading account and has no balance of its own", accountNumber)
+	}
+	return acct, nil
+}
+
+// recSettings returns an account's reconciliation settings
+func (s *Service) recSettings(companyName, accountNumber string) (*RecAccount, error) {
+	var a RecAccount
+	err := s.db.QueryRow(`
+		SELECT account_number, category, COALESCE(tolerance, 0), COALESCE(instructions, '')
+		FROM account_rec_accounts
+		WHERE company_name = ? AND account_number = ?`, companyName, accountNumber).Scan(&a.AccountNumber, &a.Category, &a.Tolerance, &a.Instructions)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("account %s is not set up for reconciliation", accountNumber)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reconciled account: %w", err)
+	}
+	return &a, nil
+}
+
+// GetAccountRecStatus lists every reconciled account with its GL balance and
+// the state of its reconciliation for the period
+func (s *Service) GetAccountRecStatus(companyName string, p ledger.Period) ([]AccountRecSummary, error) {
+	accounts, err := s.GetRecAccounts(companyName)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := periods.NewService(s.db).Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(accounts))
+	for _, a := range accounts {
+		wanted[a.AccountNumber] = true
+	}
+	balances, _, err := glBalances(companyName, cal, wanted, p)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []AccountRecSummary{}
+	for _, a := range accounts {
+		sum := AccountRecSummary{
+			AccountNumber: a.AccountNumber,
+			Description:   a.Description,
+			Category:      a.Category,
+			Status:        AccountRecNotStarted,
+			GLBalance:     balances[a.AccountNumber].ToFloat64(),
+		}
+		var total float64
+		err := s.db.QueryRow(`
+			SELECT r.id, r.status, COALESCE(r.prepared_by, ''), COALESCE(r.reviewed_by, ''),
+			       COALESCE(SUM(CASE WHEN i.cleared THEN 0 ELSE i.amount END), 0),
+			       COALESCE(SUM(CASE WHEN i.cleared OR i.id IS NULL THEN 0 ELSE 1 END), 0)
+			FROM account_recs r
+			LEFT JOIN account_rec_items i ON i.rec_id = r.id
+			WHERE r.company_name = ? AND r.account_number = ? AND r.fiscal_year = ? AND r.period = ?
+			GROUP BY r.id`, companyName, a.AccountNumber, p.Year, p.Period).Scan(
+			&sum.RecID, &sum.Status, &sum.PreparedBy, &sum.ReviewedBy, &total, &sum.OpenItems)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to query reconciliation of %s: %w", a.AccountNumber, err)
+		}
+		if err == nil {
+			schedule := currency.NewFromFloat(total)
+			st, diff := schedule.ToFloat64(), balances[a.AccountNumber].Sub(schedule).ToFloat64()
+			sum.ScheduleTotal, sum.Difference = &st, &diff
+		}
+		summaries = append(summaries, sum)
+	}
+	return summaries, nil
+}
+
+// StartAccountRec creates the workpaper for an account and period and rolls
+// forward the uncleared items of the latest earlier reconciliation
+func (s *Service) StartAccountRec(companyName, accountNumber string, p ledger.Period, username string) (*AccountRec, error) {
+	if _, err := s.recAccount(companyName, accountNumber); err != nil {
+		return nil, err
+	}
+	if _, err := s.recSettings(companyName, accountNumber); err != nil {
+		return nil, err
+	}
+	cal, err := periods.NewService(s.db).Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+	if p.Period < 1 || p.Period > cal.PeriodCount() {
+		return nil, fmt.Errorf("invalid period: %s", p)
+	}
+	_, periodEnd := cal.PeriodDates(p)
+
+	var prior *AccountRec
+	var priorID int
+	err = s.db.QueryRow(`
+		SELECT id FROM account_recs
+		WHERE company_name = ? AND account_number = ? AND (fiscal_year < ? OR (fiscal_year = ? AND period < ?))
+		ORDER BY fiscal_year DESC, period DESC LIMIT 1`,
+		companyName, accountNumber, p.Year, p.Year, p.Period).Scan(&priorID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to find the prior reconciliation: %w", err)
+	}
+	if err == nil {
+		if prior, err = s.loadAccountRec(companyName, priorID); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var rolledFrom interface{}
+	if prior != nil {
+		rolledFrom = prior.ID
+	}
+	result, err := tx.Exec(`
+		INSERT INTO account_recs (company_name, account_number, fiscal_year, period, period_end, status, rolled_from_id, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, companyName, accountNumber, p.Year, p.Period, periodEnd.Format("2006-01-02"), AccountRecOpen, rolledFrom, username)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, fmt.Errorf("account %s already has a reconciliation for %s", accountNumber, p)
+		}
+		return nil, fmt.Errorf("failed to create reconciliation: %w", err)
+	}
+	id, _ := result.LastInsertId()
+
+	note := "No earlier reconciliation to roll forward"
+	if prior != nil {
+		rolled, err := rollForward(tx, prior, int(id), username)
+		if err != nil {
+			return nil, err
+		}
+		note = fmt.Sprintf("Rolled forward %d open item(s) from %s", rolled, prior.Period)
+	}
+	if err := logRecAction(tx, int(id), RecActionStart, username, note); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to create reconciliation: %w", err)
+	}
+
+	rec, err := s.GetAccountRec(companyName, int(id))
+	if err != nil {
+		return nil, err
+	}
+	if prior != nil && prior.Status != AccountRecReviewed {
+		rec.Warnings = append(rec.Warnings, fmt.Sprintf("The %s reconciliation these items were rolled forward from has not been reviewed", prior.Period))
+	}
+	return rec, nil
+}
+
+// GetAccountRec returns a workpaper with its items, recomputed against the GL
+// as it stands now
+func (s *Service) GetAccountRec(companyName string, id int) (*AccountRec, error) {
+	rec, err := s.loadAccountRec(companyName, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshAccountRec(rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// PrepareAccountRec signs the reconciliation off as prepared. A difference over
+// the account's tolerance needs an explanation.
+func (s *Service) PrepareAccountRec(companyName string, id int, explanation, username string) (*AccountRec, error) {
+	rec, err := s.GetAccountRec(companyName, id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Status != AccountRecOpen {
+		return nil, fmt.Errorf("the reconciliation is already %s", rec.Status)
+	}
+	explanation = strings.TrimSpace(explanation)
+	if !withinTolerance(rec.Difference, rec.Tolerance) && explanation == "" {
+		return nil, fmt.Errorf("the unreconciled difference of %.2f is over the %.2f tolerance and needs an explanation", rec.Difference, rec.Tolerance)
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE account_recs SET status = ?, gl_balance = ?, schedule_total = ?, difference = ?, explanation = ?,
+			prepared_by = ?, prepared_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, AccountRecPrepared, rec.GLBalance, rec.ScheduleTotal, rec.Difference, explanation, username, id); err != nil {
+		return nil, fmt.Errorf("failed to prepare reconciliation: %w", err)
+	}
+	note := fmt.Sprintf("GL %.2f, schedule %.2f, difference %.2f", rec.GLBalance, rec.ScheduleTotal, rec.Difference)
+	if err := logRecAction(tx, id, RecActionPrepare, username, note); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to prepare reconciliation: %w", err)
+	}
+	return s.GetAccountRec(companyName, id)
+}
+
+// ReviewAccountRec signs off a prepared reconciliation. The reviewer cannot be
+// the preparer, and the GL must not have moved since it was prepared.
+func (s *Service) ReviewAccountRec(companyName string, id int, note, username string) (*AccountRec, error) {
+	rec, err := s.GetAccountRec(companyName, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case rec.Status != AccountRecPrepared:
+		return nil, fmt.Errorf("only a prepared reconciliation can be reviewed (this one is %s)", rec.Status)
+	case strings.EqualFold(rec.PreparedBy, username):
+		return nil, fmt.Errorf("the reconciliation must be reviewed by someone other than its preparer")
+	case rec.GLChanged:
+		return nil, fmt.Errorf("the GL balance has changed from %.2f to %.2f since the reconciliation was prepared; reopen it and update the schedule",
+			*rec.PreparedGLBalance, rec.GLBalance)
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE account_recs SET status = ?, reviewed_by = ?, reviewed_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		AccountRecReviewed, username, id); err != nil {
+		return nil, fmt.Errorf("failed to review reconciliation: %w", err)
+	}
+	if err := logRecAction(tx, id, RecActionReview, username, strings.TrimSpace(note)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to review reconciliation: %w", err)
+	}
+	return s.GetAccountRec(companyName, id)
+}
+
+// ReopenAccountRec clears the sign-offs so the schedule can be changed
+func (s *Service) ReopenAccountRec(companyName string, id int, reason, username string) (*AccountRec, error) {
+	rec, err := s.loadAccountRec(companyName, id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Status == AccountRecOpen {
+		return nil, fmt.Errorf("the reconciliation is already open")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("a reason is required to reopen a reconciliation")
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE account_recs SET status = ?, gl_balance = NULL, schedule_total = NULL, difference = NULL,
+			prepared_by = NULL, prepared_at = NULL, reviewed_by = NULL, reviewed_at = NULL
+		WHERE id = ?
+	`, AccountRecOpen, id); err != nil {
+		return nil, fmt.Errorf("failed to reopen reconciliation: %w", err)
+	}
+	if err := logRecAction(tx, id, RecActionReopen, username, reason); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to reopen reconciliation: %w", err)
+	}
+	return s.GetAccountRec(companyName, id)
+}
+
+// SaveAccountRecNotes updates the preparer's notes on an open reconciliation
+func (s *Service) SaveAccountRecNotes(companyName string, id int, notes string) (*AccountRec, error) {
+	if _, err := s.openAccountRec(companyName, id); err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Exec(`UPDATE account_recs SET notes = ? WHERE id = ?`, notes, id); err != nil {
+		return nil, fmt.Errorf("failed to save notes: %w", err)
+	}
+	return s.GetAccountRec(companyName, id)
+}
+
+// GetAccountRecLog returns a reconciliation's sign-off history, oldest first
+func (s *Service) GetAccountRecLog(companyName string, id int) ([]RecLogEntry, error) {
+	if _, err := s.loadAccountRec(companyName, id); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`
+		SELECT action, COALESCE(username, ''), COALESCE(note, ''), logged_at
+		FROM account_rec_log WHERE rec_id = ? ORDER BY id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reconciliation log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []RecLogEntry{}
+	for rows.Next() {
+		var e RecLogEntry
+		var loggedAt interface{}
+		if err := rows.Scan(&e.Action, &e.Username, &e.Note, &loggedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reconciliation log: %w", err)
+		}
+		e.LoggedAt = recTime(loggedAt)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func logRecAction(tx *sql.Tx, recID int, action, username, note string) error {
+	if _, err := tx.Exec(`INSERT INTO account_rec_log (rec_id, action, username, note) VALUES (?, ?, ?, ?)`,
+		recID, action, username, note); err != nil {
+		return fmt.Errorf("failed to write reconciliation log: %w", err)
+	}
+	return nil
+}
+
+// loadAccountRec reads a workpaper and its items as stored
+func (s *Service) loadAccountRec(companyName string, id int) (*AccountRec, error) {
+	rec := &AccountRec{Items: []RecItem{}, Warnings: []string{}}
+	var periodEnd, createdAt, preparedAt, reviewedAt interface{}
+	var prepared sql.NullFloat64
+	var rolledFrom sql.NullInt64
+	err := s.db.QueryRow(`
+		SELECT id, company_name, account_number, fiscal_year, period, period_end, status, gl_balance,
+		       COALESCE(explanation, ''), COALESCE(notes, ''), rolled_from_id, COALESCE(created_by, ''), created_at,
+		       COALESCE(prepared_by, ''), prepared_at, COALESCE(reviewed_by, ''), reviewed_at
+		FROM account_recs WHERE id = ? AND company_name = ?`, id, companyName).Scan(
+		&rec.ID, &rec.CompanyName, &rec.AccountNumber, &rec.Period.Year, &rec.Period.Period, &periodEnd, &rec.Status, &prepared,
+		&rec.Explanation, &rec.Notes, &rolledFrom, &rec.CreatedBy, &createdAt,
+		&rec.PreparedBy, &preparedAt, &rec.ReviewedBy, &reviewedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("account reconciliation %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account reconciliation: %w", err)
+	}
+	if d, ok := ledger.AsDate(periodEnd); ok {
+		rec.PeriodEnd = d
+	}
+	if prepared.Valid {
+		rec.PreparedGLBalance = &prepared.Float64
+	}
+	if rolledFrom.Valid {
+		from := int(rolledFrom.Int64)
+		rec.RolledFromID = &from
+	}
+	rec.CreatedAt, rec.PreparedAt, rec.ReviewedAt = recTime(createdAt), recTime(preparedAt), recTime(reviewedAt)
+
+	if rec.Items, err = s.recItems(id); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// openAccountRec loads a workpaper that can still be edited
+func (s *Service) openAccountRec(companyName string, id int) (*AccountRec, error) {
+	rec, err := s.loadAccountRec(companyName, id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Status != AccountRecOpen {
+		return nil, fmt.Errorf("the reconciliation is %s; reopen it to make changes", rec.Status)
+	}
+	return rec, nil
+}
+
+// refreshAccountRec fills in the account, the current GL balance and the totals
+func (s *Service) refreshAccountRec(rec *AccountRec) error {
+	accounts, err := ledger.LoadAccounts(rec.CompanyName)
+	if err != nil {
+		return err
+	}
+	if acct, ok := ledger.AccountMap(accounts)[rec.AccountNumber]; ok {
+		rec.AccountName = acct.Description
+	}
+	if settings, err := s.recSettings(rec.CompanyName, rec.AccountNumber); err == nil {
+		rec.Category, rec.Tolerance = settings.Category, settings.Tolerance
+	} else {
+		rec.Warnings = append(rec.Warnings, fmt.Sprintf("Account %s is no longer set up for reconciliation", rec.AccountNumber))
+	}
+
+	cal, err := periods.NewService(s.db).Calendar(rec.CompanyName)
+	if err != nil {
+		return err
+	}
+	balances, undated, err := glBalances(rec.CompanyName, cal, map[string]bool{rec.AccountNumber: true}, rec.Period)
+	if err != nil {
+		return err
+	}
+	if undated > 0 {
+		rec.Warnings = append(rec.Warnings, fmt.Sprintf("%d GL line(s) on the account have no year, period or date and are left out of the balance", undated))
+	}
+
+	gl := balances[rec.AccountNumber]
+	schedule := currency.Zero()
+	for _, item := range rec.Items {
+		if !item.Cleared {
+			schedule = schedule.Add(currency.NewFromFloat(item.Amount))
+		}
+	}
+	rec.GLBalance = gl.ToFloat64()
+	rec.ScheduleTotal = schedule.ToFloat64()
+	rec.Difference = gl.Sub(schedule).ToFloat64()
+	if rec.PreparedGLBalance != nil && !currency.NewFromFloat(*rec.PreparedGLBalance).Equal(gl) {
+		rec.GLChanged = true
+		rec.Warnings = append(rec.Warnings, fmt.Sprintf("The GL balance has changed from %.2f to %.2f since the reconciliation was prepared",
+			*rec.PreparedGLBalance, rec.GLBalance))
+	}
+	return nil
+}
+
+func withinTolerance(difference, tolerance float64) bool {
+	d := currency.NewFromFloat(difference)
+	if d.IsNegative() {
+		d = d.Neg()
+	}
+	return !d.GreaterThan(currency.NewFromFloat(tolerance))
+}
+
+// glBalances totals GLMASTER for the given accounts through the end of period p.
+// It also returns how many of their lines have no fiscal period.
+func glBalances(companyName string, cal *ledger.Calendar, accounts map[string]bool, p ledger.Period) (map[string]currency.Currency, int, error) {
+	balances := make(map[string]currency.Currency, len(accounts))
+	for account := range accounts {
+		balances[account] = currency.Zero()
+	}
+	undated := 0
+	err := ledger.ScanGLEntries(companyName, func(e ledger.GLEntry) error {
+		if !accounts[e.AccountNo] {
+			return nil
+		}
+		fp, ok := e.FiscalPeriodIn(cal)
+		if !ok {
+			undated++
+			return nil
+		}
+		if !p.Before(fp) {
+			balances[e.AccountNo] = balances[e.AccountNo].Add(e.Net())
+		}
+		return nil
+	})
+	return balances, undated, err
+}
+
+// sortItems orders a schedule by date, then reference
+func sortItems(items []RecItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		di, dj := items[i].ItemDate, items[j].ItemDate
+		switch {
+		case di == nil && dj != nil:
+			return false
+		case di != nil && dj == nil:
+			return true
+		case di != nil && dj != nil && !di.Equal(*dj):
+			return di.Before(*dj)
+		}
+		return items[i].Reference < items[j].Reference
+	})
+}
+
+func recTime(v interface{}) *time.Time {
+	if t, ok := v.(time.Time); ok && !t.IsZero() {
+		return &t
+	}
+	if t, ok := ledger.AsDate(v); ok {
+		return &t
+	}
+	return nil
+}
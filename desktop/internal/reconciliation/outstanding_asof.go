@@ -0,0 +1,311 @@
+package reconciliation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Clear date sources, in the order they are trusted
+const (
+	ClearSourcePaidItems      = "paid_items"        // Bank paid items file
+	ClearSourceBankStatement  = "bank_statement"    // Matched imported bank transaction
+	ClearSourceReconciliation = "reconciliation"    // Committed reconciliation statement date
+	ClearSourceChecksDBF      = "checks_dbf"        // CHECKS.DRECDATE
+	ClearSourceCheckRec       = "checkrec_estimate" // First CHECKREC reconciliation after the check date
+)
+
+// HistoricalOutstandingItem is a check or deposit that was outstanding on the as-of date
+type HistoricalOutstandingItem struct {
+	ledger.Check
+	ClearedDate     *time.Time `json:"cleared_date,omitempty"`
+	ClearedSource   string     `json:"cleared_source,omitempty"`
+	DaysOutstanding int        `json:"days_outstanding"`
+}
+
+// OutstandingAsOfReport is the outstanding list for an account as of a past date,
+// tied back to the GL balance on that date
+type OutstandingAsOfReport struct {
+	CompanyName       string                      `json:"company_name"`
+	AccountNumber     string                      `json:"account_number"`
+	AsOfDate          time.Time                   `json:"as_of_date"`
+	OutstandingChecks []HistoricalOutstandingItem `json:"outstanding_checks"`
+	DepositsInTransit []HistoricalOutstandingItem `json:"deposits_in_transit"`
+	ChecksTotal       float64                     `json:"outstanding_checks_total"`
+	DepositsTotal     float64                     `json:"deposits_in_transit_total"`
+	GLBalance         float64                     `json:"gl_balance"`
+	GLEntryCount      int                         `json:"gl_entry_count"`
+	// AdjustedBankBalance is what the bank should have shown on the as-of date:
+	// GL balance + outstanding checks - deposits in transit
+	AdjustedBankBalance float64  `json:"adjusted_bank_balance"`
+	StatementBalance    *float64 `json:"statement_balance,omitempty"`
+	StatementSource     string   `json:"statement_source,omitempty"`
+	Difference          *float64 `json:"difference,omitempty"`
+	Ties                bool     `json:"ties"`
+	EstimatedCount      int      `json:"estimated_count"` // Items whose clear date came from a CHECKREC estimate
+	UnknownClearCount   int      `json:"unknown_clear_count"`
+	VoidedExcluded      int      `json:"voided_excluded"`
+	Warnings            []string `json:"warnings"`
+}
+
+type clearDate struct {
+	date   time.Time
+	source string
+}
+
+// GetOutstandingChecksAsOf rebuilds the outstanding check and deposit list for an
+// account as it stood on asOf. Items issued on or before asOf count as outstanding
+// unless their clear date (from paid items, matched bank transactions, committed
+// reconciliations, CHECKS.DRECDATE or CHECKREC) is also on or before asOf.
+func (s *Service) GetOutstandingChecksAsOf(companyName, accountNumber string, asOf time.Time) (*OutstandingAsOfReport, error) {
+	asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+
+	checks, err := ledger.LoadChecks(companyName)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OutstandingAsOfReport{
+		CompanyName:       companyName,
+		AccountNumber:     accountNumber,
+		AsOfDate:          asOf,
+		OutstandingChecks: []HistoricalOutstandingItem{},
+		DepositsInTransit: []HistoricalOutstandingItem{},
+		Warnings:          []string{},
+	}
+
+	clearDates, err := s.loadClearDates(companyName, accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	// CHECKREC is optional - older companies may not have it
+	var recDates []time.Time
+	checkRecs, err := ledger.LoadCheckRecs(companyName)
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("CHECKREC.dbf not available: %v", err))
+	}
+	for _, rec := range checkRecs {
+		if rec.AccountNo == accountNumber {
+			recDates = append(recDates, rec.RecDate)
+		}
+	}
+
+	checksTotal := currency.Zero()
+	depositsTotal := currency.Zero()
+	for _, check := range checks {
+		if check.AccountNo != accountNumber || check.CheckDate.IsZero() || check.CheckDate.After(asOf) {
+			continue
+		}
+		// CHECKS.dbf has no void date, so a voided check is treated as never issued
+		if check.IsVoid {
+			report.VoidedExcluded++
+			continue
+		}
+
+		cleared, known := clearDates[check.CIDCHEC]
+		if !known && !check.RecDate.IsZero() && check.IsCleared {
+			cleared, known = clearDate{date: check.RecDate, source: ClearSourceChecksDBF}, true
+		}
+		if !known && check.IsCleared {
+			if d, ok := firstRecOnOrAfter(recDates, check.CheckDate); ok {
+				cleared, known = clearDate{date: d, source: ClearSourceCheckRec}, true
+				if !d.After(asOf) {
+					report.EstimatedCount++
+				}
+			} else {
+				// Cleared with no trace of when - assume it cleared before asOf
+				report.UnknownClearCount++
+				continue
+			}
+		}
+		if known && !cleared.date.After(asOf) {
+			continue
+		}
+
+		item := HistoricalOutstandingItem{
+			Check:           check,
+			DaysOutstanding: int(asOf.Sub(check.CheckDate).Hours() / 24),
+		}
+		if known {
+			d := cleared.date
+			item.ClearedDate = &d
+			item.ClearedSource = cleared.source
+		}
+
+		amount := currency.NewFromFloat(check.Amount)
+		if check.IsDeposit() {
+			report.DepositsInTransit = append(report.DepositsInTransit, item)
+			depositsTotal = depositsTotal.Add(amount)
+		} else {
+			report.OutstandingChecks = append(report.OutstandingChecks, item)
+			checksTotal = checksTotal.Add(amount)
+		}
+	}
+
+	sortByCheckDate := func(items []HistoricalOutstandingItem) {
+		sort.Slice(items, func(i, j int) bool {
+			if !items[i].CheckDate.Equal(items[j].CheckDate) {
+				return items[i].CheckDate.Before(items[j].CheckDate)
+			}
+			return items[i].CheckNumber < items[j].CheckNumber
+		})
+	}
+	sortByCheckDate(report.OutstandingChecks)
+	sortByCheckDate(report.DepositsInTransit)
+
+	entries, err := ledger.LoadGLEntries(companyName)
+	if err != nil {
+		return nil, err
+	}
+	glBalance, glCount := ledger.AccountBalanceAsOf(entries, accountNumber, asOf)
+
+	adjusted := glBalance.Add(checksTotal).Sub(depositsTotal)
+	report.ChecksTotal = checksTotal.ToFloat64()
+	report.DepositsTotal = depositsTotal.ToFloat64()
+	report.GLBalance = glBalance.ToFloat64()
+	report.GLEntryCount = glCount
+	report.AdjustedBankBalance = adjusted.ToFloat64()
+
+	// Tie to the bank balance if a reconciliation was done for exactly this date
+	if balance, source, ok := s.statementBalanceOn(companyName, accountNumber, asOf, checkRecs); ok {
+		diff := currency.NewFromFloat(balance).Sub(adjusted).ToFloat64()
+		report.StatementBalance = &balance
+		report.StatementSource = source
+		report.Difference = &diff
+		report.Ties = currency.NewFromFloat(diff).IsZero()
+	}
+
+	if report.UnknownClearCount > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d cleared items have no recorded clear date and were treated as cleared before the as-of date", report.UnknownClearCount))
+	}
+	if report.EstimatedCount > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d clear dates were estimated from CHECKREC reconciliation dates", report.EstimatedCount))
+	}
+	if report.VoidedExcluded > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d voided items were excluded; CHECKS.dbf does not record when they were voided", report.VoidedExcluded))
+	}
+
+	return report, nil
+}
+
+// loadClearDates collects the earliest known clear date per CIDCHEC from SQLite.
+// Sources are applied from least to most trusted so the better source wins.
+func (s *Service) loadClearDates(companyName, accountNumber string) (map[string]clearDate, error) {
+	dates := make(map[string]clearDate)
+
+	// Committed reconciliations - the statement date is the latest the item could have cleared
+	rows, err := s.db.Query(`
+		SELECT statement_date, selected_checks_json FROM reconciliations
+		WHERE company_name = ? AND account_number = ? AND status = 'committed'
+		ORDER BY statement_date ASC
+	`, companyName, accountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reconciliation history: %w", err)
+	}
+	for rows.Next() {
+		var statementDate interface{}
+		var selectedJSON *string
+		if err := rows.Scan(&statementDate, &selectedJSON); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan reconciliation: %w", err)
+		}
+		d, ok := ledger.AsDate(statementDate)
+		if !ok || selectedJSON == nil || *selectedJSON == "" {
+			continue
+		}
+		var selected []SelectedCheck
+		if err := json.Unmarshal([]byte(*selectedJSON), &selected); err != nil {
+			continue
+		}
+		for _, sc := range selected {
+			if _, seen := dates[sc.CIDCHEC]; !seen && sc.CIDCHEC != "" {
+				dates[sc.CIDCHEC] = clearDate{date: d, source: ClearSourceReconciliation}
+			}
+		}
+	}
+	rows.Close()
+
+	queries := []struct {
+		source string
+		query  string
+	}{
+		{ClearSourceBankStatement, `
+			SELECT matched_check_id, MIN(transaction_date) FROM bank_transactions
+			WHERE company_name = ? AND account_number = ? AND is_matched = TRUE
+			  AND matched_check_id IS NOT NULL AND matched_check_id != ''
+			GROUP BY matched_check_id`},
+		{ClearSourcePaidItems, `
+			SELECT cidchec, MIN(paid_date) FROM paid_items
+			WHERE company_name = ? AND account_number = ? AND status IN ('cleared', 'already_cleared')
+			  AND cidchec IS NOT NULL AND cidchec != '' AND paid_date IS NOT NULL
+			GROUP BY cidchec`},
+	}
+	for _, q := range queries {
+		rows, err := s.db.Query(q.query, companyName, accountNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s clear dates: %w", q.source, err)
+		}
+		for rows.Next() {
+			var cidchec string
+			var raw interface{}
+			if err := rows.Scan(&cidchec, &raw); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s clear date: %w", q.source, err)
+			}
+			if d, ok := ledger.AsDate(raw); ok {
+				dates[cidchec] = clearDate{date: d, source: q.source}
+			}
+		}
+		rows.Close()
+	}
+
+	return dates, nil
+}
+
+// statementBalanceOn finds a bank balance recorded for exactly the given date,
+// preferring committed reconciliations, then imported statements, then CHECKREC
+func (s *Service) statementBalanceOn(companyName, accountNumber string, date time.Time, checkRecs []ledger.CheckRec) (float64, string, bool) {
+	day := date.Format("2006-01-02")
+
+	var balance float64
+	err := s.db.QueryRow(`
+		SELECT statement_balance FROM reconciliations
+		WHERE company_name = ? AND account_number = ? AND status = 'committed' AND DATE(statement_date) = ?
+		ORDER BY committed_at DESC LIMIT 1
+	`, companyName, accountNumber, day).Scan(&balance)
+	if err == nil {
+		return balance, ClearSourceReconciliation, true
+	}
+
+	var ending *float64
+	err = s.db.QueryRow(`
+		SELECT ending_balance FROM bank_statements
+		WHERE company_name = ? AND account_number = ? AND is_active = TRUE AND DATE(statement_date) = ?
+		LIMIT 1
+	`, companyName, accountNumber, day).Scan(&ending)
+	if err == nil && ending != nil {
+		return *ending, ClearSourceBankStatement, true
+	}
+
+	for _, rec := range checkRecs {
+		// NENDBAL is zero on interim or unfinished reconciliations, which have no statement balance
+		if rec.AccountNo == accountNumber && rec.RecDate.Equal(date) && rec.EndBalance != 0 {
+			return rec.EndBalance, "checkrec", true
+		}
+	}
+	return 0, "", false
+}
+
+// firstRecOnOrAfter returns the first date in sorted dates that is not before d
+func firstRecOnOrAfter(dates []time.Time, d time.Time) (time.Time, bool) {
+	i := sort.Search(len(dates), func(i int) bool { return !dates[i].Before(d) })
+	if i == len(dates) {
+		return time.Time{}, false
+	}
+	return dates[i], true
+}
@@ -0,0 +1,441 @@
+package reconciliation
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// Paid item statuses. Everything except cleared and already_cleared is an exception
+// that needs someone to look at it.
+const (
+	PaidItemCleared        = "cleared"
+	PaidItemAlreadyCleared = "already_cleared"
+	PaidItemAmountMismatch = "amount_mismatch" // Possible altered check
+	PaidItemNotIssued      = "not_issued"      // Possible fraud - no such check was written
+	PaidItemPaidVoid       = "paid_void"       // A voided check cleared the bank
+	PaidItemDuplicate      = "duplicate"       // Same check number appears twice in the file
+)
+
+// PaidItem is one line of a bank "paid items" file
+type PaidItem struct {
+	LineNumber  int     `json:"line_number"`
+	CheckNumber string  `json:"check_number"`
+	Amount      float64 `json:"amount"`
+	PaidDate    string  `json:"paid_date"`
+}
+
+// PaidItemResult is the outcome of applying a paid item to CHECKS.dbf
+type PaidItemResult struct {
+	ID int `json:"id,omitempty"`
+	PaidItem
+	AccountNumber  string     `json:"account_number"`
+	Status         string     `json:"status"`
+	IsException    bool       `json:"is_exception"`
+	Message        string     `json:"message"`
+	CIDCHEC        string     `json:"cidchec"`
+	Payee          string     `json:"payee"`
+	IssuedAmount   float64    `json:"issued_amount"`
+	Difference     float64    `json:"difference"`
+	CheckDate      string     `json:"check_date"`
+	ResolvedBy     string     `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	ResolutionNote string     `json:"resolution_note,omitempty"`
+}
+
+// PaidItemsImportRequest describes a paid items file to apply
+type PaidItemsImportRequest struct {
+	CompanyName   string `json:"company_name"`
+	AccountNumber string `json:"account_number"`
+	FileName      string `json:"file_name"`
+	Content       string `json:"content"`
+	ImportedBy    string `json:"imported_by"`
+	Preview       bool   `json:"preview"` // Classify only, do not write to DBF or SQLite
+
+	// EnsureOpen, when set, is called with each paid date before anything is
+	// written and stops the import if the date's period is closed
+	EnsureOpen func(paidDate time.Time) error `json:"-"`
+}
+
+// PaidItemsImportResult summarizes an import
+type PaidItemsImportResult struct {
+	ImportBatchID  string           `json:"import_batch_id"`
+	Preview        bool             `json:"preview"`
+	TotalItems     int              `json:"total_items"`
+	ClearedCount   int              `json:"cleared_count"`
+	ExceptionCount int              `json:"exception_count"`
+	ClearedTotal   float64          `json:"cleared_total"`
+	DBFRowsUpdated int              `json:"dbf_rows_updated"`
+	Items          []PaidItemResult `json:"items"`
+	Exceptions     []PaidItemResult `json:"exceptions"`
+}
+
+// ParsePaidItems parses a paid items CSV. Column headers are matched loosely
+// (e.g. "Serial", "Check #", "Paid Amount", "Date Paid"); files without a header
+// row are read positionally as check number, amount, paid date.
+func ParsePaidItems(content string) ([]PaidItem, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimSpace(content)))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse paid items file: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("paid items file is empty")
+	}
+
+	checkIdx, amountIdx, dateIdx := -1, -1, -1
+	for i, col := range records[0] {
+		switch strings.ToLower(strings.TrimSpace(col)) {
+		case "check number", "check #", "check no", "check no.", "checkno", "check", "serial", "serial number", "serial #", "item number":
+			checkIdx = i
+		case "amount", "paid amount", "check amount", "item amount":
+			amountIdx = i
+		case "paid date", "date paid", "date", "posted date", "post date", "clear date", "cleared date", "process date":
+			dateIdx = i
+		}
+	}
+
+	start := 1
+	if checkIdx == -1 && amountIdx == -1 && dateIdx == -1 {
+		// No header row - assume check number, amount, paid date
+		checkIdx, amountIdx, dateIdx = 0, 1, 2
+		start = 0
+	}
+	if checkIdx == -1 || amountIdx == -1 || dateIdx == -1 {
+		return nil, fmt.Errorf("paid items file must have check number, amount and paid date columns")
+	}
+
+	var items []PaidItem
+	for i := start; i < len(records); i++ {
+		fields := records[i]
+		if len(fields) <= checkIdx || len(fields) <= amountIdx || len(fields) <= dateIdx {
+			continue // Skip malformed rows
+		}
+
+		checkNumber := strings.TrimSpace(fields[checkIdx])
+		if checkNumber == "" {
+			continue
+		}
+
+		amount, err := parseAmount(fields[amountIdx])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount %q", i+1, fields[amountIdx])
+		}
+
+		paidDate, ok := ledger.ParseDate(fields[dateIdx])
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid paid date %q", i+1, fields[dateIdx])
+		}
+
+		items = append(items, PaidItem{
+			LineNumber:  i + 1,
+			CheckNumber: checkNumber,
+			Amount:      amount.Abs().ToFloat64(),
+			PaidDate:    paidDate.Format("2006-01-02"),
+		})
+	}
+
+	return items, nil
+}
+
+// parseAmount parses amounts like "$1,234.56" or "(1,234.56)"
+func parseAmount(s string) (currency.Currency, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	s = strings.Trim(s, "()")
+	s = strings.ReplaceAll(s, "$", "")
+	s = strings.ReplaceAll(s, ",", "")
+	amount, err := currency.NewFromString(s)
+	if err != nil {
+		return currency.Zero(), err
+	}
+	if negative {
+		amount = amount.Neg()
+	}
+	return amount, nil
+}
+
+// ClassifyPaidItems matches paid items against the checks issued on an account.
+// It does not modify anything.
+func ClassifyPaidItems(items []PaidItem, checks []ledger.Check, accountNumber string) []PaidItemResult {
+	issued := make(map[string][]ledger.Check)
+	for _, check := range checks {
+		if check.IsDeposit() || (accountNumber != "" && check.AccountNo != accountNumber) {
+			continue
+		}
+		key := ledger.NormalizeCheckNumber(check.CheckNumber)
+		issued[key] = append(issued[key], check)
+	}
+
+	seen := make(map[string]bool)
+	results := make([]PaidItemResult, 0, len(items))
+	for _, item := range items {
+		key := ledger.NormalizeCheckNumber(item.CheckNumber)
+		result := PaidItemResult{PaidItem: item, AccountNumber: accountNumber}
+
+		if seen[key] {
+			result.Status = PaidItemDuplicate
+			result.Message = fmt.Sprintf("Check %s appears more than once in the paid items file", item.CheckNumber)
+			result.IsException = true
+			results = append(results, result)
+			continue
+		}
+		seen[key] = true
+
+		candidates := issued[key]
+		if len(candidates) == 0 {
+			result.Status = PaidItemNotIssued
+			result.Message = fmt.Sprintf("Check %s was paid by the bank but was never issued - possible fraud", item.CheckNumber)
+			result.IsException = true
+			results = append(results, result)
+			continue
+		}
+
+		match := pickIssuedCheck(candidates, item.Amount)
+		result.CIDCHEC = match.CIDCHEC
+		result.Payee = match.Payee
+		result.IssuedAmount = match.Amount
+		result.Difference = currency.NewFromFloat(item.Amount).Sub(currency.NewFromFloat(match.Amount)).ToFloat64()
+		if !match.CheckDate.IsZero() {
+			result.CheckDate = match.CheckDate.Format("2006-01-02")
+		}
+
+		switch {
+		case match.IsVoid:
+			result.Status = PaidItemPaidVoid
+			result.Message = fmt.Sprintf("Check %s was voided but cleared the bank", item.CheckNumber)
+			result.IsException = true
+		case !currency.NewFromFloat(item.Amount).Equal(currency.NewFromFloat(match.Amount)):
+			result.Status = PaidItemAmountMismatch
+			result.Message = fmt.Sprintf("Check %s paid for %.2f but was issued for %.2f - possible altered check",
+				item.CheckNumber, item.Amount, match.Amount)
+			result.IsException = true
+		case match.IsCleared:
+			result.Status = PaidItemAlreadyCleared
+			result.Message = fmt.Sprintf("Check %s is already cleared", item.CheckNumber)
+		default:
+			result.Status = PaidItemCleared
+			result.Message = fmt.Sprintf("Check %s cleared on %s", item.CheckNumber, item.PaidDate)
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// pickIssuedCheck chooses the best issued check for a paid item when a check number
+// was reused: prefer a live check with the exact amount, then any live check.
+func pickIssuedCheck(candidates []ledger.Check, amount float64) ledger.Check {
+	paid := currency.NewFromFloat(amount)
+	for _, c := range candidates {
+		if !c.IsVoid && currency.NewFromFloat(c.Amount).Equal(paid) {
+			return c
+		}
+	}
+	for _, c := range candidates {
+		if !c.IsVoid {
+			return c
+		}
+	}
+	return candidates[0]
+}
+
+// ImportPaidItems parses a paid items file, marks the matching checks cleared in
+// CHECKS.dbf and records every item (including exceptions) in SQLite
+func (s *Service) ImportPaidItems(req PaidItemsImportRequest) (*PaidItemsImportResult, error) {
+	if req.AccountNumber == "" {
+		return nil, fmt.Errorf("account number is required")
+	}
+
+	items, err := ParsePaidItems(req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	checks, err := ledger.LoadChecks(req.CompanyName)
+	if err != nil {
+		return nil, err
+	}
+
+	results := ClassifyPaidItems(items, checks, req.AccountNumber)
+
+	summary := &PaidItemsImportResult{
+		ImportBatchID: newPaidItemsBatchID(req.AccountNumber),
+		Preview:       req.Preview,
+		TotalItems:    len(results),
+		Items:         results,
+		Exceptions:    []PaidItemResult{},
+	}
+
+	clearedTotal := currency.Zero()
+	toClear := make(map[string]time.Time)
+	for _, r := range results {
+		if r.IsException {
+			summary.ExceptionCount++
+			summary.Exceptions = append(summary.Exceptions, r)
+		}
+		if r.Status == PaidItemCleared {
+			summary.ClearedCount++
+			clearedTotal = clearedTotal.Add(currency.NewFromFloat(r.Amount))
+			paidDate, err := time.Parse("2006-01-02", r.PaidDate)
+			if err != nil {
+				return nil, fmt.Errorf("invalid paid date for check %s: %s", r.CheckNumber, r.PaidDate)
+			}
+			toClear[r.CIDCHEC] = paidDate
+		}
+	}
+	summary.ClearedTotal = clearedTotal.ToFloat64()
+
+	if req.Preview {
+		return summary, nil
+	}
+
+	if req.EnsureOpen != nil {
+		checked := make(map[time.Time]bool)
+		for _, paidDate := range toClear {
+			if checked[paidDate] {
+				continue
+			}
+			checked[paidDate] = true
+			if err := req.EnsureOpen(paidDate); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Write CHECKS.dbf first so SQLite never claims a clearing that did not happen
+	written, err := ledger.MarkChecksCleared(req.CompanyName, toClear)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark checks cleared in CHECKS.dbf: %w", err)
+	}
+	summary.DBFRowsUpdated = len(written)
+	dbfUpdated := make(map[string]bool, len(written))
+	for _, id := range written {
+		dbfUpdated[id] = true
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO paid_item_imports (
+			company_name, account_number, import_batch_id, file_name, imported_by,
+			item_count, cleared_count, exception_count
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		req.CompanyName, req.AccountNumber, summary.ImportBatchID, req.FileName, req.ImportedBy,
+		summary.TotalItems, summary.ClearedCount, summary.ExceptionCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record paid items import: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO paid_items (
+			company_name, account_number, import_batch_id, check_number, paid_amount,
+			paid_date, cidchec, issued_amount, status, is_exception, dbf_updated
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare paid item insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range results {
+		var cidchec interface{}
+		if r.CIDCHEC != "" {
+			cidchec = r.CIDCHEC
+		}
+		_, err := stmt.Exec(req.CompanyName, req.AccountNumber, summary.ImportBatchID, r.CheckNumber,
+			currency.NewFromFloat(r.Amount).ToString(), r.PaidDate, cidchec,
+			currency.NewFromFloat(r.IssuedAmount).ToString(), r.Status, r.IsException,
+			r.CIDCHEC != "" && dbfUpdated[r.CIDCHEC])
+		if err != nil {
+			return nil, fmt.Errorf("failed to record paid item %s: %w", r.CheckNumber, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit paid items import: %w", err)
+	}
+
+	return summary, nil
+}
+
+// GetPaidItemExceptions lists paid item exceptions for an account
+func (s *Service) GetPaidItemExceptions(companyName, accountNumber string, includeResolved bool) ([]PaidItemResult, error) {
+	query := `
+		SELECT id, account_number, check_number, paid_amount, paid_date, COALESCE(cidchec, ''),
+			COALESCE(issued_amount, 0), status, is_exception,
+			COALESCE(resolved_by, ''), resolved_at, COALESCE(resolution_note, '')
+		FROM paid_items
+		WHERE company_name = ? AND is_exception = TRUE`
+	args := []interface{}{companyName}
+	if accountNumber != "" {
+		query += ` AND account_number = ?`
+		args = append(args, accountNumber)
+	}
+	if !includeResolved {
+		query += ` AND resolved_at IS NULL`
+	}
+	query += ` ORDER BY paid_date DESC, id DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query paid item exceptions: %w", err)
+	}
+	defer rows.Close()
+
+	exceptions := []PaidItemResult{}
+	for rows.Next() {
+		var r PaidItemResult
+		var paidDate time.Time
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(&r.ID, &r.AccountNumber, &r.CheckNumber, &r.Amount, &paidDate, &r.CIDCHEC,
+			&r.IssuedAmount, &r.Status, &r.IsException, &r.ResolvedBy, &resolvedAt, &r.ResolutionNote); err != nil {
+			return nil, fmt.Errorf("failed to scan paid item exception: %w", err)
+		}
+		r.PaidDate = paidDate.Format("2006-01-02")
+		r.Difference = currency.NewFromFloat(r.Amount).Sub(currency.NewFromFloat(r.IssuedAmount)).ToFloat64()
+		if resolvedAt.Valid {
+			r.ResolvedAt = &resolvedAt.Time
+		}
+		exceptions = append(exceptions, r)
+	}
+
+	return exceptions, rows.Err()
+}
+
+// ResolvePaidItemException marks a paid item exception as reviewed
+func (s *Service) ResolvePaidItemException(id int, resolvedBy, note string) error {
+	result, err := s.db.Exec(`
+		UPDATE paid_items
+		SET resolved_by = ?, resolved_at = CURRENT_TIMESTAMP, resolution_note = ?
+		WHERE id = ? AND is_exception = TRUE AND resolved_at IS NULL`,
+		resolvedBy, note, id)
+	if err != nil {
+		return fmt.Errorf("failed to resolve paid item exception: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("paid item exception %d not found or already resolved", id)
+	}
+	return nil
+}
+
+// newPaidItemsBatchID names an import. The random suffix keeps two imports of the
+// same account in the same second apart.
+func newPaidItemsBatchID(accountNumber string) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("paid_%d_%s_%s", time.Now().Unix(), accountNumber, hex.EncodeToString(suffix))
+}
@@ -0,0 +1,527 @@
+package reconciliation
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+)
+
+// MatchTypeTransfer marks a bank transaction that is one leg of an interbank transfer
+const MatchTypeTransfer = "transfer"
+
+// DefaultTransferWindowDays is how far apart the two legs of a transfer may post
+const DefaultTransferWindowDays = 3
+
+// transferKeywords raise confidence when they appear in a bank description
+var transferKeywords = []string{"TRANSFER", "XFER", "TRSF", "TFR", "SWEEP", "BOOK TRANSFER", "ONLINE TRANSFER"}
+
+// TransferLeg is one side of an interbank transfer, taken from bank_transactions
+type TransferLeg struct {
+	TransactionID   int       `json:"transaction_id"`
+	AccountNumber   string    `json:"account_number"`
+	StatementID     int       `json:"statement_id"`
+	TransactionDate time.Time `json:"transaction_date"`
+	Description     string    `json:"description"`
+	CheckNumber     string    `json:"check_number"`
+	Amount          float64   `json:"amount"` // Signed: negative leaves the account, positive arrives
+}
+
+// TransferCandidate is a proposed pairing of an outgoing and incoming leg
+type TransferCandidate struct {
+	From       TransferLeg `json:"from"`
+	To         TransferLeg `json:"to"`
+	Amount     float64     `json:"amount"`
+	DaysApart  int         `json:"days_apart"`
+	Confidence float64     `json:"confidence"`
+	Ambiguous  bool        `json:"ambiguous"` // Another leg offsets this one equally well
+	Reason     string      `json:"reason"`
+}
+
+// BankTransfer is a matched interbank transfer
+type BankTransfer struct {
+	ID                int       `json:"id"`
+	CompanyName       string    `json:"company_name"`
+	FromAccount       string    `json:"from_account"`
+	ToAccount         string    `json:"to_account"`
+	FromTransactionID int       `json:"from_transaction_id"`
+	ToTransactionID   int       `json:"to_transaction_id"`
+	Amount            float64   `json:"amount"`
+	FromDate          time.Time `json:"from_date"`
+	ToDate            time.Time `json:"to_date"`
+	Confidence        float64   `json:"confidence"`
+	AutoDetected      bool      `json:"auto_detected"`
+	MatchedBy         string    `json:"matched_by"`
+	MatchedAt         time.Time `json:"matched_at"`
+	// Direction and CounterpartyAccount are relative to the account the transfer was requested for
+	Direction           string `json:"direction,omitempty"` // out or in
+	CounterpartyAccount string `json:"counterparty_account,omitempty"`
+}
+
+// loadUnmatchedLegs returns unmatched transactions on active statements for the given accounts
+func (s *Service) loadUnmatchedLegs(companyName string, accounts []string) ([]TransferLeg, error) {
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(accounts)), ",")
+	args := []interface{}{companyName}
+	for _, account := range accounts {
+		args = append(args, account)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT bt.id, bt.account_number, bt.statement_id, bt.transaction_date,
+		       COALESCE(bt.description, ''), COALESCE(bt.check_number, ''), bt.amount, COALESCE(bt.transaction_type, '')
+		FROM bank_transactions bt
+		INNER JOIN bank_statements bs ON bt.statement_id = bs.id
+		WHERE bt.company_name = ? AND bt.account_number IN (`+placeholders+`)
+		  AND bs.is_active = TRUE
+		  AND bt.is_matched = FALSE
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unmatched bank transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var legs []TransferLeg
+	for rows.Next() {
+		var leg TransferLeg
+		var rawDate interface{}
+		var txnType string
+		if err := rows.Scan(&leg.TransactionID, &leg.AccountNumber, &leg.StatementID, &rawDate,
+			&leg.Description, &leg.CheckNumber, &leg.Amount, &txnType); err != nil {
+			return nil, fmt.Errorf("failed to scan bank transaction: %w", err)
+		}
+		d, ok := ledger.AsDate(rawDate)
+		if !ok {
+			continue
+		}
+		leg.TransactionDate = d
+		leg.Amount = signedBankAmount(leg.Amount, txnType)
+		legs = append(legs, leg)
+	}
+	return legs, rows.Err()
+}
+
+// signedBankAmount normalizes banks that export unsigned amounts with a type column
+func signedBankAmount(amount float64, txnType string) float64 {
+	if amount <= 0 {
+		return amount
+	}
+	switch strings.ToLower(strings.TrimSpace(txnType)) {
+	case "check", "debit", "withdrawal", "dr":
+		return -amount
+	}
+	return amount
+}
+
+// FindTransferCandidates looks across the given bank accounts for unmatched
+// transactions that offset each other (same amount, opposite sign, different
+// account) within windowDays. Each transaction appears in at most one candidate;
+// the closest pair in date wins, with transfer wording in the description as a
+// tie breaker.
+func (s *Service) FindTransferCandidates(companyName string, accounts []string, windowDays int) ([]TransferCandidate, error) {
+	if windowDays <= 0 {
+		windowDays = DefaultTransferWindowDays
+	}
+
+	legs, err := s.loadUnmatchedLegs(companyName, accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bucket incoming legs by amount in cents so each outgoing leg only looks at real offsets
+	incoming := make(map[int64][]TransferLeg)
+	var outgoing []TransferLeg
+	for _, leg := range legs {
+		cents := currency.NewFromFloat(leg.Amount).ToCents()
+		switch {
+		case cents > 0:
+			incoming[cents] = append(incoming[cents], leg)
+		case cents < 0:
+			outgoing = append(outgoing, leg)
+		}
+	}
+
+	var all []TransferCandidate
+	offsetCount := make(map[int]int) // transaction ID -> number of possible offsets
+	for _, out := range outgoing {
+		cents := -currency.NewFromFloat(out.Amount).ToCents()
+		for _, in := range incoming[cents] {
+			if in.AccountNumber == out.AccountNumber {
+				continue
+			}
+			days := int(math.Abs(in.TransactionDate.Sub(out.TransactionDate).Hours() / 24))
+			if days > windowDays {
+				continue
+			}
+			candidate := TransferCandidate{
+				From:      out,
+				To:        in,
+				Amount:    in.Amount,
+				DaysApart: days,
+			}
+			candidate.Confidence, candidate.Reason = scoreTransfer(candidate, windowDays)
+			all = append(all, candidate)
+			offsetCount[out.TransactionID]++
+			offsetCount[in.TransactionID]++
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Confidence != all[j].Confidence {
+			return all[i].Confidence > all[j].Confidence
+		}
+		if all[i].DaysApart != all[j].DaysApart {
+			return all[i].DaysApart < all[j].DaysApart
+		}
+		return all[i].From.TransactionDate.Before(all[j].From.TransactionDate)
+	})
+
+	used := make(map[int]bool)
+	candidates := []TransferCandidate{}
+	for _, c := range all {
+		if used[c.From.TransactionID] || used[c.To.TransactionID] {
+			continue
+		}
+		used[c.From.TransactionID] = true
+		used[c.To.TransactionID] = true
+		if offsetCount[c.From.TransactionID] > 1 || offsetCount[c.To.TransactionID] > 1 {
+			c.Ambiguous = true
+			c.Reason += "; other transactions offset the same amount"
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, nil
+}
+
+// scoreTransfer rates a pairing: same-day offsets with transfer wording score highest
+func scoreTransfer(c TransferCandidate, windowDays int) (float64, string) {
+	score := 0.8 - 0.1*float64(c.DaysApart)
+	reason := fmt.Sprintf("equal and opposite amounts %d day(s) apart", c.DaysApart)
+	if c.DaysApart == 0 {
+		reason = "equal and opposite amounts on the same day"
+	}
+
+	if hasTransferKeyword(c.From.Description) || hasTransferKeyword(c.To.Description) {
+		score += 0.2
+		reason += ", transfer wording in description"
+	}
+	if c.From.CheckNumber != "" || c.To.CheckNumber != "" {
+		// A check number usually means a real check, not a book transfer
+		score -= 0.2
+		reason += ", check number present"
+	}
+
+	return math.Max(0, math.Min(1, math.Round(score*100)/100)), reason
+}
+
+func hasTransferKeyword(description string) bool {
+	upper := strings.ToUpper(description)
+	for _, keyword := range transferKeywords {
+		if strings.Contains(upper, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchTransfer pairs two bank transactions as the legs of one transfer and marks
+// both as matched so they drop out of each account's unmatched list. ensureOpen,
+// when set, is called with each leg's date and stops the match if it fails.
+func (s *Service) MatchTransfer(companyName string, fromTransactionID, toTransactionID int, confidence float64, autoDetected bool, matchedBy string, ensureOpen func(time.Time) error) (*BankTransfer, error) {
+	legs := make(map[int]TransferLeg)
+	for _, id := range []int{fromTransactionID, toTransactionID} {
+		var leg TransferLeg
+		var rawDate interface{}
+		var txnType string
+		var isMatched bool
+		err := s.db.QueryRow(`
+			SELECT id, account_number, statement_id, transaction_date, COALESCE(description, ''),
+			       COALESCE(check_number, ''), amount, COALESCE(transaction_type, ''), is_matched
+			FROM bank_transactions WHERE id = ? AND company_name = ?
+		`, id, companyName).Scan(&leg.TransactionID, &leg.AccountNumber, &leg.StatementID, &rawDate,
+			&leg.Description, &leg.CheckNumber, &leg.Amount, &txnType, &isMatched)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("bank transaction %d not found", id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bank transaction %d: %w", id, err)
+		}
+		if isMatched {
+			return nil, fmt.Errorf("bank transaction %d is already matched", id)
+		}
+		leg.TransactionDate, _ = ledger.AsDate(rawDate)
+		leg.Amount = signedBankAmount(leg.Amount, txnType)
+		legs[id] = leg
+	}
+
+	from, to := legs[fromTransactionID], legs[toTransactionID]
+	if from.AccountNumber == to.AccountNumber {
+		return nil, fmt.Errorf("both transactions are in account %s", from.AccountNumber)
+	}
+	// Accept the legs in either order
+	if from.Amount > 0 && to.Amount < 0 {
+		from, to = to, from
+	}
+	if !currency.NewFromFloat(from.Amount).Add(currency.NewFromFloat(to.Amount)).IsZero() || from.Amount >= 0 {
+		return nil, fmt.Errorf("transactions do not offset: %.2f and %.2f", from.Amount, to.Amount)
+	}
+	if ensureOpen != nil {
+		for _, leg := range []TransferLeg{from, to} {
+			if err := ensureOpen(leg.TransactionDate); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO bank_transfers (
+			company_name, from_account, to_account, from_transaction_id, to_transaction_id,
+			amount, from_date, to_date, confidence, auto_detected, matched_by
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, companyName, from.AccountNumber, to.AccountNumber, from.TransactionID, to.TransactionID,
+		to.Amount, from.TransactionDate.Format("2006-01-02"), to.TransactionDate.Format("2006-01-02"),
+		confidence, autoDetected, matchedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save transfer: %w", err)
+	}
+	transferID, _ := result.LastInsertId()
+
+	for _, leg := range []TransferLeg{from, to} {
+		if _, err := tx.Exec(`
+			UPDATE bank_transactions
+			SET match_type = ?, match_confidence = ?, is_matched = TRUE, manually_matched = ?
+			WHERE id = ?
+		`, MatchTypeTransfer, confidence, !autoDetected, leg.TransactionID); err != nil {
+			return nil, fmt.Errorf("failed to mark transaction %d as transfer: %w", leg.TransactionID, err)
+		}
+		if err := updateStatementMatchedCount(tx, leg.StatementID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transfer: %w", err)
+	}
+
+	return &BankTransfer{
+		ID:                int(transferID),
+		CompanyName:       companyName,
+		FromAccount:       from.AccountNumber,
+		ToAccount:         to.AccountNumber,
+		FromTransactionID: from.TransactionID,
+		ToTransactionID:   to.TransactionID,
+		Amount:            to.Amount,
+		FromDate:          from.TransactionDate,
+		ToDate:            to.TransactionDate,
+		Confidence:        confidence,
+		AutoDetected:      autoDetected,
+		MatchedBy:         matchedBy,
+		MatchedAt:         time.Now(),
+	}, nil
+}
+
+// AutoMatchTransfers matches every unambiguous candidate at or above minConfidence
+func (s *Service) AutoMatchTransfers(companyName string, accounts []string, windowDays int, minConfidence float64, matchedBy string, ensureOpen func(time.Time) error) ([]*BankTransfer, []TransferCandidate, error) {
+	candidates, err := s.FindTransferCandidates(companyName, accounts, windowDays)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matched := []*BankTransfer{}
+	remaining := []TransferCandidate{}
+	for _, c := range candidates {
+		if c.Ambiguous || c.Confidence < minConfidence || !legsOpen(c, ensureOpen) {
+			remaining = append(remaining, c)
+			continue
+		}
+		transfer, err := s.MatchTransfer(companyName, c.From.TransactionID, c.To.TransactionID, c.Confidence, true, matchedBy, ensureOpen)
+		if err != nil {
+			return matched, remaining, err
+		}
+		matched = append(matched, transfer)
+	}
+	return matched, remaining, nil
+}
+
+// legsOpen reports whether both legs of a candidate fall in periods ensureOpen allows
+func legsOpen(c TransferCandidate, ensureOpen func(time.Time) error) bool {
+	if ensureOpen == nil {
+		return true
+	}
+	return ensureOpen(c.From.TransactionDate) == nil && ensureOpen(c.To.TransactionDate) == nil
+}
+
+// UnmatchTransfer removes a transfer and returns both legs to the unmatched list
+func (s *Service) UnmatchTransfer(companyName string, transferID int) error {
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := unmatchTransfer(tx, companyName, transferID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UnmatchAccountTransfers removes every transfer with a leg in the account, so
+// clearing an account's matches does not leave the other account half-matched.
+// Returns the number of transfers removed.
+func (s *Service) UnmatchAccountTransfers(companyName, accountNumber string) (int, error) {
+	rows, err := s.db.Query(`
+		SELECT id FROM bank_transfers
+		WHERE company_name = ? AND (from_account = ? OR to_account = ?)
+	`, companyName, accountNumber, accountNumber)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query transfers: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan transfer: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		if err := unmatchTransfer(tx, companyName, id); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to unmatch transfers: %w", err)
+	}
+	return len(ids), nil
+}
+
+// unmatchTransfer deletes a company's transfer and unmatches both legs
+func unmatchTransfer(tx *sql.Tx, companyName string, transferID int) error {
+	var fromID, toID int
+	err := tx.QueryRow(`
+		SELECT from_transaction_id, to_transaction_id FROM bank_transfers WHERE id = ? AND company_name = ?
+	`, transferID, companyName).Scan(&fromID, &toID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("transfer %d not found", transferID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load transfer: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM bank_transfers WHERE id = ? AND company_name = ?`, transferID, companyName); err != nil {
+		return fmt.Errorf("failed to delete transfer: %w", err)
+	}
+	for _, id := range []int{fromID, toID} {
+		var statementID int
+		if err := tx.QueryRow(`SELECT statement_id FROM bank_transactions WHERE id = ?`, id).Scan(&statementID); err != nil {
+			return fmt.Errorf("failed to load transaction %d: %w", id, err)
+		}
+		if _, err := tx.Exec(`
+			UPDATE bank_transactions
+			SET match_type = '', match_confidence = 0, is_matched = FALSE, manually_matched = FALSE
+			WHERE id = ?
+		`, id); err != nil {
+			return fmt.Errorf("failed to unmatch transaction %d: %w", id, err)
+		}
+		if err := updateStatementMatchedCount(tx, statementID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTransferIDForTransaction returns the transfer a bank transaction belongs to, or 0
+func (s *Service) GetTransferIDForTransaction(transactionID int) (int, error) {
+	var id int
+	err := s.db.QueryRow(`
+		SELECT id FROM bank_transfers WHERE from_transaction_id = ? OR to_transaction_id = ?
+	`, transactionID, transactionID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// GetTransfers returns matched transfers touching an account (either leg), so the
+// same transfer shows up in both accounts' reconciliations
+func (s *Service) GetTransfers(companyName, accountNumber string) ([]BankTransfer, error) {
+	rows, err := s.db.Query(`
+		SELECT id, company_name, from_account, to_account, from_transaction_id, to_transaction_id,
+		       amount, from_date, to_date, COALESCE(confidence, 0), auto_detected, matched_by, matched_at
+		FROM bank_transfers
+		WHERE company_name = ? AND (from_account = ? OR to_account = ?)
+		ORDER BY from_date DESC, id DESC
+	`, companyName, accountNumber, accountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := []BankTransfer{}
+	for rows.Next() {
+		var t BankTransfer
+		var fromDate, toDate interface{}
+		if err := rows.Scan(&t.ID, &t.CompanyName, &t.FromAccount, &t.ToAccount, &t.FromTransactionID,
+			&t.ToTransactionID, &t.Amount, &fromDate, &toDate, &t.Confidence, &t.AutoDetected,
+			&t.MatchedBy, &t.MatchedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan transfer: %w", err)
+		}
+		t.FromDate, _ = ledger.AsDate(fromDate)
+		t.ToDate, _ = ledger.AsDate(toDate)
+		if t.FromAccount == accountNumber {
+			t.Direction = "out"
+			t.CounterpartyAccount = t.ToAccount
+		} else {
+			t.Direction = "in"
+			t.CounterpartyAccount = t.FromAccount
+		}
+		transfers = append(transfers, t)
+	}
+	return transfers, rows.Err()
+}
+
+// updateStatementMatchedCount keeps bank_statements.matched_count in step, as RetryMatching does
+func updateStatementMatchedCount(tx *sql.Tx, statementID int) error {
+	_, err := tx.Exec(`
+		UPDATE bank_statements
+		SET matched_count = (
+			SELECT COUNT(*) FROM bank_transactions
+			WHERE statement_id = ? AND is_matched = TRUE
+		)
+		WHERE id = ?
+	`, statementID, statementID)
+	if err != nil {
+		return fmt.Errorf("failed to update statement matched count: %w", err)
+	}
+	return nil
+}
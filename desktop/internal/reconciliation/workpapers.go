@@ -0,0 +1,455 @@
+package reconciliation
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivoten/financialsx/desktop/internal/currency"
+	"github.com/pivoten/financialsx/desktop/internal/ledger"
+	"github.com/pivoten/financialsx/desktop/internal/periods"
+	"github.com/pivoten/financialsx/desktop/internal/reports"
+)
+
+// Where a schedule item came from
+const (
+	ItemManual      = "manual"
+	ItemGL          = "gl"
+	ItemRollForward = "rollforward"
+)
+
+// How PullGLItems groups GL lines into schedule items
+const (
+	GroupByLine      = "line"      // One item per GL line
+	GroupByReference = "reference" // Lines sharing a reference net to one item, e.g. an invoice and its amortization
+	GroupByBatch     = "batch"     // Lines sharing a batch net to one item
+)
+
+// Which GL lines PullGLItems looks at
+const (
+	PullPeriod = "period" // Groups with activity in the reconciliation period
+	PullAll    = "all"    // Every group with activity through the period end
+)
+
+// RecItem is one line of a reconciliation's supporting schedule. Amounts are
+// debit-positive; cleared items stay on the workpaper but out of the total.
+type RecItem struct {
+	ID           int        `json:"id"`
+	RecID        int        `json:"rec_id"`
+	Source       string     `json:"source"`
+	ItemDate     *time.Time `json:"item_date,omitempty"`
+	Reference    string     `json:"reference"`
+	Description  string     `json:"description"`
+	Amount       float64    `json:"amount"`
+	GLKey        string     `json:"gl_key,omitempty"`
+	OriginItemID *int       `json:"origin_item_id,omitempty"`
+	Cleared      bool       `json:"cleared"`
+	ClearedNote  string     `json:"cleared_note"`
+	AddedBy      string     `json:"added_by"`
+	AddedAt      *time.Time `json:"added_at,omitempty"`
+}
+
+// PullResult counts what PullGLItems did to the schedule
+type PullResult struct {
+	Added   int         `json:"added"`
+	Updated int         `json:"updated"`
+	Cleared int         `json:"cleared"`
+	Skipped int         `json:"skipped"` // Groups that net to zero and were never on the schedule
+	Rec     *AccountRec `json:"rec"`
+}
+
+// recItems reads a schedule in date order
+func (s *Service) recItems(recID int) ([]RecItem, error) {
+	rows, err := s.db.Query(`
+		SELECT id, rec_id, source, item_date, COALESCE(reference, ''), COALESCE(description, ''), amount,
+		       COALESCE(gl_key, ''), origin_item_id, COALESCE(cleared, 0), COALESCE(cleared_note, ''),
+		       COALESCE(added_by, ''), added_at
+		FROM account_rec_items WHERE rec_id = ? ORDER BY id`, recID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reconciliation items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []RecItem{}
+	for rows.Next() {
+		var item RecItem
+		var itemDate, addedAt interface{}
+		var origin sql.NullInt64
+		if err := rows.Scan(&item.ID, &item.RecID, &item.Source, &itemDate, &item.Reference, &item.Description, &item.Amount,
+			&item.GLKey, &origin, &item.Cleared, &item.ClearedNote, &item.AddedBy, &addedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reconciliation item: %w", err)
+		}
+		item.ItemDate, item.AddedAt = recTime(itemDate), recTime(addedAt)
+		if origin.Valid {
+			id := int(origin.Int64)
+			item.OriginItemID = &id
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortItems(items)
+	return items, nil
+}
+
+// rollForward copies the prior reconciliation's uncleared items onto a new one
+func rollForward(tx *sql.Tx, prior *AccountRec, recID int, username string) (int, error) {
+	rolled := 0
+	for _, item := range prior.Items {
+		if item.Cleared {
+			continue
+		}
+		origin := item.ID
+		if item.OriginItemID != nil {
+			origin = *item.OriginItemID
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO account_rec_items (rec_id, source, item_date, reference, description, amount, gl_key, origin_item_id, added_by)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, recID, ItemRollForward, itemDateValue(item.ItemDate), item.Reference, item.Description, item.Amount,
+			nullString(item.GLKey), origin, username); err != nil {
+			return 0, fmt.Errorf("failed to roll forward reconciliation items: %w", err)
+		}
+		rolled++
+	}
+	return rolled, nil
+}
+
+// AddRecItem adds a manual item to an open reconciliation's schedule
+func (s *Service) AddRecItem(companyName string, recID int, item RecItem, username string) (*AccountRec, error) {
+	if _, err := s.openAccountRec(companyName, recID); err != nil {
+		return nil, err
+	}
+	if err := validateRecItem(&item); err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Exec(`
+		INSERT INTO account_rec_items (rec_id, source, item_date, reference, description, amount, cleared, cleared_note, added_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, recID, ItemManual, itemDateValue(item.ItemDate), item.Reference, item.Description, item.Amount,
+		item.Cleared, item.ClearedNote, username); err != nil {
+		return nil, fmt.Errorf("failed to add reconciliation item: %w", err)
+	}
+	return s.GetAccountRec(companyName, recID)
+}
+
+// UpdateRecItem changes an item on an open reconciliation, including clearing it
+func (s *Service) UpdateRecItem(companyName string, item RecItem) (*AccountRec, error) {
+	recID, err := s.itemRecID(item.ID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.openAccountRec(companyName, recID); err != nil {
+		return nil, err
+	}
+	if err := validateRecItem(&item); err != nil {
+		return nil, err
+	}
+	if !item.Cleared {
+		item.ClearedNote = ""
+	}
+	if _, err := s.db.Exec(`
+		UPDATE account_rec_items SET item_date = ?, reference = ?, description = ?, amount = ?, cleared = ?, cleared_note = ?
+		WHERE id = ?
+	`, itemDateValue(item.ItemDate), item.Reference, item.Description, item.Amount, item.Cleared, item.ClearedNote, item.ID); err != nil {
+		return nil, fmt.Errorf("failed to update reconciliation item: %w", err)
+	}
+	return s.GetAccountRec(companyName, recID)
+}
+
+// DeleteRecItem removes an item from an open reconciliation
+func (s *Service) DeleteRecItem(companyName string, itemID int) (*AccountRec, error) {
+	recID, err := s.itemRecID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.openAccountRec(companyName, recID); err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Exec(`DELETE FROM account_rec_items WHERE id = ?`, itemID); err != nil {
+		return nil, fmt.Errorf("failed to delete reconciliation item: %w", err)
+	}
+	return s.GetAccountRec(companyName, recID)
+}
+
+func (s *Service) itemRecID(itemID int) (int, error) {
+	var recID int
+	err := s.db.QueryRow(`SELECT rec_id FROM account_rec_items WHERE id = ?`, itemID).Scan(&recID)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("reconciliation item %d not found", itemID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load reconciliation item: %w", err)
+	}
+	return recID, nil
+}
+
+func validateRecItem(item *RecItem) error {
+	item.Reference = strings.TrimSpace(item.Reference)
+	item.Description = strings.TrimSpace(item.Description)
+	item.ClearedNote = strings.TrimSpace(item.ClearedNote)
+	if item.Reference == "" && item.Description == "" {
+		return fmt.Errorf("an item needs a reference or a description")
+	}
+	amount := currency.NewFromFloat(item.Amount)
+	if amount.IsZero() {
+		return fmt.Errorf("an item needs a nonzero amount")
+	}
+	item.Amount = amount.ToFloat64()
+	return nil
+}
+
+// glGroup is the GL lines behind one pulled schedule item
+type glGroup struct {
+	key, reference, description string
+	date                        time.Time
+	total                       currency.Currency
+	inPeriod                    bool
+}
+
+// PullGLItems builds schedule items from the account's GL lines through the
+// period end. Each group's amount is its net activity to date, so an existing
+// item with the same key is brought up to date and cleared once it nets to zero,
+// e.g. a clearing account receipt and the distribution that relieved it.
+func (s *Service) PullGLItems(companyName string, recID int, scope, groupBy, username string) (*PullResult, error) {
+	rec, err := s.openAccountRec(companyName, recID)
+	if err != nil {
+		return nil, err
+	}
+	if scope == "" {
+		scope = PullPeriod
+	}
+	if groupBy == "" {
+		groupBy = GroupByLine
+	}
+	if scope != PullPeriod && scope != PullAll {
+		return nil, fmt.Errorf("unknown pull scope: %s", scope)
+	}
+	if groupBy != GroupByLine && groupBy != GroupByReference && groupBy != GroupByBatch {
+		return nil, fmt.Errorf("unknown grouping: %s", groupBy)
+	}
+	cal, err := periods.NewService(s.db).Calendar(companyName)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*glGroup)
+	var order []string
+	err = ledger.ScanGLEntries(companyName, func(e ledger.GLEntry) error {
+		if e.AccountNo != rec.AccountNumber {
+			return nil
+		}
+		fp, ok := e.FiscalPeriodIn(cal)
+		if !ok || rec.Period.Before(fp) {
+			return nil
+		}
+		key, reference, description := glItemKey(e, groupBy)
+		g, found := groups[key]
+		if !found {
+			g = &glGroup{key: key, reference: reference, description: description, date: e.Date, total: currency.Zero()}
+			groups[key] = g
+			order = append(order, key)
+		}
+		if !e.Date.IsZero() && (g.date.IsZero() || e.Date.Before(g.date)) {
+			g.date = e.Date
+		}
+		g.total = g.total.Add(e.Net())
+		g.inPeriod = g.inPeriod || fp == rec.Period
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]RecItem)
+	for _, item := range rec.Items {
+		if item.GLKey == "" {
+			continue
+		}
+		if prev, found := existing[item.GLKey]; !found || (prev.Cleared && !item.Cleared) {
+			existing[item.GLKey] = item
+		}
+	}
+
+	tx, err := s.db.GetConn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &PullResult{}
+	for _, key := range order {
+		g := groups[key]
+		item, onSchedule := existing[key]
+		if scope == PullPeriod && !g.inPeriod && !(onSchedule && !item.Cleared) {
+			continue
+		}
+		amount := g.total.ToFloat64()
+		switch {
+		case !onSchedule && g.total.IsZero():
+			result.Skipped++
+		case !onSchedule:
+			if _, err := tx.Exec(`
+				INSERT INTO account_rec_items (rec_id, source, item_date, reference, description, amount, gl_key, added_by)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			`, recID, ItemGL, itemDateValue(&g.date), g.reference, g.description, amount, key, username); err != nil {
+				return nil, fmt.Errorf("failed to add reconciliation item: %w", err)
+			}
+			result.Added++
+		case item.Cleared:
+			// Left alone unless later activity reopened it
+			if g.total.IsZero() || g.total.Equal(currency.NewFromFloat(item.Amount)) {
+				continue
+			}
+			if _, err := tx.Exec(`UPDATE account_rec_items SET amount = ?, cleared = 0, cleared_note = '' WHERE id = ?`, amount, item.ID); err != nil {
+				return nil, fmt.Errorf("failed to update reconciliation item: %w", err)
+			}
+			result.Updated++
+		case g.total.IsZero():
+			note := fmt.Sprintf("Cleared by GL activity through %s", rec.Period)
+			if _, err := tx.Exec(`UPDATE account_rec_items SET cleared = 1, cleared_note = ? WHERE id = ?`, note, item.ID); err != nil {
+				return nil, fmt.Errorf("failed to clear reconciliation item: %w", err)
+			}
+			result.Cleared++
+		case !g.total.Equal(currency.NewFromFloat(item.Amount)):
+			if _, err := tx.Exec(`UPDATE account_rec_items SET amount = ? WHERE id = ?`, amount, item.ID); err != nil {
+				return nil, fmt.Errorf("failed to update reconciliation item: %w", err)
+			}
+			result.Updated++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save reconciliation items: %w", err)
+	}
+
+	if result.Rec, err = s.GetAccountRec(companyName, recID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// glItemKey identifies the schedule item a GL line belongs to. Lines without a
+// reference or batch fall back to an item of their own.
+func glItemKey(e ledger.GLEntry, groupBy string) (key, reference, description string) {
+	reference = strings.TrimSpace(e.Reference)
+	description = strings.TrimSpace(e.Description)
+	batch := strings.TrimSpace(e.Batch)
+	switch {
+	case groupBy == GroupByReference && reference != "":
+		return "ref:" + reference, reference, description
+	case groupBy == GroupByBatch && batch != "":
+		return "batch:" + batch, batch, description
+	}
+	line := strings.TrimSpace(e.CIDGLMA)
+	if line == "" {
+		line = fmt.Sprintf("row%d", e.RowIndex)
+	}
+	return "gl:" + line, reference, description
+}
+
+func itemDateValue(d *time.Time) interface{} {
+	if d == nil || d.IsZero() {
+		return nil
+	}
+	return d.Format("2006-01-02")
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// AccountRecTable prints the reconciliation workpaper: the open items, the items
+// cleared this period, the tie-out to the GL and the sign-offs
+func AccountRecTable(rec *AccountRec, displayName string) *reports.Table {
+	title := rec.AccountNumber
+	if rec.AccountName != "" {
+		title += " " + rec.AccountName
+	}
+	t := &reports.Table{
+		CompanyName: displayName,
+		Title:       "Account Reconciliation",
+		Subtitles: []string{
+			title,
+			fmt.Sprintf("Period %s, ending %s", rec.Period, rec.PeriodEnd.Format("01/02/2006")),
+		},
+		Portrait: true,
+		Columns: []reports.Column{
+			{Header: "Date", Width: 22},
+			{Header: "Reference", Width: 30},
+			{Header: "Description", Width: 88},
+			{Header: "Source", Width: 22},
+			{Header: "Amount", Width: 33, Align: "R"},
+		},
+	}
+	if rec.Category != "" {
+		t.Subtitles = append(t.Subtitles, "Category: "+strings.ToUpper(rec.Category[:1])+rec.Category[1:])
+	}
+
+	itemRow := func(item RecItem, description string) {
+		date := ""
+		if item.ItemDate != nil {
+			date = item.ItemDate.Format("01/02/2006")
+		}
+		t.AddRow(date, item.Reference, description, itemSourceName(item.Source), reports.FormatAmount(item.Amount))
+	}
+
+	t.AddHeading("Open items")
+	var cleared []RecItem
+	for _, item := range rec.Items {
+		if item.Cleared {
+			cleared = append(cleared, item)
+			continue
+		}
+		itemRow(item, item.Description)
+	}
+	t.AddTotal("", "", "Schedule total", "", reports.FormatAmount(rec.ScheduleTotal))
+	t.AddTotal("", "", "Balance per general ledger", "", reports.FormatAmount(rec.GLBalance))
+	t.AddTotal("", "", "Unreconciled difference", "", reports.FormatAmount(rec.Difference))
+
+	if len(cleared) > 0 {
+		t.AddHeading("Cleared items")
+		for _, item := range cleared {
+			description := item.Description
+			if item.ClearedNote != "" {
+				description = strings.TrimSpace(description + " - " + item.ClearedNote)
+			}
+			itemRow(item, description)
+		}
+	}
+
+	if rec.Explanation != "" {
+		t.Notes = append(t.Notes, "Explanation of difference: "+rec.Explanation)
+	}
+	if rec.Notes != "" {
+		t.Notes = append(t.Notes, "Notes: "+rec.Notes)
+	}
+	t.Notes = append(t.Notes, "Prepared by: "+signOff(rec.PreparedBy, rec.PreparedAt))
+	t.Notes = append(t.Notes, "Reviewed by: "+signOff(rec.ReviewedBy, rec.ReviewedAt))
+	t.Notes = append(t.Notes, rec.Warnings...)
+	return t
+}
+
+func itemSourceName(source string) string {
+	switch source {
+	case ItemGL:
+		return "GL"
+	case ItemRollForward:
+		return "Prior period"
+	}
+	return "Manual"
+}
+
+func signOff(username string, at *time.Time) string {
+	if username == "" {
+		return "________________"
+	}
+	if at == nil {
+		return username
+	}
+	return fmt.Sprintf("%s on %s", username, at.Format("01/02/2006"))
+}
@@ -0,0 +1,58 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+)
+
+// RenderCSV writes the table as CSV: title and subtitles first, then the header
+// row and data rows. Indentation is kept as leading spaces in the first column.
+// DataOnly tables skip the title block and notes.
+func RenderCSV(t *Table) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if !t.DataOnly {
+		if t.Title != "" {
+			w.Write([]string{t.Title})
+		}
+		if t.CompanyName != "" {
+			w.Write([]string{t.CompanyName})
+		}
+		for _, sub := range t.Subtitles {
+			w.Write([]string{sub})
+		}
+		if t.Title != "" || len(t.Subtitles) > 0 {
+			w.Write([]string{})
+		}
+	}
+
+	headers := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		headers[i] = col.Header
+	}
+	w.Write(headers)
+
+	for _, row := range t.Rows {
+		cells := make([]string, len(row.Cells))
+		copy(cells, row.Cells)
+		if len(cells) > 0 && row.Indent > 0 {
+			cells[0] = strings.Repeat("  ", row.Indent) + cells[0]
+		}
+		w.Write(cells)
+	}
+
+	if len(t.Notes) > 0 && !t.DataOnly {
+		w.Write([]string{})
+		for _, note := range t.Notes {
+			w.Write([]string{note})
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,164 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/jung-kurt/gofpdf/v2"
+)
+
+// RenderPDF draws the table on Letter pages using the same look as the Chart of
+// Accounts report: company header, title, light gray header row and a footer
+// with the generation time and page number.
+func RenderPDF(t *Table) ([]byte, error) {
+	orientation, pageWidth := "L", 279.4
+	if t.Portrait {
+		orientation, pageWidth = "P", 215.9
+	}
+	right := pageWidth - 10
+
+	pdf := gofpdf.New(orientation, "mm", "Letter", "")
+	pdf.SetAutoPageBreak(true, 20)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "", 7)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.SetDrawColor(200, 200, 200)
+		pdf.Line(10, pdf.GetY(), right, pdf.GetY())
+		pdf.Ln(2)
+		pdf.SetX(10)
+		pdf.Cell(60, 5, "Pivoten - Financials")
+		pdf.Cell(80, 5, fmt.Sprintf("Generated: %s", time.Now().Format("January 2, 2006 3:04 PM")))
+		pageText := fmt.Sprintf("Page %d", pdf.PageNo())
+		pdf.SetX(right - pdf.GetStringWidth(pageText))
+		pdf.Cell(pdf.GetStringWidth(pageText), 5, pageText)
+	})
+
+	drawHeader := func() {
+		pdf.SetFont("Helvetica", "B", 8)
+		pdf.SetFillColor(245, 245, 245)
+		pdf.SetTextColor(40, 40, 40)
+		pdf.SetDrawColor(200, 200, 200)
+		pdf.SetLineWidth(0.2)
+		for _, col := range t.Columns {
+			pdf.CellFormat(col.Width, 7, tr(col.Header), "1", 0, align(col.Align), true, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.AddPage()
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.SetXY(10, 10)
+	pdf.Cell(0, 7, tr(t.CompanyName))
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.Cell(0, 7, tr(t.Title))
+	pdf.Ln(7)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(60, 60, 60)
+	for _, sub := range t.Subtitles {
+		pdf.Cell(0, 5, tr(sub))
+		pdf.Ln(5)
+	}
+	pdf.Ln(2)
+	pdf.SetDrawColor(200, 200, 200)
+	pdf.SetLineWidth(0.5)
+	pdf.Line(10, pdf.GetY(), right, pdf.GetY())
+	pdf.Ln(4)
+
+	drawHeader()
+
+	_, pageHeight := pdf.GetPageSize()
+	for i, row := range t.Rows {
+		if pdf.GetY() > pageHeight-28 {
+			pdf.AddPage()
+			drawHeader()
+		}
+
+		style := ""
+		if row.Bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 8)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetDrawColor(230, 230, 230)
+		switch {
+		case row.Shaded:
+			pdf.SetFillColor(235, 240, 245)
+		case i%2 == 1:
+			pdf.SetFillColor(250, 250, 250)
+		default:
+			pdf.SetFillColor(255, 255, 255)
+		}
+
+		if row.Shaded && len(row.Cells) == 1 {
+			total := 0.0
+			for _, col := range t.Columns {
+				total += col.Width
+			}
+			pdf.CellFormat(total, 6, tr(row.Cells[0]), "LR", 0, "L", true, 0, "")
+			pdf.Ln(-1)
+			continue
+		}
+
+		for c, col := range t.Columns {
+			text := ""
+			if c < len(row.Cells) {
+				text = row.Cells[c]
+			}
+			if c == 0 && row.Indent > 0 {
+				text = fmt.Sprintf("%*s%s", row.Indent*3, "", text)
+			}
+			text = fit(pdf, tr(text), col.Width-2)
+			border := "LR"
+			if row.Bold && !row.Shaded && c > 0 {
+				border = "LRT"
+			}
+			pdf.CellFormat(col.Width, 6, text, border, 0, align(col.Align), true, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.SetDrawColor(52, 73, 94)
+	pdf.Line(10, pdf.GetY(), right, pdf.GetY())
+
+	if len(t.Notes) > 0 {
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(60, 60, 60)
+		for _, note := range t.Notes {
+			pdf.MultiCell(right-10, 4, tr(note), "", "L", false)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func align(a string) string {
+	switch a {
+	case "R", "C":
+		return a
+	}
+	return "L"
+}
+
+// fit truncates text so it does not overflow its cell
+func fit(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
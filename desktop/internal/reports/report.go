@@ -0,0 +1,110 @@
+// Package reports renders tabular financial reports to PDF, CSV and Excel so each
+// report only has to build a Table instead of drawing its own pages.
+package reports
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivoten/financialsx/desktop/internal/company"
+)
+
+// Column describes one report column. Align is "L", "C" or "R"; Width is in mm
+// on a landscape Letter page (259mm usable).
+type Column struct {
+	Header string  `json:"header"`
+	Width  float64 `json:"width"`
+	Align  string  `json:"align"`
+}
+
+// Row is one report line. Bold rows are subtotals/totals; Indent shifts the first
+// cell right for hierarchy.
+type Row struct {
+	Cells  []string `json:"cells"`
+	Bold   bool     `json:"bold"`
+	Indent int      `json:"indent"`
+	Shaded bool     `json:"shaded"` // Section headings
+}
+
+// Table is a complete report ready to render
+type Table struct {
+	CompanyName string   `json:"company_name"` // Display name printed in the header
+	Title       string   `json:"title"`
+	Subtitles   []string `json:"subtitles"`
+	Columns     []Column `json:"columns"`
+	Rows        []Row    `json:"rows"`
+	Notes       []string `json:"notes"` // Printed after the table (warnings, tie-out messages)
+	Portrait    bool     `json:"portrait"`
+	DataOnly    bool     `json:"data_only"` // CSV holds only the header and data rows so it can be imported
+}
+
+// AddRow appends a plain row
+func (t *Table) AddRow(cells ...string) {
+	t.Rows = append(t.Rows, Row{Cells: cells})
+}
+
+// AddTotal appends a bold row
+func (t *Table) AddTotal(cells ...string) {
+	t.Rows = append(t.Rows, Row{Cells: cells, Bold: true})
+}
+
+// AddHeading appends a shaded section heading
+func (t *Table) AddHeading(text string) {
+	t.Rows = append(t.Rows, Row{Cells: []string{text}, Bold: true, Shaded: true})
+}
+
+// FormatAmount formats money as 1,234.56 with parentheses for negatives
+func FormatAmount(amount float64) string {
+	negative := amount < 0
+	if negative {
+		amount = -amount
+	}
+	s := fmt.Sprintf("%.2f", amount)
+	whole, cents := s[:len(s)-3], s[len(s)-3:]
+
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteRune(',')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString(cents)
+
+	if negative {
+		return "(" + b.String() + ")"
+	}
+	return b.String()
+}
+
+// FormatPercent formats a ratio (0.125) as 12.5%
+func FormatPercent(ratio float64) string {
+	return fmt.Sprintf("%.1f%%", ratio*100)
+}
+
+// CompanyDisplayName returns CPRODUCER from VERSION.DBF, falling back to the folder name
+func CompanyDisplayName(companyName string) string {
+	data, err := company.ReadDBFFile(companyName, "VERSION.DBF", "", 0, 1, "", "")
+	if err != nil {
+		return companyName
+	}
+	columns, _ := data["columns"].([]string)
+	rows, _ := data["rows"].([][]interface{})
+	if len(rows) == 0 {
+		return companyName
+	}
+	for i, col := range columns {
+		if strings.EqualFold(col, "CPRODUCER") && i < len(rows[0]) && rows[0][i] != nil {
+			if name := strings.TrimSpace(fmt.Sprintf("%v", rows[0][i])); name != "" {
+				return name
+			}
+		}
+	}
+	return companyName
+}
+
+// SafeFileName replaces characters that are not allowed in file names
+func SafeFileName(name string) string {
+	replacer := strings.NewReplacer("\\", "_", "/", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+	return replacer.Replace(name)
+}
@@ -0,0 +1,236 @@
+package reports
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Cell styles, indexes into cellXfs in xlsxStyles
+const (
+	styleText = iota
+	styleBold
+	styleAmount
+	styleAmountBold
+	stylePercent
+	stylePercentBold
+	styleHeading
+)
+
+var (
+	amountCell  = regexp.MustCompile(`^\(?-?[0-9][0-9,]*\.[0-9]{2}\)?$`)
+	percentCell = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?%$`)
+)
+
+// RenderXLSX writes the table as a single-sheet Excel workbook laid out like the
+// CSV. Cells formatted by FormatAmount and FormatPercent are written as numbers
+// so they can be summed in Excel.
+func RenderXLSX(t *Table) ([]byte, error) {
+	var sheet strings.Builder
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if len(t.Columns) > 0 {
+		sheet.WriteString("<cols>")
+		for i, col := range t.Columns {
+			width := col.Width / 2
+			if width < 8 {
+				width = 8
+			}
+			fmt.Fprintf(&sheet, `<col min="%d" max="%d" width="%.1f" customWidth="1"/>`, i+1, i+1, width)
+		}
+		sheet.WriteString("</cols>")
+	}
+	sheet.WriteString("<sheetData>")
+
+	rowNum := 0
+	writeRow := func(cells []string, bold, heading bool) {
+		rowNum++
+		fmt.Fprintf(&sheet, `<row r="%d">`, rowNum)
+		for i, text := range cells {
+			if text == "" {
+				continue
+			}
+			ref := fmt.Sprintf("%s%d", columnName(i), rowNum)
+			style := styleText
+			if bold {
+				style = styleBold
+			}
+			if heading {
+				style = styleHeading
+			}
+			if n, ok := parseAmountCell(text); ok {
+				style = styleAmount
+				if bold {
+					style = styleAmountBold
+				}
+				fmt.Fprintf(&sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(n, 'f', -1, 64))
+				continue
+			}
+			if percentCell.MatchString(text) {
+				if n, err := strconv.ParseFloat(strings.TrimSuffix(text, "%"), 64); err == nil {
+					style = stylePercent
+					if bold {
+						style = stylePercentBold
+					}
+					fmt.Fprintf(&sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(n/100, 'f', -1, 64))
+					continue
+				}
+			}
+			fmt.Fprintf(&sheet, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+			xml.EscapeText(&sheet, []byte(text))
+			sheet.WriteString(`</t></is></c>`)
+		}
+		sheet.WriteString("</row>")
+	}
+
+	if !t.DataOnly {
+		if t.Title != "" {
+			writeRow([]string{t.Title}, true, false)
+		}
+		if t.CompanyName != "" {
+			writeRow([]string{t.CompanyName}, false, false)
+		}
+		for _, sub := range t.Subtitles {
+			writeRow([]string{sub}, false, false)
+		}
+		if t.Title != "" || len(t.Subtitles) > 0 {
+			writeRow(nil, false, false)
+		}
+	}
+
+	headers := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		headers[i] = col.Header
+	}
+	writeRow(headers, true, false)
+
+	for _, row := range t.Rows {
+		cells := make([]string, len(row.Cells))
+		copy(cells, row.Cells)
+		if len(cells) > 0 && row.Indent > 0 {
+			cells[0] = strings.Repeat("  ", row.Indent) + cells[0]
+		}
+		writeRow(cells, row.Bold, row.Shaded)
+	}
+
+	if len(t.Notes) > 0 && !t.DataOnly {
+		writeRow(nil, false, false)
+		for _, note := range t.Notes {
+			writeRow([]string{note}, false, false)
+		}
+	}
+	sheet.WriteString("</sheetData></worksheet>")
+
+	sheetName := t.Title
+	if sheetName == "" {
+		sheetName = "Report"
+	}
+	var name bytes.Buffer
+	xml.EscapeText(&name, []byte(sheetTitle(sheetName)))
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, name.String())},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+	for _, p := range parts {
+		w, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(p.body)); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseAmountCell reads a FormatAmount value: 1,234.56 or (1,234.56)
+func parseAmountCell(text string) (float64, bool) {
+	if !amountCell.MatchString(text) {
+		return 0, false
+	}
+	negative := strings.HasPrefix(text, "(")
+	n, err := strconv.ParseFloat(strings.NewReplacer(",", "", "(", "", ")", "").Replace(text), 64)
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		n = -n
+	}
+	return n, true
+}
+
+// columnName converts a 0-based column index to its letters (A, B, ... AA)
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// sheetTitle makes a worksheet name Excel accepts: at most 31 characters and
+// none of : \ / ? * [ ]
+func sheetTitle(title string) string {
+	title = strings.NewReplacer(":", " ", "\\", " ", "/", " ", "?", " ", "*", " ", "[", "(", "]", ")").Replace(title)
+	if r := []rune(title); len(r) > 31 {
+		title = string(r[:31])
+	}
+	return strings.TrimSpace(title)
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles defines the cell styles in the order of the style constants
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="#,##0.00;(#,##0.00)"/><numFmt numFmtId="165" formatCode="0.0%"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FFE6E6E6"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="7">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="164" fontId="1" fillId="0" borderId="0" xfId="0" applyNumberFormat="1" applyFont="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="1" fillId="0" borderId="0" xfId="0" applyNumberFormat="1" applyFont="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>` +
+	`</cellXfs></styleSheet>`